@@ -0,0 +1,66 @@
+// Command grant-role grants a role to an existing user. Operators use it to
+// bootstrap the first administrator, who can then manage roles over the API:
+//
+//	go run ./cmd/grant-role -email ops@example.com -role admin
+//
+// The user must already have registered; run it only for an account whose
+// owner you have confirmed.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/database"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the registered user")
+	role := flag.String("role", auth.RoleAdmin, "role to grant (viewer, analyst, data-editor, admin)")
+	flag.Parse()
+
+	if *email == "" || !auth.IsValidRole(*role) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := database.InitPostgreSQL()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to PostgreSQL:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := grantRole(ctx, models.NewUserRepository(db), *email, *role); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func grantRole(ctx context.Context, users *models.UserRepository, email, role string) error {
+	user, err := users.GetUserByEmail(ctx, email)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no user registered with email %s", email)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	roles, err := users.GrantRole(ctx, user.ID, role)
+	if err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	// Existing access tokens keep their roles until they are refreshed
+	fmt.Printf("Granted %s to user %d (%s); roles are now %v. The user must log in again to use it.\n", role, user.ID, user.Email, roles)
+	return nil
+}
@@ -1,26 +1,35 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"ethosview-backend/internal/server"
 	"ethosview-backend/pkg/database"
+	"ethosview-backend/pkg/logging"
 )
 
 func main() {
+	// Log JSON at LOG_LEVEL; the standard log package writes through it too
+	logger := logging.FromEnv()
+	slog.SetDefault(logger.Logger)
+
 	// Initialize database connections
 	db, err := database.InitPostgreSQL()
 	if err != nil {
-		log.Fatal("Failed to connect to PostgreSQL:", err)
+		logger.Error("Failed to connect to PostgreSQL", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
 
 	redisClient, err := database.InitRedis()
 	if err != nil {
-		log.Fatal("Failed to connect to Redis:", err)
+		logger.Error("Failed to connect to Redis", "error", err)
+		db.Close()
+		os.Exit(1)
 	}
-	defer redisClient.Close()
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -28,10 +37,32 @@ func main() {
 		port = "8080"
 	}
 
+	// Shut down gracefully on SIGINT or SIGTERM; a second signal exits at once
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	// Initialize and start server
-	srv := server.NewServer(db, redisClient)
-	log.Printf("Starting server on port %s", port)
-	if err := srv.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := server.NewServer(db, redisClient, logger)
+	logger.Info("Starting server", "port", port)
+	runErr := srv.Run(ctx, ":"+port)
+	if runErr != nil {
+		logger.Error("Server stopped with error", "error", runErr)
+	}
+
+	// Close connections once nothing uses them: Redis first, then the
+	// database the last alert and webhook writes went to
+	if err := redisClient.Close(); err != nil {
+		logger.Error("Failed to close Redis", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		logger.Error("Failed to close PostgreSQL", "error", err)
+	}
+
+	if runErr != nil {
+		os.Exit(1)
 	}
+	logger.Info("Server stopped")
 }
@@ -6,7 +6,7 @@ export function Footer() {
   const [metrics, setMetrics] = useState<{ timestamp?: string } | null>(null);
   useEffect(() => {
     let alive = true;
-    fetch(`${API_BASE_URL}/metrics`).then(r => (r.ok ? r.json() : null)).then((m) => { if (alive) setMetrics(m); }).catch(() => {});
+    fetch(`${API_BASE_URL}/metrics/json`).then(r => (r.ok ? r.json() : null)).then((m) => { if (alive) setMetrics(m); }).catch(() => {});
     return () => { alive = false; };
   }, []);
   return (
@@ -0,0 +1,169 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/webhooks"
+	"ethosview-backend/internal/websocket"
+)
+
+// Channel names rules may deliver over
+const (
+	ChannelWebSocket = "websocket"
+	ChannelEmail     = "email"
+	ChannelWebhook   = "webhook"
+)
+
+// ChannelNames are the channels rules may choose, whether or not this server
+// has each one configured
+var ChannelNames = []string{ChannelWebSocket, ChannelEmail, ChannelWebhook}
+
+// IsChannel reports whether name is a known channel
+func IsChannel(name string) bool {
+	for _, channel := range ChannelNames {
+		if channel == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Channel delivers triggered alert rules to their owner
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, notification events.AlertRuleTriggered) error
+}
+
+// WebSocketChannel pushes notifications to the owner's open WebSocket connections
+type WebSocketChannel struct {
+	manager *websocket.Manager
+}
+
+// NewWebSocketChannel creates a WebSocket channel
+func NewWebSocketChannel(manager *websocket.Manager) *WebSocketChannel {
+	return &WebSocketChannel{manager: manager}
+}
+
+// Name returns the channel name
+func (c *WebSocketChannel) Name() string {
+	return ChannelWebSocket
+}
+
+// Send delivers the notification as a user_alert message to every instance
+func (c *WebSocketChannel) Send(ctx context.Context, notification events.AlertRuleTriggered) error {
+	c.manager.BroadcastToUser(notification.UserID, "user_alert", notification)
+	return nil
+}
+
+// WebhookChannel delivers notifications to the owner's webhook endpoints
+// subscribed to alert.rule.triggered
+type WebhookChannel struct {
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookChannel creates a webhook channel
+func NewWebhookChannel(dispatcher *webhooks.Dispatcher) *WebhookChannel {
+	return &WebhookChannel{dispatcher: dispatcher}
+}
+
+// Name returns the channel name
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send queues a signed delivery; retries are handled by the webhook worker
+func (c *WebhookChannel) Send(ctx context.Context, notification events.AlertRuleTriggered) error {
+	event := events.NewEvent(events.TypeAlertRuleTriggered, events.AlertRuleTriggeredVersion, notification)
+	return c.dispatcher.DeliverToUser(ctx, notification.UserID, event)
+}
+
+// SMTPConfig configures outgoing email
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPConfigFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and
+// SMTP_FROM. ok is false when SMTP_HOST is unset.
+func SMTPConfigFromEnv() (config SMTPConfig, ok bool) {
+	config = SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.From == "" {
+		config.From = "alerts@ethosview.com"
+	}
+	return config, config.Host != ""
+}
+
+// EmailLookup returns the email address of a user
+type EmailLookup func(ctx context.Context, userID int) (string, error)
+
+// EmailChannel emails notifications to the owner over SMTP
+type EmailChannel struct {
+	config   SMTPConfig
+	lookup   EmailLookup
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailChannel creates an email channel
+func NewEmailChannel(config SMTPConfig, lookup EmailLookup) *EmailChannel {
+	return &EmailChannel{config: config, lookup: lookup, sendMail: smtp.SendMail}
+}
+
+// Name returns the channel name
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send emails the notification to the rule owner
+func (c *EmailChannel) Send(ctx context.Context, notification events.AlertRuleTriggered) error {
+	to, err := c.lookup(ctx, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("looking up email for user %d: %w", notification.UserID, err)
+	}
+
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+
+	addr := net.JoinHostPort(c.config.Host, c.config.Port)
+	return c.sendMail(addr, auth, c.config.From, []string{to}, emailMessage(c.config.From, to, notification))
+}
+
+// emailMessage formats a plain-text notification email
+func emailMessage(from, to string, notification events.AlertRuleTriggered) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerSafe(fmt.Sprintf("[EthosView] %s: %s", notification.RuleName, notification.CompanySymbol)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "%s\r\n\r\n", notification.Message)
+	fmt.Fprintf(&b, "Rule: %s (%s)\r\n", notification.RuleName, notification.RuleType)
+	fmt.Fprintf(&b, "Value: %.2f, threshold: %.2f\r\n", notification.Value, notification.Threshold)
+	fmt.Fprintf(&b, "Triggered at: %s\r\n", notification.TriggeredAt.UTC().Format("2006-01-02 15:04 MST"))
+	return []byte(b.String())
+}
+
+// headerSafe strips line breaks so user-supplied text cannot inject headers
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,78 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"ethosview-backend/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailChannelSendsToRuleOwner(t *testing.T) {
+	var sentTo []string
+	var sentAddr string
+	var message string
+	channel := NewEmailChannel(
+		SMTPConfig{Host: "mail.local", Port: "2525", From: "alerts@ethosview.com"},
+		func(ctx context.Context, userID int) (string, error) {
+			require.Equal(t, 3, userID)
+			return "owner@example.com", nil
+		},
+	)
+	channel.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr, sentTo, message = addr, to, string(msg)
+		assert.Nil(t, auth)
+		return nil
+	}
+
+	err := channel.Send(context.Background(), events.AlertRuleTriggered{
+		UserID:        3,
+		RuleName:      "Governance\r\nBcc: attacker@example.com",
+		CompanySymbol: "AAPL",
+		Message:       "AAPL governance score is 55.0, below 60.0",
+		TriggeredAt:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "mail.local:2525", sentAddr)
+	assert.Equal(t, []string{"owner@example.com"}, sentTo)
+	assert.Contains(t, message, "AAPL governance score is 55.0, below 60.0")
+
+	headers := message[:strings.Index(message, "\r\n\r\n")]
+	assert.NotContains(t, headers, "\r\nBcc:")
+	assert.Contains(t, headers, "Subject: [EthosView] Governance  Bcc: attacker@example.com: AAPL")
+}
+
+func TestEmailChannelReportsLookupFailure(t *testing.T) {
+	channel := NewEmailChannel(SMTPConfig{Host: "mail.local", Port: "25"}, func(context.Context, int) (string, error) {
+		return "", errors.New("no such user")
+	})
+	channel.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		t.Fatal("mail sent without a recipient")
+		return nil
+	}
+
+	assert.Error(t, channel.Send(context.Background(), events.AlertRuleTriggered{UserID: 1}))
+}
+
+func TestSMTPConfigFromEnv(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	_, ok := SMTPConfigFromEnv()
+	assert.False(t, ok)
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	config, ok := SMTPConfigFromEnv()
+	assert.True(t, ok)
+	assert.Equal(t, "587", config.Port)
+}
+
+func TestIsChannel(t *testing.T) {
+	assert.True(t, IsChannel(ChannelEmail))
+	assert.False(t, IsChannel("sms"))
+}
@@ -0,0 +1,111 @@
+package alerting
+
+import (
+	"fmt"
+	"strconv"
+
+	"ethosview-backend/internal/models"
+)
+
+// ESG metrics rules may watch; price rules always watch the close
+const (
+	MetricOverall       = "overall"
+	MetricEnvironmental = "environmental"
+	MetricSocial        = "social"
+	MetricGovernance    = "governance"
+	MetricClose         = "close"
+)
+
+// IsESGMetric reports whether metric names an ESG score
+func IsESGMetric(metric string) bool {
+	switch metric {
+	case MetricOverall, MetricEnvironmental, MetricSocial, MetricGovernance:
+		return true
+	}
+	return false
+}
+
+// companyData is what rules are evaluated against for one company. ESG and
+// Price are only loaded when a rule needs them and may be nil.
+type companyData struct {
+	Symbol string
+	ESG    *models.ESGComparison
+	Price  *models.LatestPrice
+}
+
+// trigger describes a rule whose condition holds. dedupKey identifies the data
+// that satisfied it so the same score or price never notifies twice.
+type trigger struct {
+	value    float64
+	dedupKey string
+	message  string
+}
+
+// check evaluates a rule against a company's data, returning nil when the
+// condition does not hold or the data it needs is missing
+func check(rule *models.AlertRule, data companyData) *trigger {
+	switch rule.RuleType {
+	case models.AlertRuleESGBelow:
+		if data.ESG == nil || data.ESG.Current == nil {
+			return nil
+		}
+		current := data.ESG.Current
+		value := metricValue(current, rule.Metric)
+		if value >= rule.Threshold {
+			return nil
+		}
+		return &trigger{
+			value:    value,
+			dedupKey: "esg:" + strconv.Itoa(current.ID),
+			message:  fmt.Sprintf("%s %s score is %.1f, below %.1f", data.Symbol, rule.Metric, value, rule.Threshold),
+		}
+
+	case models.AlertRuleESGDrop:
+		if data.ESG == nil || data.ESG.Current == nil || data.ESG.Previous == nil {
+			return nil
+		}
+		current, previous := data.ESG.Current, data.ESG.Previous
+		drop := metricValue(previous, rule.Metric) - metricValue(current, rule.Metric)
+		if drop <= rule.Threshold {
+			return nil
+		}
+		return &trigger{
+			value:    drop,
+			dedupKey: "esg:" + strconv.Itoa(current.ID),
+			message: fmt.Sprintf("%s %s score fell %.1f points since %s (%.1f to %.1f)",
+				data.Symbol, rule.Metric, drop, previous.ScoreDate.Format("2006-01-02"),
+				metricValue(previous, rule.Metric), metricValue(current, rule.Metric)),
+		}
+
+	case models.AlertRulePriceDrop:
+		price := data.Price
+		if price == nil || price.PreviousClose == nil || *price.PreviousClose <= 0 {
+			return nil
+		}
+		fall := (*price.PreviousClose - price.ClosePrice) / *price.PreviousClose * 100
+		if fall <= rule.Threshold {
+			return nil
+		}
+		date := price.Date.Format("2006-01-02")
+		return &trigger{
+			value:    fall,
+			dedupKey: "price:" + date,
+			message:  fmt.Sprintf("%s fell %.2f%% on %s to %.2f", data.Symbol, fall, date, price.ClosePrice),
+		}
+	}
+	return nil
+}
+
+// metricValue returns the named pillar of an ESG score
+func metricValue(score *models.ESGScore, metric string) float64 {
+	switch metric {
+	case MetricEnvironmental:
+		return score.EnvironmentalScore
+	case MetricSocial:
+		return score.SocialScore
+	case MetricGovernance:
+		return score.GovernanceScore
+	default:
+		return score.OverallScore
+	}
+}
@@ -0,0 +1,70 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func esgScore(id int, date string, overall, governance float64) *models.ESGScore {
+	scoreDate, _ := time.Parse("2006-01-02", date)
+	return &models.ESGScore{ID: id, ScoreDate: scoreDate, OverallScore: overall, GovernanceScore: governance}
+}
+
+func TestCheckESGBelow(t *testing.T) {
+	rule := &models.AlertRule{RuleType: models.AlertRuleESGBelow, Metric: MetricGovernance, Threshold: 60}
+	data := companyData{Symbol: "AAPL", ESG: &models.ESGComparison{Current: esgScore(7, "2024-06-30", 80, 55)}}
+
+	triggered := check(rule, data)
+	require.NotNil(t, triggered)
+	assert.Equal(t, 55.0, triggered.value)
+	assert.Equal(t, "esg:7", triggered.dedupKey)
+	assert.Contains(t, triggered.message, "AAPL governance score is 55.0")
+
+	rule.Metric = MetricOverall
+	assert.Nil(t, check(rule, data))
+	assert.Nil(t, check(rule, companyData{Symbol: "AAPL"}))
+}
+
+func TestCheckESGDrop(t *testing.T) {
+	rule := &models.AlertRule{RuleType: models.AlertRuleESGDrop, Metric: MetricOverall, Threshold: 10}
+	data := companyData{Symbol: "XOM", ESG: &models.ESGComparison{
+		Current:  esgScore(9, "2024-06-30", 58, 0),
+		Previous: esgScore(4, "2024-03-31", 70, 0),
+	}}
+
+	triggered := check(rule, data)
+	require.NotNil(t, triggered)
+	assert.Equal(t, 12.0, triggered.value)
+	assert.Equal(t, "esg:9", triggered.dedupKey)
+	assert.Contains(t, triggered.message, "since 2024-03-31")
+
+	rule.Threshold = 12
+	assert.Nil(t, check(rule, data), "a drop equal to the threshold does not trigger")
+
+	data.ESG.Previous = nil
+	assert.Nil(t, check(rule, data))
+}
+
+func TestCheckPriceDrop(t *testing.T) {
+	previous := 200.0
+	price := &models.LatestPrice{PreviousClose: &previous}
+	price.Date = time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	price.ClosePrice = 188
+	rule := &models.AlertRule{RuleType: models.AlertRulePriceDrop, Metric: MetricClose, Threshold: 5}
+
+	triggered := check(rule, companyData{Symbol: "TSLA", Price: price})
+	require.NotNil(t, triggered)
+	assert.InDelta(t, 6.0, triggered.value, 1e-9)
+	assert.Equal(t, "price:2024-05-02", triggered.dedupKey)
+
+	price.ClosePrice = 195
+	assert.Nil(t, check(rule, companyData{Symbol: "TSLA", Price: price}))
+
+	price.PreviousClose = nil
+	assert.Nil(t, check(rule, companyData{Symbol: "TSLA", Price: price}))
+}
@@ -0,0 +1,236 @@
+// Package alerting evaluates user-defined ESG and price alert rules and
+// notifies rule owners over WebSocket, email and webhook channels.
+package alerting
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/logging"
+)
+
+const (
+	// queueSize bounds companies waiting for evaluation after writes; the
+	// scheduled pass picks up any that are dropped
+	queueSize = 256
+
+	sendTimeout = 15 * time.Second
+)
+
+var (
+	esgRuleTypes   = []string{models.AlertRuleESGBelow, models.AlertRuleESGDrop}
+	priceRuleTypes = []string{models.AlertRulePriceDrop}
+	allRuleTypes   = []string{models.AlertRuleESGBelow, models.AlertRuleESGDrop, models.AlertRulePriceDrop}
+)
+
+// evaluation asks for a company's rules of some types to be evaluated
+type evaluation struct {
+	companyID int
+	ruleTypes []string
+}
+
+// Evaluator checks alert rules after ESG and price writes and on a schedule.
+// A rule notifies at most once per triggering score or price, and not again
+// for the same company within its cooldown.
+type Evaluator struct {
+	rules     *models.AlertRuleRepository
+	prices    *models.FinancialImportRepository
+	companies *models.CompanyRepository
+	channels  map[string]Channel
+	pending   chan evaluation
+	logger    *slog.Logger
+}
+
+// NewEvaluator creates an evaluator delivering over the given channels. Rules
+// naming a channel that is not configured skip it. A nil logger uses the
+// default.
+func NewEvaluator(db *sql.DB, logger *slog.Logger, channels ...Channel) *Evaluator {
+	byName := make(map[string]Channel)
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return &Evaluator{
+		rules:     models.NewAlertRuleRepository(db),
+		prices:    models.NewFinancialImportRepository(db),
+		companies: models.NewCompanyRepository(db),
+		channels:  byName,
+		pending:   make(chan evaluation, queueSize),
+		logger:    logging.Or(logger).With("component", "alert_rules"),
+	}
+}
+
+// Subscribe evaluates a company's rules when its ESG score or price changes
+func (e *Evaluator) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.TypeESGScoreUpdated, func(event events.Event) {
+		e.queue(event.Data.(events.ESGScoreUpdated).CompanyID, esgRuleTypes)
+	})
+	bus.Subscribe(events.TypePriceTick, func(event events.Event) {
+		e.queue(event.Data.(events.PriceTick).CompanyID, priceRuleTypes)
+	})
+}
+
+// Run evaluates queued companies as they arrive, and every rule each interval,
+// until ctx is cancelled
+func (e *Evaluator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.EvaluateAll(ctx); err != nil {
+				e.logger.Error("Error evaluating alert rules", "error", err)
+			}
+		case next := <-e.pending:
+			if err := e.EvaluateCompany(ctx, next.companyID, next.ruleTypes); err != nil {
+				e.logger.Error("Error evaluating alert rules for company", "company_id", next.companyID, "error", err)
+			}
+		}
+	}
+}
+
+// EvaluateAll evaluates every active rule
+func (e *Evaluator) EvaluateAll(ctx context.Context) error {
+	companyIDs, err := e.rules.CompaniesWithActiveRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, companyID := range companyIDs {
+		if err := e.EvaluateCompany(ctx, companyID, allRuleTypes); err != nil {
+			e.logger.Error("Error evaluating alert rules for company", "company_id", companyID, "error", err)
+		}
+	}
+	return nil
+}
+
+// EvaluateCompany evaluates the active rules of the given types that apply to a company
+func (e *Evaluator) EvaluateCompany(ctx context.Context, companyID int, ruleTypes []string) error {
+	rules, err := e.rules.RulesForCompany(ctx, companyID, ruleTypes)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	data, err := e.loadCompanyData(ctx, companyID, rules)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		t := check(rule, data)
+		if t == nil {
+			continue
+		}
+
+		notification := &models.AlertNotification{
+			CompanyID:     companyID,
+			CompanySymbol: data.Symbol,
+			DedupKey:      t.dedupKey,
+			Message:       t.message,
+			Value:         t.value,
+			Threshold:     rule.Threshold,
+		}
+		recorded, err := e.rules.RecordNotification(ctx, rule, notification)
+		if err != nil {
+			e.logger.Error("Error recording alert rule notification", "rule_id", rule.ID, "company_id", companyID, "error", err)
+			continue
+		}
+		if recorded {
+			e.notify(ctx, rule, notification)
+		}
+	}
+	return nil
+}
+
+// queue schedules a company for evaluation without blocking event dispatch
+func (e *Evaluator) queue(companyID int, ruleTypes []string) {
+	select {
+	case e.pending <- evaluation{companyID: companyID, ruleTypes: ruleTypes}:
+	default:
+		e.logger.Warn("Alert rule queue full, deferring company to the scheduled pass", "company_id", companyID)
+	}
+}
+
+// loadCompanyData loads the data the rules need
+func (e *Evaluator) loadCompanyData(ctx context.Context, companyID int, rules []*models.AlertRule) (companyData, error) {
+	var data companyData
+
+	company, err := e.companies.GetCompanyByID(ctx, companyID)
+	if err != nil {
+		return data, err
+	}
+	data.Symbol = company.Symbol
+
+	var needESG, needPrice bool
+	for _, rule := range rules {
+		if rule.RuleType == models.AlertRulePriceDrop {
+			needPrice = true
+		} else {
+			needESG = true
+		}
+	}
+
+	if needESG {
+		if data.ESG, err = e.rules.ESGComparison(ctx, companyID); err != nil {
+			return data, err
+		}
+	}
+	if needPrice {
+		prices, err := e.prices.LatestPrices(ctx, []int{companyID})
+		if err != nil {
+			return data, err
+		}
+		if len(prices) > 0 {
+			data.Price = prices[0]
+		}
+	}
+	return data, nil
+}
+
+// notify delivers a recorded notification over the rule's channels and
+// records which succeeded
+func (e *Evaluator) notify(ctx context.Context, rule *models.AlertRule, notification *models.AlertNotification) {
+	payload := events.AlertRuleTriggered{
+		NotificationID: notification.ID,
+		RuleID:         rule.ID,
+		RuleName:       rule.Name,
+		RuleType:       rule.RuleType,
+		UserID:         rule.UserID,
+		CompanyID:      notification.CompanyID,
+		CompanySymbol:  notification.CompanySymbol,
+		Metric:         rule.Metric,
+		Value:          notification.Value,
+		Threshold:      notification.Threshold,
+		Message:        notification.Message,
+		TriggeredAt:    notification.TriggeredAt,
+	}
+
+	delivered := []string{}
+	for _, name := range rule.Channels {
+		channel, ok := e.channels[name]
+		if !ok {
+			e.logger.Warn("Alert rule channel is not configured", "rule_id", rule.ID, "user_id", rule.UserID, "channel", name)
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := channel.Send(sendCtx, payload)
+		cancel()
+		if err != nil {
+			e.logger.Error("Error sending alert rule notification", "rule_id", rule.ID, "user_id", rule.UserID, "channel", name, "error", err)
+			continue
+		}
+		delivered = append(delivered, name)
+	}
+
+	if err := e.rules.SetNotificationChannels(ctx, notification.ID, delivered); err != nil {
+		e.logger.Error("Error recording alert notification channels", "notification_id", notification.ID, "error", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package events is the in-process domain event bus. Handlers publish typed
+// events after a write commits; subscribers such as the WebSocket forwarder
+// consume them without the publishers knowing who is listening.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"ethosview-backend/pkg/logging"
+)
+
+// queueSize bounds events waiting for dispatch; publishes beyond it are dropped
+const queueSize = 1024
+
+// Event is the envelope for every domain event. Version is the schema version
+// of Data for this Type; consumers should ignore versions they do not know.
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Version    int         `json:"version"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Handler consumes an event
+type Handler func(Event)
+
+// Bus dispatches events to subscribers on a single goroutine, preserving
+// publish order. A nil *Bus discards events, so publishers need no checks.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	queue    chan Event
+	logger   *slog.Logger
+}
+
+// NewBus creates an event bus; call Run to start dispatching. A nil logger
+// uses the default.
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		queue:    make(chan Event, queueSize),
+		logger:   logging.Or(logger).With("component", "event_bus"),
+	}
+}
+
+// Subscribe registers a handler for an event type, or for every type with "*"
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish queues an event for dispatch without blocking the caller
+func (b *Bus) Publish(eventType string, version int, data interface{}) {
+	if b == nil {
+		return
+	}
+	b.PublishEvents(NewEvent(eventType, version, data))
+}
+
+// PublishEvents queues events that were built with NewEvent, such as those
+// recorded in the event outbox, without blocking the caller
+func (b *Bus) PublishEvents(evts ...Event) {
+	if b == nil {
+		return
+	}
+
+	for _, event := range evts {
+		select {
+		case b.queue <- event:
+		default:
+			b.logger.Warn("Event queue full, dropping event", "event_type", event.Type, "event_id", event.ID)
+		}
+	}
+}
+
+// NewEvent wraps a payload in a new event envelope. Publish does this itself;
+// use it to record an event in the outbox or deliver it outside the bus.
+func NewEvent(eventType string, version int, data interface{}) Event {
+	return Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Run dispatches queued events until ctx is cancelled, then dispatches the
+// events still queued before returning
+func (b *Bus) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			b.drain()
+			return
+		case event := <-b.queue:
+			b.dispatch(event)
+		}
+	}
+}
+
+// drain dispatches the events already queued
+func (b *Bus) drain() {
+	for {
+		select {
+		case event := <-b.queue:
+			b.dispatch(event)
+		default:
+			return
+		}
+	}
+}
+
+// dispatch calls the handlers for an event, isolating handler panics
+func (b *Bus) dispatch(event Event) {
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[event.Type]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					b.logger.Error("Event handler panicked", "event_type", event.Type, "event_id", event.ID, "panic", r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
+
+// newEventID returns a random event ID
+func newEventID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusDispatchesInOrder(t *testing.T) {
+	bus := NewBus(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	received := make(chan Event, 10)
+	bus.Subscribe(TypePriceTick, func(e Event) { received <- e })
+	bus.Subscribe(TypePriceTick, func(e Event) { panic("broken subscriber") })
+	all := make(chan string, 10)
+	bus.Subscribe("*", func(e Event) { all <- e.Type })
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish(TypePriceTick, PriceTickVersion, PriceTick{CompanyID: i})
+	}
+	bus.Publish(TypeESGScoreUpdated, ESGScoreUpdatedVersion, ESGScoreUpdated{CompanyID: 1})
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case e := <-received:
+			assert.Equal(t, i, e.Data.(PriceTick).CompanyID)
+			assert.Equal(t, PriceTickVersion, e.Version)
+			assert.NotEmpty(t, e.ID)
+		case <-time.After(time.Second):
+			t.Fatal("event not delivered")
+		}
+	}
+
+	var types []string
+	for len(types) < 4 {
+		select {
+		case eventType := <-all:
+			types = append(types, eventType)
+		case <-time.After(time.Second):
+			t.Fatal("wildcard subscriber missed events")
+		}
+	}
+	assert.Equal(t, TypeESGScoreUpdated, types[3])
+}
+
+func TestNilBusDiscardsEvents(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() { bus.Publish(TypePriceTick, PriceTickVersion, PriceTick{}) })
+}
+
+func TestNewESGScoreUpdatedDelta(t *testing.T) {
+	old := &ESGScores{Environmental: 70, Social: 60, Governance: 80, Overall: 70}
+	event := NewESGScoreUpdated(3, "ACME", "Energy", 9, time.Now(), old, ESGScores{Environmental: 75, Social: 58, Governance: 80, Overall: 71})
+
+	require.NotNil(t, event.Delta)
+	assert.InDelta(t, 5, event.Delta.Environmental, 1e-9)
+	assert.InDelta(t, -2, event.Delta.Social, 1e-9)
+	assert.InDelta(t, 1, event.Delta.Overall, 1e-9)
+
+	first := NewESGScoreUpdated(3, "ACME", "Energy", 9, time.Now(), nil, ESGScores{Overall: 71})
+	assert.Nil(t, first.Delta)
+}
+
+// TestPayloadsMatchSchemas guards against payload structs drifting from their published schemas
+func TestPayloadsMatchSchemas(t *testing.T) {
+	payloads := map[string]interface{}{
+		TypeESGScoreUpdated:    NewESGScoreUpdated(1, "ACME", "Energy", 2, time.Now(), &ESGScores{}, ESGScores{}),
+		TypeESGScoreCreated:    ESGScoreCreated{ScoreID: 1},
+		TypePriceTick:          PriceTick{CompanyID: 1},
+		TypeCompanyCreated:     CompanyCreated{CompanyID: 1},
+		TypeCompanyDeleted:     CompanyDeleted{CompanyID: 1},
+		TypeAlertRaised:        Alert{AlertID: "a"},
+		TypeAlertResolved:      Alert{AlertID: "a"},
+		TypeAlertRuleTriggered: AlertRuleTriggered{RuleID: 1},
+	}
+	versions := map[string]int{
+		TypeESGScoreUpdated:    ESGScoreUpdatedVersion,
+		TypeESGScoreCreated:    ESGScoreCreatedVersion,
+		TypePriceTick:          PriceTickVersion,
+		TypeCompanyCreated:     CompanyCreatedVersion,
+		TypeCompanyDeleted:     CompanyDeletedVersion,
+		TypeAlertRaised:        AlertRaisedVersion,
+		TypeAlertResolved:      AlertResolvedVersion,
+		TypeAlertRuleTriggered: AlertRuleTriggeredVersion,
+	}
+
+	for eventType, payload := range payloads {
+		t.Run(eventType, func(t *testing.T) {
+			raw, err := Schema(eventType, versions[eventType])
+			require.NoError(t, err)
+
+			var schema struct {
+				Properties struct {
+					Data struct {
+						Required   []string               `json:"required"`
+						Properties map[string]interface{} `json:"properties"`
+					} `json:"data"`
+				} `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(raw, &schema))
+
+			encoded, err := json.Marshal(payload)
+			require.NoError(t, err)
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(encoded, &fields))
+
+			assert.ElementsMatch(t, schema.Properties.Data.Required, keys(fields))
+			assert.ElementsMatch(t, keys(schema.Properties.Data.Properties), keys(fields))
+		})
+	}
+
+	assert.Len(t, SchemaNames(), len(payloads))
+}
+
+func keys(m map[string]interface{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestBusDrainsQueueOnStop(t *testing.T) {
+	bus := NewBus(nil)
+	var received []int
+	bus.Subscribe(TypePriceTick, func(e Event) { received = append(received, e.Data.(PriceTick).CompanyID) })
+	for i := 1; i <= 3; i++ {
+		bus.Publish(TypePriceTick, PriceTickVersion, PriceTick{CompanyID: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bus.Run(ctx)
+	assert.Equal(t, []int{1, 2, 3}, received)
+}
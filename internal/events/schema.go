@@ -0,0 +1,28 @@
+package events
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema returns the JSON Schema document for a version of an event type
+func Schema(eventType string, version int) ([]byte, error) {
+	return schemaFiles.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", eventType, version))
+}
+
+// SchemaNames lists the available schemas as "<type>.v<version>"
+func SchemaNames() []string {
+	entries, _ := fs.ReadDir(schemaFiles, "schemas")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
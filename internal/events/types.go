@@ -0,0 +1,156 @@
+package events
+
+import "time"
+
+// Event types and the current schema version of each payload. Bump a version
+// when a change would break existing consumers, and add a schema file for it.
+const (
+	TypeESGScoreUpdated    = "esg.score.updated"
+	ESGScoreUpdatedVersion = 1
+
+	TypeESGScoreCreated    = "esg.score.created"
+	ESGScoreCreatedVersion = 1
+
+	TypePriceTick    = "price.tick"
+	PriceTickVersion = 1
+
+	TypeCompanyCreated    = "company.created"
+	CompanyCreatedVersion = 1
+
+	TypeCompanyDeleted    = "company.deleted"
+	CompanyDeletedVersion = 1
+
+	TypeAlertRaised    = "alert.raised"
+	AlertRaisedVersion = 1
+
+	TypeAlertResolved    = "alert.resolved"
+	AlertResolvedVersion = 1
+
+	TypeAlertRuleTriggered    = "alert.rule.triggered"
+	AlertRuleTriggeredVersion = 1
+)
+
+// ESGScores is one set of ESG pillar scores
+type ESGScores struct {
+	Environmental float64 `json:"environmental"`
+	Social        float64 `json:"social"`
+	Governance    float64 `json:"governance"`
+	Overall       float64 `json:"overall"`
+}
+
+// Sub returns the per-pillar difference s - other
+func (s ESGScores) Sub(other ESGScores) ESGScores {
+	return ESGScores{
+		Environmental: s.Environmental - other.Environmental,
+		Social:        s.Social - other.Social,
+		Governance:    s.Governance - other.Governance,
+		Overall:       s.Overall - other.Overall,
+	}
+}
+
+// ESGScoreUpdated (v1) is published when a company's current ESG score is
+// created or changed. Old and Delta are nil for a company's first score.
+type ESGScoreUpdated struct {
+	CompanyID     int        `json:"company_id"`
+	CompanySymbol string     `json:"company_symbol"`
+	Sector        string     `json:"sector"`
+	ScoreID       int        `json:"score_id"`
+	ScoreDate     time.Time  `json:"score_date"`
+	Old           *ESGScores `json:"old"`
+	New           ESGScores  `json:"new"`
+	Delta         *ESGScores `json:"delta"`
+}
+
+// NewESGScoreUpdated builds the payload, computing the delta when there is an old score
+func NewESGScoreUpdated(companyID int, symbol, sector string, scoreID int, scoreDate time.Time, old *ESGScores, current ESGScores) ESGScoreUpdated {
+	event := ESGScoreUpdated{
+		CompanyID:     companyID,
+		CompanySymbol: symbol,
+		Sector:        sector,
+		ScoreID:       scoreID,
+		ScoreDate:     scoreDate,
+		Old:           old,
+		New:           current,
+	}
+	if old != nil {
+		delta := current.Sub(*old)
+		event.Delta = &delta
+	}
+	return event
+}
+
+// PriceTick (v1) is published with a company's latest stored price after new
+// prices are ingested. Change fields are nil when there is no earlier close.
+type PriceTick struct {
+	CompanyID     int       `json:"company_id"`
+	CompanySymbol string    `json:"company_symbol"`
+	Sector        string    `json:"sector"`
+	Date          time.Time `json:"date"`
+	Open          float64   `json:"open"`
+	High          float64   `json:"high"`
+	Low           float64   `json:"low"`
+	Close         float64   `json:"close"`
+	Volume        int64     `json:"volume"`
+	PreviousClose *float64  `json:"previous_close"`
+	Change        *float64  `json:"change"`
+	ChangePercent *float64  `json:"change_percent"`
+}
+
+// ESGScoreCreated (v1) is published for every new ESG score, including
+// backfilled history that does not change the company's current score
+type ESGScoreCreated struct {
+	ScoreID       int       `json:"score_id"`
+	CompanyID     int       `json:"company_id"`
+	CompanySymbol string    `json:"company_symbol"`
+	Sector        string    `json:"sector"`
+	ScoreDate     time.Time `json:"score_date"`
+	Scores        ESGScores `json:"scores"`
+	DataSource    string    `json:"data_source"`
+}
+
+// CompanyCreated (v1) is published when a company is added
+type CompanyCreated struct {
+	CompanyID int    `json:"company_id"`
+	Name      string `json:"name"`
+	Symbol    string `json:"symbol"`
+	Sector    string `json:"sector"`
+	Industry  string `json:"industry"`
+	Country   string `json:"country"`
+}
+
+// CompanyDeleted (v1) is published when a company is removed
+type CompanyDeleted struct {
+	CompanyID int    `json:"company_id"`
+	Name      string `json:"name"`
+	Symbol    string `json:"symbol"`
+}
+
+// Alert (v1) is the payload of alert.raised and alert.resolved. ResolvedAt is
+// nil for alert.raised.
+type Alert struct {
+	AlertID    string     `json:"alert_id"`
+	AlertType  string     `json:"alert_type"`
+	Severity   string     `json:"severity"`
+	Message    string     `json:"message"`
+	Value      float64    `json:"value"`
+	Threshold  float64    `json:"threshold"`
+	RaisedAt   time.Time  `json:"raised_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// AlertRuleTriggered (v1) describes a user's alert rule triggering for a
+// company. It is delivered only to the rule's owner, never broadcast.
+type AlertRuleTriggered struct {
+	NotificationID int       `json:"notification_id"`
+	RuleID         int       `json:"rule_id"`
+	RuleName       string    `json:"rule_name"`
+	RuleType       string    `json:"rule_type"`
+	UserID         int       `json:"user_id"`
+	CompanyID      int       `json:"company_id"`
+	CompanySymbol  string    `json:"company_symbol"`
+	Metric         string    `json:"metric"`
+	Value          float64   `json:"value"`
+	Threshold      float64   `json:"threshold"`
+	Message        string    `json:"message"`
+	TriggeredAt    time.Time `json:"triggered_at"`
+}
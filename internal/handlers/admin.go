@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles user administration HTTP requests
+type AdminHandler struct {
+	userRepo         *models.UserRepository
+	refreshTokenRepo *models.RefreshTokenRepository
+	jwtManager       *auth.JWTManager
+	cache            *cache.AdvancedCache
+}
+
+// NewAdminHandler creates a new admin handler that invalidates a user's
+// cached profile when their roles change
+func NewAdminHandler(db *sql.DB, jwtManager *auth.JWTManager, responses *cache.AdvancedCache) *AdminHandler {
+	return &AdminHandler{
+		userRepo:         models.NewUserRepository(db),
+		refreshTokenRepo: models.NewRefreshTokenRepository(db),
+		jwtManager:       jwtManager,
+		cache:            responses,
+	}
+}
+
+// RoleRequest represents the grant role request
+type RoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ListUsers handles GET /api/v1/admin/users
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, err := h.userRepo.ListUsers(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":  users,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GrantRole handles POST /api/v1/admin/users/:id/roles
+func (h *AdminHandler) GrantRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if !auth.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Supported: viewer, analyst, data-editor, admin"})
+		return
+	}
+
+	roles, err := h.userRepo.GrantRole(c.Request.Context(), id, req.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant role"})
+		return
+	}
+	middleware.RequestLogger(c).Info("Role granted", "target_user_id", id, "role", req.Role)
+	invalidateCache(c, h.cache, cache.UserTag(id))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role granted successfully",
+		"user_id": id,
+		"roles":   roles,
+	})
+}
+
+// RevokeRole handles DELETE /api/v1/admin/users/:id/roles/:role. The user's
+// outstanding access tokens are revoked so the change applies on their next
+// token refresh instead of when the current token expires.
+func (h *AdminHandler) RevokeRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	role := c.Param("role")
+	if !auth.IsValidRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Supported: viewer, analyst, data-editor, admin"})
+		return
+	}
+
+	if currentID, _ := c.Get("user_id"); currentID == id && role == auth.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Administrators cannot revoke their own admin role"})
+		return
+	}
+
+	roles, err := h.userRepo.RevokeRole(c.Request.Context(), id, role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke role"})
+		return
+	}
+	middleware.RequestLogger(c).Info("Role revoked", "target_user_id", id, "role", role)
+	invalidateCache(c, h.cache, cache.UserTag(id))
+
+	active, err := h.refreshTokenRepo.ActiveAccessTokens(c.Request.Context(), id)
+	if err == nil {
+		err = denyAccessTokens(c.Request.Context(), h.jwtManager, active)
+	}
+	if err != nil {
+		middleware.RequestLogger(c).Error("Failed to revoke access tokens after role change", "target_user_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Role revoked but active tokens could not be revoked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role revoked successfully",
+		"user_id": id,
+		"roles":   roles,
+	})
+}
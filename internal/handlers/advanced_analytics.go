@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"ethosview-backend/internal/models"
 
@@ -31,7 +35,7 @@ func (h *AdvancedAnalyticsHandler) PredictESGScore(c *gin.Context) {
 		return
 	}
 
-	prediction, err := h.advancedAnalyticsRepo.PredictESGScore(companyID)
+	prediction, err := h.advancedAnalyticsRepo.PredictESGScore(c.Request.Context(), companyID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient data for prediction"})
@@ -47,8 +51,28 @@ func (h *AdvancedAnalyticsHandler) PredictESGScore(c *gin.Context) {
 	})
 }
 
-// OptimizePortfolio creates an optimized portfolio
+// OptimizePortfolio creates a mean-variance optimized portfolio
 func (h *AdvancedAnalyticsHandler) OptimizePortfolio(c *gin.Context) {
+	constraints, ok := optimizationConstraints(c)
+	if !ok {
+		return
+	}
+
+	optimization, err := h.advancedAnalyticsRepo.OptimizePortfolio(c.Request.Context(), constraints)
+	if err != nil {
+		writeOptimizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"optimization": optimization,
+		"message":      "Portfolio optimized successfully",
+	})
+}
+
+// optimizationConstraints parses the optimizer's query parameters, writing a
+// 400 response if one is invalid
+func optimizationConstraints(c *gin.Context) (models.PortfolioConstraints, bool) {
 	// Parse query parameters
 	targetReturnStr := c.DefaultQuery("target_return", "0.10")
 	riskTolerance := c.DefaultQuery("risk_tolerance", "medium")
@@ -57,7 +81,7 @@ func (h *AdvancedAnalyticsHandler) OptimizePortfolio(c *gin.Context) {
 	targetReturn, err := strconv.ParseFloat(targetReturnStr, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target return"})
-		return
+		return models.PortfolioConstraints{}, false
 	}
 
 	maxCompanies, err := strconv.Atoi(maxCompaniesStr)
@@ -71,20 +95,62 @@ func (h *AdvancedAnalyticsHandler) OptimizePortfolio(c *gin.Context) {
 		riskTolerance = "medium"
 	}
 
-	optimization, err := h.advancedAnalyticsRepo.OptimizePortfolio(targetReturn, riskTolerance, maxCompanies)
+	// Risk tolerance sets the default concentration limit per name
+	defaultMaxWeights := map[string]string{"low": "0.15", "medium": "0.25", "high": "0.40"}
+
+	maxWeight, err := strconv.ParseFloat(c.DefaultQuery("max_weight", defaultMaxWeights[riskTolerance]), 64)
+	if err != nil || maxWeight <= 0 || maxWeight > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_weight. Must be in (0, 1]"})
+		return models.PortfolioConstraints{}, false
+	}
+
+	maxSectorWeight, err := strconv.ParseFloat(c.DefaultQuery("max_sector_weight", "1"), 64)
+	if err != nil || maxSectorWeight <= 0 || maxSectorWeight > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_sector_weight. Must be in (0, 1]"})
+		return models.PortfolioConstraints{}, false
+	}
+
+	minESGScore, err := strconv.ParseFloat(c.DefaultQuery("min_esg_score", "0"), 64)
+	if err != nil || minESGScore < 0 || minESGScore > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_esg_score. Must be between 0 and 100"})
+		return models.PortfolioConstraints{}, false
+	}
+
+	allowShort, err := strconv.ParseBool(c.DefaultQuery("allow_short", "false"))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient data for portfolio optimization"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to optimize portfolio"})
-		return
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid allow_short. Must be true or false"})
+		return models.PortfolioConstraints{}, false
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"optimization": optimization,
-		"message":      "Portfolio optimized successfully",
-	})
+	lookbackDays, err := strconv.Atoi(c.DefaultQuery("lookback_days", "365"))
+	if err != nil || lookbackDays < 30 || lookbackDays > 1825 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lookback_days. Must be between 30 and 1825"})
+		return models.PortfolioConstraints{}, false
+	}
+
+	return models.PortfolioConstraints{
+		TargetReturn:    targetReturn,
+		RiskTolerance:   riskTolerance,
+		MaxCompanies:    maxCompanies,
+		MinESGScore:     minESGScore,
+		MaxWeight:       maxWeight,
+		MaxSectorWeight: maxSectorWeight,
+		AllowShort:      allowShort,
+		LookbackDays:    lookbackDays,
+	}, true
+}
+
+// writeOptimizationError writes the response for a failed optimization
+func writeOptimizationError(c *gin.Context, err error) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient data for portfolio optimization"})
+		return
+	}
+	if errors.Is(err, models.ErrInfeasiblePortfolio) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Constraints cannot be satisfied", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to optimize portfolio"})
 }
 
 // AssessRisk calculates risk metrics for a company
@@ -96,12 +162,21 @@ func (h *AdvancedAnalyticsHandler) AssessRisk(c *gin.Context) {
 		return
 	}
 
-	assessment, err := h.advancedAnalyticsRepo.AssessRisk(companyID)
+	benchmark, lookbackDays, ok := parseBetaParams(c)
+	if !ok {
+		return
+	}
+
+	assessment, err := h.advancedAnalyticsRepo.AssessRisk(c.Request.Context(), companyID, benchmark, lookbackDays)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient data for risk assessment"})
 			return
 		}
+		if errors.Is(err, models.ErrInsufficientOverlap) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Insufficient overlap", "details": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assess risk"})
 		return
 	}
@@ -112,6 +187,184 @@ func (h *AdvancedAnalyticsHandler) AssessRisk(c *gin.Context) {
 	})
 }
 
+// EstimateBeta calculates beta, alpha and correlation against a market benchmark
+func (h *AdvancedAnalyticsHandler) EstimateBeta(c *gin.Context) {
+	companyIDStr := c.Param("id")
+	companyID, err := strconv.Atoi(companyIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		return
+	}
+
+	benchmark, lookbackDays, ok := parseBetaParams(c)
+	if !ok {
+		return
+	}
+
+	estimate, err := h.advancedAnalyticsRepo.EstimateBeta(c.Request.Context(), companyID, benchmark, lookbackDays)
+	if err != nil {
+		if errors.Is(err, models.ErrInsufficientOverlap) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Insufficient overlap", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate beta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"beta":    estimate,
+		"message": "Beta estimated successfully",
+	})
+}
+
+// parseBetaParams reads the benchmark and lookback window query parameters,
+// writing a 400 response and returning false when they are invalid
+func parseBetaParams(c *gin.Context) (string, int, bool) {
+	benchmark := c.DefaultQuery("benchmark", "sp500")
+	if !models.IsValidBenchmark(benchmark) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid benchmark. Supported: sp500, nasdaq, dow"})
+		return "", 0, false
+	}
+
+	lookbackDays, err := strconv.Atoi(c.DefaultQuery("lookback_days", "365"))
+	if err != nil || lookbackDays < 30 || lookbackDays > 1825 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lookback_days. Must be between 30 and 1825"})
+		return "", 0, false
+	}
+
+	return benchmark, lookbackDays, true
+}
+
+// BacktestRequest represents a portfolio backtest request
+type BacktestRequest struct {
+	StartDate          string             `json:"start_date" binding:"required"`
+	EndDate            string             `json:"end_date" binding:"required"`
+	InitialCapital     float64            `json:"initial_capital"`
+	Rule               string             `json:"rule" binding:"required,oneof=fixed_weights top_n_esg"`
+	Weights            map[string]float64 `json:"weights"`
+	TopN               int                `json:"top_n"`
+	Weighting          string             `json:"weighting"`
+	Sector             string             `json:"sector"`
+	RebalanceFrequency string             `json:"rebalance_frequency"`
+	TransactionCostBps float64            `json:"transaction_cost_bps"`
+	RiskFreeRate       *float64           `json:"risk_free_rate"`
+}
+
+// RunBacktest replays a fixed-weight portfolio or a top-N ESG rule over historical prices
+func (h *AdvancedAnalyticsHandler) RunBacktest(c *gin.Context) {
+	var req BacktestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := backtestConfigFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.RiskFreeRate != nil {
+		config.RiskFreeRate = *req.RiskFreeRate
+	} else {
+		config.RiskFreeRate = h.advancedAnalyticsRepo.LatestRiskFreeRate(c.Request.Context())
+	}
+
+	result, err := h.advancedAnalyticsRepo.RunBacktest(c.Request.Context(), config)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient price data for backtest"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run backtest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backtest": result,
+		"message":  "Backtest completed successfully",
+	})
+}
+
+// backtestConfigFromRequest validates a backtest request and applies defaults
+func backtestConfigFromRequest(req BacktestRequest) (models.BacktestConfig, error) {
+	config := models.BacktestConfig{
+		InitialCapital:     req.InitialCapital,
+		Rule:               req.Rule,
+		TopN:               req.TopN,
+		Weighting:          req.Weighting,
+		Sector:             req.Sector,
+		RebalanceFrequency: req.RebalanceFrequency,
+		TransactionCostBps: req.TransactionCostBps,
+	}
+
+	var err error
+	if config.StartDate, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+		return config, fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+	}
+	if config.EndDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+		return config, fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+	}
+	if !config.EndDate.After(config.StartDate) {
+		return config, fmt.Errorf("end_date must be after start_date")
+	}
+	if config.EndDate.Sub(config.StartDate) > 10*365*24*time.Hour {
+		return config, fmt.Errorf("backtest window cannot exceed 10 years")
+	}
+
+	if config.InitialCapital == 0 {
+		config.InitialCapital = 100000
+	}
+	if config.InitialCapital < 0 {
+		return config, fmt.Errorf("initial_capital must be positive")
+	}
+
+	if config.RebalanceFrequency == "" {
+		config.RebalanceFrequency = models.RebalanceMonthly
+	}
+	if !models.IsValidRebalanceFrequency(config.RebalanceFrequency) {
+		return config, fmt.Errorf("invalid rebalance_frequency. Supported: none, daily, weekly, monthly, quarterly")
+	}
+
+	if config.TransactionCostBps < 0 || config.TransactionCostBps > 1000 {
+		return config, fmt.Errorf("transaction_cost_bps must be between 0 and 1000")
+	}
+
+	switch config.Rule {
+	case models.BacktestRuleFixedWeights:
+		if len(req.Weights) == 0 || len(req.Weights) > 100 {
+			return config, fmt.Errorf("weights must contain between 1 and 100 symbols")
+		}
+		config.Weights = make(map[string]float64, len(req.Weights))
+		total := 0.0
+		for symbol, weight := range req.Weights {
+			if weight < 0 {
+				return config, fmt.Errorf("weight for %s must not be negative", symbol)
+			}
+			config.Weights[strings.ToUpper(strings.TrimSpace(symbol))] = weight
+			total += weight
+		}
+		if total <= 0 || total > 1.0001 {
+			return config, fmt.Errorf("weights must sum to a value in (0, 1]; the remainder is held as cash")
+		}
+
+	case models.BacktestRuleTopNESG:
+		if config.TopN == 0 {
+			config.TopN = 10
+		}
+		if config.TopN < 1 || config.TopN > 50 {
+			return config, fmt.Errorf("top_n must be between 1 and 50")
+		}
+		if config.Weighting == "" {
+			config.Weighting = "equal"
+		}
+		if config.Weighting != "equal" && config.Weighting != "esg" {
+			return config, fmt.Errorf("invalid weighting. Supported: equal, esg")
+		}
+	}
+
+	return config, nil
+}
+
 // AnalyzeTrend performs trend analysis on various metrics
 func (h *AdvancedAnalyticsHandler) AnalyzeTrend(c *gin.Context) {
 	companyIDStr := c.Param("id")
@@ -131,7 +384,7 @@ func (h *AdvancedAnalyticsHandler) AnalyzeTrend(c *gin.Context) {
 		return
 	}
 
-	analysis, err := h.advancedAnalyticsRepo.AnalyzeTrend(companyID, metric, period)
+	analysis, err := h.advancedAnalyticsRepo.AnalyzeTrend(c.Request.Context(), companyID, metric, period)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Insufficient data for trend analysis"})
@@ -150,7 +403,14 @@ func (h *AdvancedAnalyticsHandler) AnalyzeTrend(c *gin.Context) {
 // GetAdvancedAnalyticsSummary provides a comprehensive analytics summary
 func (h *AdvancedAnalyticsHandler) GetAdvancedAnalyticsSummary(c *gin.Context) {
 	// Get portfolio optimization for top companies
-	optimization, err := h.advancedAnalyticsRepo.OptimizePortfolio(0.10, "medium", 5)
+	optimization, err := h.advancedAnalyticsRepo.OptimizePortfolio(c.Request.Context(), models.PortfolioConstraints{
+		TargetReturn:    0.10,
+		RiskTolerance:   "medium",
+		MaxCompanies:    5,
+		MaxWeight:       0.40,
+		MaxSectorWeight: 1,
+		LookbackDays:    365,
+	})
 	if err != nil {
 		optimization = nil
 	}
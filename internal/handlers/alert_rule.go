@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"ethosview-backend/internal/alerting"
+	"ethosview-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCooldownMinutes is how long a rule stays quiet for a company after notifying
+const defaultCooldownMinutes = 24 * 60
+
+// AlertRuleHandler handles user-defined ESG and price alert rules
+type AlertRuleHandler struct {
+	repo        *models.AlertRuleRepository
+	companyRepo *models.CompanyRepository
+	watchlists  *models.WatchlistRepository
+}
+
+// NewAlertRuleHandler creates a new alert rule handler
+func NewAlertRuleHandler(db *sql.DB) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		repo:        models.NewAlertRuleRepository(db),
+		companyRepo: models.NewCompanyRepository(db),
+		watchlists:  models.NewWatchlistRepository(db),
+	}
+}
+
+// AlertRuleRequest represents the create and update alert rule request. A rule
+// watches one company, given by company_id or symbol, or a watchlist.
+type AlertRuleRequest struct {
+	Name            string   `json:"name" binding:"required,max=100"`
+	RuleType        string   `json:"rule_type" binding:"required,oneof=esg_below esg_drop price_drop"`
+	CompanyID       *int     `json:"company_id"`
+	Symbol          string   `json:"symbol"`
+	WatchlistID     *int     `json:"watchlist_id"`
+	Metric          string   `json:"metric"`
+	Threshold       *float64 `json:"threshold" binding:"required"`
+	Channels        []string `json:"channels"`
+	CooldownMinutes *int     `json:"cooldown_minutes" binding:"omitempty,min=0,max=43200"`
+	Active          *bool    `json:"active"`
+}
+
+// ListAlertRules handles GET /api/v1/me/alert-rules
+func (h *AlertRuleHandler) ListAlertRules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.repo.ListRules(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alert_rules": rules})
+}
+
+// CreateAlertRule handles POST /api/v1/me/alert-rules
+func (h *AlertRuleHandler) CreateAlertRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := h.bindAlertRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.CreateRule(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetAlertRule handles GET /api/v1/me/alert-rules/:id
+func (h *AlertRuleHandler) GetAlertRule(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "alert rule")
+	if !ok {
+		return
+	}
+
+	rule, err := h.repo.GetRule(c.Request.Context(), userID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateAlertRule handles PUT /api/v1/me/alert-rules/:id
+func (h *AlertRuleHandler) UpdateAlertRule(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "alert rule")
+	if !ok {
+		return
+	}
+
+	rule, ok := h.bindAlertRule(c, userID)
+	if !ok {
+		return
+	}
+	rule.ID = id
+
+	if err := h.repo.UpdateRule(c.Request.Context(), rule); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAlertRule handles DELETE /api/v1/me/alert-rules/:id
+func (h *AlertRuleHandler) DeleteAlertRule(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "alert rule")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteRule(c.Request.Context(), userID, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted successfully"})
+}
+
+// ListAlertNotifications handles GET /api/v1/me/alert-notifications
+func (h *AlertRuleHandler) ListAlertNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+
+	notifications, err := h.repo.ListNotifications(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
+
+// bindAlertRule binds and validates an alert rule request, resolving its
+// company or checking that its watchlist belongs to the user
+func (h *AlertRuleHandler) bindAlertRule(c *gin.Context, userID int) (*models.AlertRule, bool) {
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return nil, false
+	}
+
+	rule, err := validateAlertRule(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	rule.UserID = userID
+
+	switch {
+	case req.Symbol != "":
+		company, err := h.companyRepo.GetCompanyBySymbol(c.Request.Context(), normalizeSymbol(req.Symbol))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown symbol " + normalizeSymbol(req.Symbol)})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve symbol"})
+			return nil, false
+		}
+		rule.CompanyID = &company.ID
+	case req.CompanyID != nil:
+		if _, err := h.companyRepo.GetCompanyByID(c.Request.Context(), *req.CompanyID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Company not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve company"})
+			return nil, false
+		}
+	default:
+		if _, err := h.watchlists.GetWatchlist(c.Request.Context(), userID, *req.WatchlistID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Watchlist not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve watchlist"})
+			return nil, false
+		}
+	}
+
+	return rule, true
+}
+
+// validateAlertRule checks a request and applies defaults: the overall metric
+// for ESG rules, the WebSocket channel and a one-day cooldown
+func validateAlertRule(req AlertRuleRequest) (*models.AlertRule, error) {
+	targets := 0
+	if req.CompanyID != nil || req.Symbol != "" {
+		targets++
+	}
+	if req.WatchlistID != nil {
+		targets++
+	}
+	if targets != 1 || (req.CompanyID != nil && req.Symbol != "") {
+		return nil, fmt.Errorf("specify exactly one of company_id, symbol or watchlist_id")
+	}
+
+	threshold := *req.Threshold
+	metric := req.Metric
+	switch req.RuleType {
+	case models.AlertRulePriceDrop:
+		if metric != "" && metric != alerting.MetricClose {
+			return nil, fmt.Errorf("price_drop rules watch the close; metric must be close or omitted")
+		}
+		metric = alerting.MetricClose
+		if threshold <= 0 || threshold >= 100 {
+			return nil, fmt.Errorf("price_drop threshold is a percentage between 0 and 100")
+		}
+	default:
+		if metric == "" {
+			metric = alerting.MetricOverall
+		}
+		if !alerting.IsESGMetric(metric) {
+			return nil, fmt.Errorf("metric must be overall, environmental, social or governance")
+		}
+		if threshold <= 0 || threshold > 100 {
+			return nil, fmt.Errorf("%s threshold must be between 0 and 100 score points", req.RuleType)
+		}
+	}
+
+	channels := []string{}
+	seen := make(map[string]bool)
+	for _, channel := range req.Channels {
+		if seen[channel] {
+			continue
+		}
+		if !alerting.IsChannel(channel) {
+			return nil, fmt.Errorf("unknown channel %s", channel)
+		}
+		seen[channel] = true
+		channels = append(channels, channel)
+	}
+	if len(channels) == 0 {
+		channels = []string{alerting.ChannelWebSocket}
+	}
+
+	cooldown := defaultCooldownMinutes
+	if req.CooldownMinutes != nil {
+		cooldown = *req.CooldownMinutes
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return &models.AlertRule{
+		Name:            req.Name,
+		RuleType:        req.RuleType,
+		CompanyID:       req.CompanyID,
+		WatchlistID:     req.WatchlistID,
+		Metric:          metric,
+		Threshold:       threshold,
+		Channels:        channels,
+		CooldownMinutes: cooldown,
+		Active:          active,
+	}, nil
+}
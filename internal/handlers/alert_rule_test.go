@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAlertRule(t *testing.T) {
+	threshold := func(v float64) *float64 { return &v }
+	id := func(v int) *int { return &v }
+
+	rule, err := validateAlertRule(AlertRuleRequest{
+		Name:      "AAPL governance",
+		RuleType:  models.AlertRuleESGBelow,
+		Symbol:    "AAPL",
+		Metric:    "governance",
+		Threshold: threshold(60),
+		Channels:  []string{"email", "email", "webhook"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "webhook"}, rule.Channels)
+	assert.Equal(t, defaultCooldownMinutes, rule.CooldownMinutes)
+	assert.True(t, rule.Active)
+
+	rule, err = validateAlertRule(AlertRuleRequest{Name: "Drops", RuleType: models.AlertRulePriceDrop, WatchlistID: id(2), Threshold: threshold(5)})
+	require.NoError(t, err)
+	assert.Equal(t, "close", rule.Metric)
+	assert.Equal(t, []string{"websocket"}, rule.Channels)
+
+	tests := []struct {
+		name string
+		req  AlertRuleRequest
+	}{
+		{"no target", AlertRuleRequest{RuleType: models.AlertRuleESGBelow, Threshold: threshold(60)}},
+		{"two targets", AlertRuleRequest{RuleType: models.AlertRuleESGBelow, CompanyID: id(1), WatchlistID: id(2), Threshold: threshold(60)}},
+		{"company id and symbol", AlertRuleRequest{RuleType: models.AlertRuleESGBelow, CompanyID: id(1), Symbol: "AAPL", Threshold: threshold(60)}},
+		{"unknown metric", AlertRuleRequest{RuleType: models.AlertRuleESGDrop, CompanyID: id(1), Metric: "close", Threshold: threshold(10)}},
+		{"price metric", AlertRuleRequest{RuleType: models.AlertRulePriceDrop, CompanyID: id(1), Metric: "overall", Threshold: threshold(5)}},
+		{"score threshold out of range", AlertRuleRequest{RuleType: models.AlertRuleESGBelow, CompanyID: id(1), Threshold: threshold(150)}},
+		{"non-positive price threshold", AlertRuleRequest{RuleType: models.AlertRulePriceDrop, CompanyID: id(1), Threshold: threshold(0)}},
+		{"unknown channel", AlertRuleRequest{RuleType: models.AlertRuleESGBelow, CompanyID: id(1), Threshold: threshold(60), Channels: []string{"sms"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateAlertRule(tt.req)
+			assert.Error(t, err)
+		})
+	}
+}
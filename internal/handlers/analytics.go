@@ -37,7 +37,7 @@ func (h *AnalyticsHandler) GetESGTrends(c *gin.Context) {
 		days = 30
 	}
 
-	trends, err := h.analyticsRepo.GetESGTrends(companyID, days)
+	trends, err := h.analyticsRepo.GetESGTrends(c.Request.Context(), companyID, days)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ESG trends"})
 		return
@@ -53,7 +53,7 @@ func (h *AnalyticsHandler) GetESGTrends(c *gin.Context) {
 
 // GetSectorComparisons retrieves sector-level ESG and financial comparisons
 func (h *AnalyticsHandler) GetSectorComparisons(c *gin.Context) {
-	comparisons, err := h.analyticsRepo.GetSectorComparisons()
+	comparisons, err := h.analyticsRepo.GetSectorComparisons(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sector comparisons"})
 		return
@@ -73,7 +73,7 @@ func (h *AnalyticsHandler) GetFinancialComparisons(c *gin.Context) {
 		limit = 10
 	}
 
-	comparisons, err := h.analyticsRepo.GetFinancialComparisons(limit)
+	comparisons, err := h.analyticsRepo.GetFinancialComparisons(c.Request.Context(), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve financial comparisons"})
 		return
@@ -111,7 +111,7 @@ func (h *AnalyticsHandler) GetTopPerformers(c *gin.Context) {
 		limit = 10
 	}
 
-	performers, err := h.analyticsRepo.GetTopPerformers(metric, limit)
+	performers, err := h.analyticsRepo.GetTopPerformers(c.Request.Context(), metric, limit)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "No data found for the specified metric"})
@@ -131,7 +131,7 @@ func (h *AnalyticsHandler) GetTopPerformers(c *gin.Context) {
 
 // GetESGvsFinancialCorrelation retrieves correlation analysis between ESG and financial metrics
 func (h *AnalyticsHandler) GetESGvsFinancialCorrelation(c *gin.Context) {
-	correlation, err := h.analyticsRepo.GetESGvsFinancialCorrelation()
+	correlation, err := h.analyticsRepo.GetESGvsFinancialCorrelation(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate correlations"})
 		return
@@ -145,26 +145,26 @@ func (h *AnalyticsHandler) GetESGvsFinancialCorrelation(c *gin.Context) {
 // GetAnalyticsSummary retrieves a comprehensive analytics summary
 func (h *AnalyticsHandler) GetAnalyticsSummary(c *gin.Context) {
 	// Get sector comparisons
-	sectorComparisons, err := h.analyticsRepo.GetSectorComparisons()
+	sectorComparisons, err := h.analyticsRepo.GetSectorComparisons(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve analytics summary"})
 		return
 	}
 
 	// Get top ESG performers
-	topESG, err := h.analyticsRepo.GetTopPerformers("esg_score", 5)
+	topESG, err := h.analyticsRepo.GetTopPerformers(c.Request.Context(), "esg_score", 5)
 	if err != nil {
 		topESG = []models.PerformanceMetric{}
 	}
 
 	// Get top market cap performers
-	topMarketCap, err := h.analyticsRepo.GetTopPerformers("market_cap", 5)
+	topMarketCap, err := h.analyticsRepo.GetTopPerformers(c.Request.Context(), "market_cap", 5)
 	if err != nil {
 		topMarketCap = []models.PerformanceMetric{}
 	}
 
 	// Get correlation analysis
-	correlation, err := h.analyticsRepo.GetESGvsFinancialCorrelation()
+	correlation, err := h.analyticsRepo.GetESGvsFinancialCorrelation(c.Request.Context())
 	if err != nil {
 		correlation = map[string]interface{}{}
 	}
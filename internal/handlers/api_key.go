@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key management for the authenticated user
+type APIKeyHandler struct {
+	repo *models.APIKeyRepository
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(db *sql.DB) *APIKeyHandler {
+	return &APIKeyHandler{
+		repo: models.NewAPIKeyRepository(db),
+	}
+}
+
+// CreateAPIKeyRequest represents the create API key request. Keys without
+// scopes are read-only; expiry is optional and may be given as a timestamp or
+// a number of days.
+type CreateAPIKeyRequest struct {
+	Name          string     `json:"name" binding:"required,max=100"`
+	Scopes        []string   `json:"scopes"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	ExpiresInDays int        `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// ListAPIKeys handles GET /api/v1/me/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.repo.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey handles POST /api/v1/me/api-keys. The raw key is only returned in this response.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	scopes, err := validateScopes(req.Scopes, c.GetStringSlice("user_roles"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expiresAt, err := apiKeyExpiry(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rawKey, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := h.repo.CreateAPIKey(c.Request.Context(), key, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Store the key now; it will not be shown again",
+		"key":     rawKey,
+		"api_key": key,
+	})
+}
+
+// RotateAPIKey handles POST /api/v1/me/api-keys/:id/rotate. The old key stops
+// working immediately and the replacement keeps its name, scopes and expiry.
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "API key")
+	if !ok {
+		return
+	}
+
+	rawKey, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key, err := h.repo.RotateAPIKey(c.Request.Context(), userID, id, prefix, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key rotated. Store the key now; it will not be shown again",
+		"key":     rawKey,
+		"api_key": key,
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/me/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "API key")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RevokeAPIKey(c.Request.Context(), userID, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// validateScopes checks that every scope is a known permission the owner holds,
+// dropping duplicates
+func validateScopes(scopes []string, roles []string) ([]string, error) {
+	validated := []string{}
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		if !auth.IsValidPermission(scope) {
+			return nil, fmt.Errorf("unknown scope %s", scope)
+		}
+		if !auth.HasPermission(roles, scope) {
+			return nil, fmt.Errorf("scope %s exceeds your permissions", scope)
+		}
+		seen[scope] = true
+		validated = append(validated, scope)
+	}
+	return validated, nil
+}
+
+// apiKeyExpiry resolves the requested expiry, which must be in the future
+func apiKeyExpiry(req CreateAPIKeyRequest) (*time.Time, error) {
+	if req.ExpiresAt != nil && req.ExpiresInDays > 0 {
+		return nil, fmt.Errorf("specify either expires_at or expires_in_days, not both")
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		return &expiresAt, nil
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+	return req.ExpiresAt, nil
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
+	"time"
 
 	"ethosview-backend/internal/models"
 	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -13,15 +17,20 @@ import (
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	userRepo   *models.UserRepository
-	jwtManager *auth.JWTManager
+	userRepo         *models.UserRepository
+	refreshTokenRepo *models.RefreshTokenRepository
+	jwtManager       *auth.JWTManager
+	cache            *cache.AdvancedCache
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *sql.DB) *AuthHandler {
+// NewAuthHandler creates a new auth handler that invalidates the user's
+// cached profile when it changes
+func NewAuthHandler(db *sql.DB, jwtManager *auth.JWTManager, responses *cache.AdvancedCache) *AuthHandler {
 	return &AuthHandler{
-		userRepo:   models.NewUserRepository(db),
-		jwtManager: auth.NewJWTManager(),
+		userRepo:         models.NewUserRepository(db),
+		refreshTokenRepo: models.NewRefreshTokenRepository(db),
+		jwtManager:       jwtManager,
+		cache:            responses,
 	}
 }
 
@@ -39,6 +48,25 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// UpdateProfileRequest represents the profile update request; empty fields
+// are left unchanged
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// RefreshRequest represents the token refresh and logout request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// tokenPair is an access token with its rotating refresh token
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+}
+
 // Register handles POST /api/v1/auth/register
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -48,7 +76,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Check if user already exists
-	existingUser, err := h.userRepo.GetUserByEmail(req.Email)
+	existingUser, err := h.userRepo.GetUserByEmail(c.Request.Context(), req.Email)
 	if err == nil && existingUser != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
 		return
@@ -67,28 +95,33 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		PasswordHash: string(hashedPassword),
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
+		Roles:        []string{auth.RoleViewer},
 	}
 
-	if err := h.userRepo.CreateUser(user); err != nil {
+	if err := h.userRepo.CreateUser(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Email)
+	// Generate access and refresh tokens
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
+		"message":       "User registered successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":         user.ID,
 			"email":      user.Email,
 			"first_name": user.FirstName,
 			"last_name":  user.LastName,
+			"roles":      user.Roles,
 		},
 	})
 }
@@ -102,7 +135,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Get user by email
-	user, err := h.userRepo.GetUserByEmail(req.Email)
+	user, err := h.userRepo.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
@@ -114,25 +147,213 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Email)
+	// Generate access and refresh tokens
+	tokens, err := h.issueTokens(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":         user.ID,
 			"email":      user.Email,
 			"first_name": user.FirstName,
 			"last_name":  user.LastName,
+			"roles":      user.Roles,
 		},
 	})
 }
 
+// Refresh handles POST /api/v1/auth/refresh. The presented refresh token is
+// consumed and replaced; presenting it again revokes every session in its family.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// The owner is only known once the refresh token is consumed, but the new
+	// access token's jti must be recorded with the replacement refresh token
+	claims, err := h.jwtManager.NewAccessClaims(0, "", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	refreshToken, refreshHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	next := h.newRefreshToken(c, refreshHash, claims)
+	current, revoked, err := h.refreshTokenRepo.RotateRefreshToken(c.Request.Context(), auth.HashToken(req.RefreshToken), next)
+	switch err {
+	case nil:
+	case models.ErrRefreshTokenReused:
+		denyAccessTokens(c.Request.Context(), h.jwtManager, revoked)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected; all sessions for this login have been revoked"})
+		return
+	case models.ErrRefreshTokenInvalid, models.ErrRefreshTokenExpired:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), current.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	claims.UserID = user.ID
+	claims.Email = user.Email
+	claims.Roles = user.Roles
+	accessToken, err := h.jwtManager.SignClaims(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Token refreshed successfully",
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(h.jwtManager.AccessTokenTTL().Seconds()),
+	})
+}
+
+// Logout handles POST /api/v1/auth/logout. It revokes the current access token
+// and, when a refresh token is supplied, every token in its family.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req RefreshRequest
+	_ = c.ShouldBindJSON(&req) // The refresh token is optional
+
+	var revoked []models.IssuedAccessToken
+	if req.RefreshToken != "" {
+		var err error
+		revoked, err = h.refreshTokenRepo.RevokeFamily(c.Request.Context(), userID, auth.HashToken(req.RefreshToken))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+			return
+		}
+	}
+
+	if claims, ok := c.Get("token_claims"); ok {
+		current := claims.(*auth.Claims)
+		revoked = append(revoked, models.IssuedAccessToken{JTI: current.ID, ExpiresAt: current.ExpiresAt.Time})
+	}
+
+	if err := denyAccessTokens(c.Request.Context(), h.jwtManager, revoked); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll handles POST /api/v1/auth/logout-all, revoking every session of the user
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.refreshTokenRepo.RevokeAllForUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	if claims, ok := c.Get("token_claims"); ok {
+		current := claims.(*auth.Claims)
+		revoked = append(revoked, models.IssuedAccessToken{JTI: current.ID, ExpiresAt: current.ExpiresAt.Time})
+	}
+
+	if err := denyAccessTokens(c.Request.Context(), h.jwtManager, revoked); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "All sessions logged out successfully",
+		"revoked_sessions": len(revoked),
+	})
+}
+
+// issueTokens creates an access token and a refresh token starting a new family
+func (h *AuthHandler) issueTokens(c *gin.Context, user *models.User) (*tokenPair, error) {
+	accessToken, claims, err := h.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := auth.NewTokenFamily()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := h.newRefreshToken(c, refreshHash, claims)
+	stored.UserID = user.ID
+	stored.FamilyID = familyID
+	if err := h.refreshTokenRepo.CreateRefreshToken(c.Request.Context(), stored); err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(h.jwtManager.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+func (h *AuthHandler) newRefreshToken(c *gin.Context, tokenHash string, access *auth.Claims) *models.RefreshToken {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return &models.RefreshToken{
+		TokenHash:       tokenHash,
+		AccessJTI:       access.ID,
+		AccessExpiresAt: access.ExpiresAt.Time,
+		ExpiresAt:       time.Now().Add(h.jwtManager.RefreshTokenTTL()),
+		UserAgent:       userAgent,
+		IPAddress:       c.ClientIP(),
+	}
+}
+
+// denyAccessTokens adds access tokens to the jti denylist until they expire
+func denyAccessTokens(ctx context.Context, jwtManager *auth.JWTManager, tokens []models.IssuedAccessToken) error {
+	store := jwtManager.RevocationStore()
+	if store == nil {
+		return nil
+	}
+	for _, token := range tokens {
+		if err := store.Revoke(ctx, token.JTI, token.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetProfile handles GET /api/v1/auth/profile
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -141,17 +362,20 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(userID.(int))
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), userID.(int))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.UserTag(user.ID))
+	middleware.SetLastModified(c, user.UpdatedAt)
 	c.JSON(http.StatusOK, gin.H{
 		"id":         user.ID,
 		"email":      user.Email,
 		"first_name": user.FirstName,
 		"last_name":  user.LastName,
+		"roles":      user.Roles,
 		"created_at": user.CreatedAt,
 		"updated_at": user.UpdatedAt,
 	})
@@ -165,17 +389,14 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		FirstName string `json:"first_name"`
-		LastName  string `json:"last_name"`
-	}
+	var req UpdateProfileRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(userID.(int))
+	user, err := h.userRepo.GetUserByID(c.Request.Context(), userID.(int))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -189,10 +410,11 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		user.LastName = req.LastName
 	}
 
-	if err := h.userRepo.UpdateUser(user); err != nil {
+	if err := h.userRepo.UpdateUser(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
+	invalidateCache(c, h.cache, cache.UserTag(user.ID))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Profile updated successfully",
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// invalidateCache drops cached responses tagged with any of tags. Call it
+// after the write commits and before responding, so the client's next read
+// cannot be served from before the write. Failures are logged rather than
+// returned because the write itself succeeded; the cache keeps retrying the
+// tags in the background, and until a retry succeeds reads may be served
+// from before the write.
+func invalidateCache(c *gin.Context, responses *cache.AdvancedCache, tags ...string) {
+	if err := responses.InvalidateTags(context.WithoutCancel(c.Request.Context()), tags...); err != nil {
+		middleware.RequestLogger(c).Error("Failed to invalidate cached responses", "tags", tags, "error", err)
+	}
+}
+
+// companyCacheTags returns the tags to invalidate after writing a company's
+// data: analytics and each distinct company
+func companyCacheTags(companyIDs ...int) []string {
+	tags := []string{cache.TagAnalytics}
+	seen := make(map[int]bool)
+	for _, id := range companyIDs {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			tags = append(tags, cache.CompanyTag(id))
+		}
+	}
+	return tags
+}
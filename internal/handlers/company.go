@@ -5,20 +5,28 @@ import (
 	"net/http"
 	"strconv"
 
+	"ethosview-backend/internal/events"
 	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CompanyHandler handles company-related HTTP requests
 type CompanyHandler struct {
-	repo *models.CompanyRepository
+	repo   *models.CompanyRepository
+	events *events.Bus
+	cache  *cache.AdvancedCache
 }
 
-// NewCompanyHandler creates a new company handler
-func NewCompanyHandler(db *sql.DB) *CompanyHandler {
+// NewCompanyHandler creates a new company handler that publishes company
+// changes to bus and invalidates the cached responses they affect
+func NewCompanyHandler(db *sql.DB, bus *events.Bus, responses *cache.AdvancedCache) *CompanyHandler {
 	return &CompanyHandler{
-		repo: models.NewCompanyRepository(db),
+		repo:   models.NewCompanyRepository(db),
+		events: bus,
+		cache:  responses,
 	}
 }
 
@@ -30,10 +38,24 @@ func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.CreateCompany(&company); err != nil {
+	var created []events.Event
+	err := h.repo.CreateCompany(c.Request.Context(), &company, func() []events.Event {
+		created = []events.Event{events.NewEvent(events.TypeCompanyCreated, events.CompanyCreatedVersion, events.CompanyCreated{
+			CompanyID: company.ID,
+			Name:      company.Name,
+			Symbol:    company.Symbol,
+			Sector:    company.Sector,
+			Industry:  company.Industry,
+			Country:   company.Country,
+		})}
+		return created
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create company"})
 		return
 	}
+	invalidateCache(c, h.cache, companyWriteTags(&company)...)
+	h.events.PublishEvents(created...)
 
 	c.JSON(http.StatusCreated, company)
 }
@@ -46,9 +68,11 @@ func (h *CompanyHandler) GetCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := h.repo.GetCompanyByID(id)
+	company, err := h.repo.GetCompanyByID(c.Request.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			// Creating the company invalidates companies, so the cached 404 goes with it
+			middleware.AddCacheTags(c, cache.TagCompanies)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 			return
 		}
@@ -56,6 +80,8 @@ func (h *CompanyHandler) GetCompany(c *gin.Context) {
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.CompanyTag(company.ID))
+	middleware.SetLastModified(c, company.UpdatedAt)
 	c.JSON(http.StatusOK, company)
 }
 
@@ -67,9 +93,11 @@ func (h *CompanyHandler) GetCompanyBySymbol(c *gin.Context) {
 		return
 	}
 
-	company, err := h.repo.GetCompanyBySymbol(symbol)
+	company, err := h.repo.GetCompanyBySymbol(c.Request.Context(), symbol)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			// Creating the company invalidates companies, so the cached 404 goes with it
+			middleware.AddCacheTags(c, cache.TagCompanies)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 			return
 		}
@@ -77,6 +105,8 @@ func (h *CompanyHandler) GetCompanyBySymbol(c *gin.Context) {
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.CompanyTag(company.ID))
+	middleware.SetLastModified(c, company.UpdatedAt)
 	c.JSON(http.StatusOK, company)
 }
 
@@ -99,12 +129,22 @@ func (h *CompanyHandler) ListCompanies(c *gin.Context) {
 		}
 	}
 
-	companies, err := h.repo.ListCompanies(limit, offset, sector)
+	companies, err := h.repo.ListCompanies(c.Request.Context(), limit, offset, sector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve companies"})
 		return
 	}
 
+	// A sector's list only changes when a company in that sector does
+	if sector != "" {
+		middleware.AddCacheTags(c, cache.SectorTag(sector))
+	} else {
+		middleware.AddCacheTags(c, cache.TagCompanies)
+	}
+	for _, company := range companies {
+		middleware.SetLastModified(c, company.UpdatedAt)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"companies": companies,
 		"pagination": gin.H{
@@ -129,8 +169,19 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 		return
 	}
 
+	// Load the company first so lists of the sector it leaves are invalidated
+	previous, err := h.repo.GetCompanyByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update company"})
+		return
+	}
+
 	company.ID = id
-	if err := h.repo.UpdateCompany(&company); err != nil {
+	if err := h.repo.UpdateCompany(c.Request.Context(), &company); err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 			return
@@ -138,6 +189,7 @@ func (h *CompanyHandler) UpdateCompany(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update company"})
 		return
 	}
+	invalidateCache(c, h.cache, companyWriteTags(previous, &company)...)
 
 	c.JSON(http.StatusOK, company)
 }
@@ -150,21 +202,58 @@ func (h *CompanyHandler) DeleteCompany(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.DeleteCompany(id); err != nil {
+	// Load the company first so the deletion event can identify it
+	existing, err := h.repo.GetCompanyByID(c.Request.Context(), id)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete company"})
+		return
+	}
+
+	var deletedEvents []events.Event
+	if existing != nil {
+		deletedEvents = []events.Event{events.NewEvent(events.TypeCompanyDeleted, events.CompanyDeletedVersion, events.CompanyDeleted{
+			CompanyID: existing.ID,
+			Name:      existing.Name,
+			Symbol:    existing.Symbol,
+		})}
+	}
+
+	if err := h.repo.DeleteCompany(c.Request.Context(), id, func() []events.Event { return deletedEvents }); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete company"})
 		return
 	}
+	deleted := &models.Company{ID: id}
+	if existing != nil {
+		deleted = existing
+	}
+	invalidateCache(c, h.cache, companyWriteTags(deleted)...)
+	h.events.PublishEvents(deletedEvents...)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Company deleted successfully"})
 }
 
 // GetSectors handles GET /api/v1/companies/sectors
 func (h *CompanyHandler) GetSectors(c *gin.Context) {
-	sectors, err := h.repo.GetSectors()
+	sectors, err := h.repo.GetSectors(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sectors"})
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.TagCompanies)
 	c.JSON(http.StatusOK, gin.H{"sectors": sectors})
 }
+
+// companyWriteTags returns the tags to invalidate after writing companies:
+// company lists, ESG scores (which embed company names), analytics, and each
+// company and its sector
+func companyWriteTags(companies ...*models.Company) []string {
+	tags := []string{cache.TagCompanies, cache.TagESG, cache.TagAnalytics}
+	for _, company := range companies {
+		tags = append(tags, cache.CompanyTag(company.ID))
+		if company.Sector != "" {
+			tags = append(tags, cache.SectorTag(company.Sector))
+		}
+	}
+	return tags
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"net/http"
 
 	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,36 +17,52 @@ import (
 type DashboardHandler struct {
 	companyRepo *models.CompanyRepository
 	esgRepo     *models.ESGScoreRepository
+	cache       *cache.AdvancedCache
 }
 
-// NewDashboardHandler creates a new dashboard handler
-func NewDashboardHandler(db *sql.DB) *DashboardHandler {
+// NewDashboardHandler creates a new dashboard handler that caches the
+// dashboard in responses
+func NewDashboardHandler(db *sql.DB, responses *cache.AdvancedCache) *DashboardHandler {
 	return &DashboardHandler{
 		companyRepo: models.NewCompanyRepository(db),
 		esgRepo:     models.NewESGScoreRepository(db),
+		cache:       responses,
 	}
 }
 
 // GetDashboard handles GET /api/v1/dashboard
 func (h *DashboardHandler) GetDashboard(c *gin.Context) {
-	// Get top ESG scores
-	topScores, err := h.esgRepo.ListESGScores(5, 0, 0)
+	// Every visitor loads the dashboard, so concurrent misses share one load
+	var dashboard map[string]interface{}
+	err := h.cache.GetOrSet(c.Request.Context(), "dashboard", &dashboard, h.loadDashboard, cache.ShortTerm,
+		[]string{cache.TagCompanies, cache.TagESG})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ESG scores"})
+		middleware.RequestLogger(c).Error("Failed to load dashboard", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dashboard"})
 		return
 	}
 
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// loadDashboard loads the top ESG scores and sector statistics
+func (h *DashboardHandler) loadDashboard(ctx context.Context) (interface{}, error) {
+	// Get top ESG scores
+	topScores, err := h.esgRepo.ListESGScores(ctx, 5, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve ESG scores: %w", err)
+	}
+
 	// Get sectors
-	sectors, err := h.companyRepo.GetSectors()
+	sectors, err := h.companyRepo.GetSectors(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sectors"})
-		return
+		return nil, fmt.Errorf("retrieve sectors: %w", err)
 	}
 
 	// Get companies count by sector
 	sectorStats := make(map[string]int)
 	for _, sector := range sectors {
-		companies, err := h.companyRepo.ListCompanies(100, 0, sector)
+		companies, err := h.companyRepo.ListCompanies(ctx, 100, 0, sector)
 		if err != nil {
 			continue
 		}
@@ -62,7 +82,7 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 		avgScore = totalScore / float64(scoreCount)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	return gin.H{
 		"summary": gin.H{
 			"total_companies": len(sectors) * 2, // Rough estimate
 			"total_sectors":   len(sectors),
@@ -71,5 +91,5 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 		"top_esg_scores": topScores,
 		"sectors":        sectors,
 		"sector_stats":   sectorStats,
-	})
+	}, nil
 }
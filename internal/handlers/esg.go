@@ -1,25 +1,36 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"strconv"
 
+	"ethosview-backend/internal/events"
 	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/cache"
 	"ethosview-backend/pkg/errors"
+	"ethosview-backend/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ESGHandler handles ESG score-related HTTP requests
 type ESGHandler struct {
-	repo *models.ESGScoreRepository
+	repo        *models.ESGScoreRepository
+	companyRepo *models.CompanyRepository
+	events      *events.Bus
+	cache       *cache.AdvancedCache
 }
 
-// NewESGHandler creates a new ESG handler
-func NewESGHandler(db *sql.DB) *ESGHandler {
+// NewESGHandler creates a new ESG handler that publishes score changes to bus
+// and invalidates the cached responses they affect
+func NewESGHandler(db *sql.DB, bus *events.Bus, responses *cache.AdvancedCache) *ESGHandler {
 	return &ESGHandler{
-		repo: models.NewESGScoreRepository(db),
+		repo:        models.NewESGScoreRepository(db),
+		companyRepo: models.NewCompanyRepository(db),
+		events:      bus,
+		cache:       responses,
 	}
 }
 
@@ -31,11 +42,38 @@ func (h *ESGHandler) CreateESGScore(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.CreateESGScore(&score); err != nil {
+	previous, err := h.repo.GetLatestESGScoreByCompany(c.Request.Context(), score.CompanyID)
+	if err != nil && err != sql.ErrNoRows {
 		errors.HandleDatabaseError(c, err, "ESG score")
 		return
 	}
 
+	symbol, sector := h.companyLabels(c.Request.Context(), score.CompanyID)
+	var recorded []events.Event
+	err = h.repo.CreateESGScore(c.Request.Context(), &score, func() []events.Event {
+		recorded = []events.Event{events.NewEvent(events.TypeESGScoreCreated, events.ESGScoreCreatedVersion, events.ESGScoreCreated{
+			ScoreID:       score.ID,
+			CompanyID:     score.CompanyID,
+			CompanySymbol: symbol,
+			Sector:        sector,
+			ScoreDate:     score.ScoreDate,
+			Scores:        esgEventScores(&score),
+			DataSource:    score.DataSource,
+		})}
+
+		// Backfilled history does not change the company's current score
+		if previous == nil || !score.ScoreDate.Before(previous.ScoreDate) {
+			recorded = append(recorded, scoreUpdatedEvent(symbol, sector, previous, &score))
+		}
+		return recorded
+	})
+	if err != nil {
+		errors.HandleDatabaseError(c, err, "ESG score")
+		return
+	}
+	invalidateCache(c, h.cache, esgWriteTags(score.CompanyID)...)
+	h.events.PublishEvents(recorded...)
+
 	errors.SuccessResponse(c, score)
 }
 
@@ -47,12 +85,13 @@ func (h *ESGHandler) GetESGScore(c *gin.Context) {
 		return
 	}
 
-	score, err := h.repo.GetESGScoreByID(id)
+	score, err := h.repo.GetESGScoreByID(c.Request.Context(), id)
 	if err != nil {
 		errors.HandleDatabaseError(c, err, "ESG score")
 		return
 	}
 
+	middleware.SetLastModified(c, score.UpdatedAt)
 	errors.SuccessResponse(c, score)
 }
 
@@ -64,12 +103,14 @@ func (h *ESGHandler) GetLatestESGScoreByCompany(c *gin.Context) {
 		return
 	}
 
-	score, err := h.repo.GetLatestESGScoreByCompany(companyID)
+	score, err := h.repo.GetLatestESGScoreByCompany(c.Request.Context(), companyID)
 	if err != nil {
 		errors.HandleDatabaseError(c, err, "ESG score")
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.CompanyTag(companyID))
+	middleware.SetLastModified(c, score.UpdatedAt)
 	errors.SuccessResponse(c, score)
 }
 
@@ -97,12 +138,16 @@ func (h *ESGHandler) GetESGScoresByCompany(c *gin.Context) {
 		}
 	}
 
-	scores, err := h.repo.GetESGScoresByCompany(companyID, limit, offset)
+	scores, err := h.repo.GetESGScoresByCompany(c.Request.Context(), companyID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ESG scores"})
 		return
 	}
 
+	middleware.AddCacheTags(c, cache.CompanyTag(companyID))
+	for _, score := range scores {
+		middleware.SetLastModified(c, score.UpdatedAt)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"scores": scores,
 		"pagination": gin.H{
@@ -139,12 +184,15 @@ func (h *ESGHandler) ListESGScores(c *gin.Context) {
 		}
 	}
 
-	scores, err := h.repo.ListESGScores(limit, offset, minScore)
+	scores, err := h.repo.ListESGScores(c.Request.Context(), limit, offset, minScore)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ESG scores"})
 		return
 	}
 
+	for _, score := range scores {
+		middleware.SetLastModified(c, score.UpdatedAt)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"scores": scores,
 		"pagination": gin.H{
@@ -172,8 +220,30 @@ func (h *ESGHandler) UpdateESGScore(c *gin.Context) {
 		return
 	}
 
+	previous, err := h.repo.GetESGScoreByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ESG score not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ESG score"})
+		return
+	}
+
+	if score.CompanyID != 0 && score.CompanyID != previous.CompanyID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id cannot be changed"})
+		return
+	}
+
 	score.ID = id
-	if err := h.repo.UpdateESGScore(&score); err != nil {
+	score.CompanyID = previous.CompanyID
+	symbol, sector := h.companyLabels(c.Request.Context(), score.CompanyID)
+	var recorded []events.Event
+	err = h.repo.UpdateESGScore(c.Request.Context(), &score, func() []events.Event {
+		recorded = []events.Event{scoreUpdatedEvent(symbol, sector, previous, &score)}
+		return recorded
+	})
+	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "ESG score not found"})
 			return
@@ -181,6 +251,8 @@ func (h *ESGHandler) UpdateESGScore(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ESG score"})
 		return
 	}
+	invalidateCache(c, h.cache, esgWriteTags(score.CompanyID)...)
+	h.events.PublishEvents(recorded...)
 
 	c.JSON(http.StatusOK, score)
 }
@@ -193,10 +265,90 @@ func (h *ESGHandler) DeleteESGScore(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.DeleteESGScore(id); err != nil {
+	deleted, err := h.repo.GetESGScoreByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ESG score not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ESG score"})
 		return
 	}
 
+	latest, err := h.repo.GetESGScoresByCompany(c.Request.Context(), deleted.CompanyID, 2, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ESG score"})
+		return
+	}
+
+	var describe models.EventsFunc
+	var recorded []events.Event
+	if current, changed := latestAfterDelete(latest, id); changed && current != nil {
+		symbol, sector := h.companyLabels(c.Request.Context(), deleted.CompanyID)
+		describe = func() []events.Event {
+			recorded = []events.Event{scoreUpdatedEvent(symbol, sector, deleted, current)}
+			return recorded
+		}
+	}
+
+	if err := h.repo.DeleteESGScore(c.Request.Context(), id, describe); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ESG score"})
+		return
+	}
+	invalidateCache(c, h.cache, esgWriteTags(deleted.CompanyID)...)
+	h.events.PublishEvents(recorded...)
+
 	c.JSON(http.StatusOK, gin.H{"message": "ESG score deleted successfully"})
 }
+
+// esgWriteTags returns the tags to invalidate after writing ESG scores: ESG
+// score lists, analytics and the companies whose scores changed
+func esgWriteTags(companyIDs ...int) []string {
+	return append(companyCacheTags(companyIDs...), cache.TagESG)
+}
+
+// scoreUpdatedEvent builds an esg.score.updated event; previous may be nil
+func scoreUpdatedEvent(symbol, sector string, previous, current *models.ESGScore) events.Event {
+	var old *events.ESGScores
+	if previous != nil {
+		scores := esgEventScores(previous)
+		old = &scores
+	}
+
+	return events.NewEvent(events.TypeESGScoreUpdated, events.ESGScoreUpdatedVersion, events.NewESGScoreUpdated(
+		current.CompanyID, symbol, sector, current.ID, current.ScoreDate, old, esgEventScores(current),
+	))
+}
+
+// latestAfterDelete reports whether deleting the score id changes a company's
+// current score, given its latest scores newest first, and returns the score
+// that becomes current. It is nil when the company has no scores left.
+func latestAfterDelete(latest []*models.ESGScore, id int) (*models.ESGScore, bool) {
+	if len(latest) == 0 || latest[0].ID != id {
+		return nil, false
+	}
+	if len(latest) == 1 {
+		return nil, true
+	}
+	return latest[1], true
+}
+
+// companyLabels returns a company's symbol and sector for event payloads, or
+// empty strings if the company cannot be loaded
+func (h *ESGHandler) companyLabels(ctx context.Context, companyID int) (string, string) {
+	company, err := h.companyRepo.GetCompanyByID(ctx, companyID)
+	if err != nil {
+		return "", ""
+	}
+	return company.Symbol, company.Sector
+}
+
+// esgEventScores converts a stored score to its event representation
+func esgEventScores(score *models.ESGScore) events.ESGScores {
+	return events.ESGScores{
+		Environmental: score.EnvironmentalScore,
+		Social:        score.SocialScore,
+		Governance:    score.GovernanceScore,
+		Overall:       score.OverallScore,
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"testing"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestAfterDelete(t *testing.T) {
+	newest := &models.ESGScore{ID: 7}
+	older := &models.ESGScore{ID: 3}
+
+	current, changed := latestAfterDelete([]*models.ESGScore{newest, older}, 7)
+	assert.True(t, changed)
+	assert.Same(t, older, current)
+
+	current, changed = latestAfterDelete([]*models.ESGScore{newest, older}, 3)
+	assert.False(t, changed, "deleting an older score leaves the current one")
+	assert.Nil(t, current)
+
+	current, changed = latestAfterDelete([]*models.ESGScore{newest}, 7)
+	assert.True(t, changed, "the company has no scores left")
+	assert.Nil(t, current)
+
+	_, changed = latestAfterDelete(nil, 7)
+	assert.False(t, changed)
+}
@@ -6,7 +6,9 @@ import (
 	"strconv"
 	"time"
 
+	"ethosview-backend/internal/events"
 	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/cache"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,14 +18,21 @@ type FinancialHandler struct {
 	stockPriceRepo         *models.StockPriceRepository
 	financialIndicatorRepo *models.FinancialIndicatorRepository
 	marketDataRepo         *models.MarketDataRepository
+	importRepo             *models.FinancialImportRepository
+	events                 *events.Bus
+	cache                  *cache.AdvancedCache
 }
 
-// NewFinancialHandler creates a new financial handler
-func NewFinancialHandler(db *sql.DB) *FinancialHandler {
+// NewFinancialHandler creates a new financial handler that publishes import
+// events to bus and invalidates the cached analytics imports affect
+func NewFinancialHandler(db *sql.DB, bus *events.Bus, responses *cache.AdvancedCache) *FinancialHandler {
 	return &FinancialHandler{
 		stockPriceRepo:         models.NewStockPriceRepository(db),
 		financialIndicatorRepo: models.NewFinancialIndicatorRepository(db),
 		marketDataRepo:         models.NewMarketDataRepository(db),
+		importRepo:             models.NewFinancialImportRepository(db),
+		events:                 bus,
+		cache:                  responses,
 	}
 }
 
@@ -42,7 +51,7 @@ func (h *FinancialHandler) GetStockPrices(c *gin.Context) {
 		limit = 30
 	}
 
-	prices, err := h.stockPriceRepo.GetByCompanyID(companyID, limit)
+	prices, err := h.stockPriceRepo.GetByCompanyID(c.Request.Context(), companyID, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stock prices"})
 		return
@@ -64,7 +73,7 @@ func (h *FinancialHandler) GetLatestStockPrice(c *gin.Context) {
 		return
 	}
 
-	price, err := h.stockPriceRepo.GetLatestByCompanyID(companyID)
+	price, err := h.stockPriceRepo.GetLatestByCompanyID(c.Request.Context(), companyID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Stock price not found"})
 		return
@@ -85,7 +94,7 @@ func (h *FinancialHandler) GetFinancialIndicators(c *gin.Context) {
 		return
 	}
 
-	indicators, err := h.financialIndicatorRepo.GetByCompanyID(companyID)
+	indicators, err := h.financialIndicatorRepo.GetByCompanyID(c.Request.Context(), companyID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Financial indicators not found"})
 		return
@@ -99,7 +108,7 @@ func (h *FinancialHandler) GetFinancialIndicators(c *gin.Context) {
 
 // GetMarketData retrieves the latest market data
 func (h *FinancialHandler) GetMarketData(c *gin.Context) {
-	data, err := h.marketDataRepo.GetLatest()
+	data, err := h.marketDataRepo.GetLatest(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Market data not found"})
 		return
@@ -138,7 +147,7 @@ func (h *FinancialHandler) GetMarketDataHistory(c *gin.Context) {
 		limit = 30
 	}
 
-	data, err := h.marketDataRepo.GetByDateRange(startDate, endDate, limit)
+	data, err := h.marketDataRepo.GetByDateRange(c.Request.Context(), startDate, endDate, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve market data"})
 		return
@@ -162,14 +171,14 @@ func (h *FinancialHandler) GetCompanyFinancialSummary(c *gin.Context) {
 	}
 
 	// Get latest stock price
-	stockPrice, err := h.stockPriceRepo.GetLatestByCompanyID(companyID)
+	stockPrice, err := h.stockPriceRepo.GetLatestByCompanyID(c.Request.Context(), companyID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Company financial data not found"})
 		return
 	}
 
 	// Get financial indicators
-	indicators, err := h.financialIndicatorRepo.GetByCompanyID(companyID)
+	indicators, err := h.financialIndicatorRepo.GetByCompanyID(c.Request.Context(), companyID)
 	if err != nil {
 		// Continue without indicators if not available
 		indicators = nil
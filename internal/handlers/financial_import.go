@@ -0,0 +1,730 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// importBatchSize is the number of validated rows sent to Postgres per COPY batch
+	importBatchSize = 1000
+
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+)
+
+// importRecordReader yields uploaded rows as column name -> raw value maps
+type importRecordReader interface {
+	// Next returns the next record and its 1-based row number, or io.EOF
+	Next() (map[string]string, int, error)
+}
+
+// importRecordError marks a single malformed record that can be skipped
+type importRecordError struct {
+	row int
+	err error
+}
+
+func (e *importRecordError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.row, e.err)
+}
+
+// csvRecordReader reads records from a CSV stream with a header row
+type csvRecordReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func newCSVRecordReader(r io.Reader) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("CSV upload is empty")
+		}
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	return &csvRecordReader{reader: reader, header: header}, nil
+}
+
+func (r *csvRecordReader) Next() (map[string]string, int, error) {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	r.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, r.row, &importRecordError{row: r.row, err: parseErr.Err}
+		}
+		return nil, r.row, err
+	}
+
+	if len(fields) != len(r.header) {
+		return nil, r.row, &importRecordError{
+			row: r.row,
+			err: fmt.Errorf("expected %d columns, got %d", len(r.header), len(fields)),
+		}
+	}
+
+	record := make(map[string]string, len(fields))
+	for i, value := range fields {
+		record[r.header[i]] = strings.TrimSpace(value)
+	}
+	return record, r.row, nil
+}
+
+// ndjsonRecordReader reads one JSON object per line
+type ndjsonRecordReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func newNDJSONRecordReader(r io.Reader) *ndjsonRecordReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &ndjsonRecordReader{scanner: scanner}
+}
+
+func (r *ndjsonRecordReader) Next() (map[string]string, int, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		r.row++
+
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, r.row, &importRecordError{row: r.row, err: errors.New("invalid JSON object")}
+		}
+
+		record := make(map[string]string, len(raw))
+		for key, value := range raw {
+			key = strings.ToLower(strings.TrimSpace(key))
+			switch v := value.(type) {
+			case nil:
+				record[key] = ""
+			case string:
+				record[key] = strings.TrimSpace(v)
+			case json.Number:
+				record[key] = v.String()
+			default:
+				return nil, r.row, &importRecordError{row: r.row, err: fmt.Errorf("field %q must be a string or number", key)}
+			}
+		}
+		return record, r.row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, r.row, err
+	}
+	return nil, 0, io.EOF
+}
+
+// openImportUpload resolves the upload body and format from the request.
+// Raw bodies use the format query parameter or Content-Type; multipart
+// uploads read the "file" field and fall back to its extension.
+func openImportUpload(c *gin.Context) (io.ReadCloser, string, error) {
+	format := strings.ToLower(c.Query("format"))
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	body := c.Request.Body
+	if mediaType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("multipart upload requires a \"file\" field")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		body = file
+
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+			case ".csv":
+				format = importFormatCSV
+			case ".ndjson", ".jsonl", ".json":
+				format = importFormatNDJSON
+			}
+		}
+	}
+
+	if format == "" {
+		switch mediaType {
+		case "text/csv", "application/csv":
+			format = importFormatCSV
+		case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/json":
+			format = importFormatNDJSON
+		}
+	}
+
+	if format != importFormatCSV && format != importFormatNDJSON {
+		body.Close()
+		return nil, "", errors.New("unsupported upload format, use csv or ndjson")
+	}
+
+	return body, format, nil
+}
+
+func newImportRecordReader(body io.Reader, format string) (importRecordReader, error) {
+	if format == importFormatCSV {
+		return newCSVRecordReader(body)
+	}
+	return newNDJSONRecordReader(body), nil
+}
+
+// pendingImportRow is a validated row waiting for symbol resolution
+type pendingImportRow[T any] struct {
+	row    int
+	symbol string
+	value  T
+}
+
+// importDataset describes how to validate and persist one dataset
+type importDataset[T any] struct {
+	name string
+	// parse validates a raw record and returns the typed row and its company symbol
+	parse func(record map[string]string) (T, string, *models.ImportRowError)
+	// bind attaches the resolved company ID and upload sequence to a row
+	bind func(value *T, companyID, seq int)
+	// upsert persists a batch of rows
+	upsert func(ctx context.Context, batch []T) (int64, error)
+	// afterImport, if set, runs once with the companies whose rows were committed,
+	// including when a later batch fails
+	afterImport func(ctx context.Context, companyIDs []int)
+}
+
+// runImport streams records from the upload, validating and flushing them in batches
+func runImport[T any](h *FinancialHandler, c *gin.Context, dataset importDataset[T]) {
+	body, format, err := openImportUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	reader, err := newImportRecordReader(body, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := models.NewImportReport(dataset.name, format)
+	companyIDs := make(map[string]int)
+	pending := make([]pendingImportRow[T], 0, importBatchSize)
+
+	imported := make(map[int]bool)
+	defer func() {
+		if dataset.afterImport == nil || len(imported) == 0 {
+			return
+		}
+		ids := make([]int, 0, len(imported))
+		for id := range imported {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		dataset.afterImport(c.Request.Context(), ids)
+	}()
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		// Resolve symbols not seen in earlier batches
+		var unknown []string
+		seen := make(map[string]bool)
+		for _, p := range pending {
+			if p.symbol == "" || seen[p.symbol] {
+				continue
+			}
+			if _, ok := companyIDs[p.symbol]; !ok {
+				unknown = append(unknown, p.symbol)
+				seen[p.symbol] = true
+			}
+		}
+		if len(unknown) > 0 {
+			resolved, err := h.importRepo.ResolveCompanyIDs(c.Request.Context(), unknown)
+			if err != nil {
+				return err
+			}
+			for _, symbol := range unknown {
+				if id, ok := resolved[symbol]; ok {
+					companyIDs[symbol] = id
+				} else {
+					companyIDs[symbol] = 0
+				}
+			}
+		}
+
+		batch := make([]T, 0, len(pending))
+		batchCompanies := make([]int, 0, len(pending))
+		for _, p := range pending {
+			companyID := 0
+			if p.symbol != "" {
+				companyID = companyIDs[p.symbol]
+				if companyID == 0 {
+					report.Reject(models.ImportRowError{Row: p.row, Field: "symbol", Message: fmt.Sprintf("unknown company symbol %q", p.symbol)})
+					continue
+				}
+			}
+			value := p.value
+			dataset.bind(&value, companyID, p.row)
+			batch = append(batch, value)
+			batchCompanies = append(batchCompanies, companyID)
+		}
+
+		affected, err := dataset.upsert(c.Request.Context(), batch)
+		if err != nil {
+			return err
+		}
+		report.Upserted += int(affected)
+		for _, id := range batchCompanies {
+			if id != 0 {
+				imported[id] = true
+			}
+		}
+
+		// Each batch commits on its own, so drop what it invalidates now
+		invalidateCache(c, h.cache, companyCacheTags(batchCompanies...)...)
+		pending = pending[:0]
+		return nil
+	}
+
+	for {
+		record, row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var recordErr *importRecordError
+			if errors.As(err, &recordErr) {
+				report.TotalRows++
+				report.Reject(models.ImportRowError{Row: recordErr.row, Message: recordErr.err.Error()})
+				continue
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload", "report": report})
+			return
+		}
+
+		report.TotalRows++
+		value, symbol, rowErr := dataset.parse(record)
+		if rowErr != nil {
+			rowErr.Row = row
+			report.Reject(*rowErr)
+			continue
+		}
+
+		pending = append(pending, pendingImportRow[T]{row: row, symbol: symbol, value: value})
+		if len(pending) >= importBatchSize {
+			if err := flush(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import " + dataset.name, "report": report})
+				return
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import " + dataset.name, "report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report":  report,
+		"message": "Import completed",
+	})
+}
+
+// ImportStockPrices handles POST /api/v1/financial/import/stock-prices
+func (h *FinancialHandler) ImportStockPrices(c *gin.Context) {
+	runImport(h, c, importDataset[models.StockPriceImportRow]{
+		name:  "stock_prices",
+		parse: parseStockPriceRecord,
+		bind: func(row *models.StockPriceImportRow, companyID, seq int) {
+			row.CompanyID = companyID
+			row.Seq = seq
+		},
+		upsert:      h.importRepo.UpsertStockPrices,
+		afterImport: h.publishPriceTicks,
+	})
+}
+
+// publishPriceTicks publishes a price.tick with the latest stored price of each imported company
+func (h *FinancialHandler) publishPriceTicks(ctx context.Context, companyIDs []int) {
+	prices, err := h.importRepo.LatestPrices(ctx, companyIDs)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load latest prices for price.tick events", "error", err)
+		return
+	}
+
+	for _, price := range prices {
+		tick := events.PriceTick{
+			CompanyID:     price.CompanyID,
+			CompanySymbol: price.CompanySymbol,
+			Sector:        price.Sector,
+			Date:          price.Date,
+			Open:          price.OpenPrice,
+			High:          price.HighPrice,
+			Low:           price.LowPrice,
+			Close:         price.ClosePrice,
+			Volume:        price.Volume,
+			PreviousClose: price.PreviousClose,
+		}
+		if price.PreviousClose != nil && *price.PreviousClose != 0 {
+			change := price.ClosePrice - *price.PreviousClose
+			changePercent := change / *price.PreviousClose * 100
+			tick.Change = &change
+			tick.ChangePercent = &changePercent
+		}
+		h.events.Publish(events.TypePriceTick, events.PriceTickVersion, tick)
+	}
+}
+
+// ImportFinancialIndicators handles POST /api/v1/financial/import/indicators
+func (h *FinancialHandler) ImportFinancialIndicators(c *gin.Context) {
+	runImport(h, c, importDataset[models.FinancialIndicatorImportRow]{
+		name:  "financial_indicators",
+		parse: parseFinancialIndicatorRecord,
+		bind: func(row *models.FinancialIndicatorImportRow, companyID, seq int) {
+			row.CompanyID = companyID
+			row.Seq = seq
+		},
+		upsert: h.importRepo.UpsertFinancialIndicators,
+	})
+}
+
+// ImportMarketData handles POST /api/v1/financial/import/market-data
+func (h *FinancialHandler) ImportMarketData(c *gin.Context) {
+	runImport(h, c, importDataset[models.MarketDataImportRow]{
+		name:  "market_data",
+		parse: parseMarketDataRecord,
+		bind: func(row *models.MarketDataImportRow, _ int, seq int) {
+			row.Seq = seq
+		},
+		upsert: h.importRepo.UpsertMarketData,
+	})
+}
+
+// StockPriceUploadRow documents the columns of a stock price import, as read
+// by parseStockPriceRecord; dates are YYYY-MM-DD
+type StockPriceUploadRow struct {
+	Symbol        string  `json:"symbol" binding:"required"`
+	Date          string  `json:"date" binding:"required"`
+	OpenPrice     float64 `json:"open_price" binding:"required"`
+	HighPrice     float64 `json:"high_price" binding:"required"`
+	LowPrice      float64 `json:"low_price" binding:"required"`
+	ClosePrice    float64 `json:"close_price" binding:"required"`
+	Volume        int64   `json:"volume" binding:"required,min=0"`
+	AdjustedClose float64 `json:"adjusted_close"`
+}
+
+// IndicatorUploadRow documents the columns of a financial indicator import, as
+// read by parseFinancialIndicatorRecord
+type IndicatorUploadRow struct {
+	Symbol         string   `json:"symbol" binding:"required"`
+	Date           string   `json:"date" binding:"required"`
+	MarketCap      *float64 `json:"market_cap" binding:"omitempty,min=0"`
+	PERatio        *float64 `json:"pe_ratio"`
+	PBRatio        *float64 `json:"pb_ratio"`
+	DebtToEquity   *float64 `json:"debt_to_equity"`
+	ReturnOnEquity *float64 `json:"return_on_equity"`
+	ProfitMargin   *float64 `json:"profit_margin"`
+	RevenueGrowth  *float64 `json:"revenue_growth"`
+}
+
+// MarketDataUploadRow documents the columns of a market data import, as read by
+// parseMarketDataRecord
+type MarketDataUploadRow struct {
+	Date        string   `json:"date" binding:"required"`
+	SP500Close  *float64 `json:"sp500_close" binding:"omitempty,min=0"`
+	NasdaqClose *float64 `json:"nasdaq_close" binding:"omitempty,min=0"`
+	DowClose    *float64 `json:"dow_close" binding:"omitempty,min=0"`
+	VIXClose    *float64 `json:"vix_close" binding:"omitempty,min=0"`
+	Treasury10Y *float64 `json:"treasury_10y" binding:"omitempty,min=0"`
+}
+
+// parseStockPriceRecord validates a stock price record
+func parseStockPriceRecord(record map[string]string) (models.StockPriceImportRow, string, *models.ImportRowError) {
+	var row models.StockPriceImportRow
+
+	symbol, rowErr := requiredSymbol(record)
+	if rowErr != nil {
+		return row, "", rowErr
+	}
+
+	if row.Date, rowErr = requiredDate(record, "date"); rowErr != nil {
+		return row, "", rowErr
+	}
+
+	prices := []struct {
+		field string
+		dest  *float64
+	}{
+		{"open_price", &row.OpenPrice},
+		{"high_price", &row.HighPrice},
+		{"low_price", &row.LowPrice},
+		{"close_price", &row.ClosePrice},
+	}
+	for _, p := range prices {
+		value, rowErr := requiredPositive(record, p.field)
+		if rowErr != nil {
+			return row, "", rowErr
+		}
+		*p.dest = value
+	}
+
+	if row.HighPrice < row.LowPrice {
+		return row, "", &models.ImportRowError{Field: "high_price", Message: "high_price must be greater than or equal to low_price"}
+	}
+	if row.OpenPrice < row.LowPrice || row.OpenPrice > row.HighPrice {
+		return row, "", &models.ImportRowError{Field: "open_price", Message: "open_price must be between low_price and high_price"}
+	}
+	if row.ClosePrice < row.LowPrice || row.ClosePrice > row.HighPrice {
+		return row, "", &models.ImportRowError{Field: "close_price", Message: "close_price must be between low_price and high_price"}
+	}
+
+	volumeStr := record["volume"]
+	if volumeStr == "" {
+		return row, "", &models.ImportRowError{Field: "volume", Message: "volume is required"}
+	}
+	volume, err := strconv.ParseInt(volumeStr, 10, 64)
+	if err != nil || volume < 0 {
+		return row, "", &models.ImportRowError{Field: "volume", Message: "volume must be a non-negative integer"}
+	}
+	row.Volume = volume
+
+	// Adjusted close defaults to the close price when not supplied
+	row.AdjustedClose = row.ClosePrice
+	if record["adjusted_close"] != "" {
+		if row.AdjustedClose, rowErr = requiredPositive(record, "adjusted_close"); rowErr != nil {
+			return row, "", rowErr
+		}
+	}
+
+	return row, symbol, nil
+}
+
+// parseFinancialIndicatorRecord validates a financial indicator record
+func parseFinancialIndicatorRecord(record map[string]string) (models.FinancialIndicatorImportRow, string, *models.ImportRowError) {
+	var row models.FinancialIndicatorImportRow
+
+	symbol, rowErr := requiredSymbol(record)
+	if rowErr != nil {
+		return row, "", rowErr
+	}
+
+	if row.Date, rowErr = requiredDate(record, "date"); rowErr != nil {
+		return row, "", rowErr
+	}
+
+	fields := []struct {
+		field string
+		dest  **float64
+	}{
+		{"market_cap", &row.MarketCap},
+		{"pe_ratio", &row.PERatio},
+		{"pb_ratio", &row.PBRatio},
+		{"debt_to_equity", &row.DebtToEquity},
+		{"return_on_equity", &row.ReturnOnEquity},
+		{"profit_margin", &row.ProfitMargin},
+		{"revenue_growth", &row.RevenueGrowth},
+	}
+	present := 0
+	for _, f := range fields {
+		value, rowErr := optionalFloat(record, f.field)
+		if rowErr != nil {
+			return row, "", rowErr
+		}
+		if value != nil {
+			present++
+		}
+		*f.dest = value
+	}
+
+	if row.MarketCap != nil && *row.MarketCap < 0 {
+		return row, "", &models.ImportRowError{Field: "market_cap", Message: "market_cap must not be negative"}
+	}
+	if present == 0 {
+		return row, "", &models.ImportRowError{Message: "at least one indicator value is required"}
+	}
+
+	return row, symbol, nil
+}
+
+// parseMarketDataRecord validates a market data record
+func parseMarketDataRecord(record map[string]string) (models.MarketDataImportRow, string, *models.ImportRowError) {
+	var row models.MarketDataImportRow
+	var rowErr *models.ImportRowError
+
+	if row.Date, rowErr = requiredDate(record, "date"); rowErr != nil {
+		return row, "", rowErr
+	}
+
+	fields := []struct {
+		field string
+		dest  **float64
+	}{
+		{"sp500_close", &row.SP500Close},
+		{"nasdaq_close", &row.NasdaqClose},
+		{"dow_close", &row.DowClose},
+		{"vix_close", &row.VIXClose},
+		{"treasury_10y", &row.Treasury10Y},
+	}
+	present := 0
+	for _, f := range fields {
+		value, rowErr := optionalFloat(record, f.field)
+		if rowErr != nil {
+			return row, "", rowErr
+		}
+		if value != nil {
+			if *value < 0 {
+				return row, "", &models.ImportRowError{Field: f.field, Message: f.field + " must not be negative"}
+			}
+			present++
+		}
+		*f.dest = value
+	}
+
+	if present == 0 {
+		return row, "", &models.ImportRowError{Message: "at least one market value is required"}
+	}
+
+	return row, "", nil
+}
+
+func requiredSymbol(record map[string]string) (string, *models.ImportRowError) {
+	symbol := strings.ToUpper(record["symbol"])
+	if symbol == "" {
+		return "", &models.ImportRowError{Field: "symbol", Message: "symbol is required"}
+	}
+	return symbol, nil
+}
+
+func requiredDate(record map[string]string, field string) (time.Time, *models.ImportRowError) {
+	value := record[field]
+	if value == "" {
+		return time.Time{}, &models.ImportRowError{Field: field, Message: field + " is required"}
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, &models.ImportRowError{Field: field, Message: "invalid " + field + " format (YYYY-MM-DD)"}
+	}
+	return date, nil
+}
+
+// decimalColumn is the precision and scale of a NUMERIC column
+type decimalColumn struct {
+	precision int
+	scale     int
+}
+
+// importColumns are the DECIMAL columns imported fields are stored in; values
+// must fit them once rounded, or the whole COPY batch fails
+var importColumns = map[string]decimalColumn{
+	"open_price":       {10, 2},
+	"high_price":       {10, 2},
+	"low_price":        {10, 2},
+	"close_price":      {10, 2},
+	"adjusted_close":   {10, 2},
+	"market_cap":       {20, 2},
+	"pe_ratio":         {10, 4},
+	"pb_ratio":         {10, 4},
+	"debt_to_equity":   {10, 4},
+	"return_on_equity": {10, 4},
+	"profit_margin":    {10, 4},
+	"revenue_growth":   {10, 4},
+	"sp500_close":      {10, 2},
+	"nasdaq_close":     {10, 2},
+	"dow_close":        {10, 2},
+	"vix_close":        {10, 4},
+	"treasury_10y":     {10, 4},
+}
+
+// round rounds value to the column's scale, as Postgres does on insert
+func (d decimalColumn) round(value float64) float64 {
+	factor := math.Pow10(d.scale)
+	return math.Round(value*factor) / factor
+}
+
+// fits reports whether a rounded value has no more integer digits than the
+// column allows
+func (d decimalColumn) fits(value float64) bool {
+	return math.Abs(d.round(value)) < math.Pow10(d.precision-d.scale)
+}
+
+// parseNumber parses a finite number that fits the field's column
+func parseNumber(field, value string) (float64, *models.ImportRowError) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return 0, &models.ImportRowError{Field: field, Message: field + " must be a finite number"}
+	}
+	if column, ok := importColumns[field]; ok && !column.fits(parsed) {
+		return 0, &models.ImportRowError{
+			Field:   field,
+			Message: fmt.Sprintf("%s is out of range (at most %d digits before the decimal point)", field, column.precision-column.scale),
+		}
+	}
+	return parsed, nil
+}
+
+func requiredPositive(record map[string]string, field string) (float64, *models.ImportRowError) {
+	value := record[field]
+	if value == "" {
+		return 0, &models.ImportRowError{Field: field, Message: field + " is required"}
+	}
+	parsed, rowErr := parseNumber(field, value)
+	if rowErr != nil {
+		return 0, rowErr
+	}
+	// Values that round to zero in the column are not positive either
+	if column, ok := importColumns[field]; parsed <= 0 || (ok && column.round(parsed) <= 0) {
+		return 0, &models.ImportRowError{Field: field, Message: field + " must be a positive number"}
+	}
+	return parsed, nil
+}
+
+func optionalFloat(record map[string]string, field string) (*float64, *models.ImportRowError) {
+	value := record[field]
+	if value == "" {
+		return nil, nil
+	}
+	parsed, rowErr := parseNumber(field, value)
+	if rowErr != nil {
+		return nil, rowErr
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVRecordReader(t *testing.T) {
+	input := "Symbol,Date,Close_Price\nAAPL,2024-01-02,185.64\nMSFT,2024-01-02\n"
+
+	reader, err := newCSVRecordReader(strings.NewReader(input))
+	require.NoError(t, err)
+
+	record, row, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, row)
+	assert.Equal(t, "AAPL", record["symbol"])
+	assert.Equal(t, "185.64", record["close_price"])
+
+	_, row, err = reader.Next()
+	var recordErr *importRecordError
+	require.ErrorAs(t, err, &recordErr)
+	assert.Equal(t, 2, row)
+
+	_, _, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNDJSONRecordReader(t *testing.T) {
+	input := `{"symbol":"AAPL","date":"2024-01-02","volume":1200}` + "\n\n" + `not json` + "\n"
+
+	reader := newNDJSONRecordReader(strings.NewReader(input))
+
+	record, row, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, row)
+	assert.Equal(t, "1200", record["volume"])
+
+	_, row, err = reader.Next()
+	var recordErr *importRecordError
+	require.ErrorAs(t, err, &recordErr)
+	assert.Equal(t, 2, row)
+
+	_, _, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestParseStockPriceRecord(t *testing.T) {
+	valid := map[string]string{
+		"symbol":      "aapl",
+		"date":        "2024-01-02",
+		"open_price":  "187.15",
+		"high_price":  "188.44",
+		"low_price":   "183.89",
+		"close_price": "185.64",
+		"volume":      "82488700",
+	}
+
+	row, symbol, rowErr := parseStockPriceRecord(valid)
+	require.Nil(t, rowErr)
+	assert.Equal(t, "AAPL", symbol)
+	assert.Equal(t, 185.64, row.AdjustedClose)
+	assert.Equal(t, int64(82488700), row.Volume)
+
+	tests := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{"missing symbol", "symbol", ""},
+		{"bad date", "date", "02/01/2024"},
+		{"negative price", "open_price", "-1"},
+		{"high below low", "high_price", "100"},
+		{"open above high", "open_price", "190"},
+		{"close below low", "close_price", "180"},
+		{"fractional volume", "volume", "10.5"},
+		{"NaN price", "close_price", "NaN"},
+		{"infinite price", "close_price", "+Inf"},
+		{"overflowing price", "close_price", "1e300"},
+		{"price beyond the column", "low_price", "100000000"},
+		{"price rounding beyond the column", "low_price", "99999999.996"},
+		{"price rounding to zero", "low_price", "0.001"},
+		{"NaN adjusted close", "adjusted_close", "nan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := make(map[string]string, len(valid))
+			for k, v := range valid {
+				record[k] = v
+			}
+			record[tt.field] = tt.value
+
+			_, _, rowErr := parseStockPriceRecord(record)
+			require.NotNil(t, rowErr)
+			assert.Equal(t, tt.field, rowErr.Field)
+		})
+	}
+}
+
+func TestParseMarketDataRecord(t *testing.T) {
+	row, _, rowErr := parseMarketDataRecord(map[string]string{"date": "2024-01-02", "sp500_close": "4742.83"})
+	require.Nil(t, rowErr)
+	require.NotNil(t, row.SP500Close)
+	assert.Nil(t, row.NasdaqClose)
+
+	_, _, rowErr = parseMarketDataRecord(map[string]string{"date": "2024-01-02"})
+	assert.NotNil(t, rowErr)
+}
+
+func TestParseFinancialIndicatorRecordChecksColumnRanges(t *testing.T) {
+	row, _, rowErr := parseFinancialIndicatorRecord(map[string]string{"symbol": "AAPL", "date": "2024-01-02", "pe_ratio": "999999.9999", "market_cap": "2.9e12"})
+	require.Nil(t, rowErr)
+	assert.Equal(t, 999999.9999, *row.PERatio)
+
+	for field, value := range map[string]string{
+		"pe_ratio":       "1000000",
+		"market_cap":     "NaN",
+		"revenue_growth": "-Infinity",
+		"profit_margin":  "1e300",
+	} {
+		_, _, rowErr := parseFinancialIndicatorRecord(map[string]string{"symbol": "AAPL", "date": "2024-01-02", field: value})
+		require.NotNil(t, rowErr, field)
+		assert.Equal(t, field, rowErr.Field)
+	}
+
+	_, _, rowErr = parseMarketDataRecord(map[string]string{"date": "2024-01-02", "vix_close": "NaN"})
+	require.NotNil(t, rowErr)
+	assert.Equal(t, "vix_close", rowErr.Field)
+}
+
+// uploadColumns returns the columns an upload row documents and which of
+// them are required
+func uploadColumns(row interface{}) (map[string]bool, []string) {
+	columns := make(map[string]bool)
+	var names []string
+	t := reflect.TypeOf(row)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		columns[name] = strings.Contains(field.Tag.Get("binding"), "required")
+		names = append(names, name)
+	}
+	return columns, names
+}
+
+// assertParsesDocumentedColumns checks parse against the columns row
+// documents: a record of just those columns parses, an invalid value in any
+// of them fails on it, and so does dropping a required one
+func assertParsesDocumentedColumns[T any](t *testing.T, row interface{}, parse func(map[string]string) (T, string, *models.ImportRowError)) {
+	t.Helper()
+	samples := map[string]string{"symbol": "AAPL", "date": "2024-01-02", "volume": "1000"}
+	columns, names := uploadColumns(row)
+
+	valid := make(map[string]string)
+	for _, name := range names {
+		valid[name] = "100"
+		if sample, ok := samples[name]; ok {
+			valid[name] = sample
+		}
+	}
+	_, _, rowErr := parse(valid)
+	require.Nil(t, rowErr, "a record of the documented columns parses")
+
+	for _, name := range names {
+		record := make(map[string]string, len(valid))
+		for k, v := range valid {
+			record[k] = v
+		}
+		// Any non-empty symbol is valid; dropping it below shows it is read
+		if name != "symbol" {
+			record[name] = "x"
+			_, _, rowErr := parse(record)
+			if assert.NotNil(t, rowErr, "%s is read", name) {
+				assert.Equal(t, name, rowErr.Field)
+			}
+		}
+
+		if columns[name] {
+			delete(record, name)
+			_, _, rowErr := parse(record)
+			if assert.NotNil(t, rowErr, "%s is required", name) {
+				assert.Equal(t, name, rowErr.Field)
+			}
+		}
+	}
+}
+
+func TestUploadRowsDocumentTheParsedColumns(t *testing.T) {
+	assertParsesDocumentedColumns(t, StockPriceUploadRow{}, parseStockPriceRecord)
+	assertParsesDocumentedColumns(t, IndicatorUploadRow{}, parseFinancialIndicatorRecord)
+	assertParsesDocumentedColumns(t, MarketDataUploadRow{}, parseMarketDataRecord)
+
+	documented := make(map[string]bool)
+	for _, row := range []interface{}{StockPriceUploadRow{}, IndicatorUploadRow{}, MarketDataUploadRow{}} {
+		columns, _ := uploadColumns(row)
+		for name := range columns {
+			documented[name] = true
+		}
+	}
+	for name := range importColumns {
+		assert.True(t, documented[name], "%s is imported but not documented", name)
+	}
+}
@@ -31,7 +31,7 @@ func TestIntegration_DashboardHandler(t *testing.T) {
 		return
 	}
 
-	handler := NewDashboardHandler(db)
+	handler := NewDashboardHandler(db, nil)
 	router := gin.New()
 	router.GET("/dashboard", handler.GetDashboard)
 
@@ -75,7 +75,7 @@ func TestIntegration_CompanyHandler(t *testing.T) {
 		return
 	}
 
-	handler := NewCompanyHandler(db)
+	handler := NewCompanyHandler(db, nil, nil)
 	router := gin.New()
 	router.GET("/companies", handler.ListCompanies)
 
@@ -111,7 +111,7 @@ func TestIntegration_CompanyHandler_GetBySymbol(t *testing.T) {
 		return
 	}
 
-	handler := NewCompanyHandler(db)
+	handler := NewCompanyHandler(db, nil, nil)
 	router := gin.New()
 	router.GET("/companies/symbol/:symbol", handler.GetCompanyBySymbol)
 
@@ -185,7 +185,7 @@ func TestIntegration_ESGHandler(t *testing.T) {
 		return
 	}
 
-	handler := NewESGHandler(db)
+	handler := NewESGHandler(db, nil, nil)
 	router := gin.New()
 	router.GET("/esg/scores", handler.ListESGScores)
 
@@ -221,7 +221,7 @@ func TestIntegration_FinancialHandler(t *testing.T) {
 		return
 	}
 
-	handler := NewFinancialHandler(db)
+	handler := NewFinancialHandler(db, nil, nil)
 	router := gin.New()
 	router.GET("/financial/market", handler.GetMarketData)
 
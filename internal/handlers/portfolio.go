@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// PortfolioHandler handles saved portfolio HTTP requests for the authenticated user
+type PortfolioHandler struct {
+	repo      *models.PortfolioRepository
+	optimizer *models.AdvancedAnalyticsRepository
+}
+
+// NewPortfolioHandler creates a new portfolio handler
+func NewPortfolioHandler(db *sql.DB) *PortfolioHandler {
+	return &PortfolioHandler{
+		repo:      models.NewPortfolioRepository(db),
+		optimizer: models.NewAdvancedAnalyticsRepository(db),
+	}
+}
+
+// HoldingRequest identifies a holding by symbol with a quantity or a weight
+type HoldingRequest struct {
+	Symbol   string   `json:"symbol" binding:"required"`
+	Quantity *float64 `json:"quantity"`
+	Weight   *float64 `json:"weight"`
+}
+
+// CreatePortfolioRequest represents the create portfolio request
+type CreatePortfolioRequest struct {
+	Name        string           `json:"name" binding:"required,max=100"`
+	Description string           `json:"description"`
+	Basis       string           `json:"basis" binding:"omitempty,oneof=quantity weight"`
+	Source      string           `json:"source" binding:"omitempty,max=50"`
+	Holdings    []HoldingRequest `json:"holdings" binding:"dive"`
+}
+
+// UpdatePortfolioRequest represents the update portfolio request
+type UpdatePortfolioRequest struct {
+	Name        string  `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+}
+
+// SaveOptimizedPortfolioRequest names the portfolio an optimization is saved as
+type SaveOptimizedPortfolioRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// ReplaceHoldingsRequest represents a full replacement of a portfolio's holdings
+type ReplaceHoldingsRequest struct {
+	Holdings []HoldingRequest `json:"holdings" binding:"dive"`
+}
+
+// ListPortfolios handles GET /api/v1/me/portfolios
+func (h *PortfolioHandler) ListPortfolios(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	portfolios, err := h.repo.ListPortfolios(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve portfolios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"portfolios": portfolios,
+		"count":      len(portfolios),
+	})
+}
+
+// CreatePortfolio handles POST /api/v1/me/portfolios
+func (h *PortfolioHandler) CreatePortfolio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePortfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	portfolio := &models.Portfolio{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Basis:       req.Basis,
+		Source:      req.Source,
+	}
+	if portfolio.Basis == "" {
+		portfolio.Basis = models.PortfolioBasisWeight
+	}
+	if portfolio.Source == "" {
+		portfolio.Source = "manual"
+	}
+
+	holdings, err := validateHoldings(portfolio.Basis, req.Holdings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.CreatePortfolio(c.Request.Context(), portfolio, holdings); err != nil {
+		h.writeError(c, err, "Failed to create portfolio")
+		return
+	}
+
+	c.JSON(http.StatusCreated, portfolio)
+}
+
+// SaveOptimizedPortfolio handles POST /api/v1/me/portfolios/optimize. It runs
+// the optimizer with the query parameters of GET
+// /api/v1/advanced/portfolio/optimize and saves the allocations as a weight
+// portfolio.
+func (h *PortfolioHandler) SaveOptimizedPortfolio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SaveOptimizedPortfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	constraints, ok := optimizationConstraints(c)
+	if !ok {
+		return
+	}
+	if constraints.AllowShort {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Portfolios with short positions cannot be saved"})
+		return
+	}
+
+	optimization, err := h.optimizer.OptimizePortfolio(c.Request.Context(), constraints)
+	if err != nil {
+		writeOptimizationError(c, err)
+		return
+	}
+
+	holdings, err := validateHoldings(models.PortfolioBasisWeight, allocationHoldings(optimization.Allocations))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Optimized weights cannot be saved", "details": err.Error()})
+		return
+	}
+
+	portfolio := &models.Portfolio{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Basis:       models.PortfolioBasisWeight,
+		Source:      "optimizer",
+	}
+	if err := h.repo.CreatePortfolio(c.Request.Context(), portfolio, holdings); err != nil {
+		h.writeError(c, err, "Failed to save portfolio")
+		return
+	}
+	optimization.PortfolioID = &portfolio.ID
+
+	c.JSON(http.StatusCreated, gin.H{
+		"portfolio":    portfolio,
+		"optimization": optimization,
+	})
+}
+
+// GetPortfolio handles GET /api/v1/me/portfolios/:id
+func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	portfolio, err := h.repo.GetPortfolio(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve portfolio")
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio)
+}
+
+// UpdatePortfolio handles PUT /api/v1/me/portfolios/:id
+func (h *PortfolioHandler) UpdatePortfolio(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	var req UpdatePortfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	portfolio, err := h.repo.GetPortfolio(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve portfolio")
+		return
+	}
+
+	// Update fields if provided
+	if name := strings.TrimSpace(req.Name); name != "" {
+		portfolio.Name = name
+	}
+	if req.Description != nil {
+		portfolio.Description = *req.Description
+	}
+
+	if err := h.repo.UpdatePortfolio(c.Request.Context(), portfolio); err != nil {
+		h.writeError(c, err, "Failed to update portfolio")
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio)
+}
+
+// DeletePortfolio handles DELETE /api/v1/me/portfolios/:id
+func (h *PortfolioHandler) DeletePortfolio(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeletePortfolio(c.Request.Context(), userID, id); err != nil {
+		h.writeError(c, err, "Failed to delete portfolio")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Portfolio deleted successfully"})
+}
+
+// ReplaceHoldings handles PUT /api/v1/me/portfolios/:id/holdings
+func (h *PortfolioHandler) ReplaceHoldings(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	var req ReplaceHoldingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	portfolio, err := h.repo.GetPortfolio(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve portfolio")
+		return
+	}
+
+	holdings, err := validateHoldings(portfolio.Basis, req.Holdings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.ReplaceHoldings(c.Request.Context(), userID, id, holdings); err != nil {
+		h.writeError(c, err, "Failed to update holdings")
+		return
+	}
+
+	h.respondWithPortfolio(c, userID, id, http.StatusOK)
+}
+
+// AddHolding handles POST /api/v1/me/portfolios/:id/holdings
+func (h *PortfolioHandler) AddHolding(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	var req HoldingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	portfolio, err := h.repo.GetPortfolio(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve portfolio")
+		return
+	}
+
+	// Validate the holdings the portfolio will have, so the weights cannot add
+	// up past one a holding at a time
+	holdings, err := validateHoldings(portfolio.Basis, mergeHolding(portfolio.Holdings, req))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.UpsertHolding(c.Request.Context(), userID, id, holdings[len(holdings)-1]); err != nil {
+		h.writeError(c, err, "Failed to add holding")
+		return
+	}
+
+	h.respondWithPortfolio(c, userID, id, http.StatusOK)
+}
+
+// RemoveHolding handles DELETE /api/v1/me/portfolios/:id/holdings/:symbol
+func (h *PortfolioHandler) RemoveHolding(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveHolding(c.Request.Context(), userID, id, normalizeSymbol(c.Param("symbol"))); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Holding not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove holding"})
+		return
+	}
+
+	h.respondWithPortfolio(c, userID, id, http.StatusOK)
+}
+
+// GetValuation handles GET /api/v1/me/portfolios/:id/valuation
+func (h *PortfolioHandler) GetValuation(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "portfolio")
+	if !ok {
+		return
+	}
+
+	valuation, err := h.repo.GetValuation(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to value portfolio")
+		return
+	}
+
+	c.JSON(http.StatusOK, valuation)
+}
+
+func (h *PortfolioHandler) respondWithPortfolio(c *gin.Context, userID, id, status int) {
+	portfolio, err := h.repo.GetPortfolio(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve portfolio")
+		return
+	}
+	c.JSON(status, portfolio)
+}
+
+func (h *PortfolioHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found"})
+	case errors.Is(err, models.ErrUnknownSymbol):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "A portfolio with this name already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// validateHoldings checks holdings against the portfolio basis. Quantity
+// portfolios require positive quantities; weight portfolios require weights in
+// (0, 1] summing to at most one.
+func validateHoldings(basis string, reqs []HoldingRequest) ([]models.HoldingInput, error) {
+	holdings := make([]models.HoldingInput, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	totalWeight := 0.0
+
+	for _, req := range reqs {
+		symbol := normalizeSymbol(req.Symbol)
+		if symbol == "" {
+			return nil, fmt.Errorf("holding symbol is required")
+		}
+		if seen[symbol] {
+			return nil, fmt.Errorf("duplicate holding %s", symbol)
+		}
+		seen[symbol] = true
+
+		holding := models.HoldingInput{Symbol: symbol}
+		if basis == models.PortfolioBasisQuantity {
+			if req.Quantity == nil || *req.Quantity <= 0 {
+				return nil, fmt.Errorf("holding %s requires a positive quantity", symbol)
+			}
+			if req.Weight != nil {
+				return nil, fmt.Errorf("holding %s must not set a weight in a quantity portfolio", symbol)
+			}
+			holding.Quantity = req.Quantity
+		} else {
+			if req.Weight == nil || *req.Weight <= 0 || *req.Weight > 1 {
+				return nil, fmt.Errorf("holding %s requires a weight between 0 and 1", symbol)
+			}
+			if req.Quantity != nil {
+				return nil, fmt.Errorf("holding %s must not set a quantity in a weight portfolio", symbol)
+			}
+			holding.Weight = req.Weight
+			totalWeight += *req.Weight
+		}
+
+		holdings = append(holdings, holding)
+	}
+
+	if totalWeight > 1.0001 {
+		return nil, fmt.Errorf("holding weights sum to %.4f; they must not exceed 1", totalWeight)
+	}
+
+	return holdings, nil
+}
+
+// allocationHoldings converts optimizer allocations to weight holdings
+func allocationHoldings(allocations []models.Allocation) []HoldingRequest {
+	reqs := make([]HoldingRequest, len(allocations))
+	for i, allocation := range allocations {
+		weight := allocation.Percentage / 100
+		reqs[i] = HoldingRequest{Symbol: allocation.Symbol, Weight: &weight}
+	}
+	return reqs
+}
+
+// mergeHolding returns the existing holdings as requests with req added last,
+// replacing any existing holding of the same symbol
+func mergeHolding(existing []*models.PortfolioHolding, req HoldingRequest) []HoldingRequest {
+	symbol := normalizeSymbol(req.Symbol)
+	merged := make([]HoldingRequest, 0, len(existing)+1)
+	for _, holding := range existing {
+		if holding.Symbol == symbol {
+			continue
+		}
+		merged = append(merged, HoldingRequest{Symbol: holding.Symbol, Quantity: holding.Quantity, Weight: holding.Weight})
+	}
+	return append(merged, req)
+}
+
+// currentUserID returns the authenticated user's ID, writing a 401 response if absent
+func currentUserID(c *gin.Context) (int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, false
+	}
+	return userID.(int), true
+}
+
+// ownedResourceParams returns the authenticated user's ID and the :id path parameter
+func ownedResourceParams(c *gin.Context, resource string) (int, int, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + resource + " ID"})
+		return 0, 0, false
+	}
+
+	return userID, id, true
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"testing"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHoldings(t *testing.T) {
+	qty := func(v float64) *float64 { return &v }
+
+	holdings, err := validateHoldings(models.PortfolioBasisWeight, []HoldingRequest{
+		{Symbol: " aapl ", Weight: qty(0.6)},
+		{Symbol: "MSFT", Weight: qty(0.4)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", holdings[0].Symbol)
+
+	tests := []struct {
+		name     string
+		basis    string
+		holdings []HoldingRequest
+	}{
+		{"weights above one", models.PortfolioBasisWeight, []HoldingRequest{{Symbol: "A", Weight: qty(0.7)}, {Symbol: "B", Weight: qty(0.4)}}},
+		{"missing weight", models.PortfolioBasisWeight, []HoldingRequest{{Symbol: "A"}}},
+		{"quantity in weight portfolio", models.PortfolioBasisWeight, []HoldingRequest{{Symbol: "A", Weight: qty(0.1), Quantity: qty(3)}}},
+		{"non-positive quantity", models.PortfolioBasisQuantity, []HoldingRequest{{Symbol: "A", Quantity: qty(0)}}},
+		{"duplicate symbol", models.PortfolioBasisQuantity, []HoldingRequest{{Symbol: "A", Quantity: qty(1)}, {Symbol: "a", Quantity: qty(2)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateHoldings(tt.basis, tt.holdings)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMergeHoldingValidatesTheResultingWeights(t *testing.T) {
+	weight := func(v float64) *float64 { return &v }
+	existing := []*models.PortfolioHolding{
+		{Symbol: "AAPL", Weight: weight(0.6)},
+		{Symbol: "MSFT", Weight: weight(0.3)},
+	}
+
+	_, err := validateHoldings(models.PortfolioBasisWeight, mergeHolding(existing, HoldingRequest{Symbol: "GOOG", Weight: weight(0.2)}))
+	assert.Error(t, err, "0.6 + 0.3 + 0.2 exceeds one")
+
+	holdings, err := validateHoldings(models.PortfolioBasisWeight, mergeHolding(existing, HoldingRequest{Symbol: "msft", Weight: weight(0.4)}))
+	require.NoError(t, err, "replacing MSFT's weight keeps the total at one")
+	require.Len(t, holdings, 2)
+	assert.Equal(t, "MSFT", holdings[1].Symbol)
+	assert.Equal(t, 0.4, *holdings[1].Weight)
+}
+
+func TestAllocationHoldings(t *testing.T) {
+	holdings, err := validateHoldings(models.PortfolioBasisWeight, allocationHoldings([]models.Allocation{
+		{Symbol: "AAPL", Percentage: 60},
+		{Symbol: "MSFT", Percentage: 40},
+	}))
+	require.NoError(t, err)
+	require.Len(t, holdings, 2)
+	assert.InDelta(t, 0.6, *holdings[0].Weight, 1e-9)
+	assert.InDelta(t, 0.4, *holdings[1].Weight, 1e-9)
+}
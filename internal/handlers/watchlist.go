@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"ethosview-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistHandler handles watchlist HTTP requests for the authenticated user
+type WatchlistHandler struct {
+	repo *models.WatchlistRepository
+}
+
+// NewWatchlistHandler creates a new watchlist handler
+func NewWatchlistHandler(db *sql.DB) *WatchlistHandler {
+	return &WatchlistHandler{
+		repo: models.NewWatchlistRepository(db),
+	}
+}
+
+// WatchlistRequest represents the create and rename watchlist request
+type WatchlistRequest struct {
+	Name    string   `json:"name" binding:"required,max=100"`
+	Symbols []string `json:"symbols"`
+}
+
+// WatchlistItemRequest represents the add watchlist item request
+type WatchlistItemRequest struct {
+	Symbol string `json:"symbol" binding:"required"`
+}
+
+// ListWatchlists handles GET /api/v1/me/watchlists
+func (h *WatchlistHandler) ListWatchlists(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlists, err := h.repo.ListWatchlists(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve watchlists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"watchlists": watchlists,
+		"count":      len(watchlists),
+	})
+}
+
+// CreateWatchlist handles POST /api/v1/me/watchlists
+func (h *WatchlistHandler) CreateWatchlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	symbols := make([]string, 0, len(req.Symbols))
+	seen := make(map[string]bool, len(req.Symbols))
+	for _, symbol := range req.Symbols {
+		symbol = normalizeSymbol(symbol)
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	watchlist := &models.Watchlist{UserID: userID, Name: strings.TrimSpace(req.Name)}
+	if err := h.repo.CreateWatchlist(c.Request.Context(), watchlist, symbols); err != nil {
+		h.writeError(c, err, "Failed to create watchlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+// GetWatchlist handles GET /api/v1/me/watchlists/:id
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "watchlist")
+	if !ok {
+		return
+	}
+
+	watchlist, err := h.repo.GetWatchlist(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve watchlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// RenameWatchlist handles PUT /api/v1/me/watchlists/:id
+func (h *WatchlistHandler) RenameWatchlist(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "watchlist")
+	if !ok {
+		return
+	}
+
+	var req WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	watchlist := &models.Watchlist{ID: id, UserID: userID, Name: strings.TrimSpace(req.Name)}
+	if err := h.repo.RenameWatchlist(c.Request.Context(), watchlist); err != nil {
+		h.writeError(c, err, "Failed to update watchlist")
+		return
+	}
+
+	h.respondWithWatchlist(c, userID, id, http.StatusOK)
+}
+
+// DeleteWatchlist handles DELETE /api/v1/me/watchlists/:id
+func (h *WatchlistHandler) DeleteWatchlist(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "watchlist")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteWatchlist(c.Request.Context(), userID, id); err != nil {
+		h.writeError(c, err, "Failed to delete watchlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Watchlist deleted successfully"})
+}
+
+// AddItem handles POST /api/v1/me/watchlists/:id/items
+func (h *WatchlistHandler) AddItem(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "watchlist")
+	if !ok {
+		return
+	}
+
+	var req WatchlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.repo.AddItem(c.Request.Context(), userID, id, normalizeSymbol(req.Symbol)); err != nil {
+		h.writeError(c, err, "Failed to add watchlist item")
+		return
+	}
+
+	h.respondWithWatchlist(c, userID, id, http.StatusOK)
+}
+
+// RemoveItem handles DELETE /api/v1/me/watchlists/:id/items/:symbol
+func (h *WatchlistHandler) RemoveItem(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "watchlist")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveItem(c.Request.Context(), userID, id, normalizeSymbol(c.Param("symbol"))); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Watchlist item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove watchlist item"})
+		return
+	}
+
+	h.respondWithWatchlist(c, userID, id, http.StatusOK)
+}
+
+func (h *WatchlistHandler) respondWithWatchlist(c *gin.Context, userID, id, status int) {
+	watchlist, err := h.repo.GetWatchlist(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve watchlist")
+		return
+	}
+	c.JSON(status, watchlist)
+}
+
+func (h *WatchlistHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Watchlist not found"})
+	case errors.Is(err, models.ErrUnknownSymbol):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "A watchlist with this name already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ethosview-backend/internal/models"
+	"ethosview-backend/internal/webhooks"
+	"ethosview-backend/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles webhook endpoint management for the authenticated user
+type WebhookHandler struct {
+	repo       *models.WebhookRepository
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler. The dispatcher queues
+// redeliveries; it may be nil, in which case they wait for the periodic rescue.
+func NewWebhookHandler(db *sql.DB, dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{
+		repo:       models.NewWebhookRepository(db),
+		dispatcher: dispatcher,
+	}
+}
+
+// WebhookRequest represents the create and update webhook request
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	Description string   `json:"description" binding:"max=255"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Active      *bool    `json:"active"`
+}
+
+// ListWebhooks handles GET /api/v1/me/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.repo.ListEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": endpoints, "event_types": webhooks.EventTypes})
+}
+
+// CreateWebhook handles POST /api/v1/me/webhooks. The signing secret is only
+// returned in this response.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := h.bindWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+		return
+	}
+	endpoint.UserID = userID
+	endpoint.Secret = secret
+
+	if err := h.repo.CreateEndpoint(c.Request.Context(), endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook created. Store the secret now; it will not be shown again",
+		"secret":  secret,
+		"webhook": endpoint,
+	})
+}
+
+// GetWebhook handles GET /api/v1/me/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "webhook")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.repo.GetEndpoint(c.Request.Context(), userID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// UpdateWebhook handles PUT /api/v1/me/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "webhook")
+	if !ok {
+		return
+	}
+
+	endpoint, ok := h.bindWebhook(c)
+	if !ok {
+		return
+	}
+	endpoint.ID = id
+	endpoint.UserID = userID
+
+	if err := h.repo.UpdateEndpoint(c.Request.Context(), endpoint); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteWebhook handles DELETE /api/v1/me/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "webhook")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteEndpoint(c.Request.Context(), userID, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries handles GET /api/v1/me/webhooks/:id/deliveries. An optional
+// status parameter filters by pending, succeeded or dead.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "webhook")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or dead"})
+		return
+	}
+
+	if _, err := h.repo.GetEndpoint(c.Request.Context(), userID, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+		return
+	}
+
+	deliveries, err := h.repo.ListDeliveries(c.Request.Context(), userID, id, limit, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// Redeliver handles POST /api/v1/me/webhooks/:id/deliveries/:deliveryId/redeliver.
+// The delivery is sent again with a fresh retry budget, including dead ones.
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID, id, ok := ownedResourceParams(c, "webhook")
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	if err := h.repo.Redeliver(c.Request.Context(), userID, id, deliveryID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver webhook"})
+		return
+	}
+	h.dispatcher.Enqueue(c.Request.Context(), deliveryID, time.Now())
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued"})
+}
+
+// bindWebhook binds and validates a webhook request
+func (h *WebhookHandler) bindWebhook(c *gin.Context) (*models.WebhookEndpoint, bool) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return nil, false
+	}
+
+	if err := webhooks.ValidateURL(c.Request.Context(), req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	eventTypes, err := validateEventTypes(req.EventTypes, c.GetStringSlice("user_roles"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return &models.WebhookEndpoint{
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  eventTypes,
+		Active:      active,
+	}, true
+}
+
+// validateEventTypes checks that every event type can be subscribed to by the
+// owner, dropping duplicates. Alert events are limited to administrators.
+func validateEventTypes(eventTypes []string, roles []string) ([]string, error) {
+	validated := []string{}
+	seen := make(map[string]bool)
+	for _, eventType := range eventTypes {
+		if seen[eventType] {
+			continue
+		}
+		if !webhooks.IsEventType(eventType) {
+			return nil, fmt.Errorf("unknown event type %s", eventType)
+		}
+		if webhooks.IsAdminEventType(eventType) && !containsRole(roles, auth.RoleAdmin) {
+			return nil, fmt.Errorf("event type %s requires the admin role", eventType)
+		}
+		seen[eventType] = true
+		validated = append(validated, eventType)
+	}
+	return validated, nil
+}
+
+// containsRole reports whether roles includes role
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEventTypes(t *testing.T) {
+	types, err := validateEventTypes([]string{events.TypeCompanyCreated, events.TypeESGScoreUpdated, events.TypeCompanyCreated}, []string{auth.RoleViewer})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.TypeCompanyCreated, events.TypeESGScoreUpdated}, types)
+
+	_, err = validateEventTypes([]string{events.TypePriceTick}, []string{auth.RoleAdmin})
+	assert.Error(t, err)
+
+	_, err = validateEventTypes([]string{events.TypeAlertRaised}, []string{auth.RoleAnalyst})
+	assert.Error(t, err)
+
+	types, err = validateEventTypes([]string{events.TypeAlertRaised, events.TypeAlertResolved}, []string{auth.RoleAdmin})
+	require.NoError(t, err)
+	assert.Len(t, types, 2)
+}
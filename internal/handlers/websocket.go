@@ -1,41 +1,60 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	ws "ethosview-backend/internal/websocket"
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/security"
 
 	"github.com/gin-gonic/gin"
 	gorilla "github.com/gorilla/websocket"
 )
 
+// bearerSubprotocol is offered by browser clients that pass their token as the
+// second Sec-WebSocket-Protocol entry, since browsers cannot set headers
+const bearerSubprotocol = "bearer"
+
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	manager  *ws.Manager
-	upgrader gorilla.Upgrader
+	manager    *ws.Manager
+	jwtManager *auth.JWTManager
+	upgrader   gorilla.Upgrader
 }
 
-// NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(manager *ws.Manager) *WebSocketHandler {
+// NewWebSocketHandler creates a new WebSocket handler that accepts handshakes
+// from the security middleware's allowed origins
+func NewWebSocketHandler(manager *ws.Manager, jwtManager *auth.JWTManager, securityMiddleware *security.SecurityMiddleware) *WebSocketHandler {
 	return &WebSocketHandler{
-		manager: manager,
+		manager:    manager,
+		jwtManager: jwtManager,
 		upgrader: gorilla.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // Allow all origins for development
-			},
+			CheckOrigin:  securityMiddleware.CheckOrigin,
+			Subprotocols: []string{bearerSubprotocol},
 		},
 	}
 }
 
-// HandleWebSocket handles WebSocket connections
+// HandleWebSocket handles WebSocket connections. Clients authenticate with a
+// token query parameter, a "bearer, <token>" Sec-WebSocket-Protocol, or an
+// auth frame after connecting; unauthenticated clients may use public topics.
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
-	// Get user ID from context if authenticated
-	var userID *int
-	if userIDVal, exists := c.Get("user_id"); exists {
-		if id, ok := userIDVal.(int); ok {
-			userID = &id
+	token := handshakeToken(c.Request)
+
+	var userID int
+	var expiresAt time.Time
+	if token != "" {
+		var err error
+		userID, expiresAt, err = h.validateToken(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
 		}
 	}
 
@@ -45,21 +64,21 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Upgrade HTTP connection to WebSocket
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade connection"})
+		// The upgrader has already written an error response
 		return
 	}
 
 	// Create new client
-	client := &ws.Client{
-		ID:      clientID,
-		UserID:  userID,
-		Conn:    conn,
-		Send:    make(chan []byte, 256),
-		Manager: h.manager,
+	client := ws.NewClient(clientID, conn, h.manager, h.validateToken)
+	if token != "" {
+		client.Authenticate(userID, expiresAt)
 	}
 
-	// Register client with manager
-	h.manager.Register <- client
+	// Register client with manager, unless it has stopped for shutdown
+	if !h.manager.Connect(client) {
+		conn.Close()
+		return
+	}
 
 	// Start client goroutines
 	go client.WritePump()
@@ -69,27 +88,75 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	welcomeMessage := ws.Message{
 		Type: "welcome",
 		Data: gin.H{
-			"client_id": clientID,
-			"message":   "Connected to EthosView WebSocket",
+			"client_id":     clientID,
+			"authenticated": token != "",
+			"message":       "Connected to EthosView WebSocket",
+			"topics":        []string{"company:{id}:prices", "company:{id}:esg", "sector:{name}", ws.TopicAlerts},
 		},
 	}
 
-	if welcomeData, err := welcomeMessage.Marshal(); err == nil {
-		client.Send <- welcomeData
-	}
+	h.manager.Send(client, welcomeMessage)
 }
 
 // GetWebSocketStatus returns WebSocket connection status
 func (h *WebSocketHandler) GetWebSocketStatus(c *gin.Context) {
-	clientCount := h.manager.GetClientCount()
+	cluster, err := h.manager.ClusterStatus(c.Request.Context())
+	if err != nil {
+		// Fall back to this instance's counts when the backplane is unreachable
+		c.JSON(http.StatusOK, gin.H{
+			"status":             "degraded",
+			"client_count":       cluster.LocalClientCount,
+			"local_client_count": cluster.LocalClientCount,
+			"topic_count":        h.manager.GetTopicCount(),
+			"message":            "WebSocket server is active; cluster status unavailable",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":       "running",
-		"client_count": clientCount,
-		"message":      "WebSocket server is active",
+		"status":             "running",
+		"client_count":       cluster.ClientCount,
+		"local_client_count": cluster.LocalClientCount,
+		"instances":          cluster.Instances,
+		"instance_id":        cluster.InstanceID,
+		"topic_count":        h.manager.GetTopicCount(),
+		"message":            "WebSocket server is active",
 	})
 }
 
+// validateToken checks an access token's signature, expiry and revocation
+func (h *WebSocketHandler) validateToken(ctx context.Context, token string) (int, time.Time, error) {
+	claims, err := h.jwtManager.ValidateToken(token)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	revoked, err := h.jwtManager.IsRevoked(ctx, claims)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if revoked {
+		return 0, time.Time{}, errors.New("token has been revoked")
+	}
+
+	return claims.UserID, claims.ExpiresAt.Time, nil
+}
+
+// handshakeToken returns the token from the query string or the bearer subprotocol
+func handshakeToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+
+	protocols := gorilla.Subprotocols(r)
+	for i, protocol := range protocols {
+		if strings.EqualFold(protocol, bearerSubprotocol) && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
 // generateClientID generates a unique client ID
 func generateClientID() string {
 	bytes := make([]byte, 8)
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	ws "ethosview-backend/internal/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebSocketTestServer(t *testing.T, jwtManager *auth.JWTManager) string {
+	t.Helper()
+	manager := ws.NewManager(nil)
+	go manager.Run(context.Background())
+	return serveWebSocket(t, manager, jwtManager)
+}
+
+func serveWebSocket(t *testing.T, manager *ws.Manager, jwtManager *auth.JWTManager) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/ws", NewWebSocketHandler(manager, jwtManager, security.NewSecurityMiddleware()).HandleWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func readWelcome(t *testing.T, conn *gorilla.Conn) map[string]interface{} {
+	t.Helper()
+	var msg struct {
+		Type string                 `json:"type"`
+		Data map[string]interface{} `json:"data"`
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "welcome", msg.Type)
+	return msg.Data
+}
+
+func expectClose(t *testing.T, conn *gorilla.Conn, within time.Duration, code int, reason string) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(within))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		closeErr, ok := err.(*gorilla.CloseError)
+		require.True(t, ok, "expected close frame, got %v", err)
+		assert.Equal(t, code, closeErr.Code)
+		assert.Equal(t, reason, closeErr.Text)
+		return
+	}
+}
+
+func TestWebSocketHandshakeAuthentication(t *testing.T) {
+	jwtManager := auth.NewJWTManager()
+	url := newWebSocketTestServer(t, jwtManager)
+	token, err := jwtManager.GenerateToken(5, "user@example.com", nil)
+	require.NoError(t, err)
+
+	conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	assert.Equal(t, false, readWelcome(t, conn)["authenticated"])
+	conn.Close()
+
+	conn, _, err = gorilla.DefaultDialer.Dial(url+"?token="+token, nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, readWelcome(t, conn)["authenticated"])
+	conn.Close()
+
+	dialer := gorilla.Dialer{Subprotocols: []string{"bearer", token}}
+	conn, resp, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bearer", resp.Header.Get("Sec-WebSocket-Protocol"))
+	assert.Equal(t, true, readWelcome(t, conn)["authenticated"])
+	conn.Close()
+
+	_, resp, err = gorilla.DefaultDialer.Dial(url+"?token=not-a-token", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestWebSocketRejectsDisallowedOrigin(t *testing.T) {
+	url := newWebSocketTestServer(t, auth.NewJWTManager())
+
+	header := http.Header{"Origin": []string{"https://evil.example.com"}}
+	_, resp, err := gorilla.DefaultDialer.Dial(url, header)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Origin", "http://localhost:3000")
+	conn, _, err := gorilla.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestWebSocketAuthFrame(t *testing.T) {
+	jwtManager := auth.NewJWTManager()
+	url := newWebSocketTestServer(t, jwtManager)
+	token, err := jwtManager.GenerateToken(5, "user@example.com", nil)
+	require.NoError(t, err)
+
+	conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	readWelcome(t, conn)
+
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{Type: "auth", ID: "a1", Token: token}))
+	var ack struct {
+		Type string     `json:"type"`
+		Data ws.AuthAck `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&ack))
+	assert.Equal(t, "ack", ack.Type)
+	assert.Equal(t, "a1", ack.Data.ID)
+	assert.Equal(t, 5, ack.Data.UserID)
+
+	bad, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer bad.Close()
+	readWelcome(t, bad)
+	require.NoError(t, bad.WriteJSON(ws.ClientMessage{Type: "auth", Token: "not-a-token"}))
+	expectClose(t, bad, 2*time.Second, gorilla.ClosePolicyViolation, "authentication failed")
+}
+
+func TestWebSocketClosesWhenTokenExpires(t *testing.T) {
+	t.Setenv("JWT_EXPIRY", "1s")
+	jwtManager := auth.NewJWTManager()
+	url := newWebSocketTestServer(t, jwtManager)
+	token, err := jwtManager.GenerateToken(5, "user@example.com", nil)
+	require.NoError(t, err)
+
+	conn, _, err := gorilla.DefaultDialer.Dial(url+"?token="+token, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	readWelcome(t, conn)
+	expectClose(t, conn, 3*time.Second, gorilla.ClosePolicyViolation, "token expired")
+}
+
+func TestWebSocketShutdownSendsGoingAway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager := ws.NewManager(nil)
+	go manager.Run(ctx)
+	url := serveWebSocket(t, manager, auth.NewJWTManager())
+
+	conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	readWelcome(t, conn)
+
+	shutdownCtx, done := context.WithTimeout(ctx, 2*time.Second)
+	defer done()
+	require.NoError(t, manager.Shutdown(shutdownCtx))
+
+	expectClose(t, conn, 2*time.Second, gorilla.CloseGoingAway, "server shutting down")
+	assert.Zero(t, manager.GetClientCount())
+}
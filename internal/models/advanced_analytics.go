@@ -1,11 +1,39 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"math"
+	"sort"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
 )
 
+// ErrInsufficientOverlap is returned when a company's prices and the benchmark
+// share too few dates to estimate market sensitivity
+var ErrInsufficientOverlap = errors.New("insufficient overlap between company prices and benchmark data")
+
+// minBetaObservations is the minimum number of aligned returns needed for a beta estimate
+const minBetaObservations = 20
+
+// benchmarkColumns maps supported benchmarks to their market_data columns
+var benchmarkColumns = map[string]string{
+	"sp500":  "sp500_close",
+	"nasdaq": "nasdaq_close",
+	"dow":    "dow_close",
+}
+
+// IsValidBenchmark reports whether a benchmark name is supported
+func IsValidBenchmark(benchmark string) bool {
+	_, ok := benchmarkColumns[benchmark]
+	return ok
+}
+
 // ESGPrediction represents ESG score prediction
 type ESGPrediction struct {
 	CompanyID      int       `json:"company_id"`
@@ -17,37 +45,91 @@ type ESGPrediction struct {
 	Factors        []string  `json:"factors"`
 }
 
-// PortfolioOptimization represents portfolio optimization results
+// PortfolioOptimization represents portfolio optimization results.
+// PortfolioID is set once the result is saved as a portfolio.
 type PortfolioOptimization struct {
-	PortfolioID    string       `json:"portfolio_id"`
-	TotalValue     float64      `json:"total_value"`
-	ExpectedReturn float64      `json:"expected_return"`
-	RiskLevel      string       `json:"risk_level"`
-	SharpeRatio    float64      `json:"sharpe_ratio"`
-	Allocations    []Allocation `json:"allocations"`
-	CreatedAt      time.Time    `json:"created_at"`
+	PortfolioID        *int                 `json:"portfolio_id,omitempty"`
+	TotalValue         float64              `json:"total_value"`
+	ExpectedReturn     float64              `json:"expected_return"`
+	ExpectedVolatility float64              `json:"expected_volatility"`
+	ESGScore           float64              `json:"esg_score"`
+	RiskLevel          string               `json:"risk_level"`
+	SharpeRatio        float64              `json:"sharpe_ratio"`
+	RiskFreeRate       float64              `json:"risk_free_rate"`
+	Constraints        PortfolioConstraints `json:"constraints"`
+	Allocations        []Allocation         `json:"allocations"`
+	EfficientFrontier  []FrontierPoint      `json:"efficient_frontier"`
+	CreatedAt          time.Time            `json:"created_at"`
+}
+
+// PortfolioConstraints holds the inputs to the mean-variance optimizer
+type PortfolioConstraints struct {
+	TargetReturn    float64 `json:"target_return"`
+	RiskTolerance   string  `json:"risk_tolerance"`
+	MaxCompanies    int     `json:"max_companies"`
+	MinESGScore     float64 `json:"min_esg_score"`
+	MaxWeight       float64 `json:"max_weight"`
+	MaxSectorWeight float64 `json:"max_sector_weight"`
+	AllowShort      bool    `json:"allow_short"`
+	LookbackDays    int     `json:"lookback_days"`
+}
+
+// FrontierPoint represents one portfolio on the efficient frontier
+type FrontierPoint struct {
+	ExpectedReturn float64 `json:"expected_return"`
+	Volatility     float64 `json:"volatility"`
+	SharpeRatio    float64 `json:"sharpe_ratio"`
+	ESGScore       float64 `json:"esg_score"`
 }
 
 // Allocation represents portfolio allocation
 type Allocation struct {
-	CompanyID   int     `json:"company_id"`
-	CompanyName string  `json:"company_name"`
-	Percentage  float64 `json:"percentage"`
-	Amount      float64 `json:"amount"`
-	ESGScore    float64 `json:"esg_score"`
+	CompanyID      int     `json:"company_id"`
+	CompanyName    string  `json:"company_name"`
+	Symbol         string  `json:"symbol"`
+	Sector         string  `json:"sector"`
+	Percentage     float64 `json:"percentage"`
+	Amount         float64 `json:"amount"`
+	ESGScore       float64 `json:"esg_score"`
+	ExpectedReturn float64 `json:"expected_return"`
+}
+
+// portfolioAsset is a candidate holding with its return and ESG inputs
+type portfolioAsset struct {
+	ID             int
+	Name           string
+	Symbol         string
+	Sector         string
+	ESGScore       float64
+	ExpectedReturn float64
 }
 
 // RiskAssessment represents risk assessment metrics
 type RiskAssessment struct {
-	CompanyID     int     `json:"company_id"`
-	CompanyName   string  `json:"company_name"`
-	Volatility    float64 `json:"volatility"`
-	Beta          float64 `json:"beta"`
-	ValueAtRisk   float64 `json:"value_at_risk"`
-	MaxDrawdown   float64 `json:"max_drawdown"`
-	RiskScore     float64 `json:"risk_score"`
-	RiskLevel     string  `json:"risk_level"`
-	ESGRiskFactor float64 `json:"esg_risk_factor"`
+	CompanyID     int           `json:"company_id"`
+	CompanyName   string        `json:"company_name"`
+	Volatility    float64       `json:"volatility"`
+	Beta          float64       `json:"beta"`
+	BetaEstimate  *BetaEstimate `json:"beta_estimate"`
+	ValueAtRisk   float64       `json:"value_at_risk"`
+	MaxDrawdown   float64       `json:"max_drawdown"`
+	RiskScore     float64       `json:"risk_score"`
+	RiskLevel     string        `json:"risk_level"`
+	ESGRiskFactor float64       `json:"esg_risk_factor"`
+}
+
+// BetaEstimate represents a regression of company returns on benchmark returns
+type BetaEstimate struct {
+	CompanyID     int       `json:"company_id"`
+	Benchmark     string    `json:"benchmark"`
+	LookbackDays  int       `json:"lookback_days"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	SampleSize    int       `json:"sample_size"`
+	Beta          float64   `json:"beta"`
+	Alpha         float64   `json:"alpha"` // Annualized (252 trading days)
+	Correlation   float64   `json:"correlation"`
+	StandardError float64   `json:"standard_error"`
 }
 
 // TrendAnalysis represents trend analysis results
@@ -65,16 +147,16 @@ type TrendAnalysis struct {
 
 // AdvancedAnalyticsRepository handles advanced analytical database operations
 type AdvancedAnalyticsRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewAdvancedAnalyticsRepository creates a new advanced analytics repository
 func NewAdvancedAnalyticsRepository(db *sql.DB) *AdvancedAnalyticsRepository {
-	return &AdvancedAnalyticsRepository{db: db}
+	return &AdvancedAnalyticsRepository{db: tracing.WrapDB(db)}
 }
 
 // PredictESGScore predicts ESG score for a company using historical data
-func (r *AdvancedAnalyticsRepository) PredictESGScore(companyID int) (*ESGPrediction, error) {
+func (r *AdvancedAnalyticsRepository) PredictESGScore(ctx context.Context, companyID int) (*ESGPrediction, error) {
 	// Get historical ESG scores for the company
 	query := `
 		SELECT c.name, es.overall_score, es.score_date
@@ -85,7 +167,7 @@ func (r *AdvancedAnalyticsRepository) PredictESGScore(companyID int) (*ESGPredic
 		LIMIT 10
 	`
 
-	rows, err := r.db.Query(query, companyID)
+	rows, err := r.db.QueryContext(ctx, query, companyID)
 	if err != nil {
 		return nil, err
 	}
@@ -132,100 +214,307 @@ func (r *AdvancedAnalyticsRepository) PredictESGScore(companyID int) (*ESGPredic
 	}, nil
 }
 
-// OptimizePortfolio creates an optimized portfolio based on ESG and financial criteria
-func (r *AdvancedAnalyticsRepository) OptimizePortfolio(targetReturn float64, riskTolerance string, maxCompanies int) (*PortfolioOptimization, error) {
-	// Get companies with ESG scores and financial data
+// OptimizePortfolio builds a mean-variance optimal portfolio from historical
+// returns, minimizing variance subject to the target return and ESG, weight
+// and sector constraints, and traces the efficient frontier under the same constraints
+func (r *AdvancedAnalyticsRepository) OptimizePortfolio(ctx context.Context, constraints PortfolioConstraints) (*PortfolioOptimization, error) {
+	// Candidate universe: highest ESG-rated companies with prices
 	query := `
-		SELECT 
-			c.id, c.name, c.sector,
-			es.overall_score as esg_score,
-			fi.market_cap, fi.pe_ratio, fi.return_on_equity,
-			sp.close_price
+		SELECT c.id, c.name, c.symbol, COALESCE(c.sector, ''), es.overall_score
 		FROM companies c
-		LEFT JOIN (
+		JOIN (
 			SELECT DISTINCT ON (company_id) company_id, overall_score
 			FROM esg_scores
 			ORDER BY company_id, score_date DESC
 		) es ON c.id = es.company_id
-		LEFT JOIN (
-			SELECT DISTINCT ON (company_id) company_id, market_cap, pe_ratio, return_on_equity
-			FROM financial_indicators
-			ORDER BY company_id, date DESC
-		) fi ON c.id = fi.company_id
-		LEFT JOIN (
-			SELECT DISTINCT ON (company_id) company_id, close_price
-			FROM stock_prices
-			ORDER BY company_id, date DESC
-		) sp ON c.id = sp.company_id
 		WHERE es.overall_score IS NOT NULL
-		AND fi.market_cap IS NOT NULL
-		AND sp.close_price IS NOT NULL
-		ORDER BY es.overall_score DESC, fi.return_on_equity DESC
+		AND EXISTS (SELECT 1 FROM stock_prices sp WHERE sp.company_id = c.id)
+		ORDER BY es.overall_score DESC
 		LIMIT $1
 	`
 
-	rows, err := r.db.Query(query, maxCompanies*2) // Get more companies for selection
+	rows, err := r.db.QueryContext(ctx, query, constraints.MaxCompanies*2) // Extra candidates in case some lack history
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var companies []struct {
-		ID         int
-		Name       string
-		Sector     string
-		ESGScore   float64
-		MarketCap  float64
-		PERatio    float64
-		ROE        float64
-		ClosePrice float64
-	}
-
+	var candidates []portfolioAsset
 	for rows.Next() {
-		var comp struct {
-			ID         int
-			Name       string
-			Sector     string
-			ESGScore   float64
-			MarketCap  float64
-			PERatio    float64
-			ROE        float64
-			ClosePrice float64
-		}
-		if err := rows.Scan(&comp.ID, &comp.Name, &comp.Sector, &comp.ESGScore, &comp.MarketCap, &comp.PERatio, &comp.ROE, &comp.ClosePrice); err != nil {
+		var asset portfolioAsset
+		if err := rows.Scan(&asset.ID, &asset.Name, &asset.Symbol, &asset.Sector, &asset.ESGScore); err != nil {
 			return nil, err
 		}
-		companies = append(companies, comp)
+		candidates = append(candidates, asset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if len(companies) == 0 {
+	assets, returns, err := r.loadAlignedReturns(ctx, candidates, constraints.LookbackDays, constraints.MaxCompanies)
+	if err != nil {
+		return nil, err
+	}
+	if len(assets) < 2 {
 		return nil, sql.ErrNoRows
 	}
 
-	// Simple portfolio optimization algorithm
-	allocations := r.optimizeAllocations(companies, targetReturn, riskTolerance, maxCompanies)
+	expectedReturns, covariance := meanCovariance(returns)
+	for i := range assets {
+		assets[i].ExpectedReturn = expectedReturns[i]
+	}
+
+	base := buildPortfolioProblem(assets, covariance, constraints)
+
+	weights, err := solveQP(withMinimumReturn(base, expectedReturns, constraints.TargetReturn))
+	if err != nil {
+		return nil, fmt.Errorf("%w: target return %.4f", err, constraints.TargetReturn)
+	}
+
+	riskFreeRate := r.LatestRiskFreeRate(ctx)
+	frontier := traceEfficientFrontier(base, assets, covariance, riskFreeRate, 10)
 
-	// Calculate portfolio metrics
 	totalValue := 1000000.0 // $1M portfolio
-	expectedReturn := r.calculateExpectedReturn(allocations)
-	sharpeRatio := r.calculateSharpeRatio(allocations)
+	expectedReturn, volatility, esgScore := portfolioStats(weights, assets, covariance)
+
+	sharpeRatio := 0.0
+	if volatility > 0 {
+		sharpeRatio = (expectedReturn - riskFreeRate) / volatility
+	}
+
+	allocations := make([]Allocation, 0, len(assets))
+	for i, asset := range assets {
+		if math.Abs(weights[i]) < 1e-4 {
+			continue
+		}
+		allocations = append(allocations, Allocation{
+			CompanyID:      asset.ID,
+			CompanyName:    asset.Name,
+			Symbol:         asset.Symbol,
+			Sector:         asset.Sector,
+			Percentage:     weights[i] * 100,
+			Amount:         weights[i] * totalValue,
+			ESGScore:       asset.ESGScore,
+			ExpectedReturn: asset.ExpectedReturn,
+		})
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Percentage > allocations[j].Percentage
+	})
 
 	return &PortfolioOptimization{
-		PortfolioID:    "opt_" + time.Now().Format("20060102150405"),
-		TotalValue:     totalValue,
-		ExpectedReturn: expectedReturn,
-		RiskLevel:      riskTolerance,
-		SharpeRatio:    sharpeRatio,
-		Allocations:    allocations,
-		CreatedAt:      time.Now(),
+		TotalValue:         totalValue,
+		ExpectedReturn:     expectedReturn,
+		ExpectedVolatility: volatility,
+		ESGScore:           esgScore,
+		RiskLevel:          volatilityRiskLevel(volatility),
+		SharpeRatio:        sharpeRatio,
+		RiskFreeRate:       riskFreeRate,
+		Constraints:        constraints,
+		Allocations:        allocations,
+		EfficientFrontier:  frontier,
+		CreatedAt:          time.Now(),
 	}, nil
 }
 
+// loadAlignedReturns loads adjusted closes for the candidates and returns daily
+// return series for up to maxAssets of them, restricted to dates on which
+// every retained asset traded. See selectAlignedAssets.
+func (r *AdvancedAnalyticsRepository) loadAlignedReturns(ctx context.Context, candidates []portfolioAsset, lookbackDays, maxAssets int) ([]portfolioAsset, [][]float64, error) {
+	if len(candidates) == 0 {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	ids := make([]int64, len(candidates))
+	for i, asset := range candidates {
+		ids[i] = int64(asset.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT company_id, date, adjusted_close
+		FROM stock_prices
+		WHERE company_id = ANY($1)
+		AND date >= (SELECT MAX(date) FROM stock_prices) - $2::int
+		ORDER BY date ASC
+	`, pq.Array(ids), lookbackDays)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[int]map[time.Time]float64)
+	var dates []time.Time
+	seenDates := make(map[time.Time]bool)
+	for rows.Next() {
+		var companyID int
+		var date time.Time
+		var price float64
+		if err := rows.Scan(&companyID, &date, &price); err != nil {
+			return nil, nil, err
+		}
+		if prices[companyID] == nil {
+			prices[companyID] = make(map[time.Time]float64)
+		}
+		prices[companyID][date] = price
+		if !seenDates[date] {
+			seenDates[date] = true
+			dates = append(dates, date)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return selectAlignedAssets(candidates, prices, dates, maxAssets)
+}
+
+// selectAlignedAssets ranks candidates by how many dates they have prices for,
+// keeping the given order among equals, and greedily adds them while the dates
+// shared by every selected asset stay above minBetaObservations, stopping at
+// maxAssets. A candidate whose history would shrink the overlap too far is
+// skipped rather than failing the whole selection. dates must be ascending.
+func selectAlignedAssets(candidates []portfolioAsset, prices map[int]map[time.Time]float64, dates []time.Time, maxAssets int) ([]portfolioAsset, [][]float64, error) {
+	ranked := make([]portfolioAsset, 0, len(candidates))
+	for _, asset := range candidates {
+		if len(prices[asset.ID]) > minBetaObservations {
+			ranked = append(ranked, asset)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return len(prices[ranked[i].ID]) > len(prices[ranked[j].ID])
+	})
+
+	var assets []portfolioAsset
+	var common map[time.Time]bool
+	for _, asset := range ranked {
+		if len(assets) == maxAssets {
+			break
+		}
+		shared := make(map[time.Time]bool)
+		for date := range prices[asset.ID] {
+			if common == nil || common[date] {
+				shared[date] = true
+			}
+		}
+		if len(shared) <= minBetaObservations {
+			continue
+		}
+		assets = append(assets, asset)
+		common = shared
+	}
+	if len(assets) == 0 {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	var commonDates []time.Time
+	for _, date := range dates {
+		if common[date] {
+			commonDates = append(commonDates, date)
+		}
+	}
+
+	returns := make([][]float64, len(assets))
+	for i, asset := range assets {
+		series := make([]float64, 0, len(commonDates)-1)
+		for t := 1; t < len(commonDates); t++ {
+			prev := prices[asset.ID][commonDates[t-1]]
+			curr := prices[asset.ID][commonDates[t]]
+			if prev == 0 {
+				series = append(series, 0)
+				continue
+			}
+			series = append(series, (curr-prev)/prev)
+		}
+		returns[i] = series
+	}
+
+	return assets, returns, nil
+}
+
+// LatestRiskFreeRate returns the latest 10-year treasury yield as a decimal,
+// defaulting to 2%
+func (r *AdvancedAnalyticsRepository) LatestRiskFreeRate(ctx context.Context) float64 {
+	var treasury float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT treasury_10y
+		FROM market_data
+		WHERE treasury_10y IS NOT NULL
+		ORDER BY date DESC
+		LIMIT 1
+	`).Scan(&treasury)
+	if err != nil {
+		return 0.02
+	}
+	return treasury / 100
+}
+
+// EstimateBeta regresses a company's daily adjusted-close returns on benchmark
+// returns over the lookback window, using only dates present in both series
+func (r *AdvancedAnalyticsRepository) EstimateBeta(ctx context.Context, companyID int, benchmark string, lookbackDays int) (*BetaEstimate, error) {
+	column, ok := benchmarkColumns[benchmark]
+	if !ok {
+		return nil, fmt.Errorf("unsupported benchmark %q", benchmark)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT sp.date, sp.adjusted_close, md.%[1]s
+		FROM stock_prices sp
+		JOIN market_data md ON md.date = sp.date
+		WHERE sp.company_id = $1
+		AND md.%[1]s IS NOT NULL
+		AND sp.date >= (
+			SELECT MAX(date) FROM stock_prices WHERE company_id = $1
+		) - $2::int
+		ORDER BY sp.date ASC
+	`, column)
+
+	rows, err := r.db.QueryContext(ctx, query, companyID, lookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	var stockPrices, marketPrices []float64
+	for rows.Next() {
+		var date time.Time
+		var stockPrice, marketPrice float64
+		if err := rows.Scan(&date, &stockPrice, &marketPrice); err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+		stockPrices = append(stockPrices, stockPrice)
+		marketPrices = append(marketPrices, marketPrice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stockReturns, marketReturns := alignedReturns(stockPrices, marketPrices)
+	if len(stockReturns) < minBetaObservations {
+		return nil, fmt.Errorf("%w: %d aligned returns, need at least %d",
+			ErrInsufficientOverlap, len(stockReturns), minBetaObservations)
+	}
+
+	estimate, err := r.calculateBeta(stockReturns, marketReturns)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate.CompanyID = companyID
+	estimate.Benchmark = benchmark
+	estimate.LookbackDays = lookbackDays
+	estimate.StartDate = dates[0]
+	estimate.EndDate = dates[len(dates)-1]
+
+	return estimate, nil
+}
+
 // AssessRisk calculates risk metrics for a company
-func (r *AdvancedAnalyticsRepository) AssessRisk(companyID int) (*RiskAssessment, error) {
+func (r *AdvancedAnalyticsRepository) AssessRisk(ctx context.Context, companyID int, benchmark string, lookbackDays int) (*RiskAssessment, error) {
 	// Get company information
 	var companyName string
-	err := r.db.QueryRow("SELECT name FROM companies WHERE id = $1", companyID).Scan(&companyName)
+	err := r.db.QueryRowContext(ctx, "SELECT name FROM companies WHERE id = $1", companyID).Scan(&companyName)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +528,7 @@ func (r *AdvancedAnalyticsRepository) AssessRisk(companyID int) (*RiskAssessment
 		LIMIT 30
 	`
 
-	rows, err := r.db.Query(query, companyID)
+	rows, err := r.db.QueryContext(ctx, query, companyID)
 	if err != nil {
 		return nil, err
 	}
@@ -259,19 +548,24 @@ func (r *AdvancedAnalyticsRepository) AssessRisk(companyID int) (*RiskAssessment
 		return nil, sql.ErrNoRows
 	}
 
+	betaEstimate, err := r.EstimateBeta(ctx, companyID, benchmark, lookbackDays)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate risk metrics
 	volatility := r.calculateVolatility(prices)
-	beta := r.calculateBeta(prices)
 	valueAtRisk := r.calculateValueAtRisk(prices)
 	maxDrawdown := r.calculateMaxDrawdown(prices)
-	riskScore := r.calculateRiskScore(volatility, beta, valueAtRisk)
-	esgRiskFactor := r.calculateESGRiskFactor(companyID)
+	riskScore := r.calculateRiskScore(volatility, betaEstimate.Beta, valueAtRisk)
+	esgRiskFactor := r.calculateESGRiskFactor(ctx, companyID)
 
 	return &RiskAssessment{
 		CompanyID:     companyID,
 		CompanyName:   companyName,
 		Volatility:    volatility,
-		Beta:          beta,
+		Beta:          betaEstimate.Beta,
+		BetaEstimate:  betaEstimate,
 		ValueAtRisk:   valueAtRisk,
 		MaxDrawdown:   maxDrawdown,
 		RiskScore:     riskScore,
@@ -281,9 +575,9 @@ func (r *AdvancedAnalyticsRepository) AssessRisk(companyID int) (*RiskAssessment
 }
 
 // AnalyzeTrend performs trend analysis on various metrics
-func (r *AdvancedAnalyticsRepository) AnalyzeTrend(companyID int, metric string, period string) (*TrendAnalysis, error) {
+func (r *AdvancedAnalyticsRepository) AnalyzeTrend(ctx context.Context, companyID int, metric string, period string) (*TrendAnalysis, error) {
 	var companyName string
-	err := r.db.QueryRow("SELECT name FROM companies WHERE id = $1", companyID).Scan(&companyName)
+	err := r.db.QueryRowContext(ctx, "SELECT name FROM companies WHERE id = $1", companyID).Scan(&companyName)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +614,7 @@ func (r *AdvancedAnalyticsRepository) AnalyzeTrend(companyID int, metric string,
 		return nil, sql.ErrNoRows
 	}
 
-	rows, err := r.db.Query(query, companyID)
+	rows, err := r.db.QueryContext(ctx, query, companyID)
 	if err != nil {
 		return nil, err
 	}
@@ -399,78 +693,6 @@ func (r *AdvancedAnalyticsRepository) calculateLinearRegression(values []float64
 	return slope, r2
 }
 
-func (r *AdvancedAnalyticsRepository) optimizeAllocations(companies []struct {
-	ID         int
-	Name       string
-	Sector     string
-	ESGScore   float64
-	MarketCap  float64
-	PERatio    float64
-	ROE        float64
-	ClosePrice float64
-}, targetReturn float64, riskTolerance string, maxCompanies int) []Allocation {
-
-	// Simple optimization: select top companies by ESG score and ROE
-	allocations := make([]Allocation, 0, maxCompanies)
-	totalValue := 1000000.0 // $1M portfolio
-
-	// Sort companies by combined score (ESG + ROE)
-	type scoredCompany struct {
-		company struct {
-			ID         int
-			Name       string
-			Sector     string
-			ESGScore   float64
-			MarketCap  float64
-			PERatio    float64
-			ROE        float64
-			ClosePrice float64
-		}
-		score float64
-	}
-
-	var scoredCompanies []scoredCompany
-	for _, comp := range companies {
-		score := comp.ESGScore*0.7 + comp.ROE*30 // Weight ESG more heavily
-		scoredCompanies = append(scoredCompanies, scoredCompany{company: comp, score: score})
-	}
-
-	// Select top companies
-	selectedCount := min(maxCompanies, len(scoredCompanies))
-	equalAllocation := totalValue / float64(selectedCount)
-
-	for i := 0; i < selectedCount; i++ {
-		comp := scoredCompanies[i].company
-		allocations = append(allocations, Allocation{
-			CompanyID:   comp.ID,
-			CompanyName: comp.Name,
-			Percentage:  100.0 / float64(selectedCount),
-			Amount:      equalAllocation,
-			ESGScore:    comp.ESGScore,
-		})
-	}
-
-	return allocations
-}
-
-func (r *AdvancedAnalyticsRepository) calculateExpectedReturn(allocations []Allocation) float64 {
-	totalReturn := 0.0
-	for _, alloc := range allocations {
-		// Simple return calculation based on ESG score
-		expectedReturn := 0.05 + (alloc.ESGScore/100.0)*0.10 // 5-15% range
-		totalReturn += expectedReturn * alloc.Percentage / 100.0
-	}
-	return totalReturn
-}
-
-func (r *AdvancedAnalyticsRepository) calculateSharpeRatio(allocations []Allocation) float64 {
-	expectedReturn := r.calculateExpectedReturn(allocations)
-	// Assume risk-free rate of 2% and portfolio volatility of 15%
-	riskFreeRate := 0.02
-	volatility := 0.15
-	return (expectedReturn - riskFreeRate) / volatility
-}
-
 func (r *AdvancedAnalyticsRepository) calculateVolatility(prices []float64) float64 {
 	if len(prices) < 2 {
 		return 0
@@ -498,9 +720,77 @@ func (r *AdvancedAnalyticsRepository) calculateVolatility(prices []float64) floa
 	return math.Sqrt(variance) * math.Sqrt(252) // Annualized
 }
 
-func (r *AdvancedAnalyticsRepository) calculateBeta(prices []float64) float64 {
-	// Simplified beta calculation (assuming market correlation of 0.7)
-	return 0.7
+// calculateBeta runs an ordinary least squares regression of stock returns on
+// market returns. Alpha is annualized; the standard error is that of the slope.
+func (r *AdvancedAnalyticsRepository) calculateBeta(stockReturns, marketReturns []float64) (*BetaEstimate, error) {
+	n := len(stockReturns)
+	if n != len(marketReturns) {
+		return nil, fmt.Errorf("return series length mismatch: %d vs %d", n, len(marketReturns))
+	}
+	if n < 3 {
+		return nil, fmt.Errorf("%w: %d aligned returns", ErrInsufficientOverlap, n)
+	}
+
+	meanStock := 0.0
+	meanMarket := 0.0
+	for i := 0; i < n; i++ {
+		meanStock += stockReturns[i]
+		meanMarket += marketReturns[i]
+	}
+	meanStock /= float64(n)
+	meanMarket /= float64(n)
+
+	sxx := 0.0
+	syy := 0.0
+	sxy := 0.0
+	for i := 0; i < n; i++ {
+		dx := marketReturns[i] - meanMarket
+		dy := stockReturns[i] - meanStock
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+
+	if sxx == 0 {
+		return nil, fmt.Errorf("%w: benchmark returns have no variance", ErrInsufficientOverlap)
+	}
+
+	beta := sxy / sxx
+	alpha := meanStock - beta*meanMarket
+
+	correlation := 0.0
+	if syy > 0 {
+		correlation = sxy / math.Sqrt(sxx*syy)
+	}
+
+	// Residual sum of squares for the slope's standard error
+	ssRes := 0.0
+	for i := 0; i < n; i++ {
+		residual := stockReturns[i] - (alpha + beta*marketReturns[i])
+		ssRes += residual * residual
+	}
+	standardError := math.Sqrt(ssRes / float64(n-2) / sxx)
+
+	return &BetaEstimate{
+		SampleSize:    n,
+		Beta:          beta,
+		Alpha:         alpha * 252,
+		Correlation:   correlation,
+		StandardError: standardError,
+	}, nil
+}
+
+// alignedReturns converts two date-aligned, chronologically ordered price
+// series into paired period returns, skipping periods with a zero base price
+func alignedReturns(stockPrices, marketPrices []float64) (stockReturns, marketReturns []float64) {
+	for i := 1; i < len(stockPrices) && i < len(marketPrices); i++ {
+		if stockPrices[i-1] == 0 || marketPrices[i-1] == 0 {
+			continue
+		}
+		stockReturns = append(stockReturns, (stockPrices[i]-stockPrices[i-1])/stockPrices[i-1])
+		marketReturns = append(marketReturns, (marketPrices[i]-marketPrices[i-1])/marketPrices[i-1])
+	}
+	return stockReturns, marketReturns
 }
 
 func (r *AdvancedAnalyticsRepository) calculateValueAtRisk(prices []float64) float64 {
@@ -559,10 +849,10 @@ func (r *AdvancedAnalyticsRepository) getRiskLevel(riskScore float64) string {
 	}
 }
 
-func (r *AdvancedAnalyticsRepository) calculateESGRiskFactor(companyID int) float64 {
+func (r *AdvancedAnalyticsRepository) calculateESGRiskFactor(ctx context.Context, companyID int) float64 {
 	// Get latest ESG score
 	var esgScore float64
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT overall_score 
 		FROM esg_scores 
 		WHERE company_id = $1 
@@ -591,10 +881,3 @@ func (r *AdvancedAnalyticsRepository) determineTrend(slope float64) string {
 func (r *AdvancedAnalyticsRepository) calculateConfidence(r2 float64) float64 {
 	return r2 * 100 // Convert to percentage
 }
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
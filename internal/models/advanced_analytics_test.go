@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateBeta_PerfectFit(t *testing.T) {
+	r := &AdvancedAnalyticsRepository{}
+
+	market := []float64{0.01, -0.02, 0.015, 0.003, -0.007, 0.012, -0.004, 0.009}
+	stock := make([]float64, len(market))
+	for i, m := range market {
+		stock[i] = 0.0001 + 1.5*m
+	}
+
+	estimate, err := r.calculateBeta(stock, market)
+	require.NoError(t, err)
+
+	assert.Equal(t, len(market), estimate.SampleSize)
+	assert.InDelta(t, 1.5, estimate.Beta, 1e-9)
+	assert.InDelta(t, 0.0001*252, estimate.Alpha, 1e-9)
+	assert.InDelta(t, 1.0, estimate.Correlation, 1e-9)
+	assert.InDelta(t, 0.0, estimate.StandardError, 1e-9)
+}
+
+func TestCalculateBeta_NoisyFit(t *testing.T) {
+	r := &AdvancedAnalyticsRepository{}
+
+	market := []float64{0.01, -0.02, 0.015, 0.003, -0.007, 0.012, -0.004, 0.009}
+	noise := []float64{0.002, -0.001, -0.002, 0.001, 0.003, -0.003, 0.0, 0.001}
+	stock := make([]float64, len(market))
+	for i, m := range market {
+		stock[i] = 0.8*m + noise[i]
+	}
+
+	estimate, err := r.calculateBeta(stock, market)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.8, estimate.Beta, 0.15)
+	assert.Greater(t, estimate.StandardError, 0.0)
+	assert.Less(t, estimate.Correlation, 1.0)
+	assert.False(t, math.IsNaN(estimate.StandardError))
+}
+
+func TestCalculateBeta_InsufficientData(t *testing.T) {
+	r := &AdvancedAnalyticsRepository{}
+
+	_, err := r.calculateBeta([]float64{0.01, 0.02}, []float64{0.01, 0.02})
+	assert.True(t, errors.Is(err, ErrInsufficientOverlap))
+
+	_, err = r.calculateBeta([]float64{0.01, 0.02, 0.03}, []float64{0.01, 0.01, 0.01})
+	assert.True(t, errors.Is(err, ErrInsufficientOverlap))
+}
+
+func TestAlignedReturns(t *testing.T) {
+	stock, market := alignedReturns([]float64{100, 110, 99}, []float64{50, 50, 55})
+
+	require.Len(t, stock, 2)
+	assert.InDelta(t, 0.10, stock[0], 1e-9)
+	assert.InDelta(t, -0.10, stock[1], 1e-9)
+	assert.InDelta(t, 0.0, market[0], 1e-9)
+	assert.InDelta(t, 0.10, market[1], 1e-9)
+}
+
+// tradingDays returns n consecutive dates
+func tradingDays(n int) []time.Time {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := make([]time.Time, n)
+	for i := range days {
+		days[i] = base.AddDate(0, 0, i)
+	}
+	return days
+}
+
+func TestSelectAlignedAssetsSkipsMisalignedCandidates(t *testing.T) {
+	dates := tradingDays(100)
+	prices := make(map[int]map[time.Time]float64)
+	addPrices := func(id int, days []time.Time) {
+		prices[id] = make(map[time.Time]float64)
+		for i, day := range days {
+			prices[id][day] = 100 + float64(i)
+		}
+	}
+	addPrices(1, dates[:40])
+	addPrices(2, dates[:70])
+	addPrices(3, dates[55:]) // Overlaps 2 on only 15 dates
+	addPrices(4, dates)
+	addPrices(5, dates[:10]) // Too little history on its own
+	candidates := []portfolioAsset{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+
+	assets, returns, err := selectAlignedAssets(candidates, prices, dates, 3)
+	require.NoError(t, err)
+	require.Len(t, assets, 3)
+	assert.Equal(t, []int{4, 2, 1}, []int{assets[0].ID, assets[1].ID, assets[2].ID}, "ranked by coverage")
+	for _, series := range returns {
+		assert.Len(t, series, 39, "returns over the 40 shared dates")
+	}
+
+	assets, _, err = selectAlignedAssets(candidates, prices, dates, 2)
+	require.NoError(t, err)
+	assert.Len(t, assets, 2, "stops at the maximum")
+
+	_, _, err = selectAlignedAssets([]portfolioAsset{{ID: 5}}, prices, dates, 3)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,386 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
+)
+
+// Alert rule types
+const (
+	// AlertRuleESGBelow triggers when a company's latest score for the metric is below the threshold
+	AlertRuleESGBelow = "esg_below"
+	// AlertRuleESGDrop triggers when the metric fell by more than threshold points
+	// since the company's score a quarter earlier
+	AlertRuleESGDrop = "esg_drop"
+	// AlertRulePriceDrop triggers when the latest close fell by more than threshold
+	// percent from the previous close
+	AlertRulePriceDrop = "price_drop"
+)
+
+// AlertRule is a user-defined condition on a company, or on every company of a
+// watchlist, that notifies its owner over the chosen channels
+type AlertRule struct {
+	ID              int        `json:"id"`
+	UserID          int        `json:"user_id"`
+	Name            string     `json:"name"`
+	RuleType        string     `json:"rule_type"`
+	CompanyID       *int       `json:"company_id"`
+	WatchlistID     *int       `json:"watchlist_id"`
+	Metric          string     `json:"metric"`
+	Threshold       float64    `json:"threshold"`
+	Channels        []string   `json:"channels"`
+	CooldownMinutes int        `json:"cooldown_minutes"`
+	Active          bool       `json:"active"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AlertNotification records one triggering of a rule for a company
+type AlertNotification struct {
+	ID            int       `json:"id"`
+	RuleID        int       `json:"rule_id"`
+	UserID        int       `json:"user_id"`
+	CompanyID     int       `json:"company_id"`
+	CompanySymbol string    `json:"company_symbol"`
+	DedupKey      string    `json:"-"`
+	Message       string    `json:"message"`
+	Value         float64   `json:"value"`
+	Threshold     float64   `json:"threshold"`
+	Channels      []string  `json:"channels"`
+	TriggeredAt   time.Time `json:"triggered_at"`
+}
+
+// ESGComparison is a company's latest ESG score and its score a quarter earlier
+type ESGComparison struct {
+	Current  *ESGScore
+	Previous *ESGScore
+}
+
+// AlertRuleRepository handles database operations for alert rules
+type AlertRuleRepository struct {
+	db *tracing.DB
+}
+
+// NewAlertRuleRepository creates a new alert rule repository
+func NewAlertRuleRepository(db *sql.DB) *AlertRuleRepository {
+	return &AlertRuleRepository{db: tracing.WrapDB(db)}
+}
+
+const alertRuleColumns = `id, user_id, name, rule_type, company_id, watchlist_id, metric, threshold,
+	channels, cooldown_minutes, active, last_triggered_at, created_at, updated_at`
+
+// CreateRule creates an alert rule
+func (r *AlertRuleRepository) CreateRule(ctx context.Context, rule *AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (user_id, name, rule_type, company_id, watchlist_id, metric, threshold, channels, cooldown_minutes, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx,
+		query,
+		rule.UserID,
+		rule.Name,
+		rule.RuleType,
+		rule.CompanyID,
+		rule.WatchlistID,
+		rule.Metric,
+		rule.Threshold,
+		pq.Array(rule.Channels),
+		rule.CooldownMinutes,
+		rule.Active,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// ListRules retrieves a user's alert rules
+func (r *AlertRuleRepository) ListRules(ctx context.Context, userID int) ([]*AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertRules(rows)
+}
+
+// GetRule retrieves an alert rule owned by a user
+func (r *AlertRuleRepository) GetRule(ctx context.Context, userID, id int) (*AlertRule, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = $1 AND user_id = $2`, id, userID)
+	return scanAlertRule(row)
+}
+
+// UpdateRule updates an owned alert rule
+func (r *AlertRuleRepository) UpdateRule(ctx context.Context, rule *AlertRule) error {
+	query := `
+		UPDATE alert_rules
+		SET name = $1, rule_type = $2, company_id = $3, watchlist_id = $4, metric = $5, threshold = $6,
+			channels = $7, cooldown_minutes = $8, active = $9
+		WHERE id = $10 AND user_id = $11
+		RETURNING last_triggered_at, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx,
+		query,
+		rule.Name,
+		rule.RuleType,
+		rule.CompanyID,
+		rule.WatchlistID,
+		rule.Metric,
+		rule.Threshold,
+		pq.Array(rule.Channels),
+		rule.CooldownMinutes,
+		rule.Active,
+		rule.ID,
+		rule.UserID,
+	).Scan(&rule.LastTriggeredAt, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// DeleteRule deletes an owned alert rule and its notifications
+func (r *AlertRuleRepository) DeleteRule(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// RulesForCompany retrieves the active rules of the given types that apply to
+// a company, directly or through a watchlist
+func (r *AlertRuleRepository) RulesForCompany(ctx context.Context, companyID int, ruleTypes []string) ([]*AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+alertRuleColumns+`
+		FROM alert_rules r
+		WHERE r.active AND r.rule_type = ANY($2)
+			AND (r.company_id = $1 OR EXISTS (
+				SELECT 1 FROM watchlist_items wi
+				JOIN watchlists w ON w.id = wi.watchlist_id
+				WHERE wi.watchlist_id = r.watchlist_id AND w.user_id = r.user_id AND wi.company_id = $1
+			))
+		ORDER BY r.id
+	`, companyID, pq.Array(ruleTypes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertRules(rows)
+}
+
+// CompaniesWithActiveRules returns every company at least one active rule applies to
+func (r *AlertRuleRepository) CompaniesWithActiveRules(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT company_id FROM alert_rules WHERE active AND company_id IS NOT NULL
+		UNION
+		SELECT wi.company_id
+		FROM alert_rules r
+		JOIN watchlists w ON w.id = r.watchlist_id AND w.user_id = r.user_id
+		JOIN watchlist_items wi ON wi.watchlist_id = w.id
+		WHERE r.active
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ESGComparison loads a company's latest ESG score and the most recent score
+// dated at least three months before it. Previous is nil without such a score.
+func (r *AlertRuleRepository) ESGComparison(ctx context.Context, companyID int) (*ESGComparison, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH latest AS (
+			SELECT * FROM esg_scores WHERE company_id = $1
+			ORDER BY score_date DESC, id DESC LIMIT 1
+		)
+		SELECT id, company_id, environmental_score, social_score, governance_score, overall_score, score_date
+		FROM latest
+		UNION ALL
+		SELECT * FROM (
+			SELECT es.id, es.company_id, es.environmental_score, es.social_score, es.governance_score,
+				es.overall_score, es.score_date
+			FROM esg_scores es, latest
+			WHERE es.company_id = $1 AND es.score_date <= latest.score_date - INTERVAL '3 months'
+			ORDER BY es.score_date DESC, es.id DESC LIMIT 1
+		) previous
+		ORDER BY score_date DESC
+	`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []*ESGScore
+	for rows.Next() {
+		score := &ESGScore{}
+		if err := rows.Scan(
+			&score.ID,
+			&score.CompanyID,
+			&score.EnvironmentalScore,
+			&score.SocialScore,
+			&score.GovernanceScore,
+			&score.OverallScore,
+			&score.ScoreDate,
+		); err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	comparison := &ESGComparison{}
+	if len(scores) > 0 {
+		comparison.Current = scores[0]
+	}
+	if len(scores) > 1 {
+		comparison.Previous = scores[1]
+	}
+	return comparison, nil
+}
+
+// RecordNotification stores a triggered notification unless the same data
+// already triggered the rule for the company, or the rule fired for the company
+// within its cooldown. It reports whether the notification was recorded.
+func (r *AlertRuleRepository) RecordNotification(ctx context.Context, rule *AlertRule, notification *AlertNotification) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO alert_notifications (rule_id, user_id, company_id, dedup_key, message, value, threshold)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (
+			SELECT 1 FROM alert_notifications
+			WHERE rule_id = $1 AND company_id = $3
+				AND triggered_at > CURRENT_TIMESTAMP - make_interval(mins => $8)
+		)
+		ON CONFLICT (rule_id, company_id, dedup_key) DO NOTHING
+		RETURNING id, triggered_at
+	`,
+		rule.ID,
+		rule.UserID,
+		notification.CompanyID,
+		notification.DedupKey,
+		notification.Message,
+		notification.Value,
+		notification.Threshold,
+		rule.CooldownMinutes,
+	).Scan(&notification.ID, &notification.TriggeredAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE alert_rules SET last_triggered_at = $2 WHERE id = $1`, rule.ID, notification.TriggeredAt); err != nil {
+		return false, err
+	}
+
+	notification.RuleID = rule.ID
+	notification.UserID = rule.UserID
+	return true, tx.Commit()
+}
+
+// SetNotificationChannels records which channels a notification was delivered over
+func (r *AlertRuleRepository) SetNotificationChannels(ctx context.Context, id int, channels []string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE alert_notifications SET channels = $2 WHERE id = $1`, id, pq.Array(channels))
+	return err
+}
+
+// ListNotifications retrieves a user's most recent notifications
+func (r *AlertRuleRepository) ListNotifications(ctx context.Context, userID, limit int) ([]*AlertNotification, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.id, n.rule_id, n.user_id, n.company_id, c.symbol, n.message, n.value, n.threshold,
+			n.channels, n.triggered_at
+		FROM alert_notifications n
+		JOIN companies c ON c.id = n.company_id
+		WHERE n.user_id = $1
+		ORDER BY n.triggered_at DESC, n.id DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*AlertNotification{}
+	for rows.Next() {
+		notification := &AlertNotification{}
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.RuleID,
+			&notification.UserID,
+			&notification.CompanyID,
+			&notification.CompanySymbol,
+			&notification.Message,
+			&notification.Value,
+			&notification.Threshold,
+			pq.Array(&notification.Channels),
+			&notification.TriggeredAt,
+		); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// scanAlertRules scans rows selected with alertRuleColumns
+func scanAlertRules(rows *sql.Rows) ([]*AlertRule, error) {
+	rules := []*AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// scanAlertRule scans a row selected with alertRuleColumns
+func scanAlertRule(row interface{ Scan(...interface{}) error }) (*AlertRule, error) {
+	rule := &AlertRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.RuleType,
+		&rule.CompanyID,
+		&rule.WatchlistID,
+		&rule.Metric,
+		&rule.Threshold,
+		pq.Array(&rule.Channels),
+		&rule.CooldownMinutes,
+		&rule.Active,
+		&rule.LastTriggeredAt,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
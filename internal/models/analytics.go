@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
 )
 
 // ESGTrend represents ESG score trends over time
@@ -55,16 +58,16 @@ type PerformanceMetric struct {
 
 // AnalyticsRepository handles complex analytical database operations
 type AnalyticsRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewAnalyticsRepository creates a new analytics repository
 func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
-	return &AnalyticsRepository{db: db}
+	return &AnalyticsRepository{db: tracing.WrapDB(db)}
 }
 
 // GetESGTrends retrieves ESG score trends for a company
-func (r *AnalyticsRepository) GetESGTrends(companyID int, days int) ([]ESGTrend, error) {
+func (r *AnalyticsRepository) GetESGTrends(ctx context.Context, companyID int, days int) ([]ESGTrend, error) {
 	query := `
 		SELECT es.company_id, c.name as company_name, es.score_date, es.overall_score, es.environmental_score, es.social_score, es.governance_score
 		FROM esg_scores es
@@ -74,7 +77,7 @@ func (r *AnalyticsRepository) GetESGTrends(companyID int, days int) ([]ESGTrend,
 		LIMIT $2
 	`
 
-	rows, err := r.db.Query(query, companyID, days)
+	rows, err := r.db.QueryContext(ctx, query, companyID, days)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +100,7 @@ func (r *AnalyticsRepository) GetESGTrends(companyID int, days int) ([]ESGTrend,
 }
 
 // GetSectorComparisons retrieves sector-level ESG and financial comparisons
-func (r *AnalyticsRepository) GetSectorComparisons() ([]SectorComparison, error) {
+func (r *AnalyticsRepository) GetSectorComparisons(ctx context.Context) ([]SectorComparison, error) {
 	query := `
 		WITH latest_esg AS (
 			SELECT DISTINCT ON (company_id) company_id, overall_score, score_date
@@ -151,7 +154,7 @@ func (r *AnalyticsRepository) GetSectorComparisons() ([]SectorComparison, error)
 		ORDER BY ss.avg_esg_score DESC
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +178,7 @@ func (r *AnalyticsRepository) GetSectorComparisons() ([]SectorComparison, error)
 }
 
 // GetFinancialComparisons retrieves financial performance comparisons
-func (r *AnalyticsRepository) GetFinancialComparisons(limit int) ([]FinancialComparison, error) {
+func (r *AnalyticsRepository) GetFinancialComparisons(ctx context.Context, limit int) ([]FinancialComparison, error) {
 	query := `
 		WITH latest_esg AS (
 			SELECT DISTINCT ON (company_id) company_id, overall_score
@@ -218,7 +221,7 @@ func (r *AnalyticsRepository) GetFinancialComparisons(limit int) ([]FinancialCom
 		LIMIT $1
 	`
 
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -242,7 +245,7 @@ func (r *AnalyticsRepository) GetFinancialComparisons(limit int) ([]FinancialCom
 }
 
 // GetTopPerformers retrieves top performing companies by various metrics
-func (r *AnalyticsRepository) GetTopPerformers(metric string, limit int) ([]PerformanceMetric, error) {
+func (r *AnalyticsRepository) GetTopPerformers(ctx context.Context, metric string, limit int) ([]PerformanceMetric, error) {
 	var query string
 
 	switch metric {
@@ -323,7 +326,7 @@ func (r *AnalyticsRepository) GetTopPerformers(metric string, limit int) ([]Perf
 		return nil, sql.ErrNoRows
 	}
 
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +350,7 @@ func (r *AnalyticsRepository) GetTopPerformers(metric string, limit int) ([]Perf
 }
 
 // GetESGvsFinancialCorrelation calculates correlation between ESG scores and financial metrics
-func (r *AnalyticsRepository) GetESGvsFinancialCorrelation() (map[string]interface{}, error) {
+func (r *AnalyticsRepository) GetESGvsFinancialCorrelation(ctx context.Context) (map[string]interface{}, error) {
 	query := `
 		WITH latest_data AS (
 			SELECT 
@@ -403,7 +406,7 @@ func (r *AnalyticsRepository) GetESGvsFinancialCorrelation() (map[string]interfa
 	var avgESGScore, avgMarketCap, avgPERatio, avgROE, avgProfitMargin float64
 	var esgMarketCapCorr, esgPECorr, esgROECorr, esgProfitCorr float64
 
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRowContext(ctx, query).Scan(
 		&sampleSize, &avgESGScore, &avgMarketCap, &avgPERatio, &avgROE, &avgProfitMargin,
 		&esgMarketCapCorr, &esgPECorr, &esgROECorr, &esgProfitCorr,
 	)
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
+)
+
+// APIKey represents an API key owned by a user. The raw key is only returned
+// once at creation or rotation; the database stores its hash.
+type APIKey struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db *tracing.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: tracing.WrapDB(db)}
+}
+
+// CreateAPIKey stores a new API key with its hash
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx,
+		query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		keyHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt, &key.UpdatedAt)
+}
+
+// ListAPIKeys retrieves all API keys owned by a user, newest first
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID int) ([]*APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at, updated_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		key := &APIKey{}
+		if err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			pq.Array(&key.Scopes),
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.CreatedAt,
+			&key.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateAPIKey revokes an active key and issues a replacement with the same
+// name, scopes and expiry, returning the replacement
+func (r *APIKeyRepository) RotateAPIKey(ctx context.Context, userID, id int, prefix, keyHash string) (*APIKey, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	key := &APIKey{UserID: userID, Prefix: prefix}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING name, scopes, expires_at
+	`, id, userID).Scan(&key.Name, pq.Array(&key.Scopes), &key.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, userID, key.Name, prefix, keyHash, pq.Array(key.Scopes), key.ExpiresAt).Scan(&key.ID, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, tx.Commit()
+}
+
+// RevokeAPIKey revokes an active key owned by a user
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// AuthenticateAPIKey validates a raw key against the store and returns its owner.
+// Roles are read from the owner's current record so role changes apply immediately.
+// Last-used time is recorded at most once a minute per key.
+func (r *APIKeyRepository) AuthenticateAPIKey(ctx context.Context, rawKey string) (*auth.APIKeyIdentity, error) {
+	lookup, err := auth.ParseAPIKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &auth.APIKeyIdentity{}
+	var keyHash string
+	var expiresAt, revokedAt *time.Time
+	err = r.db.QueryRowContext(ctx, `
+		SELECT k.id, k.key_hash, k.scopes, k.expires_at, k.revoked_at, u.id, u.email, u.roles
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = $1
+	`, lookup).Scan(
+		&identity.KeyID,
+		&keyHash,
+		pq.Array(&identity.Scopes),
+		&expiresAt,
+		&revokedAt,
+		&identity.UserID,
+		&identity.Email,
+		pq.Array(&identity.Roles),
+	)
+	if err == sql.ErrNoRows {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.APIKeyMatches(rawKey, keyHash) || revokedAt != nil {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if expiresAt != nil && time.Now().After(*expiresAt) {
+		return nil, auth.ErrAPIKeyExpired
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, identity.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
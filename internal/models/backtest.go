@@ -0,0 +1,565 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Rebalance frequencies supported by the backtester
+const (
+	RebalanceNone      = "none"
+	RebalanceDaily     = "daily"
+	RebalanceWeekly    = "weekly"
+	RebalanceMonthly   = "monthly"
+	RebalanceQuarterly = "quarterly"
+)
+
+// Backtest rule types
+const (
+	BacktestRuleFixedWeights = "fixed_weights"
+	BacktestRuleTopNESG      = "top_n_esg"
+)
+
+// BacktestConfig describes a backtest to run
+type BacktestConfig struct {
+	StartDate          time.Time          `json:"start_date"`
+	EndDate            time.Time          `json:"end_date"`
+	InitialCapital     float64            `json:"initial_capital"`
+	Rule               string             `json:"rule"`
+	Weights            map[string]float64 `json:"weights,omitempty"` // Symbol -> weight for fixed_weights
+	TopN               int                `json:"top_n,omitempty"`
+	Weighting          string             `json:"weighting,omitempty"` // "equal" or "esg" for top_n_esg
+	Sector             string             `json:"sector,omitempty"`
+	RebalanceFrequency string             `json:"rebalance_frequency"`
+	TransactionCostBps float64            `json:"transaction_cost_bps"`
+	RiskFreeRate       float64            `json:"risk_free_rate"`
+}
+
+// BacktestResult holds the outcome of a backtest
+type BacktestResult struct {
+	Config      BacktestConfig   `json:"config"`
+	Metrics     BacktestMetrics  `json:"metrics"`
+	Benchmark   BacktestMetrics  `json:"benchmark"`
+	EquityCurve []EquityPoint    `json:"equity_curve"`
+	Rebalances  []RebalanceEvent `json:"rebalances"`
+	Companies   map[int]string   `json:"companies"`
+	Warnings    []string         `json:"warnings,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// BacktestMetrics summarizes the performance of an equity curve
+type BacktestMetrics struct {
+	TotalReturn        float64 `json:"total_return"`
+	CAGR               float64 `json:"cagr"`
+	Volatility         float64 `json:"volatility"`
+	MaxDrawdown        float64 `json:"max_drawdown"`
+	SharpeRatio        float64 `json:"sharpe_ratio"`
+	Turnover           float64 `json:"turnover"`
+	AnnualizedTurnover float64 `json:"annualized_turnover"`
+	TransactionCosts   float64 `json:"transaction_costs"`
+}
+
+// EquityPoint is one day of the portfolio and benchmark equity curves
+type EquityPoint struct {
+	Date           time.Time `json:"date"`
+	Value          float64   `json:"value"`
+	BenchmarkValue float64   `json:"benchmark_value"`
+}
+
+// RebalanceEvent records the target weights set on a rebalance date
+type RebalanceEvent struct {
+	Date     time.Time       `json:"date"`
+	Weights  map[int]float64 `json:"weights"` // Company ID -> weight
+	Turnover float64         `json:"turnover"`
+	Cost     float64         `json:"cost"`
+}
+
+// esgObservation is a dated overall ESG score
+type esgObservation struct {
+	date  time.Time
+	score float64
+}
+
+// backtestInputs holds the market data replayed by the simulator
+type backtestInputs struct {
+	dates     []time.Time
+	prices    map[int]map[time.Time]float64
+	benchmark map[time.Time]float64
+	// targetWeights returns company weights to hold from the close of a rebalance date
+	targetWeights func(date time.Time) map[int]float64
+}
+
+// RunBacktest replays historical adjusted closes for a fixed-weight portfolio or
+// a top-N-by-ESG rule. ESG scores are looked up as of each rebalance date so
+// that selections never use scores dated after the trade.
+func (r *AdvancedAnalyticsRepository) RunBacktest(ctx context.Context, config BacktestConfig) (*BacktestResult, error) {
+	companies, err := r.backtestUniverse(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(companies) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	ids := make([]int64, 0, len(companies))
+	for id := range companies {
+		ids = append(ids, int64(id))
+	}
+
+	prices, dates, err := r.loadBacktestPrices(ctx, ids, config.StartDate, config.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(dates) < 2 {
+		return nil, sql.ErrNoRows
+	}
+
+	benchmark, err := r.loadBenchmarkCloses(ctx, config.StartDate, config.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := backtestInputs{dates: dates, prices: prices, benchmark: benchmark}
+	var warnings []string
+
+	switch config.Rule {
+	case BacktestRuleFixedWeights:
+		weights := make(map[int]float64)
+		for id, symbol := range companies {
+			weights[id] = config.Weights[symbol]
+		}
+		for symbol := range config.Weights {
+			found := false
+			for _, s := range companies {
+				if s == symbol {
+					found = true
+					break
+				}
+			}
+			if !found {
+				warnings = append(warnings, fmt.Sprintf("unknown symbol %s held as cash", symbol))
+			}
+		}
+		inputs.targetWeights = func(time.Time) map[int]float64 { return weights }
+
+	case BacktestRuleTopNESG:
+		scores, err := r.loadESGHistory(ctx, ids, config.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		inputs.targetWeights = func(date time.Time) map[int]float64 {
+			return selectTopESG(scores, prices, date, config.TopN, config.Weighting)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported backtest rule %q", config.Rule)
+	}
+
+	result := r.simulateBacktest(config, inputs)
+	result.Companies = companies
+	result.Warnings = append(warnings, result.Warnings...)
+
+	return result, nil
+}
+
+// backtestUniverse returns company ID -> symbol for the companies the backtest may hold
+func (r *AdvancedAnalyticsRepository) backtestUniverse(ctx context.Context, config BacktestConfig) (map[int]string, error) {
+	var rows *sql.Rows
+	var err error
+
+	if config.Rule == BacktestRuleFixedWeights {
+		symbols := make([]string, 0, len(config.Weights))
+		for symbol := range config.Weights {
+			symbols = append(symbols, symbol)
+		}
+		rows, err = r.db.QueryContext(ctx, `SELECT id, symbol FROM companies WHERE symbol = ANY($1)`, pq.Array(symbols))
+	} else if config.Sector != "" {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT id, symbol FROM companies
+			WHERE sector = $1 AND EXISTS (SELECT 1 FROM esg_scores es WHERE es.company_id = companies.id)
+		`, config.Sector)
+	} else {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT id, symbol FROM companies
+			WHERE EXISTS (SELECT 1 FROM esg_scores es WHERE es.company_id = companies.id)
+		`)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	companies := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		companies[id] = symbol
+	}
+
+	return companies, rows.Err()
+}
+
+// loadBacktestPrices loads adjusted closes for the window and the sorted set of trading dates
+func (r *AdvancedAnalyticsRepository) loadBacktestPrices(ctx context.Context, ids []int64, start, end time.Time) (map[int]map[time.Time]float64, []time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT company_id, date, adjusted_close
+		FROM stock_prices
+		WHERE company_id = ANY($1)
+		AND date BETWEEN $2 AND $3
+		ORDER BY date ASC
+	`, pq.Array(ids), start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[int]map[time.Time]float64)
+	var dates []time.Time
+	seen := make(map[time.Time]bool)
+	for rows.Next() {
+		var companyID int
+		var date time.Time
+		var price float64
+		if err := rows.Scan(&companyID, &date, &price); err != nil {
+			return nil, nil, err
+		}
+		if prices[companyID] == nil {
+			prices[companyID] = make(map[time.Time]float64)
+		}
+		prices[companyID][date] = price
+		if !seen[date] {
+			seen[date] = true
+			dates = append(dates, date)
+		}
+	}
+
+	return prices, dates, rows.Err()
+}
+
+// loadBenchmarkCloses loads S&P 500 closes for the window
+func (r *AdvancedAnalyticsRepository) loadBenchmarkCloses(ctx context.Context, start, end time.Time) (map[time.Time]float64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date, sp500_close
+		FROM market_data
+		WHERE date BETWEEN $1 AND $2
+		AND sp500_close IS NOT NULL
+		ORDER BY date ASC
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closes := make(map[time.Time]float64)
+	for rows.Next() {
+		var date time.Time
+		var close float64
+		if err := rows.Scan(&date, &close); err != nil {
+			return nil, err
+		}
+		closes[date] = close
+	}
+
+	return closes, rows.Err()
+}
+
+// loadESGHistory loads every overall score dated on or before end, oldest first per company
+func (r *AdvancedAnalyticsRepository) loadESGHistory(ctx context.Context, ids []int64, end time.Time) (map[int][]esgObservation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT company_id, score_date, overall_score
+		FROM esg_scores
+		WHERE company_id = ANY($1)
+		AND score_date <= $2
+		AND overall_score IS NOT NULL
+		ORDER BY company_id, score_date ASC
+	`, pq.Array(ids), end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make(map[int][]esgObservation)
+	for rows.Next() {
+		var companyID int
+		var obs esgObservation
+		if err := rows.Scan(&companyID, &obs.date, &obs.score); err != nil {
+			return nil, err
+		}
+		history[companyID] = append(history[companyID], obs)
+	}
+
+	return history, rows.Err()
+}
+
+// selectTopESG picks the top N companies by the latest score dated on or before
+// the rebalance date, among companies with a price on that date
+func selectTopESG(history map[int][]esgObservation, prices map[int]map[time.Time]float64, date time.Time, topN int, weighting string) map[int]float64 {
+	type candidate struct {
+		id    int
+		score float64
+	}
+
+	var candidates []candidate
+	for id, observations := range history {
+		if _, ok := prices[id][date]; !ok {
+			continue
+		}
+		// Observations are sorted ascending; find the last one not after the date
+		idx := sort.Search(len(observations), func(i int) bool {
+			return observations[i].date.After(date)
+		}) - 1
+		if idx < 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, score: observations[idx].score})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score == candidates[j].score {
+			return candidates[i].id < candidates[j].id
+		}
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > topN {
+		candidates = candidates[:topN]
+	}
+
+	weights := make(map[int]float64, len(candidates))
+	total := 0.0
+	for _, c := range candidates {
+		if weighting == "esg" {
+			total += c.score
+		} else {
+			total++
+		}
+	}
+	for _, c := range candidates {
+		if total == 0 {
+			break
+		}
+		if weighting == "esg" {
+			weights[c.id] = c.score / total
+		} else {
+			weights[c.id] = 1 / total
+		}
+	}
+
+	return weights
+}
+
+// simulateBacktest replays the trading dates, rebalancing at the close of each
+// scheduled date and charging transaction costs on traded notional
+func (r *AdvancedAnalyticsRepository) simulateBacktest(config BacktestConfig, in backtestInputs) *BacktestResult {
+	result := &BacktestResult{
+		Config:      config,
+		EquityCurve: make([]EquityPoint, 0, len(in.dates)),
+		Rebalances:  []RebalanceEvent{},
+		CreatedAt:   time.Now(),
+	}
+
+	costRate := config.TransactionCostBps / 10000
+	cash := config.InitialCapital
+	units := make(map[int]float64)
+	lastPrice := make(map[int]float64)
+
+	var benchmarkBase, lastBenchmark float64
+	totalTurnover := 0.0
+	totalCosts := 0.0
+
+	values := make([]float64, 0, len(in.dates))
+	benchmarkValues := make([]float64, 0, len(in.dates))
+
+	for i, date := range in.dates {
+		for id, series := range in.prices {
+			if price, ok := series[date]; ok {
+				lastPrice[id] = price
+			}
+		}
+
+		value := cash
+		for id, u := range units {
+			value += u * lastPrice[id]
+		}
+
+		if i == 0 || isRebalanceDate(config.RebalanceFrequency, in.dates[i-1], date) {
+			targets := in.targetWeights(date)
+
+			// Only hold names with a known price; unallocated weight stays in cash
+			traded := 0.0
+			targetValues := make(map[int]float64)
+			for id, w := range targets {
+				if lastPrice[id] > 0 && w != 0 {
+					targetValues[id] = w * value
+				}
+			}
+			for id, u := range units {
+				traded += math.Abs(targetValues[id] - u*lastPrice[id])
+			}
+			for id, tv := range targetValues {
+				if _, held := units[id]; !held {
+					traded += math.Abs(tv)
+				}
+			}
+
+			cost := traded * costRate
+			investable := value - cost
+			scale := 0.0
+			if value > 0 {
+				scale = investable / value
+			}
+
+			units = make(map[int]float64)
+			invested := 0.0
+			for id, tv := range targetValues {
+				units[id] = tv * scale / lastPrice[id]
+				invested += tv * scale
+			}
+			cash = investable - invested
+
+			turnover := 0.0
+			if value > 0 {
+				turnover = traded / (2 * value)
+			}
+			// The initial purchase is funding, not turnover
+			if i > 0 {
+				totalTurnover += turnover
+			}
+			totalCosts += cost
+			value = investable
+
+			weights := make(map[int]float64, len(targetValues))
+			for id, tv := range targetValues {
+				if value > 0 {
+					weights[id] = tv * scale / value
+				}
+			}
+			result.Rebalances = append(result.Rebalances, RebalanceEvent{
+				Date:     date,
+				Weights:  weights,
+				Turnover: turnover,
+				Cost:     cost,
+			})
+		}
+
+		if close, ok := in.benchmark[date]; ok {
+			if benchmarkBase == 0 {
+				benchmarkBase = close
+			}
+			lastBenchmark = close
+		}
+		benchmarkValue := 0.0
+		if benchmarkBase > 0 {
+			benchmarkValue = config.InitialCapital * lastBenchmark / benchmarkBase
+		}
+
+		values = append(values, value)
+		benchmarkValues = append(benchmarkValues, benchmarkValue)
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{
+			Date:           date,
+			Value:          value,
+			BenchmarkValue: benchmarkValue,
+		})
+	}
+
+	start := in.dates[0]
+	end := in.dates[len(in.dates)-1]
+	result.Metrics = r.equityCurveMetrics(values, start, end, config.RiskFreeRate)
+	result.Metrics.Turnover = totalTurnover
+	result.Metrics.TransactionCosts = totalCosts
+	if years := end.Sub(start).Hours() / 24 / 365.25; years > 0 {
+		result.Metrics.AnnualizedTurnover = totalTurnover / years
+	}
+
+	if benchmarkBase > 0 {
+		// Ignore the leading days before the first benchmark observation
+		firstBenchmark := 0
+		for firstBenchmark < len(benchmarkValues) && benchmarkValues[firstBenchmark] == 0 {
+			firstBenchmark++
+		}
+		result.Benchmark = r.equityCurveMetrics(benchmarkValues[firstBenchmark:], in.dates[firstBenchmark], end, config.RiskFreeRate)
+	} else {
+		result.Warnings = append(result.Warnings, "no S&P 500 benchmark data in the backtest window")
+	}
+
+	return result
+}
+
+// equityCurveMetrics computes return, risk and drawdown statistics for a daily equity curve
+func (r *AdvancedAnalyticsRepository) equityCurveMetrics(values []float64, start, end time.Time, riskFreeRate float64) BacktestMetrics {
+	var metrics BacktestMetrics
+	if len(values) < 2 || values[0] <= 0 {
+		return metrics
+	}
+
+	last := values[len(values)-1]
+	metrics.TotalReturn = last/values[0] - 1
+
+	if years := end.Sub(start).Hours() / 24 / 365.25; years > 0 && last > 0 {
+		metrics.CAGR = math.Pow(last/values[0], 1/years) - 1
+	}
+
+	returns := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] > 0 {
+			returns = append(returns, values[i]/values[i-1]-1)
+		}
+	}
+
+	mean := 0.0
+	for _, ret := range returns {
+		mean += ret
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, ret := range returns {
+		variance += (ret - mean) * (ret - mean)
+	}
+	if len(returns) > 1 {
+		variance /= float64(len(returns) - 1)
+	}
+
+	metrics.Volatility = math.Sqrt(variance) * math.Sqrt(252)
+	metrics.MaxDrawdown = r.calculateMaxDrawdown(values)
+	if metrics.Volatility > 0 {
+		metrics.SharpeRatio = (mean*252 - riskFreeRate) / metrics.Volatility
+	}
+
+	return metrics
+}
+
+// isRebalanceDate reports whether the portfolio rebalances on date given the previous trading date
+func isRebalanceDate(frequency string, previous, date time.Time) bool {
+	switch frequency {
+	case RebalanceDaily:
+		return true
+	case RebalanceWeekly:
+		py, pw := previous.ISOWeek()
+		y, w := date.ISOWeek()
+		return py != y || pw != w
+	case RebalanceMonthly:
+		return previous.Year() != date.Year() || previous.Month() != date.Month()
+	case RebalanceQuarterly:
+		return previous.Year() != date.Year() || (int(previous.Month())-1)/3 != (int(date.Month())-1)/3
+	default:
+		return false
+	}
+}
+
+// IsValidRebalanceFrequency reports whether a rebalance frequency is supported
+func IsValidRebalanceFrequency(frequency string) bool {
+	switch frequency {
+	case RebalanceNone, RebalanceDaily, RebalanceWeekly, RebalanceMonthly, RebalanceQuarterly:
+		return true
+	}
+	return false
+}
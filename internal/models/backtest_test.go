@@ -0,0 +1,126 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func backtestDates(start time.Time, n int) []time.Time {
+	dates := make([]time.Time, n)
+	for i := range dates {
+		dates[i] = start.AddDate(0, 0, i)
+	}
+	return dates
+}
+
+func TestSimulateBacktest_BuyAndHold(t *testing.T) {
+	r := &AdvancedAnalyticsRepository{}
+	dates := backtestDates(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 3)
+
+	inputs := backtestInputs{
+		dates: dates,
+		prices: map[int]map[time.Time]float64{
+			1: {dates[0]: 10, dates[1]: 11, dates[2]: 12},
+			2: {dates[0]: 20, dates[1]: 18, dates[2]: 20},
+		},
+		benchmark: map[time.Time]float64{dates[0]: 100, dates[1]: 105, dates[2]: 110},
+		targetWeights: func(time.Time) map[int]float64 {
+			return map[int]float64{1: 0.5, 2: 0.5}
+		},
+	}
+
+	result := r.simulateBacktest(BacktestConfig{
+		InitialCapital:     1000,
+		RebalanceFrequency: RebalanceNone,
+	}, inputs)
+
+	require.Len(t, result.EquityCurve, 3)
+	require.Len(t, result.Rebalances, 1)
+
+	// 50 units of #1 and 25 units of #2
+	assert.InDelta(t, 1000, result.EquityCurve[0].Value, 1e-9)
+	assert.InDelta(t, 550+450, result.EquityCurve[1].Value, 1e-9)
+	assert.InDelta(t, 600+500, result.EquityCurve[2].Value, 1e-9)
+	assert.InDelta(t, 1100, result.EquityCurve[2].BenchmarkValue, 1e-9)
+
+	assert.InDelta(t, 0.10, result.Metrics.TotalReturn, 1e-9)
+	assert.InDelta(t, 0.0, result.Metrics.Turnover, 1e-9)
+	assert.InDelta(t, 0.0, result.Metrics.MaxDrawdown, 1e-9)
+	assert.InDelta(t, 0.10, result.Benchmark.TotalReturn, 1e-9)
+}
+
+func TestSimulateBacktest_RebalanceCostsAndTurnover(t *testing.T) {
+	r := &AdvancedAnalyticsRepository{}
+	dates := backtestDates(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 2)
+
+	inputs := backtestInputs{
+		dates: dates,
+		prices: map[int]map[time.Time]float64{
+			1: {dates[0]: 10, dates[1]: 10},
+			2: {dates[0]: 10, dates[1]: 10},
+		},
+		benchmark: map[time.Time]float64{},
+		targetWeights: func(date time.Time) map[int]float64 {
+			if date.Equal(dates[0]) {
+				return map[int]float64{1: 1}
+			}
+			return map[int]float64{2: 1}
+		},
+	}
+
+	result := r.simulateBacktest(BacktestConfig{
+		InitialCapital:     1000,
+		RebalanceFrequency: RebalanceDaily,
+		TransactionCostBps: 10,
+	}, inputs)
+
+	require.Len(t, result.Rebalances, 2)
+
+	// Initial purchase of 1000 costs 1; switching 999 out and in trades 1998
+	assert.InDelta(t, 1.0, result.Rebalances[0].Cost, 1e-9)
+	assert.InDelta(t, 1.998, result.Rebalances[1].Cost, 1e-9)
+	assert.InDelta(t, 1.0, result.Metrics.Turnover, 1e-9)
+	assert.InDelta(t, 1000-1-1.998, result.EquityCurve[1].Value, 1e-9)
+	assert.NotEmpty(t, result.Warnings)
+}
+
+func TestSelectTopESG_PointInTime(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	history := map[int][]esgObservation{
+		1: {{date: jan, score: 60}, {date: feb, score: 90}},
+		2: {{date: jan, score: 80}},
+		3: {{date: feb, score: 95}},
+	}
+	prices := map[int]map[time.Time]float64{
+		1: {jan: 10, feb: 10},
+		2: {jan: 10, feb: 10},
+		3: {jan: 10, feb: 10},
+	}
+
+	// Scores dated in February must not be visible in January
+	weights := selectTopESG(history, prices, jan, 1, "equal")
+	assert.Equal(t, map[int]float64{2: 1}, weights)
+
+	weights = selectTopESG(history, prices, feb, 2, "esg")
+	require.Len(t, weights, 2)
+	assert.InDelta(t, 95.0/185, weights[3], 1e-9)
+	assert.InDelta(t, 90.0/185, weights[1], 1e-9)
+}
+
+func TestIsRebalanceDate(t *testing.T) {
+	mar29 := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	apr1 := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	apr2 := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, isRebalanceDate(RebalanceMonthly, mar29, apr1))
+	assert.True(t, isRebalanceDate(RebalanceQuarterly, mar29, apr1))
+	assert.True(t, isRebalanceDate(RebalanceWeekly, mar29, apr1))
+	assert.False(t, isRebalanceDate(RebalanceWeekly, apr1, apr2))
+	assert.False(t, isRebalanceDate(RebalanceMonthly, apr1, apr2))
+	assert.False(t, isRebalanceDate(RebalanceNone, mar29, apr1))
+}
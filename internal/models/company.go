@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
 )
 
 // Company represents a company in the system
@@ -20,42 +23,44 @@ type Company struct {
 
 // CompanyRepository handles database operations for companies
 type CompanyRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewCompanyRepository creates a new company repository
 func NewCompanyRepository(db *sql.DB) *CompanyRepository {
-	return &CompanyRepository{db: db}
+	return &CompanyRepository{db: tracing.WrapDB(db)}
 }
 
-// CreateCompany creates a new company
-func (r *CompanyRepository) CreateCompany(company *Company) error {
+// CreateCompany creates a new company and records the events describe returns
+func (r *CompanyRepository) CreateCompany(ctx context.Context, company *Company, describe EventsFunc) error {
 	query := `
 		INSERT INTO companies (name, symbol, sector, industry, country, market_cap)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(
-		query,
-		company.Name,
-		company.Symbol,
-		company.Sector,
-		company.Industry,
-		company.Country,
-		company.MarketCap,
-	).Scan(&company.ID, &company.CreatedAt, &company.UpdatedAt)
+	return writeWithEvents(ctx, r.db, func(tx *tracing.Tx) error {
+		return tx.QueryRowContext(ctx,
+			query,
+			company.Name,
+			company.Symbol,
+			company.Sector,
+			company.Industry,
+			company.Country,
+			company.MarketCap,
+		).Scan(&company.ID, &company.CreatedAt, &company.UpdatedAt)
+	}, describe)
 }
 
 // GetCompanyByID retrieves a company by ID
-func (r *CompanyRepository) GetCompanyByID(id int) (*Company, error) {
+func (r *CompanyRepository) GetCompanyByID(ctx context.Context, id int) (*Company, error) {
 	company := &Company{}
 	query := `
 		SELECT id, name, symbol, sector, industry, country, market_cap, created_at, updated_at
 		FROM companies WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&company.ID,
 		&company.Name,
 		&company.Symbol,
@@ -75,14 +80,14 @@ func (r *CompanyRepository) GetCompanyByID(id int) (*Company, error) {
 }
 
 // GetCompanyBySymbol retrieves a company by symbol
-func (r *CompanyRepository) GetCompanyBySymbol(symbol string) (*Company, error) {
+func (r *CompanyRepository) GetCompanyBySymbol(ctx context.Context, symbol string) (*Company, error) {
 	company := &Company{}
 	query := `
 		SELECT id, name, symbol, sector, industry, country, market_cap, created_at, updated_at
 		FROM companies WHERE symbol = $1
 	`
 
-	err := r.db.QueryRow(query, symbol).Scan(
+	err := r.db.QueryRowContext(ctx, query, symbol).Scan(
 		&company.ID,
 		&company.Name,
 		&company.Symbol,
@@ -102,7 +107,7 @@ func (r *CompanyRepository) GetCompanyBySymbol(symbol string) (*Company, error)
 }
 
 // UpdateCompany updates an existing company
-func (r *CompanyRepository) UpdateCompany(company *Company) error {
+func (r *CompanyRepository) UpdateCompany(ctx context.Context, company *Company) error {
 	query := `
 		UPDATE companies 
 		SET name = $1, sector = $2, industry = $3, country = $4, market_cap = $5, updated_at = CURRENT_TIMESTAMP
@@ -110,7 +115,7 @@ func (r *CompanyRepository) UpdateCompany(company *Company) error {
 		RETURNING updated_at
 	`
 
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(ctx,
 		query,
 		company.Name,
 		company.Sector,
@@ -121,15 +126,17 @@ func (r *CompanyRepository) UpdateCompany(company *Company) error {
 	).Scan(&company.UpdatedAt)
 }
 
-// DeleteCompany deletes a company by ID
-func (r *CompanyRepository) DeleteCompany(id int) error {
+// DeleteCompany deletes a company by ID and records the events describe returns
+func (r *CompanyRepository) DeleteCompany(ctx context.Context, id int, describe EventsFunc) error {
 	query := `DELETE FROM companies WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	return writeWithEvents(ctx, r.db, func(tx *tracing.Tx) error {
+		_, err := tx.ExecContext(ctx, query, id)
+		return err
+	}, describe)
 }
 
 // ListCompanies retrieves all companies with pagination and optional filtering
-func (r *CompanyRepository) ListCompanies(limit, offset int, sector string) ([]*Company, error) {
+func (r *CompanyRepository) ListCompanies(ctx context.Context, limit, offset int, sector string) ([]*Company, error) {
 	var query string
 	var args []interface{}
 
@@ -152,7 +159,7 @@ func (r *CompanyRepository) ListCompanies(limit, offset int, sector string) ([]*
 		args = []interface{}{limit, offset}
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -182,10 +189,10 @@ func (r *CompanyRepository) ListCompanies(limit, offset int, sector string) ([]*
 }
 
 // GetSectors retrieves all unique sectors
-func (r *CompanyRepository) GetSectors() ([]string, error) {
+func (r *CompanyRepository) GetSectors(ctx context.Context) ([]string, error) {
 	query := `SELECT DISTINCT sector FROM companies WHERE sector IS NOT NULL ORDER BY sector`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
 )
 
 // ESGScore represents an ESG score for a company
@@ -25,36 +28,38 @@ type ESGScore struct {
 
 // ESGScoreRepository handles database operations for ESG scores
 type ESGScoreRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewESGScoreRepository creates a new ESG score repository
 func NewESGScoreRepository(db *sql.DB) *ESGScoreRepository {
-	return &ESGScoreRepository{db: db}
+	return &ESGScoreRepository{db: tracing.WrapDB(db)}
 }
 
-// CreateESGScore creates a new ESG score
-func (r *ESGScoreRepository) CreateESGScore(score *ESGScore) error {
+// CreateESGScore creates a new ESG score and records the events describe returns
+func (r *ESGScoreRepository) CreateESGScore(ctx context.Context, score *ESGScore, describe EventsFunc) error {
 	query := `
 		INSERT INTO esg_scores (company_id, environmental_score, social_score, governance_score, overall_score, date, data_source)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(
-		query,
-		score.CompanyID,
-		score.EnvironmentalScore,
-		score.SocialScore,
-		score.GovernanceScore,
-		score.OverallScore,
-		score.ScoreDate,
-		score.DataSource,
-	).Scan(&score.ID, &score.CreatedAt, &score.UpdatedAt)
+	return writeWithEvents(ctx, r.db, func(tx *tracing.Tx) error {
+		return tx.QueryRowContext(ctx,
+			query,
+			score.CompanyID,
+			score.EnvironmentalScore,
+			score.SocialScore,
+			score.GovernanceScore,
+			score.OverallScore,
+			score.ScoreDate,
+			score.DataSource,
+		).Scan(&score.ID, &score.CreatedAt, &score.UpdatedAt)
+	}, describe)
 }
 
 // GetESGScoreByID retrieves an ESG score by ID
-func (r *ESGScoreRepository) GetESGScoreByID(id int) (*ESGScore, error) {
+func (r *ESGScoreRepository) GetESGScoreByID(ctx context.Context, id int) (*ESGScore, error) {
 	score := &ESGScore{}
 	query := `
 		SELECT es.id, es.company_id, es.environmental_score, es.social_score, es.governance_score, 
@@ -65,7 +70,7 @@ func (r *ESGScoreRepository) GetESGScoreByID(id int) (*ESGScore, error) {
 		WHERE es.id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&score.ID,
 		&score.CompanyID,
 		&score.EnvironmentalScore,
@@ -88,7 +93,7 @@ func (r *ESGScoreRepository) GetESGScoreByID(id int) (*ESGScore, error) {
 }
 
 // GetLatestESGScoreByCompany retrieves the latest ESG score for a company
-func (r *ESGScoreRepository) GetLatestESGScoreByCompany(companyID int) (*ESGScore, error) {
+func (r *ESGScoreRepository) GetLatestESGScoreByCompany(ctx context.Context, companyID int) (*ESGScore, error) {
 	score := &ESGScore{}
 	query := `
 		SELECT es.id, es.company_id, es.environmental_score, es.social_score, es.governance_score, 
@@ -101,7 +106,7 @@ func (r *ESGScoreRepository) GetLatestESGScoreByCompany(companyID int) (*ESGScor
 		LIMIT 1
 	`
 
-	err := r.db.QueryRow(query, companyID).Scan(
+	err := r.db.QueryRowContext(ctx, query, companyID).Scan(
 		&score.ID,
 		&score.CompanyID,
 		&score.EnvironmentalScore,
@@ -124,7 +129,7 @@ func (r *ESGScoreRepository) GetLatestESGScoreByCompany(companyID int) (*ESGScor
 }
 
 // GetESGScoresByCompany retrieves all ESG scores for a company
-func (r *ESGScoreRepository) GetESGScoresByCompany(companyID int, limit, offset int) ([]*ESGScore, error) {
+func (r *ESGScoreRepository) GetESGScoresByCompany(ctx context.Context, companyID int, limit, offset int) ([]*ESGScore, error) {
 	query := `
 		SELECT es.id, es.company_id, es.environmental_score, es.social_score, es.governance_score, 
 		       es.overall_score, es.score_date, es.data_source, es.created_at, es.updated_at,
@@ -136,7 +141,7 @@ func (r *ESGScoreRepository) GetESGScoresByCompany(companyID int, limit, offset
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(query, companyID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, companyID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +174,7 @@ func (r *ESGScoreRepository) GetESGScoresByCompany(companyID int, limit, offset
 }
 
 // ListESGScores retrieves all ESG scores with pagination and optional filtering
-func (r *ESGScoreRepository) ListESGScores(limit, offset int, minScore float64) ([]*ESGScore, error) {
+func (r *ESGScoreRepository) ListESGScores(ctx context.Context, limit, offset int, minScore float64) ([]*ESGScore, error) {
 	var query string
 	var args []interface{}
 
@@ -198,7 +203,7 @@ func (r *ESGScoreRepository) ListESGScores(limit, offset int, minScore float64)
 		args = []interface{}{limit, offset}
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -230,8 +235,8 @@ func (r *ESGScoreRepository) ListESGScores(limit, offset int, minScore float64)
 	return scores, nil
 }
 
-// UpdateESGScore updates an existing ESG score
-func (r *ESGScoreRepository) UpdateESGScore(score *ESGScore) error {
+// UpdateESGScore updates an existing ESG score and records the events describe returns
+func (r *ESGScoreRepository) UpdateESGScore(ctx context.Context, score *ESGScore, describe EventsFunc) error {
 	query := `
 		UPDATE esg_scores 
 		SET environmental_score = $1, social_score = $2, governance_score = $3, 
@@ -240,21 +245,25 @@ func (r *ESGScoreRepository) UpdateESGScore(score *ESGScore) error {
 		RETURNING updated_at
 	`
 
-	return r.db.QueryRow(
-		query,
-		score.EnvironmentalScore,
-		score.SocialScore,
-		score.GovernanceScore,
-		score.OverallScore,
-		score.ScoreDate,
-		score.DataSource,
-		score.ID,
-	).Scan(&score.UpdatedAt)
+	return writeWithEvents(ctx, r.db, func(tx *tracing.Tx) error {
+		return tx.QueryRowContext(ctx,
+			query,
+			score.EnvironmentalScore,
+			score.SocialScore,
+			score.GovernanceScore,
+			score.OverallScore,
+			score.ScoreDate,
+			score.DataSource,
+			score.ID,
+		).Scan(&score.UpdatedAt)
+	}, describe)
 }
 
-// DeleteESGScore deletes an ESG score by ID
-func (r *ESGScoreRepository) DeleteESGScore(id int) error {
+// DeleteESGScore deletes an ESG score by ID and records the events describe returns
+func (r *ESGScoreRepository) DeleteESGScore(ctx context.Context, id int, describe EventsFunc) error {
 	query := `DELETE FROM esg_scores WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	return writeWithEvents(ctx, r.db, func(tx *tracing.Tx) error {
+		_, err := tx.ExecContext(ctx, query, id)
+		return err
+	}, describe)
 }
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
 )
 
 // StockPrice represents a daily stock price record
@@ -51,16 +54,16 @@ type MarketData struct {
 
 // StockPriceRepository handles database operations for stock prices
 type StockPriceRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewStockPriceRepository creates a new stock price repository
 func NewStockPriceRepository(db *sql.DB) *StockPriceRepository {
-	return &StockPriceRepository{db: db}
+	return &StockPriceRepository{db: tracing.WrapDB(db)}
 }
 
 // GetByCompanyID retrieves stock prices for a specific company
-func (r *StockPriceRepository) GetByCompanyID(companyID int, limit int) ([]StockPrice, error) {
+func (r *StockPriceRepository) GetByCompanyID(ctx context.Context, companyID int, limit int) ([]StockPrice, error) {
 	query := `
 		SELECT id, company_id, date, open_price, high_price, low_price, close_price, volume, adjusted_close, created_at, updated_at
 		FROM stock_prices 
@@ -69,7 +72,7 @@ func (r *StockPriceRepository) GetByCompanyID(companyID int, limit int) ([]Stock
 		LIMIT $2
 	`
 
-	rows, err := r.db.Query(query, companyID, limit)
+	rows, err := r.db.QueryContext(ctx, query, companyID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +96,7 @@ func (r *StockPriceRepository) GetByCompanyID(companyID int, limit int) ([]Stock
 }
 
 // GetLatestByCompanyID gets the most recent stock price for a company
-func (r *StockPriceRepository) GetLatestByCompanyID(companyID int) (*StockPrice, error) {
+func (r *StockPriceRepository) GetLatestByCompanyID(ctx context.Context, companyID int) (*StockPrice, error) {
 	query := `
 		SELECT id, company_id, date, open_price, high_price, low_price, close_price, volume, adjusted_close, created_at, updated_at
 		FROM stock_prices 
@@ -103,7 +106,7 @@ func (r *StockPriceRepository) GetLatestByCompanyID(companyID int) (*StockPrice,
 	`
 
 	var price StockPrice
-	err := r.db.QueryRow(query, companyID).Scan(
+	err := r.db.QueryRowContext(ctx, query, companyID).Scan(
 		&price.ID, &price.CompanyID, &price.Date, &price.OpenPrice, &price.HighPrice,
 		&price.LowPrice, &price.ClosePrice, &price.Volume, &price.AdjustedClose,
 		&price.CreatedAt, &price.UpdatedAt,
@@ -117,16 +120,16 @@ func (r *StockPriceRepository) GetLatestByCompanyID(companyID int) (*StockPrice,
 
 // FinancialIndicatorRepository handles database operations for financial indicators
 type FinancialIndicatorRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewFinancialIndicatorRepository creates a new financial indicator repository
 func NewFinancialIndicatorRepository(db *sql.DB) *FinancialIndicatorRepository {
-	return &FinancialIndicatorRepository{db: db}
+	return &FinancialIndicatorRepository{db: tracing.WrapDB(db)}
 }
 
 // GetByCompanyID retrieves financial indicators for a specific company
-func (r *FinancialIndicatorRepository) GetByCompanyID(companyID int) (*FinancialIndicator, error) {
+func (r *FinancialIndicatorRepository) GetByCompanyID(ctx context.Context, companyID int) (*FinancialIndicator, error) {
 	query := `
 		SELECT id, company_id, date, market_cap, pe_ratio, pb_ratio, debt_to_equity, 
 		       return_on_equity, profit_margin, revenue_growth, created_at, updated_at
@@ -137,7 +140,7 @@ func (r *FinancialIndicatorRepository) GetByCompanyID(companyID int) (*Financial
 	`
 
 	var indicator FinancialIndicator
-	err := r.db.QueryRow(query, companyID).Scan(
+	err := r.db.QueryRowContext(ctx, query, companyID).Scan(
 		&indicator.ID, &indicator.CompanyID, &indicator.Date, &indicator.MarketCap,
 		&indicator.PERatio, &indicator.PBRatio, &indicator.DebtToEquity,
 		&indicator.ReturnOnEquity, &indicator.ProfitMargin, &indicator.RevenueGrowth,
@@ -152,16 +155,16 @@ func (r *FinancialIndicatorRepository) GetByCompanyID(companyID int) (*Financial
 
 // MarketDataRepository handles database operations for market data
 type MarketDataRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewMarketDataRepository creates a new market data repository
 func NewMarketDataRepository(db *sql.DB) *MarketDataRepository {
-	return &MarketDataRepository{db: db}
+	return &MarketDataRepository{db: tracing.WrapDB(db)}
 }
 
 // GetLatest retrieves the most recent market data
-func (r *MarketDataRepository) GetLatest() (*MarketData, error) {
+func (r *MarketDataRepository) GetLatest(ctx context.Context) (*MarketData, error) {
 	query := `
 		SELECT id, date, sp500_close, nasdaq_close, dow_close, vix_close, treasury_10y, created_at, updated_at
 		FROM market_data 
@@ -170,7 +173,7 @@ func (r *MarketDataRepository) GetLatest() (*MarketData, error) {
 	`
 
 	var data MarketData
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRowContext(ctx, query).Scan(
 		&data.ID, &data.Date, &data.SP500Close, &data.NasdaqClose, &data.DowClose,
 		&data.VIXClose, &data.Treasury10Y, &data.CreatedAt, &data.UpdatedAt,
 	)
@@ -182,7 +185,7 @@ func (r *MarketDataRepository) GetLatest() (*MarketData, error) {
 }
 
 // GetByDateRange retrieves market data for a date range
-func (r *MarketDataRepository) GetByDateRange(startDate, endDate time.Time, limit int) ([]MarketData, error) {
+func (r *MarketDataRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time, limit int) ([]MarketData, error) {
 	query := `
 		SELECT id, date, sp500_close, nasdaq_close, dow_close, vix_close, treasury_10y, created_at, updated_at
 		FROM market_data 
@@ -191,7 +194,7 @@ func (r *MarketDataRepository) GetByDateRange(startDate, endDate time.Time, limi
 		LIMIT $3
 	`
 
-	rows, err := r.db.Query(query, startDate, endDate, limit)
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, limit)
 	if err != nil {
 		return nil, err
 	}
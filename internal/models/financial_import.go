@@ -0,0 +1,354 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
+)
+
+// maxReportedImportErrors caps the number of row errors returned in a report
+const maxReportedImportErrors = 500
+
+// ImportRowError describes why a single uploaded row was rejected
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarizes the outcome of a bulk import
+type ImportReport struct {
+	Dataset         string           `json:"dataset"`
+	Format          string           `json:"format"`
+	TotalRows       int              `json:"total_rows"`
+	Upserted        int              `json:"upserted"`
+	Rejected        int              `json:"rejected"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated"`
+}
+
+// NewImportReport creates an empty report for a dataset
+func NewImportReport(dataset, format string) *ImportReport {
+	return &ImportReport{
+		Dataset: dataset,
+		Format:  format,
+		Errors:  []ImportRowError{},
+	}
+}
+
+// Reject records a rejected row, keeping at most maxReportedImportErrors details
+func (r *ImportReport) Reject(rowErr ImportRowError) {
+	r.Rejected++
+	if len(r.Errors) >= maxReportedImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// StockPriceImportRow is a validated stock price row ready to be upserted
+type StockPriceImportRow struct {
+	Seq           int
+	CompanyID     int
+	Date          time.Time
+	OpenPrice     float64
+	HighPrice     float64
+	LowPrice      float64
+	ClosePrice    float64
+	Volume        int64
+	AdjustedClose float64
+}
+
+// FinancialIndicatorImportRow is a validated financial indicator row ready to be upserted
+type FinancialIndicatorImportRow struct {
+	Seq            int
+	CompanyID      int
+	Date           time.Time
+	MarketCap      *float64
+	PERatio        *float64
+	PBRatio        *float64
+	DebtToEquity   *float64
+	ReturnOnEquity *float64
+	ProfitMargin   *float64
+	RevenueGrowth  *float64
+}
+
+// MarketDataImportRow is a validated market data row ready to be upserted
+type MarketDataImportRow struct {
+	Seq         int
+	Date        time.Time
+	SP500Close  *float64
+	NasdaqClose *float64
+	DowClose    *float64
+	VIXClose    *float64
+	Treasury10Y *float64
+}
+
+// FinancialImportRepository handles bulk writes of financial data
+type FinancialImportRepository struct {
+	db *tracing.DB
+}
+
+// NewFinancialImportRepository creates a new financial import repository
+func NewFinancialImportRepository(db *sql.DB) *FinancialImportRepository {
+	return &FinancialImportRepository{db: tracing.WrapDB(db)}
+}
+
+// ResolveCompanyIDs maps company symbols to IDs, omitting unknown symbols
+func (r *FinancialImportRepository) ResolveCompanyIDs(ctx context.Context, symbols []string) (map[string]int, error) {
+	ids := make(map[string]int, len(symbols))
+	if len(symbols) == 0 {
+		return ids, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, symbol FROM companies WHERE symbol = ANY($1)`, pq.Array(symbols))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		ids[symbol] = id
+	}
+
+	return ids, rows.Err()
+}
+
+// UpsertStockPrices copies a batch into a staging table and upserts it on (company_id, date)
+func (r *FinancialImportRepository) UpsertStockPrices(ctx context.Context, batch []StockPriceImportRow) (int64, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	return r.copyAndUpsert(ctx,
+		`CREATE TEMP TABLE stock_prices_import (
+			seq INTEGER, company_id INTEGER, date DATE,
+			open_price DECIMAL(10,2), high_price DECIMAL(10,2), low_price DECIMAL(10,2),
+			close_price DECIMAL(10,2), volume BIGINT, adjusted_close DECIMAL(10,2)
+		) ON COMMIT DROP`,
+		pq.CopyIn("stock_prices_import", "seq", "company_id", "date", "open_price", "high_price",
+			"low_price", "close_price", "volume", "adjusted_close"),
+		len(batch),
+		func(i int) []interface{} {
+			row := batch[i]
+			return []interface{}{row.Seq, row.CompanyID, row.Date, row.OpenPrice, row.HighPrice,
+				row.LowPrice, row.ClosePrice, row.Volume, row.AdjustedClose}
+		},
+		`INSERT INTO stock_prices (company_id, date, open_price, high_price, low_price, close_price, volume, adjusted_close)
+		SELECT DISTINCT ON (company_id, date)
+			company_id, date, open_price, high_price, low_price, close_price, volume, adjusted_close
+		FROM stock_prices_import
+		ORDER BY company_id, date, seq DESC
+		ON CONFLICT (company_id, date) DO UPDATE SET
+			open_price = EXCLUDED.open_price,
+			high_price = EXCLUDED.high_price,
+			low_price = EXCLUDED.low_price,
+			close_price = EXCLUDED.close_price,
+			volume = EXCLUDED.volume,
+			adjusted_close = EXCLUDED.adjusted_close,
+			updated_at = CURRENT_TIMESTAMP`,
+	)
+}
+
+// UpsertFinancialIndicators copies a batch into a staging table and upserts it on
+// (company_id, date). Columns left blank keep the values already stored.
+func (r *FinancialImportRepository) UpsertFinancialIndicators(ctx context.Context, batch []FinancialIndicatorImportRow) (int64, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	return r.copyAndUpsert(ctx,
+		`CREATE TEMP TABLE financial_indicators_import (
+			seq INTEGER, company_id INTEGER, date DATE,
+			market_cap DECIMAL(20,2), pe_ratio DECIMAL(10,4), pb_ratio DECIMAL(10,4),
+			debt_to_equity DECIMAL(10,4), return_on_equity DECIMAL(10,4),
+			profit_margin DECIMAL(10,4), revenue_growth DECIMAL(10,4)
+		) ON COMMIT DROP`,
+		pq.CopyIn("financial_indicators_import", "seq", "company_id", "date", "market_cap", "pe_ratio",
+			"pb_ratio", "debt_to_equity", "return_on_equity", "profit_margin", "revenue_growth"),
+		len(batch),
+		func(i int) []interface{} {
+			row := batch[i]
+			return []interface{}{row.Seq, row.CompanyID, row.Date, row.MarketCap, row.PERatio,
+				row.PBRatio, row.DebtToEquity, row.ReturnOnEquity, row.ProfitMargin, row.RevenueGrowth}
+		},
+		`INSERT INTO financial_indicators (company_id, date, market_cap, pe_ratio, pb_ratio,
+			debt_to_equity, return_on_equity, profit_margin, revenue_growth)
+		SELECT DISTINCT ON (company_id, date)
+			company_id, date, market_cap, pe_ratio, pb_ratio,
+			debt_to_equity, return_on_equity, profit_margin, revenue_growth
+		FROM financial_indicators_import
+		ORDER BY company_id, date, seq DESC
+		ON CONFLICT (company_id, date) DO UPDATE SET
+			market_cap = COALESCE(EXCLUDED.market_cap, financial_indicators.market_cap),
+			pe_ratio = COALESCE(EXCLUDED.pe_ratio, financial_indicators.pe_ratio),
+			pb_ratio = COALESCE(EXCLUDED.pb_ratio, financial_indicators.pb_ratio),
+			debt_to_equity = COALESCE(EXCLUDED.debt_to_equity, financial_indicators.debt_to_equity),
+			return_on_equity = COALESCE(EXCLUDED.return_on_equity, financial_indicators.return_on_equity),
+			profit_margin = COALESCE(EXCLUDED.profit_margin, financial_indicators.profit_margin),
+			revenue_growth = COALESCE(EXCLUDED.revenue_growth, financial_indicators.revenue_growth),
+			updated_at = CURRENT_TIMESTAMP`,
+	)
+}
+
+// UpsertMarketData copies a batch into a staging table and upserts it on date.
+// Columns left blank keep the values already stored.
+func (r *FinancialImportRepository) UpsertMarketData(ctx context.Context, batch []MarketDataImportRow) (int64, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	return r.copyAndUpsert(ctx,
+		`CREATE TEMP TABLE market_data_import (
+			seq INTEGER, date DATE,
+			sp500_close DECIMAL(10,2), nasdaq_close DECIMAL(10,2), dow_close DECIMAL(10,2),
+			vix_close DECIMAL(10,4), treasury_10y DECIMAL(10,4)
+		) ON COMMIT DROP`,
+		pq.CopyIn("market_data_import", "seq", "date", "sp500_close", "nasdaq_close",
+			"dow_close", "vix_close", "treasury_10y"),
+		len(batch),
+		func(i int) []interface{} {
+			row := batch[i]
+			return []interface{}{row.Seq, row.Date, row.SP500Close, row.NasdaqClose,
+				row.DowClose, row.VIXClose, row.Treasury10Y}
+		},
+		`INSERT INTO market_data (date, sp500_close, nasdaq_close, dow_close, vix_close, treasury_10y)
+		SELECT DISTINCT ON (date)
+			date, sp500_close, nasdaq_close, dow_close, vix_close, treasury_10y
+		FROM market_data_import
+		ORDER BY date, seq DESC
+		ON CONFLICT (date) DO UPDATE SET
+			sp500_close = COALESCE(EXCLUDED.sp500_close, market_data.sp500_close),
+			nasdaq_close = COALESCE(EXCLUDED.nasdaq_close, market_data.nasdaq_close),
+			dow_close = COALESCE(EXCLUDED.dow_close, market_data.dow_close),
+			vix_close = COALESCE(EXCLUDED.vix_close, market_data.vix_close),
+			treasury_10y = COALESCE(EXCLUDED.treasury_10y, market_data.treasury_10y),
+			updated_at = CURRENT_TIMESTAMP`,
+	)
+}
+
+// copyAndUpsert streams a batch through COPY into a transaction-scoped staging
+// table and merges it into the target table with a single statement. Duplicate
+// keys within a batch are collapsed so the last uploaded row wins.
+func (r *FinancialImportRepository) copyAndUpsert(ctx context.Context, createStaging, copyStmt string, n int, rowValues func(int) []interface{}, merge string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, createStaging); err != nil {
+		return 0, fmt.Errorf("create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, copyStmt)
+	if err != nil {
+		return 0, fmt.Errorf("prepare copy: %w", err)
+	}
+
+	for i := 0; i < n; i++ {
+		if _, err := stmt.ExecContext(ctx, rowValues(i)...); err != nil {
+			stmt.Close()
+			return 0, fmt.Errorf("copy row: %w", err)
+		}
+	}
+
+	// Flush buffered COPY data
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return 0, fmt.Errorf("flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, merge)
+	if err != nil {
+		return 0, fmt.Errorf("merge staging rows: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// LatestPrice is a company's most recent stored price with the close before it
+type LatestPrice struct {
+	StockPrice
+	CompanySymbol string
+	Sector        string
+	PreviousClose *float64
+}
+
+// LatestPrices returns the most recent stored price of each company, skipping
+// companies without prices
+func (r *FinancialImportRepository) LatestPrices(ctx context.Context, companyIDs []int) ([]*LatestPrice, error) {
+	if len(companyIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.symbol, COALESCE(c.sector, ''),
+			p.date, p.open_price, p.high_price, p.low_price, p.close_price, p.volume, p.adjusted_close,
+			prev.close_price
+		FROM companies c
+		JOIN LATERAL (
+			SELECT date, open_price, high_price, low_price, close_price, volume, adjusted_close
+			FROM stock_prices WHERE company_id = c.id
+			ORDER BY date DESC LIMIT 1
+		) p ON true
+		LEFT JOIN LATERAL (
+			SELECT close_price FROM stock_prices
+			WHERE company_id = c.id AND date < p.date
+			ORDER BY date DESC LIMIT 1
+		) prev ON true
+		WHERE c.id = ANY($1)
+		ORDER BY c.id
+	`, pq.Array(companyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*LatestPrice
+	for rows.Next() {
+		price := &LatestPrice{}
+		var previous sql.NullFloat64
+		if err := rows.Scan(
+			&price.CompanyID,
+			&price.CompanySymbol,
+			&price.Sector,
+			&price.Date,
+			&price.OpenPrice,
+			&price.HighPrice,
+			&price.LowPrice,
+			&price.ClosePrice,
+			&price.Volume,
+			&price.AdjustedClose,
+			&previous,
+		); err != nil {
+			return nil, err
+		}
+		price.PreviousClose = nullFloatPtr(previous)
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
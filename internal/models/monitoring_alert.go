@@ -0,0 +1,259 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"ethosview-backend/pkg/monitoring"
+	"ethosview-backend/pkg/tracing"
+)
+
+// MonitoringAlertRepository stores monitoring rules, alerts and alert
+// history; it implements monitoring.Store
+type MonitoringAlertRepository struct {
+	db *tracing.DB
+}
+
+// NewMonitoringAlertRepository creates a new monitoring alert repository
+func NewMonitoringAlertRepository(db *sql.DB) *MonitoringAlertRepository {
+	return &MonitoringAlertRepository{db: tracing.WrapDB(db)}
+}
+
+const monitoringAlertColumns = `id, rule, severity, status, message, value, threshold, raised_at,
+	acknowledged_at, acknowledged_by, silenced_until, resolved_at, resolved_by`
+
+// LoadRules retrieves the enabled monitoring rules
+func (r *MonitoringAlertRepository) LoadRules(ctx context.Context) ([]monitoring.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT name, metric, operator, tiers, for_intervals, summary
+		FROM monitoring_rules
+		WHERE enabled
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []monitoring.Rule
+	for rows.Next() {
+		var rule monitoring.Rule
+		var tiers []byte
+		if err := rows.Scan(&rule.Name, &rule.Metric, &rule.Operator, &tiers, &rule.For, &rule.Summary); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tiers, &rule.Tiers); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// SaveTransition upserts an alert's current state and appends the transition
+// to its history in one transaction
+func (r *MonitoringAlertRepository) SaveTransition(ctx context.Context, alert monitoring.Alert, transition monitoring.Transition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var resolvedAt *time.Time
+	if alert.Resolved {
+		resolvedAt = &alert.ResolvedAt
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO monitoring_alerts (`+monitoringAlertColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			severity = EXCLUDED.severity,
+			status = EXCLUDED.status,
+			message = EXCLUDED.message,
+			value = EXCLUDED.value,
+			threshold = EXCLUDED.threshold,
+			acknowledged_at = EXCLUDED.acknowledged_at,
+			acknowledged_by = EXCLUDED.acknowledged_by,
+			silenced_until = EXCLUDED.silenced_until,
+			resolved_at = EXCLUDED.resolved_at,
+			resolved_by = EXCLUDED.resolved_by
+	`,
+		alert.ID,
+		string(alert.Type),
+		string(alert.Severity),
+		string(alert.Status),
+		alert.Message,
+		alert.Value,
+		alert.Threshold,
+		alert.Timestamp,
+		alert.AcknowledgedAt,
+		alert.AcknowledgedBy,
+		alert.SilencedUntil,
+		resolvedAt,
+		alert.ResolvedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO monitoring_alert_transitions (alert_id, action, status, severity, value, user_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		alert.ID,
+		transition.Action,
+		string(transition.Status),
+		string(transition.Severity),
+		transition.Value,
+		transition.UserID,
+		transition.Note,
+		transition.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// OpenAlerts retrieves the alerts that are not resolved
+func (r *MonitoringAlertRepository) OpenAlerts(ctx context.Context) ([]monitoring.Alert, error) {
+	return r.queryAlerts(ctx, `SELECT `+monitoringAlertColumns+` FROM monitoring_alerts WHERE status <> 'resolved' ORDER BY raised_at`)
+}
+
+// ListAlerts retrieves alerts newest first, optionally with one status
+func (r *MonitoringAlertRepository) ListAlerts(ctx context.Context, status string, limit, offset int) ([]monitoring.Alert, error) {
+	return r.queryAlerts(ctx, `
+		SELECT `+monitoringAlertColumns+`
+		FROM monitoring_alerts
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY raised_at DESC, id
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+}
+
+// Silences retrieves the latest unexpired silence for each rule
+func (r *MonitoringAlertRepository) Silences(ctx context.Context, now time.Time) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rule, MAX(silenced_until)
+		FROM monitoring_alerts
+		WHERE silenced_until > $1
+		GROUP BY rule
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	silences := make(map[string]time.Time)
+	for rows.Next() {
+		var rule string
+		var until time.Time
+		if err := rows.Scan(&rule, &until); err != nil {
+			return nil, err
+		}
+		silences[rule] = until
+	}
+
+	return silences, rows.Err()
+}
+
+// Transitions retrieves an alert's history, oldest first. It returns
+// sql.ErrNoRows if the alert does not exist.
+func (r *MonitoringAlertRepository) Transitions(ctx context.Context, alertID string) ([]monitoring.Transition, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM monitoring_alerts WHERE id = $1)`, alertID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, alert_id, action, status, severity, value, user_id, note, created_at
+		FROM monitoring_alert_transitions
+		WHERE alert_id = $1
+		ORDER BY created_at, id
+	`, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []monitoring.Transition{}
+	for rows.Next() {
+		var t monitoring.Transition
+		var userID sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.AlertID, &t.Action, &t.Status, &t.Severity, &t.Value, &userID, &t.Note, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			t.UserID = &id
+		}
+		transitions = append(transitions, t)
+	}
+
+	return transitions, rows.Err()
+}
+
+func (r *MonitoringAlertRepository) queryAlerts(ctx context.Context, query string, args ...interface{}) ([]monitoring.Alert, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []monitoring.Alert{}
+	for rows.Next() {
+		alert, err := scanMonitoringAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+func scanMonitoringAlert(row interface{ Scan(...interface{}) error }) (monitoring.Alert, error) {
+	var alert monitoring.Alert
+	var acknowledgedBy, resolvedBy sql.NullInt64
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&alert.ID,
+		&alert.Type,
+		&alert.Severity,
+		&alert.Status,
+		&alert.Message,
+		&alert.Value,
+		&alert.Threshold,
+		&alert.Timestamp,
+		&alert.AcknowledgedAt,
+		&acknowledgedBy,
+		&alert.SilencedUntil,
+		&resolvedAt,
+		&resolvedBy,
+	)
+	if err != nil {
+		return alert, err
+	}
+
+	if acknowledgedBy.Valid {
+		id := int(acknowledgedBy.Int64)
+		alert.AcknowledgedBy = &id
+	}
+	if resolvedBy.Valid {
+		id := int(resolvedBy.Int64)
+		alert.ResolvedBy = &id
+	}
+	if resolvedAt.Valid {
+		alert.Resolved = true
+		alert.ResolvedAt = resolvedAt.Time
+	}
+	return alert, nil
+}
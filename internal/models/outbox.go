@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/pkg/tracing"
+)
+
+// EventsFunc describes a write as domain events once its statements have run,
+// so the events can carry the IDs the write generated. They are recorded in
+// the event outbox in the write's transaction, so an event is stored if and
+// only if the write commits.
+type EventsFunc func() []events.Event
+
+// writeWithEvents runs write in a transaction and records the events describe
+// returns in the outbox before committing. describe may be nil.
+func writeWithEvents(ctx context.Context, db *tracing.DB, write func(tx *tracing.Tx) error, describe EventsFunc) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := write(tx); err != nil {
+		return err
+	}
+
+	if describe != nil {
+		for _, event := range describe() {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("marshal %s event: %w", event.Type, err)
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO event_outbox (event_id, event_type, payload) VALUES ($1, $2, $3)
+			`, event.ID, event.Type, payload); err != nil {
+				return fmt.Errorf("record %s event: %w", event.Type, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
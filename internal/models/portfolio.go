@@ -0,0 +1,530 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
+)
+
+// Holding bases for saved portfolios
+const (
+	PortfolioBasisQuantity = "quantity"
+	PortfolioBasisWeight   = "weight"
+)
+
+// ErrUnknownSymbol is returned when a holding or watchlist item references a symbol that does not exist
+var ErrUnknownSymbol = errors.New("unknown company symbol")
+
+// Portfolio represents a portfolio saved by a user
+type Portfolio struct {
+	ID          int                 `json:"id"`
+	UserID      int                 `json:"user_id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Basis       string              `json:"basis"`
+	Source      string              `json:"source"`
+	Holdings    []*PortfolioHolding `json:"holdings"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// PortfolioHolding represents a single position in a saved portfolio
+type PortfolioHolding struct {
+	ID          int       `json:"id"`
+	PortfolioID int       `json:"portfolio_id"`
+	CompanyID   int       `json:"company_id"`
+	Symbol      string    `json:"symbol"`
+	CompanyName string    `json:"company_name"`
+	Quantity    *float64  `json:"quantity,omitempty"`
+	Weight      *float64  `json:"weight,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HoldingInput identifies a company by symbol with its quantity or weight
+type HoldingInput struct {
+	Symbol   string
+	Quantity *float64
+	Weight   *float64
+}
+
+// PortfolioValuation values a saved portfolio at the latest close and ESG score of each holding
+type PortfolioValuation struct {
+	PortfolioID      int              `json:"portfolio_id"`
+	Name             string           `json:"name"`
+	Basis            string           `json:"basis"`
+	TotalValue       *float64         `json:"total_value,omitempty"`
+	WeightedESGScore *float64         `json:"weighted_esg_score"`
+	ESGCoverage      float64          `json:"esg_coverage"`
+	Holdings         []*ValuedHolding `json:"holdings"`
+	ValuedAt         time.Time        `json:"valued_at"`
+}
+
+// ValuedHolding is a holding joined with its latest price and ESG score
+type ValuedHolding struct {
+	CompanyID    int        `json:"company_id"`
+	Symbol       string     `json:"symbol"`
+	CompanyName  string     `json:"company_name"`
+	Sector       string     `json:"sector"`
+	Quantity     *float64   `json:"quantity,omitempty"`
+	Weight       float64    `json:"weight"`
+	LatestClose  *float64   `json:"latest_close"`
+	PriceDate    *time.Time `json:"price_date"`
+	MarketValue  *float64   `json:"market_value,omitempty"`
+	ESGScore     *float64   `json:"esg_score"`
+	ESGScoreDate *time.Time `json:"esg_score_date"`
+
+	targetWeight float64
+}
+
+// PortfolioRepository handles database operations for saved portfolios
+type PortfolioRepository struct {
+	db *tracing.DB
+}
+
+// NewPortfolioRepository creates a new portfolio repository
+func NewPortfolioRepository(db *sql.DB) *PortfolioRepository {
+	return &PortfolioRepository{db: tracing.WrapDB(db)}
+}
+
+// CreatePortfolio creates a portfolio and its holdings in one transaction
+func (r *PortfolioRepository) CreatePortfolio(ctx context.Context, portfolio *Portfolio, holdings []HoldingInput) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO portfolios (user_id, name, description, basis, source)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, portfolio.UserID, portfolio.Name, portfolio.Description, portfolio.Basis, portfolio.Source).
+		Scan(&portfolio.ID, &portfolio.CreatedAt, &portfolio.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := upsertHoldings(ctx, tx, portfolio.ID, holdings); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	portfolio.Holdings, err = r.getHoldings(ctx, portfolio.ID)
+	return err
+}
+
+// ListPortfolios retrieves all portfolios owned by a user, without holdings
+func (r *PortfolioRepository) ListPortfolios(ctx context.Context, userID int) ([]*Portfolio, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, COALESCE(description, ''), basis, source, created_at, updated_at
+		FROM portfolios
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	portfolios := []*Portfolio{}
+	for rows.Next() {
+		portfolio := &Portfolio{}
+		if err := rows.Scan(
+			&portfolio.ID,
+			&portfolio.UserID,
+			&portfolio.Name,
+			&portfolio.Description,
+			&portfolio.Basis,
+			&portfolio.Source,
+			&portfolio.CreatedAt,
+			&portfolio.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, portfolio)
+	}
+
+	return portfolios, rows.Err()
+}
+
+// GetPortfolio retrieves a portfolio with holdings, returning sql.ErrNoRows if the user does not own it
+func (r *PortfolioRepository) GetPortfolio(ctx context.Context, userID, id int) (*Portfolio, error) {
+	portfolio := &Portfolio{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, COALESCE(description, ''), basis, source, created_at, updated_at
+		FROM portfolios
+		WHERE id = $1 AND user_id = $2
+	`, id, userID).Scan(
+		&portfolio.ID,
+		&portfolio.UserID,
+		&portfolio.Name,
+		&portfolio.Description,
+		&portfolio.Basis,
+		&portfolio.Source,
+		&portfolio.CreatedAt,
+		&portfolio.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio.Holdings, err = r.getHoldings(ctx, portfolio.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolio, nil
+}
+
+// UpdatePortfolio updates a portfolio's name and description
+func (r *PortfolioRepository) UpdatePortfolio(ctx context.Context, portfolio *Portfolio) error {
+	return r.db.QueryRowContext(ctx, `
+		UPDATE portfolios
+		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND user_id = $4
+		RETURNING updated_at
+	`, portfolio.Name, portfolio.Description, portfolio.ID, portfolio.UserID).Scan(&portfolio.UpdatedAt)
+}
+
+// DeletePortfolio deletes a portfolio owned by a user
+func (r *PortfolioRepository) DeletePortfolio(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM portfolios WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ReplaceHoldings replaces every holding of a portfolio owned by a user
+func (r *PortfolioRepository) ReplaceHoldings(ctx context.Context, userID, id int, holdings []HoldingInput) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnedPortfolio(ctx, tx, userID, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM portfolio_holdings WHERE portfolio_id = $1`, id); err != nil {
+		return err
+	}
+
+	if err := upsertHoldings(ctx, tx, id, holdings); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE portfolios SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpsertHolding adds a company to a portfolio by symbol, or updates its quantity or weight
+func (r *PortfolioRepository) UpsertHolding(ctx context.Context, userID, id int, holding HoldingInput) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnedPortfolio(ctx, tx, userID, id); err != nil {
+		return err
+	}
+
+	if err := upsertHoldings(ctx, tx, id, []HoldingInput{holding}); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE portfolios SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveHolding removes a company from a portfolio owned by a user
+func (r *PortfolioRepository) RemoveHolding(ctx context.Context, userID, id int, symbol string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM portfolio_holdings h
+		USING portfolios p, companies c
+		WHERE h.portfolio_id = p.id AND h.company_id = c.id
+		AND p.id = $1 AND p.user_id = $2 AND c.symbol = $3
+	`, id, userID, symbol)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// GetValuation values a portfolio using each holding's latest close and latest ESG score
+func (r *PortfolioRepository) GetValuation(ctx context.Context, userID, id int) (*PortfolioValuation, error) {
+	portfolio := &Portfolio{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, basis FROM portfolios WHERE id = $1 AND user_id = $2
+	`, id, userID).Scan(&portfolio.ID, &portfolio.Name, &portfolio.Basis)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT h.company_id, c.symbol, c.name, COALESCE(c.sector, ''), h.quantity, h.weight,
+			sp.close_price, sp.date, es.overall_score, es.score_date
+		FROM portfolio_holdings h
+		JOIN companies c ON c.id = h.company_id
+		LEFT JOIN LATERAL (
+			SELECT close_price, date FROM stock_prices
+			WHERE company_id = h.company_id
+			ORDER BY date DESC LIMIT 1
+		) sp ON true
+		LEFT JOIN LATERAL (
+			SELECT overall_score, score_date FROM esg_scores
+			WHERE company_id = h.company_id AND overall_score IS NOT NULL
+			ORDER BY score_date DESC LIMIT 1
+		) es ON true
+		WHERE h.portfolio_id = $1
+		ORDER BY c.symbol ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holdings []*ValuedHolding
+	for rows.Next() {
+		holding := &ValuedHolding{}
+		var weight sql.NullFloat64
+		var quantity, close, esg sql.NullFloat64
+		var priceDate, scoreDate sql.NullTime
+		if err := rows.Scan(
+			&holding.CompanyID,
+			&holding.Symbol,
+			&holding.CompanyName,
+			&holding.Sector,
+			&quantity,
+			&weight,
+			&close,
+			&priceDate,
+			&esg,
+			&scoreDate,
+		); err != nil {
+			return nil, err
+		}
+		holding.Quantity = nullFloatPtr(quantity)
+		holding.targetWeight = weight.Float64
+		holding.LatestClose = nullFloatPtr(close)
+		holding.ESGScore = nullFloatPtr(esg)
+		if priceDate.Valid {
+			holding.PriceDate = &priceDate.Time
+		}
+		if scoreDate.Valid {
+			holding.ESGScoreDate = &scoreDate.Time
+		}
+		holdings = append(holdings, holding)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return valuePortfolio(portfolio, holdings), nil
+}
+
+// valuePortfolio derives effective weights and the weighted ESG score. Quantity
+// portfolios are weighted by market value; weight portfolios by their saved
+// weights, normalized to sum to one. Holdings without an ESG score are excluded
+// from the ESG average and reported through ESGCoverage.
+func valuePortfolio(portfolio *Portfolio, holdings []*ValuedHolding) *PortfolioValuation {
+	valuation := &PortfolioValuation{
+		PortfolioID: portfolio.ID,
+		Name:        portfolio.Name,
+		Basis:       portfolio.Basis,
+		Holdings:    holdings,
+		ValuedAt:    time.Now(),
+	}
+	if valuation.Holdings == nil {
+		valuation.Holdings = []*ValuedHolding{}
+	}
+
+	total := 0.0
+	if portfolio.Basis == PortfolioBasisQuantity {
+		for _, h := range holdings {
+			if h.Quantity != nil && h.LatestClose != nil {
+				value := *h.Quantity * *h.LatestClose
+				h.MarketValue = &value
+				total += value
+			}
+		}
+		valuation.TotalValue = &total
+		for _, h := range holdings {
+			if h.MarketValue != nil && total > 0 {
+				h.Weight = *h.MarketValue / total
+			}
+		}
+	} else {
+		for _, h := range holdings {
+			total += h.targetWeight
+		}
+		for _, h := range holdings {
+			if total > 0 {
+				h.Weight = h.targetWeight / total
+			}
+		}
+	}
+
+	weightedScore := 0.0
+	for _, h := range holdings {
+		if h.ESGScore != nil {
+			weightedScore += h.Weight * *h.ESGScore
+			valuation.ESGCoverage += h.Weight
+		}
+	}
+	if valuation.ESGCoverage > 0 {
+		score := weightedScore / valuation.ESGCoverage
+		valuation.WeightedESGScore = &score
+	}
+
+	return valuation
+}
+
+func (r *PortfolioRepository) getHoldings(ctx context.Context, portfolioID int) ([]*PortfolioHolding, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT h.id, h.portfolio_id, h.company_id, c.symbol, c.name, h.quantity, h.weight, h.created_at, h.updated_at
+		FROM portfolio_holdings h
+		JOIN companies c ON c.id = h.company_id
+		WHERE h.portfolio_id = $1
+		ORDER BY c.symbol ASC
+	`, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holdings := []*PortfolioHolding{}
+	for rows.Next() {
+		holding := &PortfolioHolding{}
+		var quantity, weight sql.NullFloat64
+		if err := rows.Scan(
+			&holding.ID,
+			&holding.PortfolioID,
+			&holding.CompanyID,
+			&holding.Symbol,
+			&holding.CompanyName,
+			&quantity,
+			&weight,
+			&holding.CreatedAt,
+			&holding.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		holding.Quantity = nullFloatPtr(quantity)
+		holding.Weight = nullFloatPtr(weight)
+		holdings = append(holdings, holding)
+	}
+
+	return holdings, rows.Err()
+}
+
+// lockOwnedPortfolio locks a portfolio row for the transaction, returning sql.ErrNoRows if the user does not own it
+func lockOwnedPortfolio(ctx context.Context, tx *tracing.Tx, userID, id int) error {
+	var locked int
+	return tx.QueryRowContext(ctx, `SELECT id FROM portfolios WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID).Scan(&locked)
+}
+
+// upsertHoldings resolves holding symbols and inserts or updates them
+func upsertHoldings(ctx context.Context, tx *tracing.Tx, portfolioID int, holdings []HoldingInput) error {
+	if len(holdings) == 0 {
+		return nil
+	}
+
+	symbols := make([]string, len(holdings))
+	for i, h := range holdings {
+		symbols[i] = h.Symbol
+	}
+	ids, err := resolveSymbols(ctx, tx, symbols)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range holdings {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO portfolio_holdings (portfolio_id, company_id, quantity, weight)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (portfolio_id, company_id) DO UPDATE SET
+				quantity = EXCLUDED.quantity,
+				weight = EXCLUDED.weight,
+				updated_at = CURRENT_TIMESTAMP
+		`, portfolioID, ids[h.Symbol], h.Quantity, h.Weight)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveSymbols maps symbols to company IDs, failing with ErrUnknownSymbol if any are missing
+func resolveSymbols(ctx context.Context, tx *tracing.Tx, symbols []string) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, symbol FROM companies WHERE symbol = ANY($1)`, pq.Array(symbols))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int, len(symbols))
+	for rows.Next() {
+		var id int
+		var symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		ids[symbol] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, symbol := range symbols {
+		if _, ok := ids[symbol]; !ok {
+			missing = append(missing, symbol)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, strings.Join(missing, ", "))
+	}
+
+	return ids, nil
+}
+
+// requireAffected returns sql.ErrNoRows when a write matched no rows
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func nullFloatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
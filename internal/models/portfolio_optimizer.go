@@ -0,0 +1,408 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInfeasiblePortfolio is returned when no allocation satisfies the requested constraints
+var ErrInfeasiblePortfolio = errors.New("no portfolio satisfies the requested constraints")
+
+// Solver tolerances
+const (
+	qpFeasibilityTolerance = 1e-5
+	qpMaxOuterIterations   = 40
+	qpMaxInnerIterations   = 1500
+	qpInnerTolerance       = 1e-10
+)
+
+// qpProblem describes a convex quadratic program:
+//
+//	minimize   w'Qw + c'w
+//	subject to EqA w = EqB, InA w <= InB, Lower <= w <= Upper
+type qpProblem struct {
+	Q     [][]float64
+	C     []float64
+	EqA   [][]float64
+	EqB   []float64
+	InA   [][]float64
+	InB   []float64
+	Lower []float64
+	Upper []float64
+}
+
+// solveQP solves a small dense quadratic program with an augmented Lagrangian
+// method. Box constraints are handled by projection inside an accelerated
+// projected-gradient inner loop; linear constraints carry multipliers.
+func solveQP(p qpProblem) ([]float64, error) {
+	n := len(p.C)
+	if n == 0 {
+		return nil, ErrInfeasiblePortfolio
+	}
+	for i := 0; i < n; i++ {
+		if p.Lower[i] > p.Upper[i] {
+			return nil, ErrInfeasiblePortfolio
+		}
+	}
+
+	// Start from the projection of equal weights onto the box
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1.0 / float64(n)
+	}
+	projectBox(w, p.Lower, p.Upper)
+
+	lambda := make([]float64, len(p.EqA))
+	mu := make([]float64, len(p.InA))
+	rho := 10.0
+
+	normQ := frobeniusNorm(p.Q)
+	normA := 0.0
+	for _, row := range p.EqA {
+		normA += dot(row, row)
+	}
+	for _, row := range p.InA {
+		normA += dot(row, row)
+	}
+
+	prevViolation := math.Inf(1)
+	for outer := 0; outer < qpMaxOuterIterations; outer++ {
+		lipschitz := 2*normQ + rho*normA
+		if lipschitz == 0 {
+			lipschitz = 1
+		}
+		step := 1 / lipschitz
+
+		// FISTA on the augmented Lagrangian for fixed multipliers
+		y := append([]float64(nil), w...)
+		prev := append([]float64(nil), w...)
+		t := 1.0
+		grad := make([]float64, n)
+		for inner := 0; inner < qpMaxInnerIterations; inner++ {
+			augmentedGradient(p, y, lambda, mu, rho, grad)
+
+			next := make([]float64, n)
+			for i := range next {
+				next[i] = y[i] - step*grad[i]
+			}
+			projectBox(next, p.Lower, p.Upper)
+
+			change := 0.0
+			for i := range next {
+				d := next[i] - prev[i]
+				change += d * d
+			}
+
+			tNext := (1 + math.Sqrt(1+4*t*t)) / 2
+			for i := range y {
+				y[i] = next[i] + ((t-1)/tNext)*(next[i]-prev[i])
+			}
+			prev = next
+			t = tNext
+
+			if change < qpInnerTolerance*qpInnerTolerance {
+				break
+			}
+		}
+		w = prev
+
+		// Multiplier updates
+		violation := 0.0
+		for i, row := range p.EqA {
+			r := dot(row, w) - p.EqB[i]
+			lambda[i] += rho * r
+			violation = math.Max(violation, math.Abs(r))
+		}
+		for j, row := range p.InA {
+			g := dot(row, w) - p.InB[j]
+			mu[j] = math.Max(0, mu[j]+rho*g)
+			violation = math.Max(violation, g)
+		}
+
+		if violation < qpFeasibilityTolerance*0.01 {
+			break
+		}
+		if violation > 0.25*prevViolation && rho < 1e8 {
+			rho *= 10
+		}
+		prevViolation = violation
+	}
+
+	if qpMaxViolation(p, w) > qpFeasibilityTolerance {
+		return nil, ErrInfeasiblePortfolio
+	}
+
+	return w, nil
+}
+
+// augmentedGradient writes the gradient of the augmented Lagrangian at w into grad
+func augmentedGradient(p qpProblem, w, lambda, mu []float64, rho float64, grad []float64) {
+	for i := range grad {
+		g := p.C[i]
+		for j := range w {
+			g += 2 * p.Q[i][j] * w[j]
+		}
+		grad[i] = g
+	}
+
+	for k, row := range p.EqA {
+		coeff := lambda[k] + rho*(dot(row, w)-p.EqB[k])
+		for i := range grad {
+			grad[i] += coeff * row[i]
+		}
+	}
+
+	for k, row := range p.InA {
+		coeff := mu[k] + rho*(dot(row, w)-p.InB[k])
+		if coeff <= 0 {
+			continue
+		}
+		for i := range grad {
+			grad[i] += coeff * row[i]
+		}
+	}
+}
+
+// qpMaxViolation returns the largest linear constraint violation at w
+func qpMaxViolation(p qpProblem, w []float64) float64 {
+	violation := 0.0
+	for i, row := range p.EqA {
+		violation = math.Max(violation, math.Abs(dot(row, w)-p.EqB[i]))
+	}
+	for j, row := range p.InA {
+		violation = math.Max(violation, dot(row, w)-p.InB[j])
+	}
+	return violation
+}
+
+func projectBox(w, lower, upper []float64) {
+	for i := range w {
+		if w[i] < lower[i] {
+			w[i] = lower[i]
+		} else if w[i] > upper[i] {
+			w[i] = upper[i]
+		}
+	}
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func frobeniusNorm(m [][]float64) float64 {
+	sum := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// quadraticForm returns w'Mw
+func quadraticForm(m [][]float64, w []float64) float64 {
+	sum := 0.0
+	for i := range w {
+		for j := range w {
+			sum += w[i] * m[i][j] * w[j]
+		}
+	}
+	return sum
+}
+
+// meanCovariance returns annualized mean returns and the annualized sample
+// covariance matrix for equally long, date-aligned return series
+func meanCovariance(returns [][]float64) ([]float64, [][]float64) {
+	n := len(returns)
+	means := make([]float64, n)
+	cov := make([][]float64, n)
+	if n == 0 || len(returns[0]) < 2 {
+		return means, cov
+	}
+
+	periods := len(returns[0])
+	for i, series := range returns {
+		for _, r := range series {
+			means[i] += r
+		}
+		means[i] /= float64(periods)
+	}
+
+	for i := 0; i < n; i++ {
+		cov[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sum := 0.0
+			for t := 0; t < periods; t++ {
+				sum += (returns[i][t] - means[i]) * (returns[j][t] - means[j])
+			}
+			value := sum / float64(periods-1) * 252
+			cov[i][j] = value
+			cov[j][i] = value
+		}
+	}
+
+	for i := range means {
+		means[i] *= 252
+	}
+
+	return means, cov
+}
+
+// buildPortfolioProblem translates portfolio constraints into a quadratic program
+// minimizing portfolio variance. The return constraint is added separately.
+func buildPortfolioProblem(assets []portfolioAsset, covariance [][]float64, constraints PortfolioConstraints) qpProblem {
+	n := len(assets)
+	p := qpProblem{
+		Q:     covariance,
+		C:     make([]float64, n),
+		Lower: make([]float64, n),
+		Upper: make([]float64, n),
+	}
+
+	// Fully invested
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	p.EqA = append(p.EqA, ones)
+	p.EqB = append(p.EqB, 1)
+
+	for i := 0; i < n; i++ {
+		p.Upper[i] = constraints.MaxWeight
+		if constraints.AllowShort {
+			p.Lower[i] = -constraints.MaxWeight
+		}
+	}
+
+	// Portfolio ESG score floor: sum(w_i * esg_i) >= min, scaled to unit range
+	// so the row does not dominate the solver's step size
+	if constraints.MinESGScore > 0 {
+		row := make([]float64, n)
+		for i, asset := range assets {
+			row[i] = -asset.ESGScore / 100
+		}
+		p.InA = append(p.InA, row)
+		p.InB = append(p.InB, -constraints.MinESGScore/100)
+	}
+
+	// Net weight cap per sector
+	if constraints.MaxSectorWeight < 1 {
+		sectors := make(map[string][]float64)
+		var order []string
+		for i, asset := range assets {
+			if _, ok := sectors[asset.Sector]; !ok {
+				sectors[asset.Sector] = make([]float64, n)
+				order = append(order, asset.Sector)
+			}
+			sectors[asset.Sector][i] = 1
+		}
+		for _, sector := range order {
+			p.InA = append(p.InA, sectors[sector])
+			p.InB = append(p.InB, constraints.MaxSectorWeight)
+		}
+	}
+
+	return p
+}
+
+// withMinimumReturn returns a copy of the problem with expected return >= target
+func withMinimumReturn(p qpProblem, expectedReturns []float64, target float64) qpProblem {
+	row := make([]float64, len(expectedReturns))
+	for i, r := range expectedReturns {
+		row[i] = -r
+	}
+	p.InA = append(append([][]float64(nil), p.InA...), row)
+	p.InB = append(append([]float64(nil), p.InB...), -target)
+	return p
+}
+
+// traceEfficientFrontier samples minimum-variance portfolios between the global
+// minimum-variance portfolio and the highest achievable expected return
+func traceEfficientFrontier(base qpProblem, assets []portfolioAsset, covariance [][]float64, riskFreeRate float64, points int) []FrontierPoint {
+	expectedReturns := make([]float64, len(assets))
+	for i, asset := range assets {
+		expectedReturns[i] = asset.ExpectedReturn
+	}
+
+	minVariance, err := solveQP(base)
+	if err != nil {
+		return []FrontierPoint{}
+	}
+
+	// Maximum return portfolio, with a tiny variance term to keep it well posed
+	maxReturnProblem := base
+	maxReturnProblem.Q = make([][]float64, len(covariance))
+	for i, row := range covariance {
+		maxReturnProblem.Q[i] = make([]float64, len(row))
+		for j, v := range row {
+			maxReturnProblem.Q[i][j] = v * 1e-6
+		}
+	}
+	maxReturnProblem.C = make([]float64, len(expectedReturns))
+	for i, r := range expectedReturns {
+		maxReturnProblem.C[i] = -r
+	}
+	maxReturn, err := solveQP(maxReturnProblem)
+	if err != nil {
+		maxReturn = minVariance
+	}
+
+	low := dot(minVariance, expectedReturns)
+	high := dot(maxReturn, expectedReturns)
+	if high-low < 1e-9 || points < 2 {
+		points = 1
+	}
+
+	frontier := make([]FrontierPoint, 0, points)
+	for k := 0; k < points; k++ {
+		weights := minVariance
+		if k > 0 {
+			target := low + (high-low)*float64(k)/float64(points-1)
+			weights, err = solveQP(withMinimumReturn(base, expectedReturns, target))
+			if err != nil {
+				continue
+			}
+		}
+
+		expectedReturn, volatility, esgScore := portfolioStats(weights, assets, covariance)
+		sharpeRatio := 0.0
+		if volatility > 0 {
+			sharpeRatio = (expectedReturn - riskFreeRate) / volatility
+		}
+		frontier = append(frontier, FrontierPoint{
+			ExpectedReturn: expectedReturn,
+			Volatility:     volatility,
+			SharpeRatio:    sharpeRatio,
+			ESGScore:       esgScore,
+		})
+	}
+
+	return frontier
+}
+
+// portfolioStats returns the expected return, volatility and weighted ESG score of a portfolio
+func portfolioStats(weights []float64, assets []portfolioAsset, covariance [][]float64) (expectedReturn, volatility, esgScore float64) {
+	for i, asset := range assets {
+		expectedReturn += weights[i] * asset.ExpectedReturn
+		esgScore += weights[i] * asset.ESGScore
+	}
+	volatility = math.Sqrt(math.Max(quadraticForm(covariance, weights), 0))
+	return expectedReturn, volatility, esgScore
+}
+
+// volatilityRiskLevel classifies annualized portfolio volatility
+func volatilityRiskLevel(volatility float64) string {
+	if volatility < 0.15 {
+		return "low"
+	} else if volatility < 0.25 {
+		return "medium"
+	} else {
+		return "high"
+	}
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diagonalCovariance(variances ...float64) [][]float64 {
+	cov := make([][]float64, len(variances))
+	for i, v := range variances {
+		cov[i] = make([]float64, len(variances))
+		cov[i][i] = v
+	}
+	return cov
+}
+
+func testAssets() []portfolioAsset {
+	return []portfolioAsset{
+		{ID: 1, Sector: "Technology", ESGScore: 60, ExpectedReturn: 0.14},
+		{ID: 2, Sector: "Technology", ESGScore: 70, ExpectedReturn: 0.10},
+		{ID: 3, Sector: "Utilities", ESGScore: 90, ExpectedReturn: 0.06},
+	}
+}
+
+func defaultTestConstraints() PortfolioConstraints {
+	return PortfolioConstraints{MaxWeight: 1, MaxSectorWeight: 1}
+}
+
+func TestSolveQP_MinimumVariance(t *testing.T) {
+	// For uncorrelated assets the minimum-variance weights are proportional to 1/variance
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+	p := buildPortfolioProblem(testAssets(), cov, defaultTestConstraints())
+
+	weights, err := solveQP(p)
+	require.NoError(t, err)
+
+	inv := []float64{1 / 0.04, 1 / 0.09, 1 / 0.01}
+	total := inv[0] + inv[1] + inv[2]
+	for i := range weights {
+		assert.InDelta(t, inv[i]/total, weights[i], 1e-4)
+	}
+}
+
+func TestSolveQP_TargetReturn(t *testing.T) {
+	assets := testAssets()
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+	returns := []float64{0.14, 0.10, 0.06}
+	p := withMinimumReturn(buildPortfolioProblem(assets, cov, defaultTestConstraints()), returns, 0.11)
+
+	weights, err := solveQP(p)
+	require.NoError(t, err)
+
+	expectedReturn, _, _ := portfolioStats(weights, assets, cov)
+	assert.InDelta(t, 0.11, expectedReturn, 1e-4)
+	assert.InDelta(t, 1.0, weights[0]+weights[1]+weights[2], 1e-4)
+	for _, w := range weights {
+		assert.GreaterOrEqual(t, w, -1e-6)
+	}
+}
+
+func TestSolveQP_Constraints(t *testing.T) {
+	assets := testAssets()
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+
+	constraints := defaultTestConstraints()
+	constraints.MaxWeight = 0.5
+	constraints.MaxSectorWeight = 0.6
+	constraints.MinESGScore = 75
+
+	weights, err := solveQP(buildPortfolioProblem(assets, cov, constraints))
+	require.NoError(t, err)
+
+	_, _, esgScore := portfolioStats(weights, assets, cov)
+	assert.GreaterOrEqual(t, esgScore, 75-1e-3)
+	assert.LessOrEqual(t, weights[0]+weights[1], 0.6+1e-4)
+	for _, w := range weights {
+		assert.LessOrEqual(t, w, 0.5+1e-6)
+	}
+}
+
+func TestSolveQP_Infeasible(t *testing.T) {
+	assets := testAssets()
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+	returns := []float64{0.14, 0.10, 0.06}
+
+	// No long-only portfolio can return more than the best asset
+	_, err := solveQP(withMinimumReturn(buildPortfolioProblem(assets, cov, defaultTestConstraints()), returns, 0.20))
+	assert.True(t, errors.Is(err, ErrInfeasiblePortfolio))
+
+	// Weight caps too tight to be fully invested
+	constraints := defaultTestConstraints()
+	constraints.MaxWeight = 0.2
+	_, err = solveQP(buildPortfolioProblem(assets, cov, constraints))
+	assert.True(t, errors.Is(err, ErrInfeasiblePortfolio))
+}
+
+func TestSolveQP_LongShort(t *testing.T) {
+	assets := testAssets()
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+	returns := []float64{0.14, 0.10, 0.06}
+
+	constraints := defaultTestConstraints()
+	constraints.AllowShort = true
+	constraints.MaxWeight = 2
+
+	// Target above the best single asset requires shorting the low-return asset
+	weights, err := solveQP(withMinimumReturn(buildPortfolioProblem(assets, cov, constraints), returns, 0.16))
+	require.NoError(t, err)
+
+	expectedReturn, _, _ := portfolioStats(weights, assets, cov)
+	assert.InDelta(t, 0.16, expectedReturn, 1e-4)
+	assert.Less(t, weights[2], 0.0)
+}
+
+func TestTraceEfficientFrontier(t *testing.T) {
+	assets := testAssets()
+	cov := diagonalCovariance(0.04, 0.09, 0.01)
+
+	frontier := traceEfficientFrontier(buildPortfolioProblem(assets, cov, defaultTestConstraints()), assets, cov, 0.02, 5)
+	require.Len(t, frontier, 5)
+
+	for i := 1; i < len(frontier); i++ {
+		assert.Greater(t, frontier[i].ExpectedReturn, frontier[i-1].ExpectedReturn)
+		assert.GreaterOrEqual(t, frontier[i].Volatility, frontier[i-1].Volatility-1e-6)
+	}
+	assert.InDelta(t, 0.14, frontier[len(frontier)-1].ExpectedReturn, 1e-3)
+}
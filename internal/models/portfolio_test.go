@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestValuePortfolio_QuantityBasis(t *testing.T) {
+	holdings := []*ValuedHolding{
+		{Symbol: "AAA", Quantity: floatPtr(10), LatestClose: floatPtr(30), ESGScore: floatPtr(80)},
+		{Symbol: "BBB", Quantity: floatPtr(20), LatestClose: floatPtr(35), ESGScore: floatPtr(60)},
+		{Symbol: "CCC", Quantity: floatPtr(5)}, // No price yet
+	}
+
+	valuation := valuePortfolio(&Portfolio{ID: 1, Basis: PortfolioBasisQuantity}, holdings)
+
+	require.NotNil(t, valuation.TotalValue)
+	assert.InDelta(t, 1000, *valuation.TotalValue, 1e-9)
+	assert.InDelta(t, 0.3, holdings[0].Weight, 1e-9)
+	assert.InDelta(t, 0.7, holdings[1].Weight, 1e-9)
+	assert.Nil(t, holdings[2].MarketValue)
+
+	require.NotNil(t, valuation.WeightedESGScore)
+	assert.InDelta(t, 0.3*80+0.7*60, *valuation.WeightedESGScore, 1e-9)
+	assert.InDelta(t, 1.0, valuation.ESGCoverage, 1e-9)
+}
+
+func TestValuePortfolio_WeightBasisPartialCoverage(t *testing.T) {
+	holdings := []*ValuedHolding{
+		{Symbol: "AAA", targetWeight: 0.3, ESGScore: floatPtr(90)},
+		{Symbol: "BBB", targetWeight: 0.3},
+	}
+
+	valuation := valuePortfolio(&Portfolio{ID: 2, Basis: PortfolioBasisWeight}, holdings)
+
+	assert.Nil(t, valuation.TotalValue)
+	assert.InDelta(t, 0.5, holdings[0].Weight, 1e-9)
+	assert.InDelta(t, 0.5, valuation.ESGCoverage, 1e-9)
+	require.NotNil(t, valuation.WeightedESGScore)
+	assert.InDelta(t, 90, *valuation.WeightedESGScore, 1e-9)
+}
+
+func TestValuePortfolio_Empty(t *testing.T) {
+	valuation := valuePortfolio(&Portfolio{ID: 3, Basis: PortfolioBasisWeight}, nil)
+
+	assert.NotNil(t, valuation.Holdings)
+	assert.Nil(t, valuation.WeightedESGScore)
+}
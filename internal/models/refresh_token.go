@@ -0,0 +1,229 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+)
+
+// Refresh token errors
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// RefreshToken represents a stored refresh token. Only the SHA-256 hash of the
+// token is persisted; tokens rotated from the same login share a family ID.
+type RefreshToken struct {
+	ID              int
+	UserID          int
+	FamilyID        string
+	TokenHash       string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+	UserAgent       string
+	IPAddress       string
+	CreatedAt       time.Time
+}
+
+// IssuedAccessToken identifies an access token issued alongside a refresh token
+type IssuedAccessToken struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// RefreshTokenRepository handles database operations for refresh tokens
+type RefreshTokenRepository struct {
+	db *tracing.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: tracing.WrapDB(db)}
+}
+
+// CreateRefreshToken stores a new refresh token
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+// RotateRefreshToken consumes the refresh token with the given hash and stores
+// its replacement in the same family. Presenting a token that was already
+// rotated or revoked revokes the whole family and returns ErrRefreshTokenReused
+// together with the access tokens that must be denylisted.
+func (r *RefreshTokenRepository) RotateRefreshToken(ctx context.Context, tokenHash string, next *RefreshToken) (*RefreshToken, []IssuedAccessToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	current := &RefreshToken{}
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(
+		&current.ID,
+		&current.UserID,
+		&current.FamilyID,
+		&current.ExpiresAt,
+		&current.UsedAt,
+		&current.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		issued, err := revokeRefreshTokens(ctx, tx, `family_id = $1`, current.FamilyID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, nil, err
+		}
+		return current, issued, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return current, nil, ErrRefreshTokenExpired
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1`, current.ID); err != nil {
+		return nil, nil, err
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return current, nil, nil
+}
+
+// RevokeFamily revokes every token in the family of the given refresh token
+// owned by the user, returning the access tokens to denylist
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, userID int, tokenHash string) ([]IssuedAccessToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	issued, err := revokeRefreshTokens(ctx, tx, `family_id = (
+		SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2
+	)`, tokenHash, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, tx.Commit()
+}
+
+// RevokeAllForUser revokes every refresh token of a user, returning the access tokens to denylist
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) ([]IssuedAccessToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	issued, err := revokeRefreshTokens(ctx, tx, `user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, tx.Commit()
+}
+
+// ActiveAccessTokens returns the unexpired access tokens issued to a user with a refresh token
+func (r *RefreshTokenRepository) ActiveAccessTokens(ctx context.Context, userID int) ([]IssuedAccessToken, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT access_jti, access_expires_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND access_jti IS NOT NULL AND access_expires_at > CURRENT_TIMESTAMP
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issued []IssuedAccessToken
+	for rows.Next() {
+		var token IssuedAccessToken
+		if err := rows.Scan(&token.JTI, &token.ExpiresAt); err != nil {
+			return nil, err
+		}
+		issued = append(issued, token)
+	}
+
+	return issued, rows.Err()
+}
+
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func insertRefreshToken(ctx context.Context, db rowQueryer, token *RefreshToken) error {
+	return db.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.AccessJTI,
+		token.AccessExpiresAt,
+		token.ExpiresAt,
+		token.UserAgent,
+		token.IPAddress,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// revokeRefreshTokens marks matching tokens revoked and returns the unexpired
+// access tokens that were issued with any token in the match
+func revokeRefreshTokens(ctx context.Context, tx *tracing.Tx, where string, args ...interface{}) ([]IssuedAccessToken, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+		WHERE `+where+`
+		RETURNING access_jti, access_expires_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issued []IssuedAccessToken
+	now := time.Now()
+	for rows.Next() {
+		var jti sql.NullString
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, err
+		}
+		if jti.Valid && jti.String != "" && expiresAt.Valid && expiresAt.Time.After(now) {
+			issued = append(issued, IssuedAccessToken{JTI: jti.String, ExpiresAt: expiresAt.Time})
+		}
+	}
+
+	return issued, rows.Err()
+}
@@ -1,8 +1,13 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
 )
 
 // User represents a user in the system
@@ -12,51 +17,58 @@ type User struct {
 	PasswordHash string    `json:"-"` // Never expose password hash in JSON
 	FirstName    string    `json:"first_name"`
 	LastName     string    `json:"last_name"`
+	Roles        []string  `json:"roles"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // UserRepository handles database operations for users
 type UserRepository struct {
-	db *sql.DB
+	db *tracing.DB
 }
 
 // NewUserRepository creates a new user repository
 func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: tracing.WrapDB(db)}
 }
 
 // CreateUser creates a new user
-func (r *UserRepository) CreateUser(user *User) error {
+func (r *UserRepository) CreateUser(ctx context.Context, user *User) error {
 	query := `
-		INSERT INTO users (email, password_hash, first_name, last_name)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (email, password_hash, first_name, last_name, roles)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(
+	if len(user.Roles) == 0 {
+		user.Roles = []string{"viewer"}
+	}
+
+	return r.db.QueryRowContext(ctx,
 		query,
 		user.Email,
 		user.PasswordHash,
 		user.FirstName,
 		user.LastName,
+		pq.Array(user.Roles),
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 }
 
 // GetUserByID retrieves a user by ID
-func (r *UserRepository) GetUserByID(id int) (*User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*User, error) {
 	user := &User{}
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, roles, created_at, updated_at
 		FROM users WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.FirstName,
 		&user.LastName,
+		pq.Array(&user.Roles),
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -69,19 +81,20 @@ func (r *UserRepository) GetUserByID(id int) (*User, error) {
 }
 
 // GetUserByEmail retrieves a user by email
-func (r *UserRepository) GetUserByEmail(email string) (*User, error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	user := &User{}
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, roles, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.FirstName,
 		&user.LastName,
+		pq.Array(&user.Roles),
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -94,7 +107,7 @@ func (r *UserRepository) GetUserByEmail(email string) (*User, error) {
 }
 
 // UpdateUser updates an existing user
-func (r *UserRepository) UpdateUser(user *User) error {
+func (r *UserRepository) UpdateUser(ctx context.Context, user *User) error {
 	query := `
 		UPDATE users 
 		SET email = $1, first_name = $2, last_name = $3, updated_at = CURRENT_TIMESTAMP
@@ -102,7 +115,7 @@ func (r *UserRepository) UpdateUser(user *User) error {
 		RETURNING updated_at
 	`
 
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(ctx,
 		query,
 		user.Email,
 		user.FirstName,
@@ -112,22 +125,22 @@ func (r *UserRepository) UpdateUser(user *User) error {
 }
 
 // DeleteUser deletes a user by ID
-func (r *UserRepository) DeleteUser(id int) error {
+func (r *UserRepository) DeleteUser(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
 // ListUsers retrieves all users with pagination
-func (r *UserRepository) ListUsers(limit, offset int) ([]*User, error) {
+func (r *UserRepository) ListUsers(ctx context.Context, limit, offset int) ([]*User, error) {
 	query := `
-		SELECT id, email, first_name, last_name, created_at, updated_at
+		SELECT id, email, first_name, last_name, roles, created_at, updated_at
 		FROM users
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +154,7 @@ func (r *UserRepository) ListUsers(limit, offset int) ([]*User, error) {
 			&user.Email,
 			&user.FirstName,
 			&user.LastName,
+			pq.Array(&user.Roles),
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -152,3 +166,32 @@ func (r *UserRepository) ListUsers(limit, offset int) ([]*User, error) {
 
 	return users, nil
 }
+
+// GrantRole adds a role to a user and returns the updated roles
+func (r *UserRepository) GrantRole(ctx context.Context, id int, role string) ([]string, error) {
+	query := `
+		UPDATE users
+		SET roles = CASE WHEN $2 = ANY(roles) THEN roles ELSE array_append(roles, $2) END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING roles
+	`
+
+	var roles []string
+	err := r.db.QueryRowContext(ctx, query, id, role).Scan(pq.Array(&roles))
+	return roles, err
+}
+
+// RevokeRole removes a role from a user and returns the updated roles
+func (r *UserRepository) RevokeRole(ctx context.Context, id int, role string) ([]string, error) {
+	query := `
+		UPDATE users
+		SET roles = array_remove(roles, $2), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING roles
+	`
+
+	var roles []string
+	err := r.db.QueryRowContext(ctx, query, id, role).Scan(pq.Array(&roles))
+	return roles, err
+}
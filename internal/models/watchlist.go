@@ -0,0 +1,235 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+)
+
+// Watchlist represents a named list of companies followed by a user
+type Watchlist struct {
+	ID        int              `json:"id"`
+	UserID    int              `json:"user_id"`
+	Name      string           `json:"name"`
+	Items     []*WatchlistItem `json:"items"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// WatchlistItem is a company on a watchlist with its latest close and ESG score
+type WatchlistItem struct {
+	CompanyID   int       `json:"company_id"`
+	Symbol      string    `json:"symbol"`
+	CompanyName string    `json:"company_name"`
+	Sector      string    `json:"sector"`
+	LatestClose *float64  `json:"latest_close"`
+	ESGScore    *float64  `json:"esg_score"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// WatchlistRepository handles database operations for watchlists
+type WatchlistRepository struct {
+	db *tracing.DB
+}
+
+// NewWatchlistRepository creates a new watchlist repository
+func NewWatchlistRepository(db *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{db: tracing.WrapDB(db)}
+}
+
+// CreateWatchlist creates a watchlist, optionally seeded with symbols
+func (r *WatchlistRepository) CreateWatchlist(ctx context.Context, watchlist *Watchlist, symbols []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO watchlists (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`, watchlist.UserID, watchlist.Name).Scan(&watchlist.ID, &watchlist.CreatedAt, &watchlist.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := addWatchlistItems(ctx, tx, watchlist.ID, symbols); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	watchlist.Items, err = r.getItems(ctx, watchlist.ID)
+	return err
+}
+
+// ListWatchlists retrieves all watchlists owned by a user, without items
+func (r *WatchlistRepository) ListWatchlists(ctx context.Context, userID int) ([]*Watchlist, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, created_at, updated_at
+		FROM watchlists
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watchlists := []*Watchlist{}
+	for rows.Next() {
+		watchlist := &Watchlist{}
+		if err := rows.Scan(&watchlist.ID, &watchlist.UserID, &watchlist.Name, &watchlist.CreatedAt, &watchlist.UpdatedAt); err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, watchlist)
+	}
+
+	return watchlists, rows.Err()
+}
+
+// GetWatchlist retrieves a watchlist with items, returning sql.ErrNoRows if the user does not own it
+func (r *WatchlistRepository) GetWatchlist(ctx context.Context, userID, id int) (*Watchlist, error) {
+	watchlist := &Watchlist{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, created_at, updated_at
+		FROM watchlists
+		WHERE id = $1 AND user_id = $2
+	`, id, userID).Scan(&watchlist.ID, &watchlist.UserID, &watchlist.Name, &watchlist.CreatedAt, &watchlist.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist.Items, err = r.getItems(ctx, watchlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return watchlist, nil
+}
+
+// RenameWatchlist updates a watchlist's name
+func (r *WatchlistRepository) RenameWatchlist(ctx context.Context, watchlist *Watchlist) error {
+	return r.db.QueryRowContext(ctx, `
+		UPDATE watchlists
+		SET name = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND user_id = $3
+		RETURNING updated_at
+	`, watchlist.Name, watchlist.ID, watchlist.UserID).Scan(&watchlist.UpdatedAt)
+}
+
+// DeleteWatchlist deletes a watchlist owned by a user
+func (r *WatchlistRepository) DeleteWatchlist(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM watchlists WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// AddItem adds a company to a watchlist by symbol; adding an existing symbol is a no-op
+func (r *WatchlistRepository) AddItem(ctx context.Context, userID, id int, symbol string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var owned int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM watchlists WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID).Scan(&owned)
+	if err != nil {
+		return err
+	}
+
+	if err := addWatchlistItems(ctx, tx, id, []string{symbol}); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE watchlists SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveItem removes a company from a watchlist owned by a user
+func (r *WatchlistRepository) RemoveItem(ctx context.Context, userID, id int, symbol string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM watchlist_items i
+		USING watchlists w, companies c
+		WHERE i.watchlist_id = w.id AND i.company_id = c.id
+		AND w.id = $1 AND w.user_id = $2 AND c.symbol = $3
+	`, id, userID, symbol)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *WatchlistRepository) getItems(ctx context.Context, watchlistID int) ([]*WatchlistItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.symbol, c.name, COALESCE(c.sector, ''), sp.close_price, es.overall_score, i.created_at
+		FROM watchlist_items i
+		JOIN companies c ON c.id = i.company_id
+		LEFT JOIN LATERAL (
+			SELECT close_price FROM stock_prices
+			WHERE company_id = i.company_id
+			ORDER BY date DESC LIMIT 1
+		) sp ON true
+		LEFT JOIN LATERAL (
+			SELECT overall_score FROM esg_scores
+			WHERE company_id = i.company_id AND overall_score IS NOT NULL
+			ORDER BY score_date DESC LIMIT 1
+		) es ON true
+		WHERE i.watchlist_id = $1
+		ORDER BY c.symbol ASC
+	`, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*WatchlistItem{}
+	for rows.Next() {
+		item := &WatchlistItem{}
+		var close, esg sql.NullFloat64
+		if err := rows.Scan(&item.CompanyID, &item.Symbol, &item.CompanyName, &item.Sector, &close, &esg, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		item.LatestClose = nullFloatPtr(close)
+		item.ESGScore = nullFloatPtr(esg)
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// addWatchlistItems resolves symbols and adds them to a watchlist, ignoring duplicates
+func addWatchlistItems(ctx context.Context, tx *tracing.Tx, watchlistID int, symbols []string) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	ids, err := resolveSymbols(ctx, tx, symbols)
+	if err != nil {
+		return err
+	}
+
+	for _, symbol := range symbols {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO watchlist_items (watchlist_id, company_id)
+			VALUES ($1, $2)
+			ON CONFLICT (watchlist_id, company_id) DO NOTHING
+		`, watchlistID, ids[symbol])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,393 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/lib/pq"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookEndpoint is a user-registered URL that receives signed event deliveries.
+// The signing secret is only returned when the endpoint is created.
+type WebhookEndpoint struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Secret      string    `json:"-"`
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent (or to be sent) to one endpoint
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	EndpointID     int             `json:"endpoint_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookAttempt is a pending delivery joined with where and how to send it
+type WebhookAttempt struct {
+	DeliveryID int
+	EventID    string
+	EventType  string
+	Payload    []byte
+	Attempts   int
+	URL        string
+	Secret     string
+}
+
+// WebhookRepository handles database operations for webhooks
+type WebhookRepository struct {
+	db *tracing.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: tracing.WrapDB(db)}
+}
+
+const webhookEndpointColumns = `id, user_id, url, description, secret, event_types, active, created_at, updated_at`
+
+// CreateEndpoint registers a webhook endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (user_id, url, description, secret, event_types, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx,
+		query,
+		endpoint.UserID,
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.Secret,
+		pq.Array(endpoint.EventTypes),
+		endpoint.Active,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+}
+
+// ListEndpoints retrieves a user's webhook endpoints
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, userID int) ([]*WebhookEndpoint, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*WebhookEndpoint{}
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// GetEndpoint retrieves a webhook endpoint owned by a user
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, userID, id int) (*WebhookEndpoint, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, id, userID)
+	return scanWebhookEndpoint(row)
+}
+
+// UpdateEndpoint updates the URL, description, event types and active flag of an owned endpoint
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $1, description = $2, event_types = $3, active = $4
+		WHERE id = $5 AND user_id = $6
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx,
+		query,
+		endpoint.URL,
+		endpoint.Description,
+		pq.Array(endpoint.EventTypes),
+		endpoint.Active,
+		endpoint.ID,
+		endpoint.UserID,
+	).Scan(&endpoint.CreatedAt, &endpoint.UpdatedAt)
+}
+
+// DeleteEndpoint deletes an owned endpoint and its delivery log
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// CreateDeliveries records a pending delivery of an event for every active
+// endpoint subscribed to its type and returns the new delivery IDs. Deliveries
+// that already exist for the event are skipped.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) ([]int, error) {
+	return r.createDeliveries(ctx, 0, eventID, eventType, payload)
+}
+
+// CreateUserDeliveries is CreateDeliveries limited to one user's endpoints
+func (r *WebhookRepository) CreateUserDeliveries(ctx context.Context, userID int, eventID, eventType string, payload []byte) ([]int, error) {
+	return r.createDeliveries(ctx, userID, eventID, eventType, payload)
+}
+
+// createDeliveries records deliveries to subscribed endpoints, limited to a
+// user's endpoints unless userID is 0
+func (r *WebhookRepository) createDeliveries(ctx context.Context, userID int, eventID, eventType string, payload []byte) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhook_endpoints
+		WHERE active AND $2 = ANY(event_types) AND ($4 = 0 OR user_id = $4)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		RETURNING id
+	`, eventID, eventType, payload, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// RelayOutbox turns up to limit events from the event outbox into pending
+// deliveries to the endpoints subscribed to their types, removing them from
+// the outbox in the same statement, and returns the new delivery IDs. Locked
+// events are skipped, so concurrent instances relay each event once.
+func (r *WebhookRepository) RelayOutbox(ctx context.Context, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH relayed AS (
+			DELETE FROM event_outbox
+			WHERE id IN (SELECT id FROM event_outbox ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+			RETURNING event_id, event_type, payload
+		)
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT e.id, relayed.event_id, relayed.event_type, relayed.payload
+		FROM relayed
+		JOIN webhook_endpoints e ON e.active AND relayed.event_type = ANY(e.event_types)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		RETURNING id
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ClaimAttempt leases a due, pending delivery to the caller by pushing its
+// next attempt past the lease, and returns it with its endpoint. It returns
+// sql.ErrNoRows if the delivery is not due, no longer pending, already claimed
+// or its endpoint is inactive, so concurrent workers never send it twice.
+func (r *WebhookRepository) ClaimAttempt(ctx context.Context, deliveryID int, lease time.Duration) (*WebhookAttempt, error) {
+	attempt := &WebhookAttempt{}
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_endpoints e
+		WHERE d.id = $1 AND e.id = d.endpoint_id AND e.active
+			AND d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP
+		RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, e.url, e.secret
+	`, deliveryID, lease.Seconds()).Scan(
+		&attempt.DeliveryID,
+		&attempt.EventID,
+		&attempt.EventType,
+		&attempt.Payload,
+		&attempt.Attempts,
+		&attempt.URL,
+		&attempt.Secret,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// RecordSuccess marks a delivery as succeeded
+func (r *WebhookRepository) RecordSuccess(ctx context.Context, deliveryID, statusCode int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', attempts = attempts + 1, last_status_code = $2, last_error = NULL,
+			delivered_at = CURRENT_TIMESTAMP, next_attempt_at = NULL
+		WHERE id = $1
+	`, deliveryID, statusCode)
+	return err
+}
+
+// RecordFailure records a failed attempt. A zero nextAttempt moves the delivery
+// to the dead-letter state; otherwise it stays pending until then.
+func (r *WebhookRepository) RecordFailure(ctx context.Context, deliveryID int, statusCode *int, message string, nextAttempt time.Time) error {
+	status := WebhookDeliveryPending
+	var next *time.Time
+	if nextAttempt.IsZero() {
+		status = WebhookDeliveryDead
+	} else {
+		next = &nextAttempt
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`, deliveryID, status, statusCode, message, next)
+	return err
+}
+
+// ListDeliveries retrieves the most recent deliveries to an owned endpoint
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, userID, endpointID, limit int, status string) ([]*WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+			d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		WHERE d.endpoint_id = $1 AND e.user_id = $2 AND ($3 = '' OR d.status = $3)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $4
+	`, endpointID, userID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*WebhookDelivery{}
+	for rows.Next() {
+		delivery := &WebhookDelivery{}
+		var statusCode sql.NullInt64
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.EndpointID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&statusCode,
+			&lastError,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			delivery.LastStatusCode = &code
+		}
+		if lastError.Valid {
+			delivery.LastError = &lastError.String
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Redeliver resets a delivery to an owned endpoint so it is attempted again
+// with a fresh retry budget, whatever its current status
+func (r *WebhookRepository) Redeliver(ctx context.Context, userID, endpointID, deliveryID int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, last_error = NULL
+		FROM webhook_endpoints e
+		WHERE d.id = $1 AND d.endpoint_id = $2 AND e.id = d.endpoint_id AND e.user_id = $3
+	`, deliveryID, endpointID, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// PendingDeliveries returns pending deliveries due before a time, oldest first,
+// so the queue can be rebuilt if Redis lost it or a worker died mid-attempt
+func (r *WebhookRepository) PendingDeliveries(ctx context.Context, before time.Time, limit int) (map[int]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, next_attempt_at FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make(map[int]time.Time)
+	for rows.Next() {
+		var id int
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		due[id] = at
+	}
+
+	return due, rows.Err()
+}
+
+// scanWebhookEndpoint scans a row selected with webhookEndpointColumns
+func scanWebhookEndpoint(row interface{ Scan(...interface{}) error }) (*WebhookEndpoint, error) {
+	endpoint := &WebhookEndpoint{}
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.UserID,
+		&endpoint.URL,
+		&endpoint.Description,
+		&endpoint.Secret,
+		pq.Array(&endpoint.EventTypes),
+		&endpoint.Active,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"ethosview-backend/pkg/monitoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSilence bounds how long an alert can be silenced
+const maxSilence = 30 * 24 * time.Hour
+
+// SilenceRequest represents a request to silence an alert
+type SilenceRequest struct {
+	Duration string `json:"duration" binding:"required"`
+}
+
+// loadAlertRules loads the monitoring rules from ALERT_RULES_FILE or the
+// monitoring_rules table and reopens persisted alerts. On failure the default
+// rules stay in place so monitoring still runs.
+func (s *Server) loadAlertRules() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	source, err := s.alertManager.LoadRules(ctx, os.Getenv("ALERT_RULES_FILE"))
+	if err != nil {
+		s.logger.Error("Failed to load alert rules; using defaults", "error", err)
+	} else {
+		s.logger.Info("Loaded alert rules", "source", source, "count", len(s.alertManager.Rules()))
+	}
+
+	if err := s.alertManager.RestoreAlerts(ctx); err != nil {
+		s.logger.Error("Failed to restore open alerts", "error", err)
+	}
+}
+
+// newAlertNotifier creates the monitoring alert notifier for every notifier
+// configured in the environment. An invalid routing configuration is logged
+// and the defaults are used instead.
+func newAlertNotifier(logger *slog.Logger) *monitoring.NotificationRouter {
+	config, err := monitoring.RouterConfigFromEnv()
+	if err != nil {
+		logger.Error("Invalid alert notification config; using defaults", "error", err)
+		config = monitoring.DefaultRouterConfig()
+	}
+
+	notifiers := monitoring.NotifiersFromEnv()
+	names := make([]string, len(notifiers))
+	for i, notifier := range notifiers {
+		names[i] = notifier.Name()
+	}
+	if len(notifiers) == 0 {
+		logger.Info("No alert notifiers configured; set ALERT_EMAIL_TO, ALERT_WEBHOOK_URL or ALERT_INCIDENT_ROUTING_KEY to notify operators")
+	} else {
+		logger.Info("Alert notifications enabled", "notifiers", names, "group_window", config.GroupWindow.String())
+	}
+
+	return monitoring.NewNotificationRouter(config, logger, notifiers...)
+}
+
+// alertRulesHandler handles GET /api/v1/admin/monitoring/rules
+func (s *Server) alertRulesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"rules":   s.alertManager.Rules(),
+		"metrics": monitoring.MetricNames(),
+	})
+}
+
+// alertHistoryHandler handles GET /api/v1/admin/monitoring/alerts, listing
+// persisted alerts newest first
+func (s *Server) alertHistoryHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	status := c.Query("status")
+	switch monitoring.AlertStatus(status) {
+	case "", monitoring.StatusFiring, monitoring.StatusAcknowledged, monitoring.StatusResolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Supported: firing, acknowledged, resolved"})
+		return
+	}
+
+	alerts, err := s.monitoringAlerts.ListAlerts(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// alertTransitionsHandler handles GET /api/v1/admin/monitoring/alerts/:id/transitions
+func (s *Server) alertTransitionsHandler(c *gin.Context) {
+	transitions, err := s.monitoringAlerts.Transitions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transitions": transitions})
+}
+
+// acknowledgeAlertHandler handles POST /api/v1/admin/monitoring/alerts/:id/acknowledge
+func (s *Server) acknowledgeAlertHandler(c *gin.Context) {
+	alert, err := s.alertManager.Acknowledge(c.Request.Context(), c.Param("id"), c.GetInt("user_id"))
+	respondAlertUpdate(c, alert, err, "Alert acknowledged")
+}
+
+// silenceAlertHandler handles POST /api/v1/admin/monitoring/alerts/:id/silence
+func (s *Server) silenceAlertHandler(c *gin.Context) {
+	var req SilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 || duration > maxSilence {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration. Use a Go duration such as 30m or 4h, up to 720h"})
+		return
+	}
+
+	alert, err := s.alertManager.Silence(c.Request.Context(), c.Param("id"), c.GetInt("user_id"), duration)
+	respondAlertUpdate(c, alert, err, "Alert silenced")
+}
+
+// resolveAlertHandler handles POST /api/v1/admin/monitoring/alerts/:id/resolve
+func (s *Server) resolveAlertHandler(c *gin.Context) {
+	alert, err := s.alertManager.ResolveAlert(c.Request.Context(), c.Param("id"), c.GetInt("user_id"))
+	respondAlertUpdate(c, alert, err, "Alert resolved")
+}
+
+// respondAlertUpdate writes the result of an alert state change
+func respondAlertUpdate(c *gin.Context, alert monitoring.Alert, err error, message string) {
+	switch {
+	case errors.Is(err, monitoring.ErrAlertNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found or no longer open"})
+	case errors.Is(err, monitoring.ErrAlertResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Alert is already resolved"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": message, "alert": alert})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"ethosview-backend/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurgeCacheRequest represents a request to purge cached responses by tag
+type PurgeCacheRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// purgeCacheHandler handles POST /api/v1/admin/cache/purge, deleting every
+// cached response tagged with any of the tags, such as "company:42" or
+// "analytics"
+func (s *Server) purgeCacheHandler(c *gin.Context) {
+	var req PurgeCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	for _, tag := range req.Tags {
+		if strings.TrimSpace(tag) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must not be empty"})
+			return
+		}
+	}
+
+	if err := s.advancedCache.InvalidateTags(c.Request.Context(), req.Tags...); err != nil {
+		middleware.RequestLogger(c).Error("Failed to purge cache", "tags", req.Tags, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge cache"})
+		return
+	}
+
+	middleware.RequestLogger(c).Info("Cache purged", "tags", req.Tags)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cache purged",
+		"tags":    req.Tags,
+	})
+}
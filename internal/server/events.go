@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"ethosview-backend/internal/alerting"
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/internal/webhooks"
+	"ethosview-backend/internal/websocket"
+	"ethosview-backend/pkg/monitoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forwardEventsToWebSocket publishes domain events to the WebSocket topics
+// clients subscribe to. The message type is the event type and the payload is
+// the full versioned event.
+func forwardEventsToWebSocket(bus *events.Bus, manager *websocket.Manager) {
+	bus.Subscribe(events.TypeESGScoreUpdated, func(event events.Event) {
+		data := event.Data.(events.ESGScoreUpdated)
+		manager.Publish(websocket.CompanyESGTopic(data.CompanyID), event.Type, event)
+		if data.Sector != "" {
+			manager.Publish(websocket.SectorTopic(data.Sector), event.Type, event)
+		}
+	})
+
+	bus.Subscribe(events.TypePriceTick, func(event events.Event) {
+		data := event.Data.(events.PriceTick)
+		manager.Publish(websocket.CompanyPricesTopic(data.CompanyID), event.Type, event)
+		if data.Sector != "" {
+			manager.Publish(websocket.SectorTopic(data.Sector), event.Type, event)
+		}
+	})
+
+	forwardAlert := func(event events.Event) {
+		manager.Publish(websocket.TopicAlerts, event.Type, event)
+	}
+	bus.Subscribe(events.TypeAlertRaised, forwardAlert)
+	bus.Subscribe(events.TypeAlertResolved, forwardAlert)
+}
+
+// publishAlertEvents publishes monitoring alerts as alert.raised and
+// alert.resolved domain events
+func publishAlertEvents(alertManager *monitoring.AlertManager, bus *events.Bus) {
+	alertManager.OnAlert(func(alert monitoring.Alert) {
+		data := events.Alert{
+			AlertID:   alert.ID,
+			AlertType: string(alert.Type),
+			Severity:  string(alert.Severity),
+			Message:   alert.Message,
+			Value:     alert.Value,
+			Threshold: alert.Threshold,
+			RaisedAt:  alert.Timestamp,
+		}
+		if !alert.Resolved {
+			bus.Publish(events.TypeAlertRaised, events.AlertRaisedVersion, data)
+			return
+		}
+		resolvedAt := alert.ResolvedAt
+		data.ResolvedAt = &resolvedAt
+		bus.Publish(events.TypeAlertResolved, events.AlertResolvedVersion, data)
+	})
+}
+
+// newAlertRuleEvaluator creates the user alert rule evaluator with every
+// available channel; email is enabled when SMTP_HOST is set
+func newAlertRuleEvaluator(db *sql.DB, manager *websocket.Manager, dispatcher *webhooks.Dispatcher, logger *slog.Logger) *alerting.Evaluator {
+	channels := []alerting.Channel{
+		alerting.NewWebSocketChannel(manager),
+		alerting.NewWebhookChannel(dispatcher),
+	}
+
+	if config, ok := alerting.SMTPConfigFromEnv(); ok {
+		users := models.NewUserRepository(db)
+		channels = append(channels, alerting.NewEmailChannel(config, func(ctx context.Context, userID int) (string, error) {
+			user, err := users.GetUserByID(ctx, userID)
+			if err != nil {
+				return "", err
+			}
+			return user.Email, nil
+		}))
+	} else {
+		logger.Info("SMTP_HOST not set; email alert notifications are disabled")
+	}
+
+	return alerting.NewEvaluator(db, logger, channels...)
+}
+
+// eventSchemasHandler lists the available event schemas
+func (s *Server) eventSchemasHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"schemas": events.SchemaNames()})
+}
+
+// eventSchemaHandler serves the JSON Schema for one version of an event type
+func (s *Server) eventSchemaHandler(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schema version"})
+		return
+	}
+
+	schema, err := events.Schema(c.Param("type"), version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schema not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/schema+json", schema)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultShutdownTimeout bounds the whole shutdown sequence
+	defaultShutdownTimeout = 20 * time.Second
+	// defaultDrainDelay is how long readiness fails before the listener
+	// closes, long enough for load balancers polling every few seconds
+	defaultDrainDelay = 5 * time.Second
+	// readHeaderTimeout bounds how long a client may take to send request headers
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Run starts the background services and serves HTTP on addr until ctx is
+// cancelled, then shuts down gracefully. It returns nil after a clean
+// shutdown.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	// Background loops get their own context so they outlive the HTTP drain:
+	// in-flight requests may still publish events and alerts
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	s.startBackgroundServices(background)
+
+	s.httpServer = &http.Server{Addr: addr, Handler: s.router, ReadHeaderTimeout: readHeaderTimeout}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopBackground()
+		s.background.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout, drainDelay := shutdownTimeoutsFromEnv(s.logger.Logger)
+	s.logger.Info("Shutting down", "drain_delay", drainDelay.String(), "timeout", timeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.shutdown(shutdownCtx, drainDelay, stopBackground)
+}
+
+// startBackgroundServices starts the WebSocket manager and its Redis
+// backplane, event dispatch, webhook delivery, alert rule evaluation, alert
+// notifications, cache invalidation retries, cache warming, metrics collection
+// and monitoring; each stops when ctx is cancelled
+func (s *Server) startBackgroundServices(ctx context.Context) {
+	run := func(service func(ctx context.Context)) {
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			service(ctx)
+		}()
+	}
+
+	run(s.wsManager.Run)
+	run(s.wsBackplane.Run)
+	run(s.events.Run)
+	run(s.webhooks.Run)
+	run(s.alertNotifier.Run)
+	run(func(ctx context.Context) { s.alertRules.Run(ctx, 15*time.Minute) })
+	run(func(ctx context.Context) { s.advancedCache.RetryInvalidations(ctx, 5*time.Second) })
+
+	// Warm the cache every 30 minutes, collect metrics every 5 and check
+	// monitoring rules every minute
+	run(func(ctx context.Context) { s.cacheWarmer.Run(ctx, 30*time.Minute) })
+	run(func(ctx context.Context) { s.metricsCollector.Run(ctx, 5*time.Minute) })
+	run(func(ctx context.Context) { s.alertManager.Run(ctx, 1*time.Minute) })
+}
+
+// shutdown fails readiness, waits drainDelay for load balancers to notice,
+// drains in-flight requests, closes WebSocket connections, stops the
+// background services and flushes traces, all within ctx's deadline. Later
+// steps still run when an earlier one times out.
+func (s *Server) shutdown(ctx context.Context, drainDelay time.Duration, stopBackground context.CancelFunc) error {
+	s.healthChecker.SetDraining(true)
+
+	if drainDelay > 0 {
+		select {
+		case <-time.After(drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("drain HTTP requests: %w", err))
+	}
+	if err := s.wsManager.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("close WebSocket connections: %w", err))
+	}
+
+	stopBackground()
+	if err := wait(ctx, &s.background); err != nil {
+		errs = append(errs, fmt.Errorf("stop background services: %w", err))
+	}
+
+	if err := s.tracerProvider.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("flush traces: %w", err))
+	}
+
+	if len(errs) == 0 {
+		s.logger.Info("Shutdown complete")
+	}
+	return errors.Join(errs...)
+}
+
+// wait waits for wg or until ctx is done
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdownTimeoutsFromEnv reads SHUTDOWN_TIMEOUT (default 20s), the deadline
+// for the whole shutdown, and SHUTDOWN_DRAIN_DELAY (default 5s), how long
+// readiness fails before the listener closes. Invalid values are logged and
+// the defaults used.
+func shutdownTimeoutsFromEnv(logger *slog.Logger) (timeout, drainDelay time.Duration) {
+	timeout, drainDelay = defaultShutdownTimeout, defaultDrainDelay
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			timeout = parsed
+		} else {
+			logger.Warn("Invalid SHUTDOWN_TIMEOUT; using default", "value", value, "default", timeout.String())
+		}
+	}
+	if value := os.Getenv("SHUTDOWN_DRAIN_DELAY"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			drainDelay = parsed
+		} else {
+			logger.Warn("Invalid SHUTDOWN_DRAIN_DELAY; using default", "value", value, "default", drainDelay.String())
+		}
+	}
+	if drainDelay >= timeout {
+		logger.Warn("SHUTDOWN_DRAIN_DELAY must be shorter than SHUTDOWN_TIMEOUT; not delaying", "drain_delay", drainDelay.String(), "timeout", timeout.String())
+		drainDelay = 0
+	}
+	return timeout, drainDelay
+}
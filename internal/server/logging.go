@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+
+	"ethosview-backend/pkg/logging"
+	"ethosview-backend/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogLevelRequest represents a request to change the log level
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// logLevelHandler handles GET /api/v1/admin/log-level
+func (s *Server) logLevelHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logging.LevelName(s.logger.Level())})
+}
+
+// setLogLevelHandler handles PUT /api/v1/admin/log-level. The change applies
+// to every logger immediately and lasts until the process restarts.
+func (s *Server) setLogLevelHandler(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log level. Supported: debug, info, warn, error"})
+		return
+	}
+
+	previous := s.logger.Level()
+	s.logger.SetLevel(level)
+	middleware.RequestLogger(c).Warn("Log level changed",
+		"previous_level", logging.LevelName(previous), "level", logging.LevelName(level))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Log level updated",
+		"level":   logging.LevelName(level),
+	})
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"ethosview-backend/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerMetrics registers this server's scrape-time metrics. Request and
+// cache metrics live on the default registry; these depend on the server's
+// own connections and managers.
+func (s *Server) registerMetrics() {
+	r := s.metricsRegistry
+	metrics.RegisterDBStats(r, s.db)
+	metrics.RegisterRedisPoolStats(r, s.redis)
+
+	gauge := func(name, help string, value func() int) {
+		r.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help},
+			func() float64 { return float64(value()) }))
+	}
+	gauge("ethosview_websocket_clients", "WebSocket clients connected to this instance.", s.wsManager.GetClientCount)
+	gauge("ethosview_websocket_authenticated_clients", "WebSocket clients on this instance authenticated as a user.", s.wsManager.GetAuthenticatedClientCount)
+	gauge("ethosview_websocket_topics", "WebSocket topics with at least one subscriber on this instance.", s.wsManager.GetTopicCount)
+
+	r.MustRegister(&activeAlertsCollector{server: s, desc: prometheus.NewDesc("ethosview_alerts_active",
+		"Unresolved monitoring alerts by severity.", []string{"severity"}, nil)})
+}
+
+// activeAlertsCollector counts unresolved monitoring alerts by severity at
+// scrape time
+type activeAlertsCollector struct {
+	server *Server
+	desc   *prometheus.Desc
+}
+
+func (c *activeAlertsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *activeAlertsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := map[string]int{"info": 0, "warning": 0, "critical": 0}
+	for _, alert := range c.server.alertManager.GetActiveAlerts() {
+		counts[string(alert.Severity)]++
+	}
+	for severity, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), severity)
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusEndpoint(t *testing.T) {
+	s := newTestServer(t)
+	newTestServer(t) // Each server registers its own collectors
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		return w.Body.String()
+	}
+
+	scrape()
+	body := scrape()
+	assert.Contains(t, body, "ethosview_websocket_clients 0\n")
+	assert.Contains(t, body, `ethosview_alerts_active{severity="critical"} 0`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="ethosview"}`)
+	assert.Contains(t, body, "go_goroutines ")
+	assert.Contains(t, body, `ethosview_http_requests_total{method="GET",route="/metrics",status="200"}`)
+}
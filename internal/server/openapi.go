@@ -0,0 +1,524 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ethosview-backend/internal/handlers"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/dashboard"
+	"ethosview-backend/pkg/errors"
+	"ethosview-backend/pkg/health"
+	"ethosview-backend/pkg/monitoring"
+	"ethosview-backend/pkg/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeAccess says which credentials an operation accepts
+type routeAccess int
+
+const (
+	accessPublic routeAccess = iota
+	// accessBearer accepts only a JWT bearer token
+	accessBearer
+	// accessAny accepts a JWT bearer token or an X-API-Key
+	accessAny
+)
+
+// apiRoute documents one registered route. Request and response bodies are
+// example values whose types the schemas are generated from; openapi.Object
+// describes gin.H bodies.
+type apiRoute struct {
+	method     string
+	path       string
+	tag        string
+	summary    string
+	access     routeAccess
+	permission string
+	params     []openapi.Parameter
+	body       interface{}
+	upload     bool
+	// optionalBody marks a body the handler accepts but does not require
+	optionalBody bool
+	status       int
+	response     interface{}
+	// mediaType of the success response when it is not JSON
+	mediaType string
+}
+
+type object = openapi.Object
+
+func query(name, typ, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: typ}}
+}
+
+func queryDefault(name, typ string, value interface{}, description string) openapi.Parameter {
+	param := query(name, typ, description)
+	param.Schema.Default = value
+	return param
+}
+
+func pathString(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Required: true, Description: description, Schema: &openapi.Schema{Type: "string"}}
+}
+
+// paging returns the limit and offset parameters with the handler defaults
+func paging(limit int) []openapi.Parameter {
+	return []openapi.Parameter{
+		queryDefault("limit", "integer", limit, "Maximum number of results"),
+		queryDefault("offset", "integer", 0, "Number of results to skip"),
+	}
+}
+
+// success wraps data in the errors.SuccessResponse envelope
+func success(data interface{}) object {
+	return object{"success": true, "data": data, "timestamp": "", "request_id": ""}
+}
+
+func message(fields object) object {
+	body := object{"message": ""}
+	for name, value := range fields {
+		body[name] = value
+	}
+	return body
+}
+
+var (
+	tokenUser = object{"id": 0, "email": "", "first_name": "", "last_name": "", "roles": []string{}}
+	tokens    = object{"token": "", "refresh_token": "", "token_type": "", "expires_in": 0}
+	page      = object{"limit": 0, "offset": 0, "count": 0}
+
+	betaParams = []openapi.Parameter{
+		queryDefault("benchmark", "string", "sp500", "Benchmark index"),
+		queryDefault("lookback_days", "integer", 365, "Days of price history to use"),
+	}
+	optimizeParams = []openapi.Parameter{
+		queryDefault("target_return", "number", 0.10, "Annual target return"),
+		{Name: "risk_tolerance", In: "query", Description: "Risk tolerance",
+			Schema: &openapi.Schema{Type: "string", Default: "medium", Enum: []interface{}{"low", "medium", "high"}}},
+		queryDefault("max_companies", "integer", 10, "Maximum number of holdings"),
+		query("max_weight", "number", "Maximum weight of one holding; defaults by risk tolerance"),
+		queryDefault("max_sector_weight", "number", 1, "Maximum weight of one sector"),
+		queryDefault("min_esg_score", "number", 0, "Minimum ESG score of a holding"),
+		queryDefault("allow_short", "boolean", false, "Allow short positions"),
+		queryDefault("lookback_days", "integer", 365, "Days of price history to use"),
+	}
+	alertID = pathString("id", "Monitoring alert ID")
+)
+
+func withTokens(fields object) object {
+	body := message(fields)
+	for name, value := range tokens {
+		body[name] = value
+	}
+	return body
+}
+
+// apiRoutes documents every route setupRoutes registers. The route coverage
+// test fails when the two disagree.
+var apiRoutes = []apiRoute{
+	// Health and operations
+	{method: "GET", path: "/health", tag: "Health", summary: "Service health", response: health.HealthStatus{}},
+	{method: "GET", path: "/health/detailed", tag: "Health", summary: "Detailed service health", response: object{}},
+	{method: "GET", path: "/health/ready", tag: "Health", summary: "Readiness check", response: object{"ready": true, "issues": []string{}}},
+	{method: "GET", path: "/health/live", tag: "Health", summary: "Liveness check", response: object{"status": "", "timestamp": time.Time{}}},
+	{method: "GET", path: "/metrics", tag: "Health", summary: "Prometheus metrics", mediaType: "text/plain; version=0.0.4"},
+	{method: "GET", path: "/metrics/json", tag: "Health", summary: "Metrics snapshot", response: object{}},
+	{method: "GET", path: "/alerts", tag: "Health", summary: "Monitoring alerts",
+		params:   []openapi.Parameter{queryDefault("all", "boolean", false, "Include resolved alerts")},
+		response: object{"alerts": []monitoring.Alert{}, "count": 0, "active_only": true}},
+	{method: "GET", path: "/dashboard/business", tag: "Health", summary: "Business dashboard", response: dashboard.DashboardData{}},
+	{method: "GET", path: "/api/v1/health", tag: "Health", summary: "Service health", response: health.HealthStatus{}},
+
+	// API documentation
+	{method: "GET", path: "/api/v1/openapi.json", tag: "Documentation", summary: "This OpenAPI document", response: object{}},
+	{method: "GET", path: "/api/v1/docs", tag: "Documentation", summary: "API documentation page", mediaType: "text/html"},
+
+	// Authentication
+	{method: "POST", path: "/api/v1/auth/register", tag: "Auth", summary: "Register a user",
+		body: handlers.RegisterRequest{}, status: http.StatusCreated, response: withTokens(object{"user": tokenUser})},
+	{method: "POST", path: "/api/v1/auth/login", tag: "Auth", summary: "Log in",
+		body: handlers.LoginRequest{}, response: withTokens(object{"user": tokenUser})},
+	{method: "POST", path: "/api/v1/auth/refresh", tag: "Auth", summary: "Exchange a refresh token for new tokens",
+		body: handlers.RefreshRequest{}, response: withTokens(nil)},
+	{method: "POST", path: "/api/v1/auth/logout", tag: "Auth", summary: "Revoke the current session", access: accessBearer,
+		body: handlers.RefreshRequest{}, optionalBody: true, response: message(nil)},
+	{method: "POST", path: "/api/v1/auth/logout-all", tag: "Auth", summary: "Revoke every session", access: accessBearer,
+		response: message(object{"revoked_sessions": 0})},
+	{method: "GET", path: "/api/v1/auth/profile", tag: "Auth", summary: "Current user's profile", access: accessBearer,
+		response: object{"id": 0, "email": "", "first_name": "", "last_name": "", "roles": []string{}, "created_at": time.Time{}, "updated_at": time.Time{}}},
+	{method: "PUT", path: "/api/v1/auth/profile", tag: "Auth", summary: "Update the current user's profile", access: accessBearer,
+		body: handlers.UpdateProfileRequest{}, response: message(object{"user": tokenUser})},
+
+	// Portfolios
+	{method: "GET", path: "/api/v1/me/portfolios", tag: "Portfolios", summary: "List portfolios", access: accessAny,
+		response: object{"portfolios": []models.Portfolio{}, "count": 0}},
+	{method: "POST", path: "/api/v1/me/portfolios", tag: "Portfolios", summary: "Create a portfolio", access: accessAny,
+		body: handlers.CreatePortfolioRequest{}, status: http.StatusCreated, response: models.Portfolio{}},
+	{method: "POST", path: "/api/v1/me/portfolios/optimize", tag: "Portfolios", summary: "Optimize a portfolio and save the result", access: accessAny,
+		params: optimizeParams, body: handlers.SaveOptimizedPortfolioRequest{}, status: http.StatusCreated,
+		response: object{"portfolio": models.Portfolio{}, "optimization": models.PortfolioOptimization{}}},
+	{method: "GET", path: "/api/v1/me/portfolios/:id", tag: "Portfolios", summary: "Get a portfolio", access: accessAny,
+		response: models.Portfolio{}},
+	{method: "PUT", path: "/api/v1/me/portfolios/:id", tag: "Portfolios", summary: "Update a portfolio", access: accessAny,
+		body: handlers.UpdatePortfolioRequest{}, response: models.Portfolio{}},
+	{method: "DELETE", path: "/api/v1/me/portfolios/:id", tag: "Portfolios", summary: "Delete a portfolio", access: accessAny,
+		response: message(nil)},
+	{method: "GET", path: "/api/v1/me/portfolios/:id/valuation", tag: "Portfolios", summary: "Value a portfolio at the latest prices", access: accessAny,
+		response: models.PortfolioValuation{}},
+	{method: "PUT", path: "/api/v1/me/portfolios/:id/holdings", tag: "Portfolios", summary: "Replace a portfolio's holdings", access: accessAny,
+		body: handlers.ReplaceHoldingsRequest{}, response: models.Portfolio{}},
+	{method: "POST", path: "/api/v1/me/portfolios/:id/holdings", tag: "Portfolios", summary: "Add or update a holding", access: accessAny,
+		body: handlers.HoldingRequest{}, response: models.Portfolio{}},
+	{method: "DELETE", path: "/api/v1/me/portfolios/:id/holdings/:symbol", tag: "Portfolios", summary: "Remove a holding", access: accessAny,
+		response: models.Portfolio{}},
+
+	// Watchlists
+	{method: "GET", path: "/api/v1/me/watchlists", tag: "Watchlists", summary: "List watchlists", access: accessAny,
+		response: object{"watchlists": []models.Watchlist{}, "count": 0}},
+	{method: "POST", path: "/api/v1/me/watchlists", tag: "Watchlists", summary: "Create a watchlist", access: accessAny,
+		body: handlers.WatchlistRequest{}, status: http.StatusCreated, response: models.Watchlist{}},
+	{method: "GET", path: "/api/v1/me/watchlists/:id", tag: "Watchlists", summary: "Get a watchlist", access: accessAny,
+		response: models.Watchlist{}},
+	{method: "PUT", path: "/api/v1/me/watchlists/:id", tag: "Watchlists", summary: "Rename a watchlist", access: accessAny,
+		body: handlers.WatchlistRequest{}, response: models.Watchlist{}},
+	{method: "DELETE", path: "/api/v1/me/watchlists/:id", tag: "Watchlists", summary: "Delete a watchlist", access: accessAny,
+		response: message(nil)},
+	{method: "POST", path: "/api/v1/me/watchlists/:id/items", tag: "Watchlists", summary: "Add a symbol", access: accessAny,
+		body: handlers.WatchlistItemRequest{}, response: models.Watchlist{}},
+	{method: "DELETE", path: "/api/v1/me/watchlists/:id/items/:symbol", tag: "Watchlists", summary: "Remove a symbol", access: accessAny,
+		response: models.Watchlist{}},
+
+	// Webhooks
+	{method: "GET", path: "/api/v1/me/webhooks", tag: "Webhooks", summary: "List webhooks and the event types they can receive", access: accessAny,
+		response: object{"webhooks": []models.WebhookEndpoint{}, "event_types": []string{}}},
+	{method: "POST", path: "/api/v1/me/webhooks", tag: "Webhooks", summary: "Create a webhook; the signing secret is only returned here", access: accessAny,
+		body: handlers.WebhookRequest{}, status: http.StatusCreated, response: message(object{"secret": "", "webhook": models.WebhookEndpoint{}})},
+	{method: "GET", path: "/api/v1/me/webhooks/:id", tag: "Webhooks", summary: "Get a webhook", access: accessAny,
+		response: models.WebhookEndpoint{}},
+	{method: "PUT", path: "/api/v1/me/webhooks/:id", tag: "Webhooks", summary: "Update a webhook", access: accessAny,
+		body: handlers.WebhookRequest{}, response: models.WebhookEndpoint{}},
+	{method: "DELETE", path: "/api/v1/me/webhooks/:id", tag: "Webhooks", summary: "Delete a webhook", access: accessAny,
+		response: message(nil)},
+	{method: "GET", path: "/api/v1/me/webhooks/:id/deliveries", tag: "Webhooks", summary: "List deliveries", access: accessAny,
+		params: []openapi.Parameter{
+			queryDefault("limit", "integer", 50, "Maximum number of results"),
+			query("status", "string", "Only deliveries with this status"),
+		},
+		response: object{"deliveries": []models.WebhookDelivery{}}},
+	{method: "POST", path: "/api/v1/me/webhooks/:id/deliveries/:deliveryId/redeliver", tag: "Webhooks", summary: "Queue a delivery again", access: accessAny,
+		status: http.StatusAccepted, response: message(nil)},
+
+	// Alert rules
+	{method: "GET", path: "/api/v1/me/alert-rules", tag: "Alert rules", summary: "List alert rules", access: accessAny,
+		response: object{"alert_rules": []models.AlertRule{}}},
+	{method: "POST", path: "/api/v1/me/alert-rules", tag: "Alert rules", summary: "Create an alert rule", access: accessAny,
+		body: handlers.AlertRuleRequest{}, status: http.StatusCreated, response: models.AlertRule{}},
+	{method: "GET", path: "/api/v1/me/alert-rules/:id", tag: "Alert rules", summary: "Get an alert rule", access: accessAny,
+		response: models.AlertRule{}},
+	{method: "PUT", path: "/api/v1/me/alert-rules/:id", tag: "Alert rules", summary: "Update an alert rule", access: accessAny,
+		body: handlers.AlertRuleRequest{}, response: models.AlertRule{}},
+	{method: "DELETE", path: "/api/v1/me/alert-rules/:id", tag: "Alert rules", summary: "Delete an alert rule", access: accessAny,
+		response: message(nil)},
+	{method: "GET", path: "/api/v1/me/alert-notifications", tag: "Alert rules", summary: "List notifications sent by alert rules", access: accessAny,
+		params:   []openapi.Parameter{queryDefault("limit", "integer", 50, "Maximum number of results")},
+		response: object{"notifications": []models.AlertNotification{}}},
+
+	// API keys
+	{method: "GET", path: "/api/v1/me/api-keys", tag: "API keys", summary: "List API keys", access: accessBearer,
+		response: object{"api_keys": []models.APIKey{}}},
+	{method: "POST", path: "/api/v1/me/api-keys", tag: "API keys", summary: "Create an API key; the key is only returned here", access: accessBearer,
+		body: handlers.CreateAPIKeyRequest{}, status: http.StatusCreated, response: message(object{"key": "", "api_key": models.APIKey{}})},
+	{method: "POST", path: "/api/v1/me/api-keys/:id/rotate", tag: "API keys", summary: "Rotate an API key", access: accessBearer,
+		response: message(object{"key": "", "api_key": models.APIKey{}})},
+	{method: "DELETE", path: "/api/v1/me/api-keys/:id", tag: "API keys", summary: "Revoke an API key", access: accessBearer,
+		response: message(nil)},
+
+	// Administration
+	{method: "GET", path: "/api/v1/admin/users", tag: "Admin", summary: "List users", access: accessAny, permission: auth.PermManageUsers,
+		params: paging(50), response: object{"users": []models.User{}, "limit": 0, "offset": 0}},
+	{method: "POST", path: "/api/v1/admin/users/:id/roles", tag: "Admin", summary: "Grant a role", access: accessAny, permission: auth.PermManageUsers,
+		body: handlers.RoleRequest{}, response: message(object{"user_id": 0, "roles": []string{}})},
+	{method: "DELETE", path: "/api/v1/admin/users/:id/roles/:role", tag: "Admin", summary: "Revoke a role", access: accessAny, permission: auth.PermManageUsers,
+		response: message(object{"user_id": 0, "roles": []string{}})},
+	{method: "GET", path: "/api/v1/admin/log-level", tag: "Admin", summary: "Current log level", access: accessAny, permission: auth.PermManageSystem,
+		response: object{"level": ""}},
+	{method: "PUT", path: "/api/v1/admin/log-level", tag: "Admin", summary: "Change the log level", access: accessAny, permission: auth.PermManageSystem,
+		body: LogLevelRequest{}, response: message(object{"level": ""})},
+	{method: "GET", path: "/api/v1/admin/monitoring/rules", tag: "Admin", summary: "Monitoring rules and the metrics they can watch", access: accessAny, permission: auth.PermManageSystem,
+		response: object{"rules": []monitoring.Rule{}, "metrics": []string{}}},
+	{method: "GET", path: "/api/v1/admin/monitoring/alerts", tag: "Admin", summary: "Alert history, newest first", access: accessAny, permission: auth.PermManageSystem,
+		params: append(paging(50), openapi.Parameter{Name: "status", In: "query", Description: "Only alerts with this status",
+			Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"firing", "acknowledged", "resolved"}}}),
+		response: object{"alerts": []monitoring.Alert{}, "limit": 0, "offset": 0}},
+	{method: "GET", path: "/api/v1/admin/monitoring/alerts/:id/transitions", tag: "Admin", summary: "An alert's state transitions", access: accessAny, permission: auth.PermManageSystem,
+		params: []openapi.Parameter{alertID}, response: object{"transitions": []monitoring.Transition{}}},
+	{method: "POST", path: "/api/v1/admin/monitoring/alerts/:id/acknowledge", tag: "Admin", summary: "Acknowledge an alert", access: accessAny, permission: auth.PermManageSystem,
+		params: []openapi.Parameter{alertID}, response: message(object{"alert": monitoring.Alert{}})},
+	{method: "POST", path: "/api/v1/admin/monitoring/alerts/:id/silence", tag: "Admin", summary: "Silence an alert", access: accessAny, permission: auth.PermManageSystem,
+		params: []openapi.Parameter{alertID}, body: SilenceRequest{}, response: message(object{"alert": monitoring.Alert{}})},
+	{method: "POST", path: "/api/v1/admin/monitoring/alerts/:id/resolve", tag: "Admin", summary: "Resolve an alert", access: accessAny, permission: auth.PermManageSystem,
+		params: []openapi.Parameter{alertID}, response: message(object{"alert": monitoring.Alert{}})},
+	{method: "POST", path: "/api/v1/admin/cache/purge", tag: "Admin", summary: "Purge cached responses by tag", access: accessAny, permission: auth.PermManageSystem,
+		body: PurgeCacheRequest{}, response: message(object{"tags": []string{}})},
+
+	// Companies
+	{method: "GET", path: "/api/v1/companies", tag: "Companies", summary: "List companies",
+		params:   append(paging(20), query("sector", "string", "Only companies in this sector")),
+		response: object{"companies": []models.Company{}, "pagination": page}},
+	{method: "GET", path: "/api/v1/companies/sectors", tag: "Companies", summary: "List sectors", response: object{"sectors": []string{}}},
+	{method: "GET", path: "/api/v1/companies/symbol/:symbol", tag: "Companies", summary: "Get a company by ticker symbol", response: models.Company{}},
+	{method: "GET", path: "/api/v1/companies/:id", tag: "Companies", summary: "Get a company", response: models.Company{}},
+	{method: "POST", path: "/api/v1/companies", tag: "Companies", summary: "Create a company", access: accessAny, permission: auth.PermWriteCompanies,
+		body: models.Company{}, status: http.StatusCreated, response: models.Company{}},
+	{method: "PUT", path: "/api/v1/companies/:id", tag: "Companies", summary: "Update a company", access: accessAny, permission: auth.PermWriteCompanies,
+		body: models.Company{}, response: models.Company{}},
+	{method: "DELETE", path: "/api/v1/companies/:id", tag: "Companies", summary: "Delete a company", access: accessAny, permission: auth.PermWriteCompanies,
+		response: message(nil)},
+
+	// ESG scores
+	{method: "GET", path: "/api/v1/esg/scores", tag: "ESG", summary: "List ESG scores",
+		params:   append(paging(20), queryDefault("min_score", "number", 0, "Minimum overall score")),
+		response: object{"scores": []models.ESGScore{}, "pagination": page, "filters": object{"min_score": 0.0}}},
+	{method: "GET", path: "/api/v1/esg/scores/:id", tag: "ESG", summary: "Get an ESG score", response: success(models.ESGScore{})},
+	{method: "GET", path: "/api/v1/esg/companies/:id/latest", tag: "ESG", summary: "A company's latest ESG score", response: success(models.ESGScore{})},
+	{method: "GET", path: "/api/v1/esg/companies/:id/scores", tag: "ESG", summary: "A company's ESG scores",
+		params: paging(20), response: object{"scores": []models.ESGScore{}, "pagination": page}},
+	{method: "POST", path: "/api/v1/esg/scores", tag: "ESG", summary: "Create an ESG score", access: accessAny, permission: auth.PermWriteESG,
+		body: models.ESGScore{}, response: success(models.ESGScore{})},
+	{method: "PUT", path: "/api/v1/esg/scores/:id", tag: "ESG", summary: "Update an ESG score", access: accessAny, permission: auth.PermWriteESG,
+		body: models.ESGScore{}, response: models.ESGScore{}},
+	{method: "DELETE", path: "/api/v1/esg/scores/:id", tag: "ESG", summary: "Delete an ESG score", access: accessAny, permission: auth.PermWriteESG,
+		response: message(nil)},
+
+	{method: "GET", path: "/api/v1/dashboard", tag: "Dashboard", summary: "Dashboard overview", response: object{}},
+
+	// Financial data
+	{method: "GET", path: "/api/v1/financial/companies/:id/prices", tag: "Financial", summary: "A company's recent stock prices",
+		params:   []openapi.Parameter{queryDefault("limit", "integer", 30, "Maximum number of prices")},
+		response: object{"company_id": 0, "prices": []models.StockPrice{}, "count": 0}},
+	{method: "GET", path: "/api/v1/financial/companies/:id/price/latest", tag: "Financial", summary: "A company's latest stock price",
+		response: object{"company_id": 0, "price": models.StockPrice{}}},
+	{method: "GET", path: "/api/v1/financial/companies/:id/indicators", tag: "Financial", summary: "A company's financial indicators",
+		response: object{"company_id": 0, "indicators": models.FinancialIndicator{}}},
+	{method: "GET", path: "/api/v1/financial/companies/:id/summary", tag: "Financial", summary: "A company's price summary and indicators",
+		response: object{"company_id": 0, "indicators": &models.FinancialIndicator{}, "summary": object{
+			"current_price": 0.0, "price_change": 0.0, "price_change_percent": 0.0, "volume": int64(0), "date": "",
+		}}},
+	{method: "GET", path: "/api/v1/financial/market", tag: "Financial", summary: "Latest market data", response: object{"market_data": models.MarketData{}}},
+	{method: "GET", path: "/api/v1/financial/market/history", tag: "Financial", summary: "Market data between two dates",
+		params: []openapi.Parameter{
+			{Name: "start_date", In: "query", Required: true, Description: "First day (YYYY-MM-DD)", Schema: &openapi.Schema{Type: "string", Format: "date"}},
+			{Name: "end_date", In: "query", Required: true, Description: "Last day (YYYY-MM-DD)", Schema: &openapi.Schema{Type: "string", Format: "date"}},
+			queryDefault("limit", "integer", 30, "Maximum number of days"),
+		},
+		response: object{"start_date": "", "end_date": "", "data": []models.MarketData{}, "count": 0}},
+	{method: "POST", path: "/api/v1/financial/import/stock-prices", tag: "Financial", summary: "Import stock prices from CSV or NDJSON", access: accessAny, permission: auth.PermImportFinancial,
+		body: handlers.StockPriceUploadRow{}, upload: true, response: message(object{"report": models.ImportReport{}})},
+	{method: "POST", path: "/api/v1/financial/import/indicators", tag: "Financial", summary: "Import financial indicators from CSV or NDJSON", access: accessAny, permission: auth.PermImportFinancial,
+		body: handlers.IndicatorUploadRow{}, upload: true, response: message(object{"report": models.ImportReport{}})},
+	{method: "POST", path: "/api/v1/financial/import/market-data", tag: "Financial", summary: "Import market data from CSV or NDJSON", access: accessAny, permission: auth.PermImportFinancial,
+		body: handlers.MarketDataUploadRow{}, upload: true, response: message(object{"report": models.ImportReport{}})},
+
+	// Analytics
+	{method: "GET", path: "/api/v1/analytics/companies/:id/esg-trends", tag: "Analytics", summary: "A company's ESG score trend",
+		params:   []openapi.Parameter{queryDefault("days", "integer", 30, "Days of history")},
+		response: object{"company_id": 0, "trends": []models.ESGTrend{}, "count": 0, "days": 0}},
+	{method: "GET", path: "/api/v1/analytics/sectors/comparisons", tag: "Analytics", summary: "Compare sectors",
+		response: object{"sector_comparisons": []models.SectorComparison{}, "count": 0}},
+	{method: "GET", path: "/api/v1/analytics/financial/comparisons", tag: "Analytics", summary: "Compare companies' ESG and financial metrics",
+		params:   []openapi.Parameter{queryDefault("limit", "integer", 10, "Maximum number of companies")},
+		response: object{"financial_comparisons": []models.FinancialComparison{}, "count": 0, "limit": 0}},
+	{method: "GET", path: "/api/v1/analytics/top-performers/:metric", tag: "Analytics", summary: "Top companies by a metric",
+		params: []openapi.Parameter{
+			{Name: "metric", In: "path", Required: true, Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"esg_score", "market_cap", "pe_ratio"}}},
+			queryDefault("limit", "integer", 10, "Maximum number of companies"),
+		},
+		response: object{"metric": "", "top_performers": []models.PerformanceMetric{}, "count": 0, "limit": 0}},
+	{method: "GET", path: "/api/v1/analytics/correlation/esg-financial", tag: "Analytics", summary: "Correlation between ESG scores and financial metrics",
+		response: object{"correlation_analysis": object{}}},
+	{method: "GET", path: "/api/v1/analytics/summary", tag: "Analytics", summary: "Analytics overview", response: object{"summary": object{}}},
+
+	// Advanced analytics
+	{method: "GET", path: "/api/v1/advanced/companies/:id/predict-esg", tag: "Advanced analytics", summary: "Predict a company's ESG score",
+		response: message(object{"prediction": models.ESGPrediction{}})},
+	{method: "GET", path: "/api/v1/advanced/portfolio/optimize", tag: "Advanced analytics", summary: "Optimize an ESG portfolio",
+		params: optimizeParams, response: message(object{"optimization": models.PortfolioOptimization{}})},
+	{method: "GET", path: "/api/v1/advanced/companies/:id/risk-assessment", tag: "Advanced analytics", summary: "Assess a company's risk",
+		params: betaParams, response: message(object{"assessment": models.RiskAssessment{}})},
+	{method: "GET", path: "/api/v1/advanced/companies/:id/beta", tag: "Advanced analytics", summary: "Estimate a company's beta",
+		params: betaParams, response: message(object{"beta": models.BetaEstimate{}})},
+	{method: "GET", path: "/api/v1/advanced/companies/:id/trends/:metric", tag: "Advanced analytics", summary: "Analyze a metric's trend",
+		params:   []openapi.Parameter{queryDefault("period", "string", "30d", "Analysis period")},
+		response: message(object{"analysis": models.TrendAnalysis{}})},
+	{method: "GET", path: "/api/v1/advanced/summary", tag: "Advanced analytics", summary: "Advanced analytics overview", response: object{"summary": object{}}},
+	{method: "POST", path: "/api/v1/advanced/backtest", tag: "Advanced analytics", summary: "Backtest a portfolio rule", access: accessAny, permission: auth.PermRunBacktests,
+		body: handlers.BacktestRequest{}, response: message(object{"backtest": models.BacktestResult{}})},
+
+	// Real time
+	{method: "GET", path: "/api/v1/ws", tag: "Real time", summary: "Open a WebSocket; authenticate with a token query parameter or the bearer subprotocol",
+		params: []openapi.Parameter{query("token", "string", "Access token")}, status: http.StatusSwitchingProtocols},
+	{method: "GET", path: "/api/v1/ws/status", tag: "Real time", summary: "WebSocket cluster status", response: object{}},
+	{method: "GET", path: "/api/v1/events/schemas", tag: "Real time", summary: "List event schemas", response: object{"schemas": []string{}}},
+	{method: "GET", path: "/api/v1/events/schemas/:type/:version", tag: "Real time", summary: "JSON Schema for a version of an event type",
+		mediaType: "application/schema+json"},
+}
+
+// apiDocument builds the OpenAPI document for apiRoutes
+func apiDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "EthosView API",
+		Version:     "1.0.0",
+		Description: "ESG and financial analytics. Errors use the AppError envelope; list endpoints document their defaults.",
+	})
+	doc.AddSecurityScheme("bearerAuth", &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+	doc.AddSecurityScheme("apiKeyAuth", &openapi.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"})
+
+	appError := doc.Schema(errors.AppError{})
+	errorResponse := func(description string) *openapi.Response {
+		return &openapi.Response{Description: description, Content: map[string]openapi.MediaType{"application/json": {Schema: appError}}}
+	}
+
+	seenTags := make(map[string]bool)
+	for _, route := range apiRoutes {
+		if !seenTags[route.tag] {
+			seenTags[route.tag] = true
+			doc.Tags = append(doc.Tags, openapi.Tag{Name: route.tag})
+		}
+
+		op := &openapi.Operation{
+			Tags:        []string{route.tag},
+			Summary:     route.summary,
+			OperationID: operationID(route.method, route.path),
+			Parameters:  pathParameters(route.path, route.params),
+			Responses:   map[string]*openapi.Response{"default": errorResponse("Error")},
+		}
+
+		if route.body != nil {
+			op.RequestBody = requestBody(doc, route)
+		}
+
+		status := route.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := &openapi.Response{Description: http.StatusText(status)}
+		switch {
+		case route.mediaType != "":
+			response.Content = map[string]openapi.MediaType{route.mediaType: {}}
+		case route.response != nil:
+			response.Content = map[string]openapi.MediaType{"application/json": {Schema: doc.Schema(route.response)}}
+		}
+		op.Responses[fmt.Sprint(status)] = response
+
+		switch route.access {
+		case accessBearer:
+			op.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}}
+		case accessAny:
+			op.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+		}
+		if route.access != accessPublic {
+			op.Responses["401"] = errorResponse("Missing, invalid or revoked credentials")
+		}
+		if route.permission != "" {
+			op.Description = "Requires the " + route.permission + " permission."
+			op.Responses["403"] = errorResponse("Missing the " + route.permission + " permission")
+		} else if strings.HasPrefix(route.path, "/api/v1/me/") && route.method != http.MethodGet && route.access == accessAny {
+			op.Description = "API keys need the " + auth.PermWriteAccount + " scope."
+			op.Responses["403"] = errorResponse("API key without the " + auth.PermWriteAccount + " scope")
+		}
+
+		doc.AddOperation(route.method, route.path, op)
+	}
+	return doc
+}
+
+// pathParameters types the route's path parameters: declared ones as given,
+// IDs as integers and the rest as strings
+func pathParameters(path string, declared []openapi.Parameter) []openapi.Parameter {
+	params := declared
+	_, names := openapi.PathTemplate(path)
+	for _, name := range names {
+		found := false
+		for _, param := range declared {
+			found = found || (param.Name == name && param.In == "path")
+		}
+		if found {
+			continue
+		}
+		if name == "id" || strings.HasSuffix(name, "Id") || name == "version" {
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}})
+		} else {
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+		}
+	}
+	return params
+}
+
+// requestBody describes a JSON body, or for imports the CSV, NDJSON and
+// multipart uploads whose rows have the body's fields
+func requestBody(doc *openapi.Document, route apiRoute) *openapi.RequestBody {
+	schema := doc.Schema(route.body)
+	if !route.upload {
+		return &openapi.RequestBody{Required: !route.optionalBody, Content: map[string]openapi.MediaType{"application/json": {Schema: schema}}}
+	}
+
+	return &openapi.RequestBody{
+		Required:    true,
+		Description: "One row per record. The format query parameter (csv or ndjson) overrides the content type or file extension.",
+		Content: map[string]openapi.MediaType{
+			"text/csv":             {Schema: &openapi.Schema{Type: "string", Description: "CSV with a header row naming the row fields"}},
+			"application/x-ndjson": {Schema: schema},
+			"multipart/form-data": {Schema: &openapi.Schema{
+				Type:       "object",
+				Required:   []string{"file"},
+				Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+			}},
+		},
+	}
+}
+
+// operationID derives a stable camelCase ID such as getApiV1CompaniesById
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			id += "By"
+			segment = segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+var apiSpec = sync.OnceValues(func() ([]byte, error) {
+	return json.Marshal(apiDocument())
+})
+
+//go:embed docs.html
+var apiDocsPage []byte
+
+// openAPIHandler handles GET /api/v1/openapi.json
+func (s *Server) openAPIHandler(c *gin.Context) {
+	spec, err := apiSpec()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render API document"})
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// apiDocsHandler handles GET /api/v1/docs, a page that renders the OpenAPI
+// document
+func (s *Server) apiDocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", apiDocsPage)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethosview-backend/pkg/cache/cachetest"
+	"ethosview-backend/pkg/logging"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer builds a server whose database is unreachable; routes that
+// do not query it still work
+func newTestServer(t *testing.T) *Server {
+	db, err := sql.Open("postgres", "postgres://127.0.0.1:1/none?sslmode=disable&connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewServer(db, cachetest.NewServer(t).Client(t), logging.New(io.Discard, slog.LevelError))
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	s := newTestServer(t)
+	doc := apiDocument()
+
+	registered := make(map[string]bool)
+	for _, route := range s.router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+		assert.NotNil(t, doc.Operation(route.Method, route.Path), "%s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+	for _, route := range apiRoutes {
+		assert.True(t, registered[route.method+" "+route.path], "%s %s is documented but not registered", route.method, route.path)
+	}
+}
+
+func TestOpenAPIDocumentDerivesSchemas(t *testing.T) {
+	doc := apiDocument()
+	schemas := doc.Components.Schemas
+
+	require.Contains(t, schemas, "RegisterRequest")
+	assert.Equal(t, []string{"email", "first_name", "last_name", "password"}, schemas["RegisterRequest"].Required)
+	assert.Equal(t, "email", schemas["RegisterRequest"].Properties["email"].Format)
+	for _, name := range []string{"Company", "ESGScore", "StockPrice", "RiskAssessment", "AppError"} {
+		assert.Contains(t, schemas, name)
+	}
+
+	op := doc.Operation("POST", "/api/v1/companies")
+	require.NotNil(t, op)
+	assert.Equal(t, "#/components/schemas/Company", op.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/AppError", op.Responses["default"].Content["application/json"].Schema.Ref)
+	assert.Contains(t, op.Responses, "403")
+	assert.Len(t, op.Security, 2)
+
+	op = doc.Operation("GET", "/api/v1/companies/:id")
+	require.NotNil(t, op)
+	assert.Empty(t, op.Security)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "integer", op.Parameters[0].Schema.Type)
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	s := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.Contains(t, doc["paths"], "/api/v1/companies/{id}")
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "openapi.json")
+}
@@ -3,21 +3,30 @@ package server
 import (
 	"database/sql"
 	"net/http"
-	"time"
+	"sync"
 
+	"ethosview-backend/internal/alerting"
+	"ethosview-backend/internal/events"
 	"ethosview-backend/internal/handlers"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/internal/webhooks"
 	"ethosview-backend/internal/websocket"
 	"ethosview-backend/pkg/auth"
 	"ethosview-backend/pkg/cache"
 	"ethosview-backend/pkg/dashboard"
 	"ethosview-backend/pkg/health"
+	"ethosview-backend/pkg/logging"
 	"ethosview-backend/pkg/metrics"
 	"ethosview-backend/pkg/middleware"
 	"ethosview-backend/pkg/monitoring"
 	"ethosview-backend/pkg/security"
+	"ethosview-backend/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Server represents the HTTP server
@@ -26,44 +35,89 @@ type Server struct {
 	db                 *sql.DB
 	redis              *redis.Client
 	wsManager          *websocket.Manager
+	wsBackplane        *websocket.Backplane
+	events             *events.Bus
+	webhooks           *webhooks.Dispatcher
+	alertRules         *alerting.Evaluator
 	cacheWarmer        *cache.CacheWarmer
 	advancedCache      *cache.AdvancedCache
 	metricsCollector   *metrics.MetricsCollector
+	metricsRegistry    *prometheus.Registry
+	tracerProvider     *sdktrace.TracerProvider
 	healthChecker      *health.HealthChecker
 	securityMiddleware *security.SecurityMiddleware
 	businessDashboard  *dashboard.BusinessDashboard
 	alertManager       *monitoring.AlertManager
+	alertNotifier      *monitoring.NotificationRouter
+	httpServer         *http.Server
+	background         sync.WaitGroup
+	monitoringAlerts   *models.MonitoringAlertRepository
+	logger             *logging.Logger
 }
 
-// NewServer creates and configures a new server instance
-func NewServer(db *sql.DB, redis *redis.Client) *Server {
+// NewServer creates and configures a new server instance that logs with logger
+func NewServer(db *sql.DB, redis *redis.Client, logger *logging.Logger) *Server {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
 
-	// Create router
-	router := gin.Default()
+	// Create router; access logs and panic recovery use the structured logger
+	router := gin.New()
 
 	// Create server instance
 	srv := &Server{
 		router:             router,
 		db:                 db,
 		redis:              redis,
-		wsManager:          websocket.NewManager(),
-		cacheWarmer:        cache.NewCacheWarmer(redis, db),
-		advancedCache:      cache.NewAdvancedCache(redis, "ethosview"),
-		metricsCollector:   metrics.NewMetricsCollector(redis, db),
+		wsManager:          websocket.NewManager(logger.Logger),
+		wsBackplane:        websocket.NewBackplane(redis, logger.Logger),
+		events:             events.NewBus(logger.Logger),
+		webhooks:           webhooks.NewDispatcher(models.NewWebhookRepository(db), redis, logger.Logger),
+		cacheWarmer:        cache.NewCacheWarmer(redis, db, logger.Logger),
+		advancedCache:      cache.NewAdvancedCache(redis, "ethosview", logger.Logger),
+		metricsCollector:   metrics.NewMetricsCollector(redis, db, logger.Logger),
+		metricsRegistry:    prometheus.NewRegistry(),
 		healthChecker:      health.NewHealthChecker(db, redis),
 		securityMiddleware: security.NewSecurityMiddleware(),
 		businessDashboard:  dashboard.NewBusinessDashboard(db, redis),
-		alertManager:       monitoring.NewAlertManager(db, redis),
+		monitoringAlerts:   models.NewMonitoringAlertRepository(db),
+		logger:             logger,
 	}
 
+	// Evaluate monitoring rules from ALERT_RULES_FILE or the database,
+	// persisting alerts and their history
+	srv.alertManager = monitoring.NewAlertManager(db, redis, srv.monitoringAlerts, logger.Logger)
+	srv.loadAlertRules()
+
+	// Notify operators of monitoring alerts by email, chat webhook and
+	// incident events, grouped and routed by severity
+	srv.alertNotifier = newAlertNotifier(logger.Logger)
+	srv.alertNotifier.Subscribe(srv.alertManager)
+
+	// Trace requests and the queries and Redis commands they make
+	srv.tracerProvider = newTracerProvider(logger.Logger)
+	otel.SetTracerProvider(srv.tracerProvider)
+	redis.AddHook(tracing.NewRedisHook())
+
+	// Relay WebSocket messages between instances through Redis
+	srv.wsManager.SetBackplane(srv.wsBackplane)
+
+	// Push domain events from write paths to WebSocket subscribers
+	forwardEventsToWebSocket(srv.events, srv.wsManager)
+
+	// Raise monitoring alerts as events and deliver events to user webhooks
+	publishAlertEvents(srv.alertManager, srv.events)
+	srv.webhooks.Subscribe(srv.events)
+
+	// Evaluate user alert rules after ESG and price writes
+	srv.alertRules = newAlertRuleEvaluator(db, srv.wsManager, srv.webhooks, logger.Logger)
+	srv.alertRules.Subscribe(srv.events)
+
+	// Export pool, WebSocket and alert gauges alongside the request metrics
+	srv.registerMetrics()
+
 	// Setup routes
 	srv.setupRoutes()
 
-	// Start background services
-	srv.startBackgroundServices()
-
 	return srv
 }
 
@@ -72,12 +126,14 @@ func (s *Server) setupRoutes() {
 	// Initialize performance middleware
 	rateLimiter := middleware.NewRateLimiter(s.redis)
 	monitoringMiddleware := middleware.MonitoringMiddleware()
-	cacheMiddleware := middleware.CacheMiddleware(s.redis, 5*time.Minute)
 	compressionMiddleware := middleware.CompressionMiddleware()
 	requestIDMiddleware := middleware.RequestIDMiddleware()
 
 	// Apply global middleware
+	s.router.Use(middleware.RecoveryMiddleware())
 	s.router.Use(requestIDMiddleware)
+	s.router.Use(middleware.TracingMiddleware(s.tracerProvider))
+	s.router.Use(middleware.LoggingMiddleware(s.logger.Logger))
 	s.router.Use(compressionMiddleware)
 	s.router.Use(monitoringMiddleware)
 	s.router.Use(s.securityMiddleware.SecurityHeaders())
@@ -86,19 +142,39 @@ func (s *Server) setupRoutes() {
 	s.router.Use(s.securityMiddleware.SQLInjectionProtection())
 	s.router.Use(s.securityMiddleware.XSSProtection())
 	s.router.Use(s.securityMiddleware.RequestSizeLimit(10 * 1024 * 1024)) // 10MB limit
+	s.router.Use(s.securityMiddleware.AuditLog())
 
 	// Health check endpoints
 	s.router.GET("/health", s.healthChecker.HealthCheckHandler())
 	s.router.GET("/health/detailed", s.healthChecker.DetailedHealthCheckHandler())
 	s.router.GET("/health/ready", s.healthChecker.ReadinessCheckHandler())
 	s.router.GET("/health/live", s.healthChecker.LivenessCheckHandler())
-	s.router.GET("/metrics", s.metricsHandler)
+	s.router.GET("/metrics", gin.WrapH(metrics.Handler(s.metricsRegistry)))
+	s.router.GET("/metrics/json", s.metricsHandler)
 	s.router.GET("/alerts", s.alertsHandler)
 	s.router.GET("/dashboard/business", s.businessDashboardHandler)
 
 	// Initialize JWT manager and middleware
 	jwtManager := auth.NewJWTManager()
-	authMiddleware := middleware.AuthMiddleware(jwtManager)
+	jwtManager.SetRevocationStore(auth.NewRevocationStore(s.redis))
+	jwtMiddleware := middleware.AuthMiddleware(jwtManager)
+
+	// Most authenticated routes accept either a bearer token or an X-API-Key;
+	// session and key management stay JWT-only so a leaked key cannot mint more keys
+	apiKeyMiddleware := s.securityMiddleware.ValidateAPIKey(models.NewAPIKeyRepository(s.db))
+	authMiddleware := middleware.AuthenticateAny(jwtMiddleware, apiKeyMiddleware)
+
+	// Cached GET routes say whose requests share a response and which query
+	// defaults their handlers apply, so equivalent URLs share an entry
+	cached := func(policy middleware.CachePolicy) gin.HandlerFunc {
+		return middleware.CacheMiddleware(s.advancedCache, cache.ShortTerm, s.router, policy)
+	}
+	public := middleware.CachePolicy{Vary: middleware.VaryPublic}
+	paged := middleware.CachePolicy{Vary: middleware.VaryPublic, Defaults: map[string]string{"limit": "20", "offset": "0"}}
+	perUser := middleware.CachePolicy{Vary: middleware.VaryUser}
+	withDefaults := func(defaults map[string]string) middleware.CachePolicy {
+		return middleware.CachePolicy{Vary: middleware.VaryPublic, Defaults: defaults}
+	}
 
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
@@ -106,48 +182,141 @@ func (s *Server) setupRoutes() {
 		// Health check for API
 		v1.GET("/health", s.healthChecker.HealthCheckHandler())
 
+		// OpenAPI document and the page that renders it
+		v1.GET("/openapi.json", s.openAPIHandler)
+		v1.GET("/docs", s.apiDocsHandler)
+
 		// Initialize handlers
-		authHandler := handlers.NewAuthHandler(s.db)
-		companyHandler := handlers.NewCompanyHandler(s.db)
-		esgHandler := handlers.NewESGHandler(s.db)
-		dashboardHandler := handlers.NewDashboardHandler(s.db)
-		financialHandler := handlers.NewFinancialHandler(s.db)
+		authHandler := handlers.NewAuthHandler(s.db, jwtManager, s.advancedCache)
+		adminHandler := handlers.NewAdminHandler(s.db, jwtManager, s.advancedCache)
+		companyHandler := handlers.NewCompanyHandler(s.db, s.events, s.advancedCache)
+		esgHandler := handlers.NewESGHandler(s.db, s.events, s.advancedCache)
+		dashboardHandler := handlers.NewDashboardHandler(s.db, s.advancedCache)
+		financialHandler := handlers.NewFinancialHandler(s.db, s.events, s.advancedCache)
 		analyticsHandler := handlers.NewAnalyticsHandler(s.db)
 		advancedAnalyticsHandler := handlers.NewAdvancedAnalyticsHandler(s.db)
+		portfolioHandler := handlers.NewPortfolioHandler(s.db)
+		watchlistHandler := handlers.NewWatchlistHandler(s.db)
+		apiKeyHandler := handlers.NewAPIKeyHandler(s.db)
+		webhookHandler := handlers.NewWebhookHandler(s.db, s.webhooks)
+		alertRuleHandler := handlers.NewAlertRuleHandler(s.db)
 
 		// Authentication routes (public)
-		auth := v1.Group("/auth")
+		authRoutes := v1.Group("/auth")
+		{
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/logout", jwtMiddleware, authHandler.Logout)
+			authRoutes.POST("/logout-all", jwtMiddleware, authHandler.LogoutAll)
+			authRoutes.GET("/profile", jwtMiddleware, cached(perUser), authHandler.GetProfile)
+			authRoutes.PUT("/profile", jwtMiddleware, authHandler.UpdateProfile)
+		}
+
+		// Saved portfolios, watchlists, webhooks and alert rules for the authenticated
+		// user; API keys need the account:write scope to change them
+		me := v1.Group("/me")
+		me.Use(authMiddleware, middleware.RequirePermissionForWrites(auth.PermWriteAccount))
+		{
+			me.GET("/portfolios", portfolioHandler.ListPortfolios)
+			me.POST("/portfolios", portfolioHandler.CreatePortfolio)
+			me.POST("/portfolios/optimize", portfolioHandler.SaveOptimizedPortfolio)
+			me.GET("/portfolios/:id", portfolioHandler.GetPortfolio)
+			me.PUT("/portfolios/:id", portfolioHandler.UpdatePortfolio)
+			me.DELETE("/portfolios/:id", portfolioHandler.DeletePortfolio)
+			me.GET("/portfolios/:id/valuation", portfolioHandler.GetValuation)
+			me.PUT("/portfolios/:id/holdings", portfolioHandler.ReplaceHoldings)
+			me.POST("/portfolios/:id/holdings", portfolioHandler.AddHolding)
+			me.DELETE("/portfolios/:id/holdings/:symbol", portfolioHandler.RemoveHolding)
+
+			me.GET("/watchlists", watchlistHandler.ListWatchlists)
+			me.POST("/watchlists", watchlistHandler.CreateWatchlist)
+			me.GET("/watchlists/:id", watchlistHandler.GetWatchlist)
+			me.PUT("/watchlists/:id", watchlistHandler.RenameWatchlist)
+			me.DELETE("/watchlists/:id", watchlistHandler.DeleteWatchlist)
+			me.POST("/watchlists/:id/items", watchlistHandler.AddItem)
+			me.DELETE("/watchlists/:id/items/:symbol", watchlistHandler.RemoveItem)
+
+			me.GET("/webhooks", webhookHandler.ListWebhooks)
+			me.POST("/webhooks", webhookHandler.CreateWebhook)
+			me.GET("/webhooks/:id", webhookHandler.GetWebhook)
+			me.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+			me.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			me.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+			me.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
+			me.GET("/alert-rules", alertRuleHandler.ListAlertRules)
+			me.POST("/alert-rules", alertRuleHandler.CreateAlertRule)
+			me.GET("/alert-rules/:id", alertRuleHandler.GetAlertRule)
+			me.PUT("/alert-rules/:id", alertRuleHandler.UpdateAlertRule)
+			me.DELETE("/alert-rules/:id", alertRuleHandler.DeleteAlertRule)
+			me.GET("/alert-notifications", alertRuleHandler.ListAlertNotifications)
+		}
+
+		// API key management for the authenticated user (JWT only)
+		apiKeys := v1.Group("/me/api-keys")
+		apiKeys.Use(jwtMiddleware)
+		{
+			apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+			apiKeys.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
+		// User administration (admin only)
+		admin := v1.Group("/admin")
+		admin.Use(authMiddleware, middleware.RequirePermission(auth.PermManageUsers))
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.GET("/profile", authMiddleware, authHandler.GetProfile)
-			auth.PUT("/profile", authMiddleware, authHandler.UpdateProfile)
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.POST("/users/:id/roles", adminHandler.GrantRole)
+			admin.DELETE("/users/:id/roles/:role", adminHandler.RevokeRole)
 		}
 
-		// Company routes (public for now, can be protected later)
+		// Runtime settings, monitoring and cache purges (admin only)
+		system := v1.Group("/admin")
+		system.Use(authMiddleware, middleware.RequirePermission(auth.PermManageSystem))
+		{
+			system.GET("/log-level", s.logLevelHandler)
+			system.PUT("/log-level", s.setLogLevelHandler)
+
+			system.GET("/monitoring/rules", s.alertRulesHandler)
+			system.GET("/monitoring/alerts", s.alertHistoryHandler)
+			system.GET("/monitoring/alerts/:id/transitions", s.alertTransitionsHandler)
+			system.POST("/monitoring/alerts/:id/acknowledge", s.acknowledgeAlertHandler)
+			system.POST("/monitoring/alerts/:id/silence", s.silenceAlertHandler)
+			system.POST("/monitoring/alerts/:id/resolve", s.resolveAlertHandler)
+
+			system.POST("/cache/purge", s.purgeCacheHandler)
+		}
+
+		// Company routes (public reads, writes require companies:write)
 		companies := v1.Group("/companies")
 		companies.Use(rateLimiter.RateLimitMiddleware(100)) // 100 requests per minute
-		companies.Use(cacheMiddleware)
 		{
-			companies.POST("", companyHandler.CreateCompany)
-			companies.GET("", companyHandler.ListCompanies)
-			companies.GET("/sectors", companyHandler.GetSectors)
-			companies.GET("/symbol/:symbol", companyHandler.GetCompanyBySymbol)
-			companies.GET("/:id", companyHandler.GetCompany)
-			companies.PUT("/:id", companyHandler.UpdateCompany)
-			companies.DELETE("/:id", companyHandler.DeleteCompany)
+			companies.GET("", cached(paged), companyHandler.ListCompanies)
+			companies.GET("/sectors", cached(public), companyHandler.GetSectors)
+			companies.GET("/symbol/:symbol", cached(public), companyHandler.GetCompanyBySymbol)
+			companies.GET("/:id", cached(public), companyHandler.GetCompany)
+
+			companyWrites := companies.Group("", authMiddleware, middleware.RequirePermission(auth.PermWriteCompanies))
+			companyWrites.POST("", companyHandler.CreateCompany)
+			companyWrites.PUT("/:id", companyHandler.UpdateCompany)
+			companyWrites.DELETE("/:id", companyHandler.DeleteCompany)
 		}
 
-		// ESG routes (public for now, can be protected later)
+		// ESG routes (public reads, writes require esg:write)
 		esg := v1.Group("/esg")
+		esg.Use(middleware.CacheTags(cache.TagESG))
 		{
-			esg.POST("/scores", esgHandler.CreateESGScore)
-			esg.GET("/scores", esgHandler.ListESGScores)
-			esg.GET("/scores/:id", esgHandler.GetESGScore)
-			esg.PUT("/scores/:id", esgHandler.UpdateESGScore)
-			esg.DELETE("/scores/:id", esgHandler.DeleteESGScore)
-			esg.GET("/companies/:id/latest", esgHandler.GetLatestESGScoreByCompany)
-			esg.GET("/companies/:id/scores", esgHandler.GetESGScoresByCompany)
+			esg.GET("/scores", cached(withDefaults(map[string]string{"limit": "20", "offset": "0", "min_score": "0"})), esgHandler.ListESGScores)
+			esg.GET("/scores/:id", cached(public), esgHandler.GetESGScore)
+			esg.GET("/companies/:id/latest", cached(public), esgHandler.GetLatestESGScoreByCompany)
+			esg.GET("/companies/:id/scores", cached(paged), esgHandler.GetESGScoresByCompany)
+
+			esgWrites := esg.Group("", authMiddleware, middleware.RequirePermission(auth.PermWriteESG))
+			esgWrites.POST("/scores", esgHandler.CreateESGScore)
+			esgWrites.PUT("/scores/:id", esgHandler.UpdateESGScore)
+			esgWrites.DELETE("/scores/:id", esgHandler.DeleteESGScore)
 		}
 
 		// Dashboard route
@@ -162,53 +331,57 @@ func (s *Server) setupRoutes() {
 			financial.GET("/companies/:id/summary", financialHandler.GetCompanyFinancialSummary)
 			financial.GET("/market", financialHandler.GetMarketData)
 			financial.GET("/market/history", financialHandler.GetMarketDataHistory)
+
+			// Bulk CSV/NDJSON ingestion (requires financial:import)
+			imports := financial.Group("/import", authMiddleware, middleware.RequirePermission(auth.PermImportFinancial))
+			imports.POST("/stock-prices", financialHandler.ImportStockPrices)
+			imports.POST("/indicators", financialHandler.ImportFinancialIndicators)
+			imports.POST("/market-data", financialHandler.ImportMarketData)
 		}
 
 		// Analytics routes (public for now, can be protected later)
 		analytics := v1.Group("/analytics")
 		analytics.Use(rateLimiter.RateLimitMiddleware(50)) // 50 requests per minute for analytics
-		analytics.Use(cacheMiddleware)
+		analytics.Use(middleware.CacheTags(cache.TagAnalytics))
 		{
-			analytics.GET("/companies/:id/esg-trends", analyticsHandler.GetESGTrends)
-			analytics.GET("/sectors/comparisons", analyticsHandler.GetSectorComparisons)
-			analytics.GET("/financial/comparisons", analyticsHandler.GetFinancialComparisons)
-			analytics.GET("/top-performers/:metric", analyticsHandler.GetTopPerformers)
-			analytics.GET("/correlation/esg-financial", analyticsHandler.GetESGvsFinancialCorrelation)
-			analytics.GET("/summary", analyticsHandler.GetAnalyticsSummary)
+			analytics.GET("/companies/:id/esg-trends", cached(withDefaults(map[string]string{"days": "30"})), analyticsHandler.GetESGTrends)
+			analytics.GET("/sectors/comparisons", cached(public), analyticsHandler.GetSectorComparisons)
+			analytics.GET("/financial/comparisons", cached(withDefaults(map[string]string{"limit": "10"})), analyticsHandler.GetFinancialComparisons)
+			analytics.GET("/top-performers/:metric", cached(withDefaults(map[string]string{"limit": "10"})), analyticsHandler.GetTopPerformers)
+			analytics.GET("/correlation/esg-financial", cached(public), analyticsHandler.GetESGvsFinancialCorrelation)
+			analytics.GET("/summary", cached(public), analyticsHandler.GetAnalyticsSummary)
 		}
 
 		// Advanced Analytics routes (public for now, can be protected later)
 		advanced := v1.Group("/advanced")
 		advanced.Use(rateLimiter.RateLimitMiddleware(30)) // 30 requests per minute for advanced analytics
-		advanced.Use(cacheMiddleware)
+		advanced.Use(middleware.CacheTags(cache.TagAnalytics))
 		{
-			advanced.GET("/companies/:id/predict-esg", advancedAnalyticsHandler.PredictESGScore)
-			advanced.GET("/portfolio/optimize", advancedAnalyticsHandler.OptimizePortfolio)
-			advanced.GET("/companies/:id/risk-assessment", advancedAnalyticsHandler.AssessRisk)
-			advanced.GET("/companies/:id/trends/:metric", advancedAnalyticsHandler.AnalyzeTrend)
-			advanced.GET("/summary", advancedAnalyticsHandler.GetAdvancedAnalyticsSummary)
+			betaDefaults := withDefaults(map[string]string{"benchmark": "sp500", "lookback_days": "365"})
+			advanced.GET("/companies/:id/predict-esg", cached(public), advancedAnalyticsHandler.PredictESGScore)
+			advanced.GET("/portfolio/optimize", cached(withDefaults(map[string]string{
+				"target_return": "0.10", "risk_tolerance": "medium", "max_companies": "10", "max_sector_weight": "1",
+				"min_esg_score": "0", "allow_short": "false", "lookback_days": "365",
+			})), advancedAnalyticsHandler.OptimizePortfolio)
+			advanced.GET("/companies/:id/risk-assessment", cached(betaDefaults), advancedAnalyticsHandler.AssessRisk)
+			advanced.GET("/companies/:id/beta", cached(betaDefaults), advancedAnalyticsHandler.EstimateBeta)
+			advanced.GET("/companies/:id/trends/:metric", cached(withDefaults(map[string]string{"period": "30d"})), advancedAnalyticsHandler.AnalyzeTrend)
+			advanced.GET("/summary", cached(public), advancedAnalyticsHandler.GetAdvancedAnalyticsSummary)
+			advanced.POST("/backtest", authMiddleware, middleware.RequirePermission(auth.PermRunBacktests), advancedAnalyticsHandler.RunBacktest)
 		}
 
 		// WebSocket routes
-		wsHandler := handlers.NewWebSocketHandler(s.wsManager)
+		wsHandler := handlers.NewWebSocketHandler(s.wsManager, jwtManager, s.securityMiddleware)
 		v1.GET("/ws", wsHandler.HandleWebSocket)
 		v1.GET("/ws/status", wsHandler.GetWebSocketStatus)
-	}
-}
-
-// startBackgroundServices starts background services
-func (s *Server) startBackgroundServices() {
-	// Start cache warming (every 30 minutes)
-	s.cacheWarmer.StartCacheWarming(30 * time.Minute)
 
-	// Start metrics collection (every 5 minutes)
-	s.metricsCollector.StartMetricsCollection(5 * time.Minute)
-	
-	// Start performance monitoring and alerting (every 1 minute)
-	s.alertManager.StartMonitoring(1 * time.Minute)
+		// Versioned JSON schemas for WebSocket and webhook event payloads
+		v1.GET("/events/schemas", s.eventSchemasHandler)
+		v1.GET("/events/schemas/:type/:version", s.eventSchemaHandler)
+	}
 }
 
-// metricsHandler handles metrics requests
+// metricsHandler serves the collected metrics snapshot as JSON
 func (s *Server) metricsHandler(c *gin.Context) {
 	metrics := s.metricsCollector.GetMetrics()
 	c.JSON(http.StatusOK, metrics)
@@ -246,11 +419,3 @@ func (s *Server) businessDashboardHandler(c *gin.Context) {
 	
 	c.JSON(http.StatusOK, dashboard)
 }
-
-// Run starts the HTTP server
-func (s *Server) Run(addr string) error {
-	// Start WebSocket manager in a goroutine
-	go s.wsManager.Start()
-
-	return s.router.Run(addr)
-}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"ethosview-backend/pkg/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newTracerProvider creates the tracer provider configured by TRACING_EXPORTER
+// and the OTEL_EXPORTER_OTLP_* variables. A bad configuration is logged and
+// tracing continues without export, so request and trace IDs still work.
+func newTracerProvider(logger *slog.Logger) *sdktrace.TracerProvider {
+	exporter, err := tracing.ExporterFromEnv(context.Background())
+	if err != nil {
+		logger.Error("Tracing export disabled", "error", err)
+		return tracing.NewProvider(nil)
+	}
+	if exporter == nil {
+		logger.Info("No trace exporter configured; set OTEL_EXPORTER_OTLP_ENDPOINT or TRACING_EXPORTER=stdout|file to export traces")
+	}
+	return tracing.NewProvider(exporter)
+}
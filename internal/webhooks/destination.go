@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination is returned for webhook URLs that resolve to
+// loopback, private, link-local or otherwise internal addresses
+var ErrForbiddenDestination = errors.New("webhook destination must be a public address")
+
+// internalPrefixes are ranges that IsPrivate, IsLoopback and friends do not
+// cover but that must not be reachable from webhooks either
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can embed internal IPv4 addresses
+	netip.MustParsePrefix("2002::/16"),     // 6to4, which embeds an IPv4 address
+	netip.MustParsePrefix("2001::/32"),     // Teredo, which embeds an IPv4 address
+}
+
+// IsPublicAddr reports whether webhooks may connect to addr
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL requires an absolute http or https URL without credentials
+// whose host resolves only to public addresses. Deliveries check the address
+// again when connecting, so a host re-pointed at an internal address later is
+// still refused.
+func ValidateURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("url must not contain credentials")
+	}
+
+	host := parsed.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(addr) {
+			return ErrForbiddenDestination
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("url host %s could not be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return ErrForbiddenDestination
+		}
+	}
+	return nil
+}
+
+// newHTTPClient creates the delivery client. It refuses connections to
+// internal addresses after DNS resolution, so rebinding a validated host does
+// not reach them, ignores proxy settings and does not follow redirects; a
+// redirect is recorded as a failed delivery.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: requestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddr(addrPort.Addr()) {
+				return ErrForbiddenDestination
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
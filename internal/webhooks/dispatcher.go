@@ -0,0 +1,375 @@
+// Package webhooks delivers domain events to user-registered HTTP endpoints.
+// Events describing database writes are recorded in an outbox in the write's
+// transaction and relayed from there, so none is lost between the commit and
+// the first attempt. Every delivery is logged in Postgres and scheduled in a
+// Redis sorted set, so pending retries survive restarts and are shared by all
+// server instances.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/logging"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	queueKey = "ethosview:webhooks:queue"
+
+	// MaxAttempts is how many times a delivery is tried before it is dead-lettered
+	MaxAttempts = 8
+
+	baseBackoff     = 30 * time.Second
+	maxBackoff      = 6 * time.Hour
+	requestTimeout  = 10 * time.Second
+	claimLease      = time.Minute
+	pollInterval    = time.Second
+	rescueInterval  = time.Minute
+	pollBatchSize   = 20
+	relayBatchSize  = 100
+	rescueBatchSize = 500
+	maxInFlight     = 8
+	maxErrorLength  = 500
+)
+
+// EventTypes are the event types endpoints may subscribe to
+var EventTypes = []string{
+	events.TypeESGScoreCreated,
+	events.TypeESGScoreUpdated,
+	events.TypeCompanyCreated,
+	events.TypeCompanyDeleted,
+	events.TypeAlertRaised,
+	events.TypeAlertResolved,
+	events.TypeAlertRuleTriggered,
+}
+
+// IsEventType reports whether endpoints may subscribe to an event type
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdminEventType reports whether an event type describes system operations
+// and may only be subscribed to by administrators
+func IsAdminEventType(eventType string) bool {
+	return eventType == events.TypeAlertRaised || eventType == events.TypeAlertResolved
+}
+
+// IsOutboxEventType reports whether an event type describes a database write
+// and is relayed from the event outbox rather than from the bus
+func IsOutboxEventType(eventType string) bool {
+	switch eventType {
+	case events.TypeESGScoreCreated, events.TypeESGScoreUpdated, events.TypeCompanyCreated, events.TypeCompanyDeleted:
+		return true
+	}
+	return false
+}
+
+// IsUserEventType reports whether an event type concerns a single user and is
+// delivered with DeliverToUser rather than fanned out from the bus
+func IsUserEventType(eventType string) bool {
+	return eventType == events.TypeAlertRuleTriggered
+}
+
+// Backoff returns the delay before the next attempt after a delivery has
+// failed attempts times: 30s doubling per failure, capped at 6h
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// Store persists webhook deliveries; *models.WebhookRepository implements it
+type Store interface {
+	CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) ([]int, error)
+	CreateUserDeliveries(ctx context.Context, userID int, eventID, eventType string, payload []byte) ([]int, error)
+	RelayOutbox(ctx context.Context, limit int) ([]int, error)
+	ClaimAttempt(ctx context.Context, deliveryID int, lease time.Duration) (*models.WebhookAttempt, error)
+	RecordSuccess(ctx context.Context, deliveryID, statusCode int) error
+	RecordFailure(ctx context.Context, deliveryID int, statusCode *int, message string, nextAttempt time.Time) error
+	PendingDeliveries(ctx context.Context, before time.Time, limit int) (map[int]time.Time, error)
+}
+
+// Dispatcher records deliveries for published events and sends them
+type Dispatcher struct {
+	store  Store
+	redis  *redis.Client
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewDispatcher creates a webhook dispatcher; call Run to start delivering. A
+// nil logger uses the default.
+func NewDispatcher(store Store, client *redis.Client, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		redis:  client,
+		client: newHTTPClient(),
+		logger: logging.Or(logger).With("component", "webhooks"),
+	}
+}
+
+// Subscribe records a delivery of the webhook event types published on the
+// bus and not relayed from the outbox to each subscribed endpoint
+func (d *Dispatcher) Subscribe(bus *events.Bus) {
+	bus.Subscribe("*", d.handleEvent)
+}
+
+// DeliverToUser records and queues deliveries of an event to one user's
+// endpoints subscribed to its type
+func (d *Dispatcher) DeliverToUser(ctx context.Context, userID int, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ids, err := d.store.CreateUserDeliveries(ctx, userID, event.ID, event.Type, payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		d.Enqueue(ctx, id, now)
+	}
+	return nil
+}
+
+// Enqueue schedules a pending delivery. Failures are logged; the delivery is
+// still picked up from Postgres by the periodic rescue.
+func (d *Dispatcher) Enqueue(ctx context.Context, deliveryID int, at time.Time) {
+	if d == nil {
+		return
+	}
+	member := redis.Z{Score: float64(at.Unix()), Member: strconv.Itoa(deliveryID)}
+	if err := d.redis.ZAdd(ctx, queueKey, member).Err(); err != nil {
+		d.logger.Warn("Error enqueuing webhook delivery", "delivery_id", deliveryID, "error", err)
+	}
+}
+
+// Run delivers due webhooks until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	rescue := time.NewTicker(rescueInterval)
+	defer rescue.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	slots := make(chan struct{}, maxInFlight)
+
+	d.rescue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rescue.C:
+			d.rescue(ctx)
+		case <-poll.C:
+			d.relayOutbox(ctx)
+			for _, id := range d.due(ctx) {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+					defer func() { <-slots }()
+					d.attempt(id)
+				}(id)
+			}
+		}
+	}
+}
+
+// handleEvent fans an event out to the endpoints subscribed to its type
+func (d *Dispatcher) handleEvent(event events.Event) {
+	if !IsEventType(event.Type) || IsUserEventType(event.Type) || IsOutboxEventType(event.Type) {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Error("Error marshaling event for webhooks", "event_type", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+
+	ctx := context.Background()
+	ids, err := d.store.CreateDeliveries(ctx, event.ID, event.Type, payload)
+	if err != nil {
+		d.logger.Error("Error recording webhook deliveries", "event_type", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		d.Enqueue(ctx, id, now)
+	}
+}
+
+// relayOutbox creates and queues the deliveries of the oldest events recorded
+// in the outbox
+func (d *Dispatcher) relayOutbox(ctx context.Context) {
+	ids, err := d.store.RelayOutbox(ctx, relayBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("Error relaying webhook events from the outbox", "error", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		d.Enqueue(ctx, id, now)
+	}
+}
+
+// due claims the deliveries whose attempt time has passed. Removing a member
+// from the queue is the claim, so each is handed to one instance only.
+func (d *Dispatcher) due(ctx context.Context) []int {
+	members, err := d.redis.ZRangeByScore(ctx, queueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().Unix(), 10),
+		Count: pollBatchSize,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Warn("Error reading webhook queue", "error", err)
+		}
+		return nil
+	}
+
+	var ids []int
+	for _, member := range members {
+		removed, err := d.redis.ZRem(ctx, queueKey, member).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		id, err := strconv.Atoi(member)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// rescue re-queues overdue pending deliveries from Postgres, covering Redis
+// data loss, failed enqueues and workers that died mid-attempt
+func (d *Dispatcher) rescue(ctx context.Context) {
+	due, err := d.store.PendingDeliveries(ctx, time.Now(), rescueBatchSize)
+	if err != nil {
+		d.logger.Error("Error loading pending webhook deliveries", "error", err)
+		return
+	}
+	for id, at := range due {
+		member := redis.Z{Score: float64(at.Unix()), Member: strconv.Itoa(id)}
+		if err := d.redis.ZAddNX(ctx, queueKey, member).Err(); err != nil {
+			d.logger.Warn("Error re-queuing webhook delivery", "delivery_id", id, "error", err)
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome. It runs to completion
+// even when the worker is stopping, so an outcome is never lost mid-attempt.
+func (d *Dispatcher) attempt(deliveryID int) {
+	ctx := context.Background()
+	attempt, err := d.store.ClaimAttempt(ctx, deliveryID, claimLease)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		d.logger.Error("Error claiming webhook delivery", "delivery_id", deliveryID, "error", err)
+		return
+	}
+
+	statusCode, err := d.send(attempt)
+	if err == nil {
+		if err := d.store.RecordSuccess(ctx, deliveryID, statusCode); err != nil {
+			d.logger.Error("Error recording webhook delivery success", "delivery_id", deliveryID, "error", err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var next time.Time
+	if attempts := attempt.Attempts + 1; attempts < MaxAttempts {
+		next = time.Now().Add(Backoff(attempts))
+	} else {
+		d.logger.Warn("Webhook delivery dead-lettered", "delivery_id", deliveryID, "event_type", attempt.EventType, "attempts", attempts, "error", err)
+	}
+
+	if err := d.store.RecordFailure(ctx, deliveryID, code, truncate(err.Error(), maxErrorLength), next); err != nil {
+		d.logger.Error("Error recording webhook delivery failure", "delivery_id", deliveryID, "error", err)
+		return
+	}
+	if !next.IsZero() {
+		d.Enqueue(ctx, deliveryID, next)
+	}
+}
+
+// send posts a signed delivery, returning the response status. Any status
+// outside 2xx is an error.
+func (d *Dispatcher) send(attempt *models.WebhookAttempt) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, attempt.URL, bytes.NewReader(attempt.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EthosView-Webhooks/1.0")
+	req.Header.Set(EventHeader, attempt.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(attempt.DeliveryID))
+	req.Header.Set(SignatureHeader, Sign(attempt.Secret, time.Now(), attempt.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ethosview-backend/internal/events"
+	"ethosview-backend/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubStore records what the dispatcher asks of the delivery log
+type stubStore struct {
+	created   []string
+	attempt   *models.WebhookAttempt
+	succeeded int
+	failed    int
+	code      *int
+	next      time.Time
+}
+
+func (s *stubStore) CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) ([]int, error) {
+	s.created = append(s.created, eventType)
+	return nil, nil
+}
+
+func (s *stubStore) CreateUserDeliveries(ctx context.Context, userID int, eventID, eventType string, payload []byte) ([]int, error) {
+	s.created = append(s.created, fmt.Sprintf("%s for user %d", eventType, userID))
+	return nil, nil
+}
+
+func (s *stubStore) RelayOutbox(ctx context.Context, limit int) ([]int, error) {
+	return nil, nil
+}
+
+func (s *stubStore) ClaimAttempt(ctx context.Context, deliveryID int, lease time.Duration) (*models.WebhookAttempt, error) {
+	if s.attempt == nil {
+		return nil, sql.ErrNoRows
+	}
+	return s.attempt, nil
+}
+
+func (s *stubStore) RecordSuccess(ctx context.Context, deliveryID, statusCode int) error {
+	s.succeeded = statusCode
+	return nil
+}
+
+func (s *stubStore) RecordFailure(ctx context.Context, deliveryID int, statusCode *int, message string, nextAttempt time.Time) error {
+	s.failed++
+	s.code = statusCode
+	s.next = nextAttempt
+	return nil
+}
+
+func (s *stubStore) PendingDeliveries(ctx context.Context, before time.Time, limit int) (map[int]time.Time, error) {
+	return nil, nil
+}
+
+// newTestDispatcher returns a dispatcher whose Redis is unreachable, so
+// re-queues fail fast and are logged. Its client may reach the loopback test
+// servers the production client refuses.
+func newTestDispatcher(store Store) *Dispatcher {
+	d := NewDispatcher(store, redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1}), nil)
+	d.client = &http.Client{Timeout: requestTimeout}
+	return d
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 8*time.Minute, Backoff(5))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+	assert.Equal(t, 30*time.Second, Backoff(0))
+}
+
+func TestHandleEventSkipsTypesRelayedFromTheOutbox(t *testing.T) {
+	store := &stubStore{}
+	d := newTestDispatcher(store)
+
+	d.handleEvent(events.Event{ID: "1", Type: events.TypePriceTick})
+	d.handleEvent(events.Event{ID: "2", Type: events.TypeCompanyCreated})
+	d.handleEvent(events.Event{ID: "3", Type: events.TypeAlertRaised})
+	d.handleEvent(events.Event{ID: "6", Type: events.TypeESGScoreUpdated})
+	d.handleEvent(events.Event{ID: "4", Type: events.TypeAlertRuleTriggered})
+	require.NoError(t, d.DeliverToUser(context.Background(), 7, events.Event{ID: "5", Type: events.TypeAlertRuleTriggered}))
+
+	assert.Equal(t, []string{
+		events.TypeAlertRaised,
+		events.TypeAlertRuleTriggered + " for user 7",
+	}, store.created)
+}
+
+func TestAttemptSendsSignedDelivery(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"id":"evt","type":"company.deleted"}`)
+	store := &stubStore{attempt: &models.WebhookAttempt{
+		DeliveryID: 12,
+		EventType:  events.TypeCompanyDeleted,
+		Payload:    payload,
+		URL:        server.URL,
+		Secret:     "whsec_test",
+	}}
+	newTestDispatcher(store).attempt(12)
+
+	require.NotNil(t, received)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, events.TypeCompanyDeleted, received.Header.Get(EventHeader))
+	assert.Equal(t, "12", received.Header.Get(DeliveryHeader))
+	assert.NoError(t, Verify("whsec_test", received.Header.Get(SignatureHeader), body, time.Minute, time.Now()))
+	assert.Equal(t, http.StatusNoContent, store.succeeded)
+	assert.Zero(t, store.failed)
+}
+
+func TestAttemptSchedulesRetryThenDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := &stubStore{attempt: &models.WebhookAttempt{DeliveryID: 3, Payload: []byte(`{}`), URL: server.URL, Attempts: 2}}
+	d := newTestDispatcher(store)
+
+	d.attempt(3)
+	require.Equal(t, 1, store.failed)
+	require.NotNil(t, store.code)
+	assert.Equal(t, http.StatusServiceUnavailable, *store.code)
+	assert.WithinDuration(t, time.Now().Add(Backoff(3)), store.next, 5*time.Second)
+
+	store.attempt.Attempts = MaxAttempts - 1
+	d.attempt(3)
+	assert.Equal(t, 2, store.failed)
+	assert.True(t, store.next.IsZero())
+}
+
+func TestAttemptSkipsUnclaimedDeliveries(t *testing.T) {
+	store := &stubStore{}
+	newTestDispatcher(store).attempt(5)
+	assert.Zero(t, store.succeeded)
+	assert.Zero(t, store.failed)
+}
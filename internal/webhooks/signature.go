@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery
+const (
+	SignatureHeader = "X-EthosView-Signature"
+	EventHeader     = "X-EthosView-Event"
+	DeliveryHeader  = "X-EthosView-Delivery"
+)
+
+// secretPrefix marks webhook signing secrets so they are recognisable if leaked
+const secretPrefix = "whsec_"
+
+// ErrInvalidSignature is returned when a signature header does not verify
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// NewSecret generates a random signing secret for an endpoint
+func NewSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(bytes), nil
+}
+
+// Sign returns the signature header value for a body sent at a time, in the
+// form "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Including the
+// timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + computeSignature(secret, t, body)
+}
+
+// Verify checks a signature header against a body, rejecting signatures older
+// than tolerance. A zero tolerance skips the age check.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+		}
+	}
+
+	expected := computeSignature(secret, t, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// computeSignature returns the hex HMAC-SHA256 of "<t>.<body>"
+func computeSignature(secret, t string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
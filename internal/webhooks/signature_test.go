@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"abc","type":"company.created"}`)
+	header := Sign("whsec_test", now, body)
+
+	assert.True(t, strings.HasPrefix(header, "t=1700000000,v1="))
+	assert.NoError(t, Verify("whsec_test", header, body, 5*time.Minute, now.Add(time.Minute)))
+
+	// A receiver rotating secrets may see several v1 signatures
+	assert.NoError(t, Verify("whsec_test", header+",v1=deadbeef", body, 0, now))
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		{"wrong secret", "whsec_other", header, body, now},
+		{"tampered body", "whsec_test", header, []byte(`{"id":"abd"}`), now},
+		{"stale timestamp", "whsec_test", header, body, now.Add(time.Hour)},
+		{"missing signature", "whsec_test", "t=1700000000", body, now},
+		{"missing timestamp", "whsec_test", strings.SplitN(header, ",", 2)[1], body, now},
+		{"garbage", "whsec_test", "nonsense", body, now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now), ErrInvalidSignature)
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	require.NoError(t, err)
+	second, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.Len(t, first, len("whsec_")+64)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// authTimeout bounds validation of an auth frame
+const authTimeout = 5 * time.Second
+
+// TokenValidator validates an access token and returns its user and expiry
+type TokenValidator func(ctx context.Context, token string) (userID int, expiresAt time.Time, err error)
+
+// AuthAck acknowledges a successful auth frame
+type AuthAck struct {
+	ID        string    `json:"id,omitempty"`
+	Action    string    `json:"action"`
+	UserID    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// closeFrame is a close code and reason written by WritePump before it exits
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+// NewClient creates a client for an upgraded connection. validate checks auth
+// frames sent over the socket; it may be nil to disable them.
+func NewClient(id string, conn *websocket.Conn, manager *Manager, validate TokenValidator) *Client {
+	return &Client{
+		ID:            id,
+		Conn:          conn,
+		Send:          make(chan []byte, 256),
+		Manager:       manager,
+		validate:      validate,
+		expiries:      make(chan time.Time, 1),
+		closeRequests: make(chan closeFrame, 1),
+	}
+}
+
+// Authenticate attaches a user to a client that authenticated during the
+// handshake. The connection is closed when the token expires unless the client
+// sends a fresh token in an auth frame first. Call it before registering.
+func (c *Client) Authenticate(userID int, expiresAt time.Time) {
+	c.UserID = &userID
+	c.scheduleExpiry(expiresAt)
+}
+
+// handleAuth validates an auth frame. Unauthenticated clients become
+// authenticated; authenticated clients extend their session, but may not
+// switch to a different user. A failed first authentication closes the socket.
+func (c *Client) handleAuth(msg ClientMessage) {
+	if c.validate == nil {
+		c.sendError(msg.ID, "Authentication is not supported on this connection")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), authTimeout)
+	defer cancel()
+
+	userID, expiresAt, err := c.validate(ctx, msg.Token)
+	current := c.Manager.userOf(c)
+	if err != nil {
+		if current == nil {
+			c.requestClose(closeFrame{code: websocket.ClosePolicyViolation, reason: "authentication failed"})
+			return
+		}
+		c.sendError(msg.ID, "Invalid or expired token")
+		return
+	}
+	if current != nil && *current != userID {
+		c.sendError(msg.ID, "Token belongs to a different user")
+		return
+	}
+
+	c.Manager.setUser(c, userID)
+	c.scheduleExpiry(expiresAt)
+	c.Manager.logger.Debug("WebSocket client authenticated", "client_id", c.ID, "user_id", userID)
+
+	c.Manager.Send(c, Message{
+		Type: "ack",
+		Data: AuthAck{ID: msg.ID, Action: "auth", UserID: userID, ExpiresAt: expiresAt},
+		Time: time.Now(),
+	})
+}
+
+// sendError replies to a client request with an error message
+func (c *Client) sendError(id, message string) {
+	c.Manager.Send(c, Message{
+		Type: "error",
+		Data: map[string]string{"id": id, "error": message},
+		Time: time.Now(),
+	})
+}
+
+// scheduleExpiry hands the latest token expiry to WritePump, replacing any pending one
+func (c *Client) scheduleExpiry(expiresAt time.Time) {
+	if c.expiries == nil {
+		return
+	}
+	select {
+	case <-c.expiries:
+	default:
+	}
+	c.expiries <- expiresAt
+}
+
+// requestClose asks WritePump to close the connection with a close frame
+func (c *Client) requestClose(frame closeFrame) {
+	if c.closeRequests == nil {
+		return
+	}
+	select {
+	case c.closeRequests <- frame:
+	default:
+	}
+}
+
+// writeClose sends a close frame; the caller must be the connection's writer
+func (c *Client) writeClose(frame closeFrame) {
+	c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(frame.code, frame.reason))
+}
+
+// userOf returns the user a client is authenticated as, or nil
+func (m *Manager) userOf(client *Client) *int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return client.UserID
+}
+
+// setUser records the user a client authenticated as
+func (m *Manager) setUser(client *Client, userID int) {
+	m.mu.Lock()
+	client.UserID = &userID
+	m.mu.Unlock()
+}
@@ -0,0 +1,190 @@
+package websocket
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"ethosview-backend/pkg/logging"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	backplaneChannel     = "ethosview:ws:broadcast"
+	instanceKeyPrefix    = "ethosview:ws:instance:"
+	heartbeatInterval    = 10 * time.Second
+	instanceTTL          = 3 * heartbeatInterval
+	backplaneSendTimeout = 2 * time.Second
+)
+
+// Envelope kinds relayed between instances
+const (
+	kindBroadcast = "broadcast"
+	kindUser      = "user"
+	kindTopic     = "topic"
+)
+
+// envelope wraps an encoded message relayed between instances. InstanceID
+// identifies the sender so it does not deliver its own messages twice.
+type envelope struct {
+	InstanceID string          `json:"instance_id"`
+	Kind       string          `json:"kind"`
+	UserID     int             `json:"user_id,omitempty"`
+	Topic      string          `json:"topic,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// ClusterStatus reports WebSocket connections across all instances
+type ClusterStatus struct {
+	InstanceID       string `json:"instance_id,omitempty"`
+	Instances        int    `json:"instances"`
+	ClientCount      int    `json:"client_count"`
+	LocalClientCount int    `json:"local_client_count"`
+}
+
+// Backplane fans WebSocket messages out to every server instance over Redis
+// pub/sub and tracks each instance's connection count for cluster-wide status.
+type Backplane struct {
+	redis      *redis.Client
+	instanceID string
+	manager    *Manager
+	logger     *slog.Logger
+}
+
+// NewBackplane creates a Redis backplane with a unique instance ID. A nil
+// logger uses the default.
+func NewBackplane(client *redis.Client, logger *slog.Logger) *Backplane {
+	instanceID := newInstanceID()
+	return &Backplane{
+		redis:      client,
+		instanceID: instanceID,
+		logger:     logging.Or(logger).With("component", "websocket_backplane", "instance_id", instanceID),
+	}
+}
+
+// InstanceID returns the ID this instance stamps on relayed messages
+func (b *Backplane) InstanceID() string {
+	return b.instanceID
+}
+
+// Run relays messages published by other instances to local clients and
+// heartbeats this instance's connection count until ctx is cancelled.
+func (b *Backplane) Run(ctx context.Context) {
+	pubsub := b.redis.Subscribe(ctx, backplaneChannel)
+	defer pubsub.Close()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	defer b.redis.Del(context.Background(), instanceKeyPrefix+b.instanceID)
+
+	b.heartbeat(ctx)
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.heartbeat(ctx)
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			b.handle([]byte(msg.Payload))
+		}
+	}
+}
+
+// ClusterStatus sums the connection counts heartbeated by live instances
+func (b *Backplane) ClusterStatus(ctx context.Context) (ClusterStatus, error) {
+	status := ClusterStatus{InstanceID: b.instanceID, LocalClientCount: b.manager.GetClientCount()}
+
+	iter := b.redis.Scan(ctx, 0, instanceKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if iter.Val() == instanceKeyPrefix+b.instanceID {
+			continue
+		}
+		count, err := b.redis.Get(ctx, iter.Val()).Int()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return status, err
+		}
+		status.Instances++
+		status.ClientCount += count
+	}
+	if err := iter.Err(); err != nil {
+		return status, err
+	}
+
+	// Count this instance live rather than from its last heartbeat
+	status.Instances++
+	status.ClientCount += status.LocalClientCount
+	return status, nil
+}
+
+// publish relays a message to other instances. Failures are logged rather than
+// returned so local delivery is unaffected when Redis is unavailable.
+func (b *Backplane) publish(env envelope) {
+	env.InstanceID = b.instanceID
+	data, err := json.Marshal(env)
+	if err != nil {
+		b.logger.Error("Error marshaling backplane message", "kind", env.Kind, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backplaneSendTimeout)
+	defer cancel()
+	if err := b.redis.Publish(ctx, backplaneChannel, data).Err(); err != nil {
+		b.logger.Warn("Error publishing to WebSocket backplane", "kind", env.Kind, "error", err)
+	}
+}
+
+// handle delivers a message relayed by another instance to local clients
+func (b *Backplane) handle(data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		b.logger.Warn("Error unmarshaling backplane message", "error", err)
+		return
+	}
+	if env.InstanceID == b.instanceID {
+		return
+	}
+
+	switch env.Kind {
+	case kindBroadcast:
+		b.manager.deliverToAll(env.Payload)
+	case kindUser:
+		b.manager.deliverToUser(env.UserID, env.Payload)
+	case kindTopic:
+		b.manager.deliverToTopic(env.Topic, env.Payload)
+	}
+}
+
+// heartbeat records this instance's connection count with a TTL so crashed
+// instances drop out of the cluster count
+func (b *Backplane) heartbeat(ctx context.Context) {
+	key := instanceKeyPrefix + b.instanceID
+	if err := b.redis.Set(ctx, key, strconv.Itoa(b.manager.GetClientCount()), instanceTTL).Err(); err != nil {
+		b.logger.Warn("Error recording WebSocket instance heartbeat", "error", err)
+	}
+}
+
+// newInstanceID combines the hostname with random bytes so replicas sharing a
+// hostname still get distinct IDs
+func newInstanceID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func relayed(t *testing.T, env envelope) []byte {
+	t.Helper()
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	return data
+}
+
+func TestBackplaneDeliversMessagesFromOtherInstances(t *testing.T) {
+	m := NewManager(nil)
+	backplane := &Backplane{instanceID: "local", logger: slog.Default()}
+	m.SetBackplane(backplane)
+	go m.Run(context.Background())
+
+	subscriber := newTestClient(t, m, "a")
+	userID := 9
+	user := newTestClient(t, m, "b")
+	user.UserID = &userID
+	m.Subscribe(subscriber, []string{"company:4:esg"})
+
+	payload := json.RawMessage(`{"type":"esg.score.updated","topic":"company:4:esg","data":null}`)
+	backplane.handle(relayed(t, envelope{InstanceID: "remote", Kind: kindTopic, Topic: "company:4:esg", Payload: payload}))
+	assert.Equal(t, "esg.score.updated", receive(t, subscriber).Type)
+	assert.Empty(t, user.Send)
+
+	backplane.handle(relayed(t, envelope{InstanceID: "remote", Kind: kindUser, UserID: userID, Payload: json.RawMessage(`{"type":"alert"}`)}))
+	assert.Equal(t, "alert", receive(t, user).Type)
+	assert.Empty(t, subscriber.Send)
+
+	backplane.handle(relayed(t, envelope{InstanceID: "remote", Kind: kindBroadcast, Payload: json.RawMessage(`{"type":"market"}`)}))
+	assert.Equal(t, "market", receive(t, subscriber).Type)
+	assert.Equal(t, "market", receive(t, user).Type)
+}
+
+func TestBackplaneIgnoresOwnMessages(t *testing.T) {
+	m := NewManager(nil)
+	backplane := &Backplane{instanceID: "local", logger: slog.Default()}
+	m.SetBackplane(backplane)
+	go m.Run(context.Background())
+
+	client := newTestClient(t, m, "a")
+	m.Subscribe(client, []string{TopicAlerts})
+
+	backplane.handle(relayed(t, envelope{InstanceID: "local", Kind: kindTopic, Topic: TopicAlerts, Payload: json.RawMessage(`{"type":"alert"}`)}))
+	backplane.handle([]byte("not json"))
+	assert.Empty(t, client.Send)
+}
+
+func TestNewInstanceIDIsUnique(t *testing.T) {
+	assert.NotEqual(t, newInstanceID(), newInstanceID())
+}
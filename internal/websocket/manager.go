@@ -1,22 +1,52 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
+	"ethosview-backend/pkg/logging"
+
 	"github.com/gorilla/websocket"
 )
 
 // Message represents a WebSocket message
 type Message struct {
 	Type   string      `json:"type"`
+	Topic  string      `json:"topic,omitempty"`
 	Data   interface{} `json:"data"`
 	Time   time.Time   `json:"time"`
 	UserID *int        `json:"user_id,omitempty"`
 }
 
+// ClientMessage is a request sent by a client over the socket. ID is echoed
+// back in the acknowledgement so clients can correlate responses.
+type ClientMessage struct {
+	Type   string   `json:"type"`
+	ID     string   `json:"id,omitempty"`
+	Topics []string `json:"topics,omitempty"`
+	Token  string   `json:"token,omitempty"`
+}
+
+// Ack acknowledges a subscribe or unsubscribe request
+type Ack struct {
+	ID            string          `json:"id,omitempty"`
+	Action        string          `json:"action"`
+	Accepted      []string        `json:"accepted"`
+	Rejected      []RejectedTopic `json:"rejected,omitempty"`
+	Subscriptions []string        `json:"subscriptions"`
+}
+
+// RejectedTopic explains why a topic in a request was not applied
+type RejectedTopic struct {
+	Topic  string `json:"topic"`
+	Reason string `json:"reason"`
+}
+
 // Marshal marshals the message to JSON
 func (m *Message) Marshal() ([]byte, error) {
 	return json.Marshal(m)
@@ -30,59 +60,90 @@ type Client struct {
 	Send    chan []byte
 	Manager *Manager
 	mu      sync.Mutex
+
+	// subscriptions is guarded by Manager.mu
+	subscriptions map[string]struct{}
+
+	// validate checks auth frames; expiries and closeRequests are consumed by WritePump
+	validate      TokenValidator
+	expiries      chan time.Time
+	closeRequests chan closeFrame
 }
 
-// Manager handles WebSocket connections and message broadcasting
+// Manager handles WebSocket connections, topic subscriptions and message broadcasting
 type Manager struct {
-	clients    map[string]*Client
-	broadcast  chan []byte
-	Register   chan *Client
-	Unregister chan *Client
-	mu         sync.RWMutex
+	clients          map[string]*Client
+	topics           map[string]map[string]*Client
+	maxSubscriptions int
+	backplane        *Backplane
+	Unregister       chan *Client
+	done             chan struct{}
+	mu               sync.RWMutex
+	logger           *slog.Logger
 }
 
-// NewManager creates a new WebSocket manager
-func NewManager() *Manager {
+// NewManager creates a new WebSocket manager. A nil logger uses the default.
+func NewManager(logger *slog.Logger) *Manager {
 	return &Manager{
-		clients:    make(map[string]*Client),
-		broadcast:  make(chan []byte),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
+		clients:          make(map[string]*Client),
+		topics:           make(map[string]map[string]*Client),
+		maxSubscriptions: maxSubscriptionsFromEnv(),
+		Unregister:       make(chan *Client),
+		done:             make(chan struct{}),
+		logger:           logging.Or(logger).With("component", "websocket"),
 	}
 }
 
-// Start starts the WebSocket manager
-func (m *Manager) Start() {
+// Run unregisters clients until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	defer close(m.done)
 	for {
 		select {
-		case client := <-m.Register:
-			m.mu.Lock()
-			m.clients[client.ID] = client
-			m.mu.Unlock()
-			log.Printf("Client %s connected", client.ID)
+		case <-ctx.Done():
+			return
 
 		case client := <-m.Unregister:
 			m.mu.Lock()
-			if _, ok := m.clients[client.ID]; ok {
-				delete(m.clients, client.ID)
-				close(client.Send)
-			}
+			m.removeClient(client)
 			m.mu.Unlock()
-			log.Printf("Client %s disconnected", client.ID)
+			m.logger.Debug("WebSocket client disconnected", "client_id", client.ID)
+		}
+	}
+}
 
-		case message := <-m.broadcast:
-			m.mu.RLock()
-			for _, client := range m.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(m.clients, client.ID)
-				}
-			}
-			m.mu.RUnlock()
+// Done is closed when Run returns. Senders on Unregister select on it so
+// they do not block once the manager has stopped.
+func (m *Manager) Done() <-chan struct{} {
+	return m.done
+}
+
+// Shutdown sends every connected client a going-away close frame and waits
+// until they have disconnected or ctx is done. Run must still be running so
+// the disconnects are processed.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.RLock()
+	for _, client := range m.clients {
+		client.requestClose(closeFrame{code: websocket.CloseGoingAway, reason: "server shutting down"})
+	}
+	m.mu.RUnlock()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for m.GetClientCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
 		}
 	}
+	return nil
+}
+
+// SetBackplane relays broadcasts through a backplane so clients connected to
+// other instances receive them too. It must be called before Run.
+func (m *Manager) SetBackplane(backplane *Backplane) {
+	m.backplane = backplane
+	backplane.manager = m
 }
 
 // Broadcast sends a message to all connected clients
@@ -95,11 +156,12 @@ func (m *Manager) Broadcast(messageType string, data interface{}) {
 
 	jsonData, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
+		m.logger.Error("Error marshaling WebSocket message", "type", messageType, "error", err)
 		return
 	}
 
-	m.broadcast <- jsonData
+	m.deliverToAll(jsonData)
+	m.relay(envelope{Kind: kindBroadcast, Payload: jsonData})
 }
 
 // BroadcastToUser sends a message to a specific user
@@ -113,22 +175,242 @@ func (m *Manager) BroadcastToUser(userID int, messageType string, data interface
 
 	jsonData, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
+		m.logger.Error("Error marshaling WebSocket message", "type", messageType, "user_id", userID, "error", err)
 		return
 	}
 
-	m.mu.RLock()
+	m.deliverToUser(userID, jsonData)
+	m.relay(envelope{Kind: kindUser, UserID: userID, Payload: jsonData})
+}
+
+// Publish sends a message to the clients subscribed to a topic
+func (m *Manager) Publish(topic, messageType string, data interface{}) {
+	message := Message{
+		Type:  messageType,
+		Topic: topic,
+		Data:  data,
+		Time:  time.Now(),
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		m.logger.Error("Error marshaling WebSocket message", "type", messageType, "topic", topic, "error", err)
+		return
+	}
+
+	m.deliverToTopic(topic, jsonData)
+	m.relay(envelope{Kind: kindTopic, Topic: topic, Payload: jsonData})
+}
+
+// relay forwards a locally delivered message to other instances, if a backplane is set
+func (m *Manager) relay(env envelope) {
+	if m.backplane != nil {
+		m.backplane.publish(env)
+	}
+}
+
+// deliverToAll sends an encoded message to every local connection
+func (m *Manager) deliverToAll(data []byte) {
+	m.mu.Lock()
+	for _, client := range m.clients {
+		m.deliver(client, data)
+	}
+	m.mu.Unlock()
+}
+
+// deliverToUser sends an encoded message to a user's local connections
+func (m *Manager) deliverToUser(userID int, data []byte) {
+	m.mu.Lock()
 	for _, client := range m.clients {
 		if client.UserID != nil && *client.UserID == userID {
-			select {
-			case client.Send <- jsonData:
-			default:
-				close(client.Send)
-				delete(m.clients, client.ID)
-			}
+			m.deliver(client, data)
 		}
 	}
-	m.mu.RUnlock()
+	m.mu.Unlock()
+}
+
+// deliverToTopic sends an encoded message to a topic's local subscribers
+func (m *Manager) deliverToTopic(topic string, data []byte) {
+	m.mu.Lock()
+	for _, client := range m.topics[topic] {
+		m.deliver(client, data)
+	}
+	m.mu.Unlock()
+}
+
+// Subscribe adds topics to a client's subscriptions. Invalid topics and topics
+// beyond the per-client limit are rejected; the rest are applied.
+func (m *Manager) Subscribe(client *Client, topics []string) Ack {
+	ack := Ack{Action: "subscribe", Accepted: []string{}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.clients[client.ID]; !ok {
+		return ack
+	}
+	if client.subscriptions == nil {
+		client.subscriptions = make(map[string]struct{})
+	}
+
+	for _, requested := range topics {
+		topic, err := NormalizeTopic(requested)
+		if err != nil {
+			ack.Rejected = append(ack.Rejected, RejectedTopic{Topic: requested, Reason: err.Error()})
+			continue
+		}
+		if _, ok := client.subscriptions[topic]; ok {
+			ack.Accepted = append(ack.Accepted, topic)
+			continue
+		}
+		if len(client.subscriptions) >= m.maxSubscriptions {
+			ack.Rejected = append(ack.Rejected, RejectedTopic{
+				Topic:  requested,
+				Reason: fmt.Sprintf("subscription limit of %d reached", m.maxSubscriptions),
+			})
+			continue
+		}
+
+		client.subscriptions[topic] = struct{}{}
+		if m.topics[topic] == nil {
+			m.topics[topic] = make(map[string]*Client)
+		}
+		m.topics[topic][client.ID] = client
+		ack.Accepted = append(ack.Accepted, topic)
+	}
+
+	ack.Subscriptions = client.subscriptionList()
+	return ack
+}
+
+// Unsubscribe removes topics from a client's subscriptions
+func (m *Manager) Unsubscribe(client *Client, topics []string) Ack {
+	ack := Ack{Action: "unsubscribe", Accepted: []string{}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, requested := range topics {
+		topic, err := NormalizeTopic(requested)
+		if err != nil {
+			ack.Rejected = append(ack.Rejected, RejectedTopic{Topic: requested, Reason: err.Error()})
+			continue
+		}
+		m.unsubscribe(client, topic)
+		ack.Accepted = append(ack.Accepted, topic)
+	}
+
+	ack.Subscriptions = client.subscriptionList()
+	return ack
+}
+
+// Subscriptions returns a client's current topics
+func (m *Manager) Subscriptions(client *Client) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return client.subscriptionList()
+}
+
+// GetTopicCount returns the number of topics with at least one subscriber
+func (m *Manager) GetTopicCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.topics)
+}
+
+// ClusterStatus reports connection counts across all instances sharing the
+// backplane, or for this instance alone when there is none
+func (m *Manager) ClusterStatus(ctx context.Context) (ClusterStatus, error) {
+	if m.backplane == nil {
+		count := m.GetClientCount()
+		return ClusterStatus{Instances: 1, ClientCount: count, LocalClientCount: count}, nil
+	}
+	return m.backplane.ClusterStatus(ctx)
+}
+
+// Connect registers a client, so messages sent once it returns reach it. It
+// returns false, registering nothing, once the manager has stopped.
+func (m *Manager) Connect(client *Client) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.done:
+		return false
+	default:
+	}
+	m.clients[client.ID] = client
+	m.logger.Debug("WebSocket client connected", "client_id", client.ID)
+	return true
+}
+
+// Send queues a message for one client, dropping it if the client has disconnected
+func (m *Manager) Send(client *Client, message Message) {
+	jsonData, err := message.Marshal()
+	if err != nil {
+		m.logger.Error("Error marshaling WebSocket message", "type", message.Type, "client_id", client.ID, "error", err)
+		return
+	}
+
+	m.mu.Lock()
+	if _, ok := m.clients[client.ID]; ok {
+		m.deliver(client, jsonData)
+	}
+	m.mu.Unlock()
+}
+
+// deliver queues data for a client, disconnecting it if its buffer is full.
+// The caller must hold m.mu for writing.
+func (m *Manager) deliver(client *Client, data []byte) {
+	select {
+	case client.Send <- data:
+	default:
+		m.removeClient(client)
+	}
+}
+
+// unregister hands a disconnected client to Run, or drops it directly once
+// Run has returned
+func (m *Manager) unregister(client *Client) {
+	select {
+	case m.Unregister <- client:
+	case <-m.done:
+		m.mu.Lock()
+		m.removeClient(client)
+		m.mu.Unlock()
+	}
+}
+
+// removeClient drops a client and its subscriptions. The caller must hold m.mu for writing.
+func (m *Manager) removeClient(client *Client) {
+	if _, ok := m.clients[client.ID]; !ok {
+		return
+	}
+	for topic := range client.subscriptions {
+		m.unsubscribe(client, topic)
+	}
+	delete(m.clients, client.ID)
+	close(client.Send)
+}
+
+// unsubscribe removes one topic from a client. The caller must hold m.mu for writing.
+func (m *Manager) unsubscribe(client *Client, topic string) {
+	delete(client.subscriptions, topic)
+	if subscribers, ok := m.topics[topic]; ok {
+		delete(subscribers, client.ID)
+		if len(subscribers) == 0 {
+			delete(m.topics, topic)
+		}
+	}
+}
+
+// subscriptionList returns the client's topics in sorted order. The caller must hold Manager.mu.
+func (c *Client) subscriptionList() []string {
+	topics := make([]string, 0, len(c.subscriptions))
+	for topic := range c.subscriptions {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
 }
 
 // GetClientCount returns the number of connected clients
@@ -138,16 +420,50 @@ func (m *Manager) GetClientCount() int {
 	return len(m.clients)
 }
 
+// GetAuthenticatedClientCount returns the number of connected clients
+// authenticated as a user
+func (m *Manager) GetAuthenticatedClientCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, client := range m.clients {
+		if client.UserID != nil {
+			count++
+		}
+	}
+	return count
+}
+
 // WritePump handles writing messages to the WebSocket connection
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(54 * time.Second)
+	// The expiry timer only starts once the client authenticates
+	expiry := time.NewTimer(0)
+	if !expiry.Stop() {
+		<-expiry.C
+	}
 	defer func() {
 		ticker.Stop()
+		expiry.Stop()
 		c.Conn.Close()
 	}()
 
 	for {
 		select {
+		case expiresAt := <-c.expiries:
+			if !expiry.Stop() {
+				select {
+				case <-expiry.C:
+				default:
+				}
+			}
+			expiry.Reset(time.Until(expiresAt))
+		case <-expiry.C:
+			c.writeClose(closeFrame{code: websocket.ClosePolicyViolation, reason: "token expired"})
+			return
+		case frame := <-c.closeRequests:
+			c.writeClose(frame)
+			return
 		case message, ok := <-c.Send:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
@@ -176,11 +492,12 @@ func (c *Client) WritePump() {
 // ReadPump handles reading messages from the WebSocket connection
 func (c *Client) ReadPump() {
 	defer func() {
-		c.Manager.Unregister <- c
+		c.Manager.unregister(c)
 		c.Conn.Close()
 	}()
 
-	c.Conn.SetReadLimit(512)
+	// Large enough for a subscribe request listing many topics
+	c.Conn.SetReadLimit(4096)
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -191,30 +508,42 @@ func (c *Client) ReadPump() {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket read error: %v", err)
+				c.Manager.logger.Warn("WebSocket read error", "client_id", c.ID, "error", err)
 			}
 			break
 		}
 
-		// Handle incoming messages (e.g., subscription requests)
-		var msg Message
-		if err := json.Unmarshal(message, &msg); err != nil {
-			log.Printf("Error unmarshaling message: %v", err)
-			continue
-		}
+		c.handleMessage(message)
+	}
+}
 
-		// Handle different message types
-		switch msg.Type {
-		case "ping":
-			response := Message{
-				Type: "pong",
-				Time: time.Now(),
-			}
-			responseData, _ := json.Marshal(response)
-			c.Send <- responseData
-		case "subscribe":
-			// Handle subscription requests
-			log.Printf("Client %s subscribed to updates", c.ID)
-		}
+// handleMessage applies a client request and replies with a pong, an ack or an error
+func (c *Client) handleMessage(raw []byte) {
+	var msg ClientMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		c.sendError("", "Invalid message format")
+		return
 	}
+
+	var ack Ack
+	switch msg.Type {
+	case "ping":
+		c.Manager.Send(c, Message{Type: "pong", Time: time.Now()})
+		return
+	case "subscribe":
+		ack = c.Manager.Subscribe(c, msg.Topics)
+	case "unsubscribe":
+		ack = c.Manager.Unsubscribe(c, msg.Topics)
+	case "auth":
+		c.handleAuth(msg)
+		return
+	case "subscriptions":
+		ack = Ack{Action: "subscriptions", Accepted: []string{}, Subscriptions: c.Manager.Subscriptions(c)}
+	default:
+		c.sendError(msg.ID, "Unknown message type: "+msg.Type)
+		return
+	}
+
+	ack.ID = msg.ID
+	c.Manager.Send(c, Message{Type: "ack", Data: ack, Time: time.Now()})
 }
@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, m *Manager, id string) *Client {
+	t.Helper()
+	client := &Client{ID: id, Send: make(chan []byte, 16), Manager: m}
+	require.True(t, m.Connect(client))
+	return client
+}
+
+func receive(t *testing.T, client *Client) Message {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("client %s received no message", client.ID)
+		return Message{}
+	}
+}
+
+func TestNormalizeTopic(t *testing.T) {
+	valid := map[string]string{
+		"alerts":            "alerts",
+		"company:12:prices": "company:12:prices",
+		"company:12:esg":    "company:12:esg",
+		"sector:Technology": "sector:technology",
+		" sector: Energy ":  "sector:energy",
+	}
+	for input, want := range valid {
+		got, err := NormalizeTopic(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got)
+	}
+
+	for _, input := range []string{"", "company:abc:prices", "company:0:esg", "company:1:volume", "sector:", "prices", "company:1"} {
+		_, err := NormalizeTopic(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestPublishReachesOnlySubscribers(t *testing.T) {
+	m := NewManager(nil)
+	go m.Run(context.Background())
+
+	subscriber := newTestClient(t, m, "a")
+	other := newTestClient(t, m, "b")
+
+	ack := m.Subscribe(subscriber, []string{"company:1:prices", "sector:Energy", "bogus"})
+	assert.Equal(t, []string{"company:1:prices", "sector:energy"}, ack.Accepted)
+	require.Len(t, ack.Rejected, 1)
+	assert.Equal(t, "bogus", ack.Rejected[0].Topic)
+	assert.Equal(t, 2, m.GetTopicCount())
+
+	m.Publish(CompanyPricesTopic(1), "price.tick", map[string]float64{"close": 101.5})
+
+	msg := receive(t, subscriber)
+	assert.Equal(t, "price.tick", msg.Type)
+	assert.Equal(t, "company:1:prices", msg.Topic)
+	assert.Empty(t, other.Send)
+
+	ack = m.Unsubscribe(subscriber, []string{"company:1:prices"})
+	assert.Equal(t, []string{"sector:energy"}, ack.Subscriptions)
+	m.Publish(CompanyPricesTopic(1), "price.tick", nil)
+	assert.Empty(t, subscriber.Send)
+	assert.Equal(t, 1, m.GetTopicCount())
+}
+
+func TestSubscriptionLimit(t *testing.T) {
+	m := NewManager(nil)
+	m.maxSubscriptions = 2
+	go m.Run(context.Background())
+
+	client := newTestClient(t, m, "a")
+	ack := m.Subscribe(client, []string{"company:1:esg", "company:2:esg", "company:3:esg", "company:1:esg"})
+
+	assert.Equal(t, []string{"company:1:esg", "company:2:esg", "company:1:esg"}, ack.Accepted)
+	require.Len(t, ack.Rejected, 1)
+	assert.Equal(t, "company:3:esg", ack.Rejected[0].Topic)
+	assert.Contains(t, ack.Rejected[0].Reason, "limit")
+}
+
+func TestUnregisterDropsSubscriptions(t *testing.T) {
+	m := NewManager(nil)
+	go m.Run(context.Background())
+
+	client := newTestClient(t, m, "a")
+	m.Subscribe(client, []string{TopicAlerts})
+	m.Unregister <- client
+
+	assert.Eventually(t, func() bool { return m.GetTopicCount() == 0 }, time.Second, 10*time.Millisecond)
+	m.Publish(TopicAlerts, "alert", nil)
+}
+
+func TestSendsDoNotBlockAfterRunReturns(t *testing.T) {
+	m := NewManager(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	go m.Run(ctx)
+
+	client := newTestClient(t, m, "a")
+	cancel()
+	<-m.Done()
+
+	m.Broadcast("market_update", nil)
+	assert.Equal(t, "market_update", receive(t, client).Type)
+
+	m.unregister(client)
+	assert.Equal(t, 0, m.GetClientCount())
+}
+
+func TestHandleMessageAcknowledgesRequests(t *testing.T) {
+	m := NewManager(nil)
+	go m.Run(context.Background())
+
+	client := newTestClient(t, m, "a")
+	client.handleMessage([]byte(`{"type":"subscribe","id":"req-1","topics":["alerts"]}`))
+
+	msg := receive(t, client)
+	assert.Equal(t, "ack", msg.Type)
+	data := msg.Data.(map[string]interface{})
+	assert.Equal(t, "req-1", data["id"])
+	assert.Equal(t, "subscribe", data["action"])
+	assert.Equal(t, []interface{}{"alerts"}, data["subscriptions"])
+
+	client.handleMessage([]byte(`{"type":"explode"}`))
+	assert.Equal(t, "error", receive(t, client).Type)
+}
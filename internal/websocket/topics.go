@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TopicAlerts carries monitoring alerts
+const TopicAlerts = "alerts"
+
+// DefaultMaxSubscriptions is the per-client topic limit when WS_MAX_SUBSCRIPTIONS is unset
+const DefaultMaxSubscriptions = 50
+
+// maxSectorNameLength bounds sector topic names
+const maxSectorNameLength = 100
+
+// CompanyPricesTopic returns the topic for a company's price updates
+func CompanyPricesTopic(companyID int) string {
+	return fmt.Sprintf("company:%d:prices", companyID)
+}
+
+// CompanyESGTopic returns the topic for a company's ESG score updates
+func CompanyESGTopic(companyID int) string {
+	return fmt.Sprintf("company:%d:esg", companyID)
+}
+
+// SectorTopic returns the topic for updates to companies in a sector.
+// Sector names are matched case-insensitively.
+func SectorTopic(sector string) string {
+	return "sector:" + strings.ToLower(strings.TrimSpace(sector))
+}
+
+// NormalizeTopic validates a topic requested by a client and returns its
+// canonical form. Supported topics are company:{id}:prices, company:{id}:esg,
+// sector:{name} and alerts.
+func NormalizeTopic(topic string) (string, error) {
+	topic = strings.TrimSpace(topic)
+	if topic == TopicAlerts {
+		return topic, nil
+	}
+
+	if name, ok := strings.CutPrefix(topic, "sector:"); ok {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > maxSectorNameLength {
+			return "", fmt.Errorf("invalid sector topic %q", topic)
+		}
+		return SectorTopic(name), nil
+	}
+
+	parts := strings.Split(topic, ":")
+	if len(parts) == 3 && parts[0] == "company" {
+		id, err := strconv.Atoi(parts[1])
+		if err != nil || id <= 0 {
+			return "", fmt.Errorf("invalid company ID in topic %q", topic)
+		}
+		switch parts[2] {
+		case "prices":
+			return CompanyPricesTopic(id), nil
+		case "esg":
+			return CompanyESGTopic(id), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown topic %q", topic)
+}
+
+// maxSubscriptionsFromEnv reads WS_MAX_SUBSCRIPTIONS, falling back to the default
+func maxSubscriptionsFromEnv() int {
+	if value, err := strconv.Atoi(os.Getenv("WS_MAX_SUBSCRIPTIONS")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultMaxSubscriptions
+}
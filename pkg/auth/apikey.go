@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+// apiKeyPrefix marks EthosView API keys; keys look like ev_<lookup>_<secret>
+const apiKeyPrefix = "ev_"
+
+// API key errors
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrAPIKeyExpired = errors.New("API key has expired")
+)
+
+// APIKeyIdentity is the owner and scopes of an authenticated API key
+type APIKeyIdentity struct {
+	KeyID  int
+	UserID int
+	Email  string
+	Roles  []string
+	Scopes []string
+}
+
+// APIKeyStore authenticates raw API keys against persisted keys
+type APIKeyStore interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*APIKeyIdentity, error)
+}
+
+// GenerateAPIKey returns a new raw API key, its public lookup prefix and the hash to store
+func GenerateAPIKey() (key, lookup, hash string, err error) {
+	lookup, err = randomToken(6)
+	if err != nil {
+		return "", "", "", err
+	}
+	// Keep the lookup free of the separator so keys split unambiguously
+	lookup = strings.NewReplacer("_", "x", "-", "y").Replace(lookup)
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + lookup + "_" + secret
+	return key, lookup, HashToken(key), nil
+}
+
+// ParseAPIKey returns the lookup prefix of a raw API key
+func ParseAPIKey(key string) (string, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", ErrInvalidAPIKey
+	}
+	lookup, secret, ok := strings.Cut(strings.TrimPrefix(key, apiKeyPrefix), "_")
+	if !ok || lookup == "" || secret == "" {
+		return "", ErrInvalidAPIKey
+	}
+	return lookup, nil
+}
+
+// APIKeyMatches reports whether a raw key matches a stored hash in constant time
+func APIKeyMatches(key, storedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(key)), []byte(storedHash)) == 1
+}
+
+// IsValidPermission reports whether a permission name is known, so it can be used as an API key scope
+func IsValidPermission(permission string) bool {
+	for _, p := range rolePermissions[RoleAdmin] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
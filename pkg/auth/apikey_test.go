@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, lookup, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "ev_"+lookup+"_"))
+	assert.NotContains(t, lookup, "_")
+	assert.Len(t, hash, 64)
+
+	parsed, err := ParseAPIKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, lookup, parsed)
+
+	assert.True(t, APIKeyMatches(key, hash))
+	assert.False(t, APIKeyMatches(key+"x", hash))
+
+	other, _, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestParseAPIKeyRejectsMalformedKeys(t *testing.T) {
+	for _, key := range []string{"", "abcdefghijkl", "ev_", "ev_lookup", "ev_lookup_", "ev__secret", "xx_lookup_secret"} {
+		_, err := ParseAPIKey(key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, key)
+	}
+}
+
+func TestIsValidPermission(t *testing.T) {
+	assert.True(t, IsValidPermission(PermRunBacktests))
+	assert.True(t, IsValidPermission(PermManageUsers))
+	assert.False(t, IsValidPermission("companies:delete"))
+	assert.False(t, IsValidPermission(RoleAdmin))
+}
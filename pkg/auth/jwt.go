@@ -1,6 +1,11 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -10,14 +15,18 @@ import (
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
+	UserID int      `json:"user_id"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secretKey []byte
+	secretKey   []byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	revocations *RevocationStore
 }
 
 // NewJWTManager creates a new JWT manager
@@ -27,32 +36,76 @@ func NewJWTManager() *JWTManager {
 		secretKey = "default-secret-key-change-in-production"
 	}
 	return &JWTManager{
-		secretKey: []byte(secretKey),
+		secretKey:  []byte(secretKey),
+		accessTTL:  durationFromEnv("JWT_EXPIRY", 15*time.Minute),
+		refreshTTL: durationFromEnv("JWT_REFRESH_EXPIRY", 30*24*time.Hour),
 	}
 }
 
-// GenerateToken generates a new JWT token for a user
-func (j *JWTManager) GenerateToken(userID int, email string) (string, error) {
-	expiryStr := os.Getenv("JWT_EXPIRY")
-	if expiryStr == "" {
-		expiryStr = "24h"
+// SetRevocationStore enables jti denylist checks for validated tokens
+func (j *JWTManager) SetRevocationStore(store *RevocationStore) {
+	j.revocations = store
+}
+
+// RevocationStore returns the configured revocation store, or nil
+func (j *JWTManager) RevocationStore() *RevocationStore {
+	return j.revocations
+}
+
+// AccessTokenTTL returns the lifetime of access tokens
+func (j *JWTManager) AccessTokenTTL() time.Duration {
+	return j.accessTTL
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens
+func (j *JWTManager) RefreshTokenTTL() time.Duration {
+	return j.refreshTTL
+}
+
+// GenerateToken generates a new short-lived access token for a user
+func (j *JWTManager) GenerateToken(userID int, email string, roles []string) (string, error) {
+	token, _, err := j.GenerateAccessToken(userID, email, roles)
+	return token, err
+}
+
+// GenerateAccessToken generates a new access token and returns it with its claims
+func (j *JWTManager) GenerateAccessToken(userID int, email string, roles []string) (string, *Claims, error) {
+	claims, err := j.NewAccessClaims(userID, email, roles)
+	if err != nil {
+		return "", nil, err
 	}
 
-	expiry, err := time.ParseDuration(expiryStr)
+	signed, err := j.SignClaims(claims)
 	if err != nil {
-		expiry = 24 * time.Hour // Default to 24 hours
+		return "", nil, err
 	}
 
-	claims := &Claims{
+	return signed, claims, nil
+}
+
+// NewAccessClaims returns access token claims with a fresh jti and expiry
+func (j *JWTManager) NewAccessClaims(userID int, email string, roles []string) (*Claims, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Claims{
 		UserID: userID,
 		Email:  email,
+		Roles:  roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.accessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
-	}
+	}, nil
+}
 
+// SignClaims signs claims into a token string
+func (j *JWTManager) SignClaims(claims *Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(j.secretKey)
 }
@@ -76,3 +129,51 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// IsRevoked reports whether a validated token's jti is on the denylist
+func (j *JWTManager) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if j.revocations == nil || claims.ID == "" {
+		return false, nil
+	}
+	return j.revocations.IsRevoked(ctx, claims.ID)
+}
+
+// GenerateRefreshToken returns a new opaque refresh token and the hash to store for it
+func GenerateRefreshToken() (string, string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// NewTokenFamily returns a new identifier for a chain of rotated refresh tokens
+func NewTokenFamily() (string, error) {
+	return randomToken(16)
+}
+
+// HashToken returns the hex-encoded SHA-256 of an opaque token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAccessToken(t *testing.T) {
+	t.Setenv("JWT_EXPIRY", "10m")
+	manager := NewJWTManager()
+
+	token, claims, err := manager.GenerateAccessToken(42, "user@example.com", []string{RoleAnalyst})
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), claims.ExpiresAt.Time, 2*time.Second)
+
+	parsed, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, 42, parsed.UserID)
+	assert.Equal(t, claims.ID, parsed.ID)
+	assert.Equal(t, []string{RoleAnalyst}, parsed.Roles)
+
+	// Without a revocation store nothing is revoked
+	revoked, err := manager.IsRevoked(context.Background(), parsed)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	_, other, err := manager.GenerateAccessToken(42, "user@example.com", []string{RoleAnalyst})
+	require.NoError(t, err)
+	assert.NotEqual(t, claims.ID, other.ID)
+}
+
+func TestValidateTokenRejectsOtherSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "first-secret")
+	token, err := NewJWTManager().GenerateToken(1, "a@example.com", nil)
+	require.NoError(t, err)
+
+	t.Setenv("JWT_SECRET", "second-secret")
+	_, err = NewJWTManager().ValidateToken(token)
+	assert.Error(t, err)
+}
+
+func TestGenerateRefreshToken(t *testing.T) {
+	token, hash, err := GenerateRefreshToken()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashToken(token))
+	assert.NotContains(t, hash, token)
+
+	other, _, err := GenerateRefreshToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestDurationFromEnv(t *testing.T) {
+	t.Setenv("TEST_TTL", "bogus")
+	assert.Equal(t, time.Minute, durationFromEnv("TEST_TTL", time.Minute))
+
+	t.Setenv("TEST_TTL", "90s")
+	assert.Equal(t, 90*time.Second, durationFromEnv("TEST_TTL", time.Minute))
+}
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		roles      []string
+		permission string
+		allowed    bool
+	}{
+		{[]string{RoleViewer}, PermWriteCompanies, false},
+		{[]string{RoleViewer}, PermRunBacktests, false},
+		{[]string{RoleViewer}, PermWriteAccount, true},
+		{[]string{RoleAnalyst}, PermRunBacktests, true},
+		{[]string{RoleAnalyst}, PermWriteESG, false},
+		{[]string{RoleViewer, RoleDataEditor}, PermWriteESG, true},
+		{[]string{RoleDataEditor}, PermManageUsers, false},
+		{[]string{RoleAdmin}, PermManageUsers, true},
+		{[]string{RoleDataEditor}, PermManageSystem, false},
+		{[]string{RoleAdmin}, PermManageSystem, true},
+		{[]string{"unknown"}, PermRunBacktests, false},
+		{nil, PermRunBacktests, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, HasPermission(tt.roles, tt.permission), "%v %s", tt.roles, tt.permission)
+	}
+
+	assert.True(t, IsValidRole(RoleDataEditor))
+	assert.False(t, IsValidRole("superuser"))
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationStore keeps a Redis denylist of revoked access token IDs (jti).
+// Entries expire with the token they revoke, so the denylist stays small.
+type RevocationStore struct {
+	redis  *redis.Client
+	prefix string
+}
+
+// NewRevocationStore creates a new revocation store
+func NewRevocationStore(redis *redis.Client) *RevocationStore {
+	return &RevocationStore{
+		redis:  redis,
+		prefix: "auth:revoked:",
+	}
+}
+
+// Revoke denylists a token ID until the token would have expired
+func (s *RevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.redis.Set(ctx, s.prefix+jti, "1", ttl).Err()
+}
+
+// IsRevoked reports whether a token ID is denylisted
+func (s *RevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.redis.Exists(ctx, s.prefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
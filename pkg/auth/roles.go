@@ -0,0 +1,61 @@
+package auth
+
+// Roles that can be granted to users
+const (
+	RoleViewer     = "viewer"
+	RoleAnalyst    = "analyst"
+	RoleDataEditor = "data-editor"
+	RoleAdmin      = "admin"
+)
+
+// Permissions checked by route middleware
+const (
+	PermRunBacktests    = "backtests:run"
+	PermWriteCompanies  = "companies:write"
+	PermWriteESG        = "esg:write"
+	PermImportFinancial = "financial:import"
+	PermManageUsers     = "users:manage"
+	PermManageSystem    = "system:manage"
+	// PermWriteAccount covers changes to the user's own portfolios,
+	// watchlists, webhooks and alert rules. Every role holds it; it exists so
+	// API keys can be scoped to allow those writes.
+	PermWriteAccount = "account:write"
+)
+
+// rolePermissions lists what each role may do beyond read-only access
+var rolePermissions = map[string][]string{
+	RoleViewer:     {PermWriteAccount},
+	RoleAnalyst:    {PermWriteAccount, PermRunBacktests},
+	RoleDataEditor: {PermWriteAccount, PermRunBacktests, PermWriteCompanies, PermWriteESG, PermImportFinancial},
+	RoleAdmin:      {PermWriteAccount, PermRunBacktests, PermWriteCompanies, PermWriteESG, PermImportFinancial, PermManageUsers, PermManageSystem},
+}
+
+// IsValidRole reports whether a role name is known
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasRole reports whether any of the given roles is held
+func HasRole(held []string, roles ...string) bool {
+	for _, h := range held {
+		for _, r := range roles {
+			if h == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any held role grants the permission
+func HasPermission(held []string, permission string) bool {
+	for _, role := range held {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
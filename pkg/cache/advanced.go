@@ -4,32 +4,39 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
+	"ethosview-backend/pkg/logging"
+	"ethosview-backend/pkg/metrics"
+
 	"github.com/redis/go-redis/v9"
 )
 
 // AdvancedCache provides enhanced caching strategies
 type AdvancedCache struct {
-	redis  *redis.Client
-	prefix string
+	redis   *redis.Client
+	prefix  string
+	logger  *slog.Logger
+	flights *flightGroup
+	pending *pendingInvalidations
 }
 
-// CacheStrategy defines different caching strategies
+// CacheStrategy defines different caching strategies. Entries are fresh for
+// the first duration; after that they are served stale, while they are
+// refreshed in the background, until the second.
 type CacheStrategy int
 
 const (
-	// ShortTerm for frequently changing data (5 minutes)
+	// ShortTerm for frequently changing data (5 minutes, stale up to 15)
 	ShortTerm CacheStrategy = iota
-	// MediumTerm for moderately changing data (30 minutes)
+	// MediumTerm for moderately changing data (30 minutes, stale up to 2 hours)
 	MediumTerm
-	// LongTerm for stable data (2 hours)
+	// LongTerm for stable data (2 hours, stale up to 6)
 	LongTerm
-	// Daily for daily aggregations (24 hours)
+	// Daily for daily aggregations (24 hours, stale up to 25)
 	Daily
 )
 
@@ -37,131 +44,76 @@ const (
 type CacheEntry struct {
 	Data      interface{} `json:"data"`
 	CreatedAt time.Time   `json:"created_at"`
+	StaleAt   time.Time   `json:"stale_at"`
 	ExpiresAt time.Time   `json:"expires_at"`
 	Version   string      `json:"version"`
 	Tags      []string    `json:"tags"`
+	// NotFound marks a cached miss; Data, if any, describes it
+	NotFound bool `json:"not_found,omitempty"`
+
+	// remaining is the entry's TTL in Redis when it was read
+	remaining time.Duration
 }
 
-// NewAdvancedCache creates a new advanced cache instance
-func NewAdvancedCache(redis *redis.Client, prefix string) *AdvancedCache {
+// NewAdvancedCache creates a new advanced cache instance; a nil logger uses
+// the default
+func NewAdvancedCache(redis *redis.Client, prefix string, logger *slog.Logger) *AdvancedCache {
 	return &AdvancedCache{
-		redis:  redis,
-		prefix: prefix,
+		redis:   redis,
+		prefix:  prefix,
+		logger:  logging.Or(logger).With("component", "cache"),
+		flights: newFlightGroup(),
+		pending: &pendingInvalidations{tags: make(map[string]time.Time)},
 	}
 }
 
 // Set stores data with advanced caching strategy
-func (ac *AdvancedCache) Set(key string, data interface{}, strategy CacheStrategy, tags []string) error {
-	ctx := context.Background()
-	
-	// Determine TTL based on strategy
-	ttl := ac.getStrategyTTL(strategy)
-	
-	// Create cache entry
-	entry := CacheEntry{
-		Data:      data,
-		CreatedAt: time.Now().UTC(),
-		ExpiresAt: time.Now().UTC().Add(ttl),
-		Version:   ac.generateVersion(data),
-		Tags:      tags,
-	}
-
-	// Serialize entry
-	jsonData, err := json.Marshal(entry)
-	if err != nil {
-		return err
-	}
-
-	// Store in Redis with TTL
+func (ac *AdvancedCache) Set(ctx context.Context, key string, data interface{}, strategy CacheStrategy, tags []string) error {
+	// Store in Redis until the entry can no longer be served stale
 	fullKey := ac.buildKey(key)
-	err = ac.redis.Set(ctx, fullKey, jsonData, ttl).Err()
-	if err != nil {
+	if err := ac.writeEntry(ctx, fullKey, newEntry(data, strategy, tags, false)); err != nil {
 		return err
 	}
 
 	// Store tags for invalidation
 	for _, tag := range tags {
-		ac.addToTagSet(tag, fullKey)
+		ac.addToTagSet(ctx, tag, fullKey)
 	}
 
 	return nil
 }
 
-// Get retrieves data from cache with validation
-func (ac *AdvancedCache) Get(key string, dest interface{}) (bool, error) {
-	ctx := context.Background()
-	fullKey := ac.buildKey(key)
-
-	data, err := ac.redis.Get(ctx, fullKey).Result()
-	if err == redis.Nil {
-		return false, nil // Cache miss
-	}
+// Get retrieves data from cache, including stale entries. A cached miss
+// returns ErrNotFound.
+func (ac *AdvancedCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	entry, err := ac.getEntry(ctx, key)
 	if err != nil {
 		return false, err
 	}
-
-	var entry CacheEntry
-	if err := json.Unmarshal([]byte(data), &entry); err != nil {
-		// Invalid cache entry, delete it
-		ac.redis.Del(ctx, fullKey)
-		return false, nil
-	}
-
-	// Check if expired (extra safety check)
-	if time.Now().UTC().After(entry.ExpiresAt) {
-		ac.redis.Del(ctx, fullKey)
-		return false, nil
-	}
-
-	// Deserialize data
-	dataBytes, err := json.Marshal(entry.Data)
-	if err != nil {
-		return false, err
+	if entry == nil {
+		metrics.CacheRequests.WithLabelValues("advanced", metrics.CacheMiss).Inc()
+		return false, nil // Cache miss
 	}
 
-	if err := json.Unmarshal(dataBytes, dest); err != nil {
+	if err := decodeEntry(entry, dest); err != nil {
 		return false, err
 	}
-
+	metrics.CacheRequests.WithLabelValues("advanced", metrics.CacheHit).Inc()
 	return true, nil
 }
 
-// GetOrSet retrieves from cache or executes function and caches result
-func (ac *AdvancedCache) GetOrSet(key string, dest interface{}, fn func() (interface{}, error), strategy CacheStrategy, tags []string) error {
-	// Try to get from cache first
-	found, err := ac.Get(key, dest)
-	if err != nil {
-		return err
-	}
-
-	if found {
-		return nil // Cache hit
-	}
-
-	// Cache miss, execute function
-	data, err := fn()
-	if err != nil {
-		return err
+// GetOrSet retrieves from cache or calls fn and caches the result under tags.
+// See Fetch for coalescing, stale entries and ErrNotFound.
+func (ac *AdvancedCache) GetOrSet(ctx context.Context, key string, dest interface{}, fn func(ctx context.Context) (interface{}, error), strategy CacheStrategy, tags []string) error {
+	load := func(ctx context.Context) (interface{}, []string, error) {
+		data, err := fn(ctx)
+		return data, tags, err
 	}
-
-	// Store in cache
-	if err := ac.Set(key, data, strategy, tags); err != nil {
-		log.Printf("Failed to cache data for key %s: %v", key, err)
-		// Continue execution even if caching fails
-	}
-
-	// Set the result
-	dataBytes, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(dataBytes, dest)
+	return ac.Fetch(ctx, key, dest, strategy, load, nil)
 }
 
 // InvalidateByTag invalidates all cache entries with a specific tag
-func (ac *AdvancedCache) InvalidateByTag(tag string) error {
-	ctx := context.Background()
+func (ac *AdvancedCache) InvalidateByTag(ctx context.Context, tag string) error {
 	tagKey := ac.buildTagKey(tag)
 
 	// Get all keys with this tag
@@ -185,8 +137,7 @@ func (ac *AdvancedCache) InvalidateByTag(tag string) error {
 }
 
 // InvalidatePattern invalidates all keys matching a pattern
-func (ac *AdvancedCache) InvalidatePattern(pattern string) error {
-	ctx := context.Background()
+func (ac *AdvancedCache) InvalidatePattern(ctx context.Context, pattern string) error {
 	fullPattern := ac.buildKey(pattern)
 
 	keys, err := ac.redis.Keys(ctx, fullPattern).Result()
@@ -202,25 +153,22 @@ func (ac *AdvancedCache) InvalidatePattern(pattern string) error {
 }
 
 // Refresh refreshes cache entry with new data
-func (ac *AdvancedCache) Refresh(key string, data interface{}, strategy CacheStrategy, tags []string) error {
+func (ac *AdvancedCache) Refresh(ctx context.Context, key string, data interface{}, strategy CacheStrategy, tags []string) error {
 	// Delete existing entry
-	ac.Delete(key)
+	ac.Delete(ctx, key)
 	
 	// Set new entry
-	return ac.Set(key, data, strategy, tags)
+	return ac.Set(ctx, key, data, strategy, tags)
 }
 
 // Delete removes a specific cache entry
-func (ac *AdvancedCache) Delete(key string) error {
-	ctx := context.Background()
+func (ac *AdvancedCache) Delete(ctx context.Context, key string) error {
 	fullKey := ac.buildKey(key)
 	return ac.redis.Del(ctx, fullKey).Err()
 }
 
 // GetStats returns cache statistics
-func (ac *AdvancedCache) GetStats() (map[string]interface{}, error) {
-	ctx := context.Background()
-	
+func (ac *AdvancedCache) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	// Get Redis info
 	info, err := ac.redis.Info(ctx, "memory", "stats").Result()
 	if err != nil {
@@ -244,24 +192,24 @@ func (ac *AdvancedCache) GetStats() (map[string]interface{}, error) {
 }
 
 // WarmupCache performs intelligent cache warming
-func (ac *AdvancedCache) WarmupCache(warmupFuncs map[string]func() (interface{}, error)) error {
-	log.Println("🔥 Starting advanced cache warmup...")
+func (ac *AdvancedCache) WarmupCache(ctx context.Context, warmupFuncs map[string]func() (interface{}, error)) error {
+	ac.logger.Info("Starting advanced cache warmup")
 
 	for key, fn := range warmupFuncs {
 		data, err := fn()
 		if err != nil {
-			log.Printf("Failed to warmup %s: %v", key, err)
+			ac.logger.Error("Failed to warm up cache key", "key", key, "error", err)
 			continue
 		}
 
 		// Use medium-term strategy for warmup
 		tags := []string{"warmup", ac.getKeyCategory(key)}
-		if err := ac.Set(key, data, MediumTerm, tags); err != nil {
-			log.Printf("Failed to cache warmup data for %s: %v", key, err)
+		if err := ac.Set(ctx, key, data, MediumTerm, tags); err != nil {
+			ac.logger.Error("Failed to cache warmup data", "key", key, "error", err)
 		}
 	}
 
-	log.Println("✅ Advanced cache warmup completed")
+	ac.logger.Info("Advanced cache warmup completed")
 	return nil
 }
 
@@ -287,18 +235,20 @@ func (ac *AdvancedCache) BuildQueryKey(table string, operation string, params ma
 
 // Helper methods
 
-func (ac *AdvancedCache) getStrategyTTL(strategy CacheStrategy) time.Duration {
+// getStrategyTTLs returns how long entries are fresh and how long they may be
+// served at all
+func getStrategyTTLs(strategy CacheStrategy) (fresh, stale time.Duration) {
 	switch strategy {
 	case ShortTerm:
-		return 5 * time.Minute
+		return 5 * time.Minute, 15 * time.Minute
 	case MediumTerm:
-		return 30 * time.Minute
+		return 30 * time.Minute, 2 * time.Hour
 	case LongTerm:
-		return 2 * time.Hour
+		return 2 * time.Hour, 6 * time.Hour
 	case Daily:
-		return 24 * time.Hour
+		return 24 * time.Hour, 25 * time.Hour
 	default:
-		return 30 * time.Minute
+		return 30 * time.Minute, 2 * time.Hour
 	}
 }
 
@@ -310,16 +260,10 @@ func (ac *AdvancedCache) buildTagKey(tag string) string {
 	return fmt.Sprintf("%s:tag:%s", ac.prefix, tag)
 }
 
-func (ac *AdvancedCache) addToTagSet(tag, key string) {
-	ctx := context.Background()
+func (ac *AdvancedCache) addToTagSet(ctx context.Context, tag, key string) {
 	tagKey := ac.buildTagKey(tag)
 	ac.redis.SAdd(ctx, tagKey, key)
-	ac.redis.Expire(ctx, tagKey, 25*time.Hour) // Slightly longer than daily cache
-}
-
-func (ac *AdvancedCache) generateVersion(data interface{}) string {
-	// Simple version based on current time
-	return fmt.Sprintf("v%d", time.Now().Unix())
+	ac.redis.Expire(ctx, tagKey, tagTTL)
 }
 
 func (ac *AdvancedCache) getKeyCategory(key string) string {
@@ -0,0 +1,369 @@
+// Package cachetest provides an in-memory Redis stand-in for tests of code
+// that caches through go-redis. It speaks enough RESP2 for strings, sets,
+// counters and expiry; anything else is answered with an error.
+package cachetest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Server is an in-memory Redis server listening on a local port
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	strings  map[string]string
+	sets     map[string]map[string]struct{}
+	expiries map[string]time.Time
+	commands map[string]int
+}
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &Server{
+		listener: listener,
+		strings:  make(map[string]string),
+		sets:     make(map[string]map[string]struct{}),
+		expiries: make(map[string]time.Time),
+		commands: make(map[string]int),
+	}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+// Client returns a go-redis client for the server, closed when the test ends
+func (s *Server) Client(t testing.TB) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: s.listener.Addr().String(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Keys returns the live keys in sorted order
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.strings {
+		if s.live(key) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range s.sets {
+		if s.live(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Count returns how many times a command, such as "GET", was received
+func (s *Server) Count(command string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands[strings.ToUpper(command)]
+}
+
+// FastForward expires keys as if d had passed
+func (s *Server) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, at := range s.expiries {
+		s.expiries[key] = at.Add(-d)
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.execute(writer, args)
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommand reads one RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("expected array, got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(header) == 0 || header[0] != '$' {
+			return nil, fmt.Errorf("expected bulk string, got %q", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// execute runs one command and writes its reply
+func (s *Server) execute(w *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command := strings.ToUpper(args[0])
+	s.commands[command]++
+	args = args[1:]
+	for _, key := range keyArgs(command, args) {
+		s.expire(key)
+	}
+
+	switch command {
+	case "PING":
+		writeSimple(w, "PONG")
+	case "CLIENT", "SELECT":
+		writeSimple(w, "OK")
+	case "GET":
+		if value, ok := s.strings[args[0]]; ok {
+			writeBulk(w, value)
+		} else {
+			writeNil(w)
+		}
+	case "MGET":
+		writeArrayHeader(w, len(args))
+		for _, key := range args {
+			if value, ok := s.strings[key]; ok {
+				writeBulk(w, value)
+			} else {
+				writeNil(w)
+			}
+		}
+	case "SET":
+		s.set(w, args)
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if s.exists(key) {
+				deleted++
+			}
+			s.delete(key)
+		}
+		writeInt(w, deleted)
+	case "EXISTS":
+		count := 0
+		for _, key := range args {
+			if s.exists(key) {
+				count++
+			}
+		}
+		writeInt(w, count)
+	case "INCR", "INCRBY":
+		by := 1
+		if command == "INCRBY" {
+			by, _ = strconv.Atoi(args[1])
+		}
+		current, _ := strconv.Atoi(s.strings[args[0]])
+		current += by
+		s.strings[args[0]] = strconv.Itoa(current)
+		writeInt(w, current)
+	case "SADD":
+		set, ok := s.sets[args[0]]
+		if !ok {
+			set = make(map[string]struct{})
+			s.sets[args[0]] = set
+		}
+		added := 0
+		for _, member := range args[1:] {
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				added++
+			}
+		}
+		writeInt(w, added)
+	case "SMEMBERS":
+		members := make([]string, 0, len(s.sets[args[0]]))
+		for member := range s.sets[args[0]] {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		writeArrayHeader(w, len(members))
+		for _, member := range members {
+			writeBulk(w, member)
+		}
+	case "EXPIRE", "PEXPIRE":
+		if !s.exists(args[0]) {
+			writeInt(w, 0)
+			return
+		}
+		n, _ := strconv.Atoi(args[1])
+		unit := time.Second
+		if command == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		s.expiries[args[0]] = time.Now().Add(time.Duration(n) * unit)
+		writeInt(w, 1)
+	case "PTTL":
+		switch at, ok := s.expiries[args[0]]; {
+		case !s.exists(args[0]):
+			writeInt(w, -2)
+		case !ok:
+			writeInt(w, -1)
+		default:
+			writeInt(w, int(time.Until(at).Milliseconds()))
+		}
+	case "KEYS":
+		var keys []string
+		for key := range s.strings {
+			if matched, _ := path.Match(args[0], key); matched {
+				keys = append(keys, key)
+			}
+		}
+		for key := range s.sets {
+			if matched, _ := path.Match(args[0], key); matched {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		writeArrayHeader(w, len(keys))
+		for _, key := range keys {
+			writeBulk(w, key)
+		}
+	default:
+		writeError(w, "ERR unknown command '"+command+"'")
+	}
+}
+
+// set handles SET key value [EX seconds | PX milliseconds] [NX | XX]
+func (s *Server) set(w *bufio.Writer, args []string) {
+	key, value := args[0], args[1]
+	var ttl time.Duration
+	nx, xx := false, false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "EX", "PX":
+			n, _ := strconv.Atoi(args[i+1])
+			ttl = time.Duration(n) * time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				ttl = time.Duration(n) * time.Millisecond
+			}
+			i++
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		}
+	}
+	if (nx && s.exists(key)) || (xx && !s.exists(key)) {
+		writeNil(w)
+		return
+	}
+	s.delete(key)
+	s.strings[key] = value
+	if ttl > 0 {
+		s.expiries[key] = time.Now().Add(ttl)
+	}
+	writeSimple(w, "OK")
+}
+
+// keyArgs returns the keys a command reads or writes, so expired ones are
+// removed first
+func keyArgs(command string, args []string) []string {
+	switch command {
+	case "MGET", "DEL", "EXISTS":
+		return args
+	case "PING", "CLIENT", "SELECT", "KEYS":
+		return nil
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args[:1]
+}
+
+func (s *Server) live(key string) bool {
+	at, ok := s.expiries[key]
+	return !ok || time.Now().Before(at)
+}
+
+func (s *Server) expire(key string) {
+	if !s.live(key) {
+		s.delete(key)
+	}
+}
+
+func (s *Server) exists(key string) bool {
+	_, isString := s.strings[key]
+	_, isSet := s.sets[key]
+	return isString || isSet
+}
+
+func (s *Server) delete(key string) {
+	delete(s.strings, key)
+	delete(s.sets, key)
+	delete(s.expiries, key)
+}
+
+func writeSimple(w *bufio.Writer, s string) { fmt.Fprintf(w, "+%s\r\n", s) }
+func writeError(w *bufio.Writer, s string)  { fmt.Fprintf(w, "-%s\r\n", s) }
+func writeInt(w *bufio.Writer, n int)       { fmt.Fprintf(w, ":%d\r\n", n) }
+func writeNil(w *bufio.Writer)              { w.WriteString("$-1\r\n") }
+func writeBulk(w *bufio.Writer, s string)   { fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s) }
+func writeArrayHeader(w *bufio.Writer, n int) {
+	fmt.Fprintf(w, "*%d\r\n", n)
+}
@@ -0,0 +1,373 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ethosview-backend/pkg/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// negativeTTL is how long a cached miss is served
+	negativeTTL = time.Minute
+	// lockTTL bounds how long one instance holds a key's load lock and how
+	// long others wait for its result before loading themselves
+	lockTTL = 10 * time.Second
+	// lockMargin allows for the Redis clock running ahead of ours; a lock
+	// older than lockTTL-lockMargin may already belong to another instance
+	lockMargin = time.Second
+	// lockPollInterval is how often a waiting instance checks for the entry
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// ErrNotFound reports data that does not exist. Loaders return it, or a
+// *NotFoundError, with tags, to have the miss cached for a minute; Fetch then
+// returns it without calling the loader. Untagged misses are not cached.
+var ErrNotFound = errors.New("cache: not found")
+
+// errLoadIncomplete is shared with waiting callers when a load panics
+var errLoadIncomplete = errors.New("cache: load did not complete")
+
+// NotFoundError is ErrNotFound with data describing the miss, such as a 404
+// response, which is cached with it and decoded into dest by Fetch
+type NotFoundError struct {
+	Data interface{}
+}
+
+func (e *NotFoundError) Error() string {
+	return ErrNotFound.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// Loader loads the value for a key and the tags it depends on. It runs with
+// the caller's context on a miss, and with a context detached from the caller
+// when refreshing a stale entry in the background.
+type Loader func(ctx context.Context) (value interface{}, tags []string, err error)
+
+// Fetch decodes the cached value for key into dest, calling load and caching
+// its result on a miss. Concurrent misses for a key load once per process
+// and, through a Redis lock, once across instances; the other callers wait for
+// that result. Entries past their strategy's fresh TTL are served until it
+// can no longer serve them stale, while refresh (load if nil) reloads them once
+// in the background. Cached misses return ErrNotFound. If Redis fails, Fetch
+// calls load directly; a nil cache always does.
+func (ac *AdvancedCache) Fetch(ctx context.Context, key string, dest interface{}, strategy CacheStrategy, load, refresh Loader) error {
+	if refresh == nil {
+		refresh = load
+	}
+	if ac == nil {
+		value, tags, err := load(ctx)
+		entry, err := loadedEntry(strategy, value, tags, err)
+		if err != nil {
+			return err
+		}
+		return decodeEntry(entry, dest)
+	}
+
+	entry, err := ac.getEntry(ctx, key)
+	if err != nil {
+		ac.logger.Warn("Cache read failed; loading directly", "key", key, "error", err)
+	}
+
+	switch {
+	case entry == nil:
+		metrics.CacheRequests.WithLabelValues("advanced", metrics.CacheMiss).Inc()
+		entry, err = ac.flights.do(ctx, key, func() (*CacheEntry, error) {
+			return ac.loadLocked(ctx, key, strategy, load)
+		})
+		if err != nil {
+			return err
+		}
+	case entry.stale():
+		metrics.CacheRequests.WithLabelValues("advanced", metrics.CacheStale).Inc()
+		ac.refreshInBackground(ctx, key, strategy, refresh)
+	default:
+		metrics.CacheRequests.WithLabelValues("advanced", metrics.CacheHit).Inc()
+	}
+	return decodeEntry(entry, dest)
+}
+
+// loadLocked loads key while holding its Redis lock, or waits for the entry
+// stored by the instance holding it. It loads without the lock if Redis fails
+// or the lock is held for longer than lockTTL.
+func (ac *AdvancedCache) loadLocked(ctx context.Context, key string, strategy CacheStrategy, load Loader) (*CacheEntry, error) {
+	lockKey := ac.buildLockKey(key)
+	deadline := time.Now().Add(lockTTL)
+	for {
+		start := time.Now()
+		acquired, err := ac.redis.SetNX(ctx, lockKey, "1", lockTTL).Result()
+		if err != nil {
+			ac.logger.Warn("Cache lock failed; loading without it", "key", key, "error", err)
+			return ac.load(ctx, key, strategy, load)
+		}
+		if acquired {
+			defer ac.unlock(ctx, lockKey, start)
+			return ac.load(ctx, key, strategy, load)
+		}
+		if time.Now().After(deadline) {
+			return ac.load(ctx, key, strategy, load)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+		if entry, _ := ac.getEntry(ctx, key); entry != nil {
+			return entry, nil
+		}
+	}
+}
+
+// refreshInBackground reloads a stale entry unless this process is already
+// refreshing it or another instance holds its lock
+func (ac *AdvancedCache) refreshInBackground(ctx context.Context, key string, strategy CacheStrategy, refresh Loader) {
+	if !ac.flights.startRefresh(key) {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer ac.flights.endRefresh(key)
+		ctx, cancel := context.WithTimeout(ctx, lockTTL)
+		defer cancel()
+
+		lockKey := ac.buildLockKey(key)
+		start := time.Now()
+		acquired, err := ac.redis.SetNX(ctx, lockKey, "1", lockTTL).Result()
+		if err != nil || !acquired {
+			return
+		}
+		defer ac.unlock(ctx, lockKey, start)
+
+		if _, err := ac.load(ctx, key, strategy, refresh); err != nil && !errors.Is(err, ErrNotFound) {
+			ac.logger.Warn("Background cache refresh failed", "key", key, "error", err)
+		}
+	}()
+}
+
+// unlock releases a lock taken at start. Once the lease may have run out the
+// lock may belong to another instance, so it is left to expire instead.
+func (ac *AdvancedCache) unlock(ctx context.Context, lockKey string, start time.Time) {
+	if time.Since(start) < lockTTL-lockMargin {
+		ac.redis.Del(context.WithoutCancel(ctx), lockKey)
+	}
+}
+
+// load calls load and caches its result. The generation is captured first so
+// a result loaded before a write commits is not cached after it.
+func (ac *AdvancedCache) load(ctx context.Context, key string, strategy CacheStrategy, load Loader) (*CacheEntry, error) {
+	generation, generationErr := ac.Generation(ctx)
+	value, tags, err := load(ctx)
+	entry, err := loadedEntry(strategy, value, tags, err)
+	if err != nil {
+		return nil, err
+	}
+	if generationErr != nil {
+		ac.logger.Warn("Cache generation unavailable; not caching", "key", key, "error", generationErr)
+		return entry, nil
+	}
+	if entry.NotFound && len(entry.Tags) == 0 {
+		// No write could invalidate an untagged miss, so creating what was
+		// missing would keep returning it until it expired
+		return entry, nil
+	}
+	if err := ac.store(context.WithoutCancel(ctx), key, entry, generation); err != nil {
+		ac.logger.Warn("Failed to cache loaded value", "key", key, "error", err)
+	}
+	return entry, nil
+}
+
+// store writes entry and indexes it under its tags, then deletes it again if
+// any of them was invalidated after generation. Indexing first means a
+// concurrent invalidation either finds the entry or is seen here.
+func (ac *AdvancedCache) store(ctx context.Context, key string, entry *CacheEntry, generation int64) error {
+	fullKey := ac.buildKey(key)
+	if err := ac.writeEntry(ctx, fullKey, entry); err != nil {
+		return err
+	}
+	if len(entry.Tags) == 0 {
+		return nil
+	}
+
+	err := ac.TagKey(ctx, fullKey, entry.Tags)
+	invalidated := false
+	if err == nil {
+		invalidated, err = ac.InvalidatedSince(ctx, entry.Tags, generation)
+	}
+	if err != nil || invalidated {
+		ac.redis.Del(ctx, fullKey)
+	}
+	return err
+}
+
+// writeEntry stores entry under fullKey until it expires
+func (ac *AdvancedCache) writeEntry(ctx context.Context, fullKey string, entry *CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return ac.redis.Set(ctx, fullKey, data, time.Until(entry.ExpiresAt)).Err()
+}
+
+// getEntry returns the entry for key, or nil if there is none
+func (ac *AdvancedCache) getEntry(ctx context.Context, key string) (*CacheEntry, error) {
+	fullKey := ac.buildKey(key)
+	pipe := ac.redis.Pipeline()
+	get := pipe.Get(ctx, fullKey)
+	ttl := pipe.PTTL(ctx, fullKey)
+	if _, err := pipe.Exec(ctx); err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal([]byte(get.Val()), &entry); err != nil {
+		// Invalid cache entry, delete it
+		ac.redis.Del(ctx, fullKey)
+		return nil, nil
+	}
+	entry.remaining = ttl.Val()
+	return &entry, nil
+}
+
+func (ac *AdvancedCache) buildLockKey(key string) string {
+	return fmt.Sprintf("%s:lock:%s", ac.prefix, key)
+}
+
+// newEntry builds an entry that is fresh and served for strategy's TTLs, or
+// for negativeTTL if it records a miss
+func newEntry(data interface{}, strategy CacheStrategy, tags []string, notFound bool) *CacheEntry {
+	fresh, stale := getStrategyTTLs(strategy)
+	if notFound {
+		fresh, stale = negativeTTL, negativeTTL
+	}
+	now := time.Now().UTC()
+	return &CacheEntry{
+		Data:      data,
+		CreatedAt: now,
+		StaleAt:   now.Add(fresh),
+		ExpiresAt: now.Add(stale),
+		Version:   fmt.Sprintf("v%d", now.Unix()),
+		Tags:      tags,
+		NotFound:  notFound,
+	}
+}
+
+// loadedEntry builds the entry for a loader's result; errors other than
+// ErrNotFound are returned instead
+func loadedEntry(strategy CacheStrategy, value interface{}, tags []string, err error) (*CacheEntry, error) {
+	var notFound *NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return newEntry(notFound.Data, strategy, tags, true), nil
+	case errors.Is(err, ErrNotFound):
+		return newEntry(nil, strategy, tags, true), nil
+	case err != nil:
+		return nil, err
+	}
+	return newEntry(value, strategy, tags, false), nil
+}
+
+// stale reports whether a stored entry is past its fresh TTL. Redis's TTL is
+// compared with the entry's stale window, so instances' clocks do not matter.
+// Entries written before soft expiry existed are fresh until they expire.
+func (e *CacheEntry) stale() bool {
+	window := e.ExpiresAt.Sub(e.StaleAt)
+	return !e.StaleAt.IsZero() && e.remaining > 0 && e.remaining < window
+}
+
+// decodeEntry decodes the entry's data into dest, returning ErrNotFound for
+// a cached miss
+func decodeEntry(entry *CacheEntry, dest interface{}) error {
+	if entry.Data != nil {
+		data, err := json.Marshal(entry.Data)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, dest); err != nil {
+			return err
+		}
+	}
+	if entry.NotFound {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// flightGroup coalesces concurrent loads and refreshes of a key within the
+// process
+type flightGroup struct {
+	mu        sync.Mutex
+	loads     map[string]*flight
+	refreshes map[string]bool
+}
+
+// flight is a load in progress; done is closed once entry and err are set
+type flight struct {
+	done  chan struct{}
+	entry *CacheEntry
+	err   error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		loads:     make(map[string]*flight),
+		refreshes: make(map[string]bool),
+	}
+}
+
+// do calls fn, or if a load of key is already in progress waits for its
+// result until ctx is done
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*CacheEntry, error)) (*CacheEntry, error) {
+	g.mu.Lock()
+	if f, ok := g.loads[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.entry, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{}), err: errLoadIncomplete}
+	g.loads[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.loads, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.entry, f.err = fn()
+	return f.entry, f.err
+}
+
+// startRefresh reports whether the caller should refresh key, marking it as
+// being refreshed until endRefresh
+func (g *flightGroup) startRefresh(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.refreshes[key] {
+		return false
+	}
+	g.refreshes[key] = true
+	return true
+}
+
+func (g *flightGroup) endRefresh(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.refreshes, key)
+}
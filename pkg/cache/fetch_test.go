@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ethosview-backend/pkg/cache/cachetest"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLoader returns value and tags, counting its calls and blocking on
+// release if set
+type countingLoader struct {
+	calls   atomic.Int32
+	release chan struct{}
+	value   atomic.Value
+	tags    []string
+	err     error
+}
+
+func (l *countingLoader) load(ctx context.Context) (interface{}, []string, error) {
+	l.calls.Add(1)
+	if l.release != nil {
+		<-l.release
+	}
+	return l.value.Load(), l.tags, l.err
+}
+
+func newCountingLoader(value string) *countingLoader {
+	loader := &countingLoader{}
+	loader.value.Store(value)
+	return loader
+}
+
+func TestFetchCoalescesConcurrentMisses(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	loader := newCountingLoader("summary")
+	loader.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, ac.Fetch(context.Background(), "analytics:summary", &results[i], ShortTerm, loader.load, nil))
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(loader.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loader.calls.Load())
+	for _, result := range results {
+		assert.Equal(t, "summary", result)
+	}
+}
+
+func TestFetchCoalescesAcrossInstances(t *testing.T) {
+	server := cachetest.NewServer(t)
+	first := NewAdvancedCache(server.Client(t), "test", nil)
+	second := NewAdvancedCache(server.Client(t), "test", nil)
+	firstLoader := newCountingLoader("from first")
+	firstLoader.release = make(chan struct{})
+	secondLoader := newCountingLoader("from second")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var result string
+		assert.NoError(t, first.Fetch(context.Background(), "hot", &result, ShortTerm, firstLoader.load, nil))
+	}()
+	require.Eventually(t, func() bool { return firstLoader.calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// The second instance waits on the first one's lock instead of loading
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(firstLoader.release)
+	}()
+	var result string
+	require.NoError(t, second.Fetch(context.Background(), "hot", &result, ShortTerm, secondLoader.load, nil))
+	<-done
+
+	assert.Equal(t, "from first", result)
+	assert.Equal(t, int32(0), secondLoader.calls.Load())
+	assert.NotContains(t, server.Keys(), "test:lock:hot", "the lock is released")
+}
+
+func TestFetchServesStaleWhileRefreshing(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	loader := newCountingLoader("v1")
+	ctx := context.Background()
+
+	var result string
+	require.NoError(t, ac.Fetch(ctx, "summary", &result, ShortTerm, loader.load, nil))
+
+	server.FastForward(4 * time.Minute)
+	require.NoError(t, ac.Fetch(ctx, "summary", &result, ShortTerm, loader.load, nil))
+	assert.Equal(t, int32(1), loader.calls.Load(), "fresh for 5 minutes")
+
+	// Past the fresh TTL the old value is served while one refresh runs
+	server.FastForward(2 * time.Minute)
+	loader.value.Store("v2")
+	loader.release = make(chan struct{})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ac.Fetch(ctx, "summary", &result, ShortTerm, loader.load, nil))
+		assert.Equal(t, "v1", result)
+	}
+	close(loader.release)
+
+	require.Eventually(t, func() bool {
+		var refreshed string
+		_, err := ac.Get(ctx, "summary", &refreshed)
+		return err == nil && refreshed == "v2"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), loader.calls.Load())
+
+	// Past the stale TTL the entry is gone and loads again
+	server.FastForward(16 * time.Minute)
+	loader.value.Store("v3")
+	require.NoError(t, ac.Fetch(ctx, "summary", &result, ShortTerm, loader.load, nil))
+	assert.Equal(t, "v3", result)
+}
+
+func TestFetchSkipsRefreshLoadedBeforeInvalidation(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	ctx := context.Background()
+
+	loader := newCountingLoader("before write")
+	loader.tags = []string{TagESG}
+	var result string
+	require.NoError(t, ac.Fetch(ctx, "scores", &result, ShortTerm, func(ctx context.Context) (interface{}, []string, error) {
+		// A write commits while the value is loading
+		require.NoError(t, ac.InvalidateTags(ctx, TagESG))
+		return loader.load(ctx)
+	}, nil))
+	assert.Equal(t, "before write", result)
+
+	found, err := ac.Get(ctx, "scores", &result)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestFetchCachesNotFound(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	loader := newCountingLoader("")
+	loader.err = &NotFoundError{Data: "no such company"}
+	loader.tags = []string{TagCompanies}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		var result string
+		err := ac.Fetch(ctx, "company:404", &result, MediumTerm, loader.load, nil)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, "no such company", result)
+	}
+	assert.Equal(t, int32(1), loader.calls.Load())
+
+	server.FastForward(negativeTTL + time.Second)
+	var result string
+	assert.ErrorIs(t, ac.Fetch(ctx, "company:404", &result, MediumTerm, loader.load, nil), ErrNotFound)
+	assert.Equal(t, int32(2), loader.calls.Load(), "misses are cached briefly")
+}
+
+func TestFetchDoesNotCacheUntaggedMisses(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	loader := newCountingLoader("")
+	loader.err = ErrNotFound
+
+	for i := 0; i < 2; i++ {
+		var result string
+		assert.ErrorIs(t, ac.Fetch(context.Background(), "company:404", &result, MediumTerm, loader.load, nil), ErrNotFound)
+	}
+	assert.Equal(t, int32(2), loader.calls.Load(), "nothing could invalidate the miss")
+	assert.Empty(t, server.Keys())
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	loader := newCountingLoader("")
+	loader.err = errors.New("database unavailable")
+
+	for i := 0; i < 2; i++ {
+		var result string
+		assert.ErrorContains(t, ac.Fetch(context.Background(), "summary", &result, ShortTerm, loader.load, nil), "database unavailable")
+	}
+	assert.Equal(t, int32(2), loader.calls.Load())
+	assert.Empty(t, server.Keys())
+}
+
+func TestFetchLoadsDirectlyWithoutRedis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+	ac := NewAdvancedCache(client, "test", nil)
+	loader := newCountingLoader("direct")
+
+	var result string
+	require.NoError(t, ac.Fetch(context.Background(), "summary", &result, ShortTerm, loader.load, nil))
+	assert.Equal(t, "direct", result)
+
+	var nilCache *AdvancedCache
+	result = ""
+	require.NoError(t, nilCache.Fetch(context.Background(), "summary", &result, ShortTerm, loader.load, nil))
+	assert.Equal(t, "direct", result)
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tags name the data a cached response depends on; writes invalidate them
+const (
+	// TagCompanies covers company lists and the sector list
+	TagCompanies = "companies"
+	// TagESG covers ESG score lists and per-company score history
+	TagESG = "esg"
+	// TagAnalytics covers analytics derived from companies, ESG scores and prices
+	TagAnalytics = "analytics"
+)
+
+// tagTTL keeps tag sets and invalidation marks slightly longer than the
+// longest cache strategy serves entries
+const tagTTL = 26 * time.Hour
+
+// CompanyTag is the tag for responses about one company
+func CompanyTag(id int) string {
+	return "company:" + strconv.Itoa(id)
+}
+
+// UserTag is the tag for responses cached for one user
+func UserTag(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
+
+// SectorTag is the tag for responses about one sector
+func SectorTag(sector string) string {
+	return "sector:" + sector
+}
+
+// Generation returns the current invalidation generation. Capture it before
+// loading data to cache and pass it to InvalidatedSince before storing.
+func (ac *AdvancedCache) Generation(ctx context.Context) (int64, error) {
+	generation, err := ac.redis.Get(ctx, ac.generationKey()).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// TagKey records that the Redis key depends on tags, so invalidating any of
+// them deletes it. Unlike Set, key is not prefixed.
+func (ac *AdvancedCache) TagKey(ctx context.Context, key string, tags []string) error {
+	pipe := ac.redis.Pipeline()
+	for _, tag := range tags {
+		tagKey := ac.buildTagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		pipe.Expire(ctx, tagKey, tagTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// InvalidatedSince reports whether any of the tags was invalidated after
+// generation was captured. Data loaded before then may predate the write and
+// must not be cached.
+func (ac *AdvancedCache) InvalidatedSince(ctx context.Context, tags []string, generation int64) (bool, error) {
+	if len(tags) == 0 {
+		return false, nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = ac.buildTagGenerationKey(tag)
+	}
+	values, err := ac.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if invalidated, err := strconv.ParseInt(s, 10, 64); err == nil && invalidated > generation {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InvalidateTags deletes every entry tagged with any of tags. Call it after
+// the write commits: it first advances the generation so responses loaded
+// before the commit are not cached afterwards, then deletes what is cached.
+// If that fails the tags are kept and RetryInvalidations retries them until
+// it succeeds. A nil cache does nothing.
+func (ac *AdvancedCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if ac == nil || len(tags) == 0 {
+		return nil
+	}
+
+	if err := ac.invalidateTags(ctx, tags); err != nil {
+		ac.pending.add(tags, time.Now())
+		return err
+	}
+	return nil
+}
+
+// RetryInvalidations retries failed tag invalidations every interval until
+// ctx is cancelled. A tag is dropped once tagTTL has passed since it first
+// failed, by when every entry cached before the write has expired. Retries
+// are held in memory, so tags still pending when the process exits are only
+// cleared by their entries expiring.
+func (ac *AdvancedCache) RetryInvalidations(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ac.retryPending(ctx, time.Now())
+		}
+	}
+}
+
+// retryPending invalidates the pending tags, keeping them if that fails again
+func (ac *AdvancedCache) retryPending(ctx context.Context, now time.Time) {
+	failed := ac.pending.take(now.Add(-tagTTL))
+	if len(failed) == 0 {
+		return
+	}
+
+	tags := make([]string, 0, len(failed))
+	for tag := range failed {
+		tags = append(tags, tag)
+	}
+	if err := ac.invalidateTags(ctx, tags); err != nil {
+		ac.logger.Warn("Retrying cache invalidation failed", "tags", tags, "error", err)
+		for tag, since := range failed {
+			ac.pending.add([]string{tag}, since)
+		}
+		return
+	}
+	ac.logger.Info("Retried cache invalidation", "tags", tags)
+}
+
+func (ac *AdvancedCache) invalidateTags(ctx context.Context, tags []string) error {
+	generation, err := ac.redis.Incr(ctx, ac.generationKey()).Result()
+	if err != nil {
+		return fmt.Errorf("advance cache generation: %w", err)
+	}
+	pipe := ac.redis.Pipeline()
+	for _, tag := range tags {
+		pipe.Set(ctx, ac.buildTagGenerationKey(tag), generation, tagTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("mark invalidated tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if err := ac.InvalidateByTag(ctx, tag); err != nil {
+			return fmt.Errorf("invalidate tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// pendingInvalidations holds the tags whose invalidation failed, with when
+// each first failed
+type pendingInvalidations struct {
+	mu   sync.Mutex
+	tags map[string]time.Time
+}
+
+// add records tags as failing since since, keeping earlier failure times
+func (p *pendingInvalidations) add(tags []string, since time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, tag := range tags {
+		if first, ok := p.tags[tag]; !ok || since.Before(first) {
+			p.tags[tag] = since
+		}
+	}
+}
+
+// take removes and returns the pending tags, discarding those that first
+// failed before cutoff
+func (p *pendingInvalidations) take(cutoff time.Time) map[string]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	taken := make(map[string]time.Time, len(p.tags))
+	for tag, since := range p.tags {
+		if !since.Before(cutoff) {
+			taken[tag] = since
+		}
+	}
+	p.tags = make(map[string]time.Time)
+	return taken
+}
+
+func (ac *AdvancedCache) generationKey() string {
+	return fmt.Sprintf("%s:tag-generation", ac.prefix)
+}
+
+func (ac *AdvancedCache) buildTagGenerationKey(tag string) string {
+	return fmt.Sprintf("%s:tag-generation:%s", ac.prefix, tag)
+}
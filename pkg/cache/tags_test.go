@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"ethosview-backend/pkg/cache/cachetest"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidateTagsDeletesTaggedEntries(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	ctx := context.Background()
+
+	require.NoError(t, ac.Set(ctx, "company:1", map[string]int{"id": 1}, ShortTerm, []string{CompanyTag(1)}))
+	require.NoError(t, ac.Set(ctx, "company:2", map[string]int{"id": 2}, ShortTerm, []string{CompanyTag(2)}))
+	require.NoError(t, ac.Set(ctx, "list", []int{1, 2}, ShortTerm, []string{TagCompanies}))
+
+	require.NoError(t, ac.InvalidateTags(ctx, CompanyTag(1), TagCompanies))
+
+	var dest interface{}
+	found, err := ac.Get(ctx, "company:1", &dest)
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = ac.Get(ctx, "list", &dest)
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = ac.Get(ctx, "company:2", &dest)
+	require.NoError(t, err)
+	assert.True(t, found, "untagged entries survive")
+}
+
+func TestInvalidatedSince(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	ctx := context.Background()
+
+	before, err := ac.Generation(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ac.InvalidateTags(ctx, SectorTag("Energy")))
+
+	stale, err := ac.InvalidatedSince(ctx, []string{SectorTag("Energy")}, before)
+	require.NoError(t, err)
+	assert.True(t, stale, "data loaded before the write is stale")
+
+	stale, err = ac.InvalidatedSince(ctx, []string{SectorTag("Technology")}, before)
+	require.NoError(t, err)
+	assert.False(t, stale, "other tags are unaffected")
+
+	after, err := ac.Generation(ctx)
+	require.NoError(t, err)
+	stale, err = ac.InvalidatedSince(ctx, []string{SectorTag("Energy")}, after)
+	require.NoError(t, err)
+	assert.False(t, stale, "data loaded after the write is fresh")
+}
+
+func TestTagKeyExpiresWithTagTTL(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	ctx := context.Background()
+
+	require.NoError(t, ac.TagKey(ctx, "cache:abc", []string{TagESG}))
+	assert.Equal(t, []string{"test:tag:esg"}, server.Keys())
+
+	server.FastForward(tagTTL + time.Second)
+	assert.Empty(t, server.Keys())
+}
+
+func TestInvalidateTagsOnNilCache(t *testing.T) {
+	var ac *AdvancedCache
+	assert.NoError(t, ac.InvalidateTags(context.Background(), TagAnalytics))
+}
+
+func TestFailedInvalidationIsRetried(t *testing.T) {
+	server := cachetest.NewServer(t)
+	ac := NewAdvancedCache(server.Client(t), "test", nil)
+	ctx := context.Background()
+	require.NoError(t, ac.Set(ctx, "company:1", map[string]int{"id": 1}, ShortTerm, []string{CompanyTag(1)}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.Close()
+	ac.redis = redis.NewClient(&redis.Options{Addr: listener.Addr().String(), MaxRetries: -1})
+	require.Error(t, ac.InvalidateTags(ctx, CompanyTag(1)))
+
+	ac.redis = server.Client(t)
+	ac.retryPending(ctx, time.Now())
+
+	var dest interface{}
+	found, err := ac.Get(ctx, "company:1", &dest)
+	require.NoError(t, err)
+	assert.False(t, found, "the retry deletes the entry")
+	assert.Empty(t, ac.pending.take(time.Time{}))
+}
+
+func TestPendingInvalidationsExpire(t *testing.T) {
+	pending := &pendingInvalidations{tags: make(map[string]time.Time)}
+	now := time.Now()
+	pending.add([]string{TagESG}, now.Add(-tagTTL-time.Minute))
+	pending.add([]string{TagCompanies}, now)
+
+	taken := pending.take(now.Add(-tagTTL))
+	assert.Equal(t, map[string]time.Time{TagCompanies: now}, taken, "tags older than tagTTL are dropped")
+}
@@ -5,53 +5,57 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"ethosview-backend/internal/models"
+	"ethosview-backend/pkg/logging"
 
 	"github.com/redis/go-redis/v9"
 )
 
 // CacheWarmer handles cache warming operations
 type CacheWarmer struct {
-	redis *redis.Client
-	db    *sql.DB
+	redis  *redis.Client
+	db     *sql.DB
+	logger *slog.Logger
 }
 
-// NewCacheWarmer creates a new cache warmer instance
-func NewCacheWarmer(redis *redis.Client, db *sql.DB) *CacheWarmer {
+// NewCacheWarmer creates a new cache warmer instance; a nil logger uses the
+// default
+func NewCacheWarmer(redis *redis.Client, db *sql.DB, logger *slog.Logger) *CacheWarmer {
 	return &CacheWarmer{
-		redis: redis,
-		db:    db,
+		redis:  redis,
+		db:     db,
+		logger: logging.Or(logger).With("component", "cache_warmer"),
 	}
 }
 
 // WarmCache performs cache warming for frequently accessed data
 func (cw *CacheWarmer) WarmCache() error {
-	log.Println("🔥 Starting cache warming...")
+	cw.logger.Info("Starting cache warming")
 
 	// Warm company data
 	if err := cw.warmCompanies(); err != nil {
-		log.Printf("Error warming companies: %v", err)
+		cw.logger.Error("Error warming companies", "error", err)
 	}
 
 	// Warm ESG scores
 	if err := cw.warmESGScores(); err != nil {
-		log.Printf("Error warming ESG scores: %v", err)
+		cw.logger.Error("Error warming ESG scores", "error", err)
 	}
 
 	// Warm sector data
 	if err := cw.warmSectors(); err != nil {
-		log.Printf("Error warming sectors: %v", err)
+		cw.logger.Error("Error warming sectors", "error", err)
 	}
 
 	// Warm analytics data
 	if err := cw.warmAnalytics(); err != nil {
-		log.Printf("Error warming analytics: %v", err)
+		cw.logger.Error("Error warming analytics", "error", err)
 	}
 
-	log.Println("✅ Cache warming completed")
+	cw.logger.Info("Cache warming completed")
 	return nil
 }
 
@@ -89,7 +93,7 @@ func (cw *CacheWarmer) warmCompanies() error {
 		cw.redis.Set(ctx, fmt.Sprintf("cache:company:symbol:%s", company.Symbol), companyData, 30*time.Minute)
 	}
 
-	log.Printf("Warmed %d companies", len(companies))
+	cw.logger.Debug("Warmed companies", "count", len(companies))
 	return nil
 }
 
@@ -139,7 +143,7 @@ func (cw *CacheWarmer) warmESGScores() error {
 	// Cache top performers
 	cw.warmTopPerformers(scores)
 
-	log.Printf("Warmed %d ESG scores", len(scores))
+	cw.logger.Debug("Warmed ESG scores", "count", len(scores))
 	return nil
 }
 
@@ -193,7 +197,7 @@ func (cw *CacheWarmer) warmSectors() error {
 	sectorsData, _ := json.Marshal(sectors)
 	cw.redis.Set(ctx, "cache:sectors:all", sectorsData, 1*time.Hour)
 
-	log.Printf("Warmed %d sectors", len(sectors))
+	cw.logger.Debug("Warmed sectors", "count", len(sectors))
 	return nil
 }
 
@@ -211,7 +215,7 @@ func (cw *CacheWarmer) warmAnalytics() error {
 	summaryData, _ := json.Marshal(summary)
 	cw.redis.Set(ctx, "cache:analytics:summary", summaryData, 10*time.Minute)
 
-	log.Println("Warmed analytics data")
+	cw.logger.Debug("Warmed analytics data")
 	return nil
 }
 
@@ -265,17 +269,20 @@ func (cw *CacheWarmer) getESGScoreCount() int {
 	return count
 }
 
-// StartCacheWarming starts periodic cache warming
-func (cw *CacheWarmer) StartCacheWarming(interval time.Duration) {
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+// Run warms the cache now and then every interval until ctx is cancelled
+func (cw *CacheWarmer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		// Initial warming
-		cw.WarmCache()
+	// Initial warming
+	cw.WarmCache()
 
-		for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 			cw.WarmCache()
 		}
-	}()
+	}
 }
@@ -30,6 +30,7 @@ type AppError struct {
 	Error     string      `json:"error"`
 	Details   interface{} `json:"details,omitempty"`
 	RequestID string      `json:"request_id,omitempty"`
+	TraceID   string      `json:"trace_id,omitempty"`
 	Timestamp string      `json:"timestamp"`
 }
 
@@ -53,10 +54,8 @@ func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 		Timestamp: getCurrentTimestamp(),
 	}
 
-	// Add request ID if available
-	if requestID, exists := c.Get("request_id"); exists {
-		appErr.RequestID = requestID.(string)
-	}
+	// Add request and trace IDs if available
+	addRequestIDs(c, appErr)
 
 	c.JSON(statusCode, appErr)
 }
@@ -103,11 +102,20 @@ func HandleRateLimitError(c *gin.Context, limit int) {
 		Timestamp: getCurrentTimestamp(),
 	}
 
+	addRequestIDs(c, appErr)
+
+	c.JSON(http.StatusTooManyRequests, appErr)
+}
+
+// addRequestIDs copies the request ID and trace ID, when set, into an error
+// so a failed request can be found in logs and traces
+func addRequestIDs(c *gin.Context, appErr *AppError) {
 	if requestID, exists := c.Get("request_id"); exists {
 		appErr.RequestID = requestID.(string)
 	}
-
-	c.JSON(http.StatusTooManyRequests, appErr)
+	if traceID, exists := c.Get("trace_id"); exists {
+		appErr.TraceID = traceID.(string)
+	}
 }
 
 // SuccessResponse sends a standardized success response
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"net/http"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,8 +14,9 @@ import (
 
 // HealthChecker handles comprehensive health checks
 type HealthChecker struct {
-	db    *sql.DB
-	redis *redis.Client
+	db       *sql.DB
+	redis    *redis.Client
+	draining atomic.Bool
 }
 
 // NewHealthChecker creates a new health checker
@@ -25,6 +27,17 @@ func NewHealthChecker(db *sql.DB, redis *redis.Client) *HealthChecker {
 	}
 }
 
+// SetDraining marks the server as shutting down; readiness then fails so load
+// balancers stop sending new traffic while in-flight requests finish
+func (hc *HealthChecker) SetDraining(draining bool) {
+	hc.draining.Store(draining)
+}
+
+// Draining reports whether the server is shutting down
+func (hc *HealthChecker) Draining() bool {
+	return hc.draining.Load()
+}
+
 // HealthStatus represents the overall health status
 type HealthStatus struct {
 	Status      string               `json:"status"`
@@ -298,6 +311,15 @@ func (hc *HealthChecker) DetailedHealthCheckHandler() gin.HandlerFunc {
 // ReadinessCheckHandler checks if the service is ready to serve traffic
 func (hc *HealthChecker) ReadinessCheckHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if hc.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"ready":    false,
+				"draining": true,
+				"issues":   []string{"Server is shutting down"},
+			})
+			return
+		}
+
 		// Check critical dependencies
 		ready := true
 		issues := []string{}
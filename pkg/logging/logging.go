@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive fields
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are matched against normalised attribute and query parameter
+// names; any name containing one of them is redacted
+var sensitiveKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"authorization",
+	"cookie",
+	"signature",
+}
+
+// Logger owns the process logger and the level it logs at, which can be
+// changed while the server runs
+type Logger struct {
+	*slog.Logger
+	level *slog.LevelVar
+}
+
+// New creates a JSON logger writing to w at the given level
+func New(w io.Writer, level slog.Level) *Logger {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       levelVar,
+		ReplaceAttr: redactAttr,
+	})
+	return &Logger{Logger: slog.New(handler), level: levelVar}
+}
+
+// FromEnv creates a JSON logger on stdout at LOG_LEVEL (debug, info, warn or
+// error; info by default). An unknown level is reported and info is used.
+func FromEnv() *Logger {
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	logger := New(os.Stdout, level)
+	if err != nil {
+		logger.Warn("Ignoring LOG_LEVEL", "error", err)
+	}
+	return logger
+}
+
+// Level returns the current minimum level
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the minimum level for every logger derived from l
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// ParseLevel parses a level name; an empty name is info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+// LevelName returns the lower-case name ParseLevel accepts
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Or returns logger, or the default logger when logger is nil, so components
+// can be constructed without one in tests
+func Or(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// IsSensitive reports whether a field or parameter name holds a credential.
+// Identifiers and counts such as api_key_id are not sensitive.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	if strings.HasSuffix(key, "_id") || strings.HasSuffix(key, "_ids") || strings.HasSuffix(key, "_count") {
+		return false
+	}
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactQuery encodes query parameters with sensitive values redacted
+func RedactQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	redacted := make(url.Values, len(query))
+	for key, values := range query {
+		if IsSensitive(key) {
+			redacted[key] = []string{Redacted}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted.Encode()
+}
+
+// redactAttr hides the value of sensitive attributes, including ones nested
+// in groups
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
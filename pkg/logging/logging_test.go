@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	logger.Info("Login attempt",
+		"email", "ada@example.com",
+		"password", "hunter2",
+		"refresh_token", "eyJhbGciOi",
+		"api_key_id", 3,
+		slog.Group("headers", "Authorization", "Bearer abc", "X-API-Key", "evk_123", "Accept", "application/json"),
+	)
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	entry := lines[0]
+	assert.Equal(t, "ada@example.com", entry["email"])
+	assert.Equal(t, Redacted, entry["password"])
+	assert.Equal(t, Redacted, entry["refresh_token"])
+	assert.Equal(t, float64(3), entry["api_key_id"], "identifiers are not redacted")
+	headers := entry["headers"].(map[string]interface{})
+	assert.Equal(t, Redacted, headers["Authorization"])
+	assert.Equal(t, Redacted, headers["X-API-Key"])
+	assert.Equal(t, "application/json", headers["Accept"])
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestRedactQuery(t *testing.T) {
+	query := url.Values{"token": {"eyJhbGciOi"}, "limit": {"10"}, "apiKey": {"evk_123"}}
+	assert.Equal(t, "apiKey=%5BREDACTED%5D&limit=10&token=%5BREDACTED%5D", RedactQuery(query))
+	assert.Equal(t, "", RedactQuery(nil))
+}
+
+func TestRuntimeLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+	derived := logger.With("component", "cache_warmer")
+
+	derived.Debug("Warmed sectors")
+	assert.Empty(t, buf.String())
+
+	logger.SetLevel(slog.LevelDebug)
+	derived.Debug("Warmed sectors")
+	assert.Equal(t, slog.LevelDebug, logger.Level())
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "cache_warmer", lines[0]["component"])
+	assert.Equal(t, "DEBUG", lines[0]["level"])
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		" INFO ":  slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+	assert.Equal(t, "warn", LevelName(slog.LevelWarn))
+}
+
+func TestContextLogger(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	assert.Equal(t, logger, FromContext(WithContext(context.Background(), logger)))
+}
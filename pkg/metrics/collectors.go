@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"database/sql"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+)
+
+var startTime = time.Now()
+
+// RegisterDBStats exports database/sql connection pool statistics as the
+// go_sql_* metrics, labelled db_name="ethosview"
+func RegisterDBStats(r prometheus.Registerer, db *sql.DB) {
+	r.MustRegister(collectors.NewDBStatsCollector(db, "ethosview"))
+}
+
+// RegisterRedisPoolStats exports go-redis connection pool statistics
+func RegisterRedisPoolStats(r prometheus.Registerer, client *redis.Client) {
+	gauge := func(name, help string, value func(*redis.PoolStats) float64) {
+		r.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help},
+			func() float64 { return value(client.PoolStats()) }))
+	}
+	counter := func(name, help string, value func(*redis.PoolStats) float64) {
+		r.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help},
+			func() float64 { return value(client.PoolStats()) }))
+	}
+
+	gauge("ethosview_redis_pool_total_connections", "Redis connections in the pool.",
+		func(s *redis.PoolStats) float64 { return float64(s.TotalConns) })
+	gauge("ethosview_redis_pool_idle_connections", "Idle Redis connections in the pool.",
+		func(s *redis.PoolStats) float64 { return float64(s.IdleConns) })
+	counter("ethosview_redis_pool_hits_total", "Times a free Redis connection was found in the pool.",
+		func(s *redis.PoolStats) float64 { return float64(s.Hits) })
+	counter("ethosview_redis_pool_misses_total", "Times no free Redis connection was found in the pool.",
+		func(s *redis.PoolStats) float64 { return float64(s.Misses) })
+	counter("ethosview_redis_pool_timeouts_total", "Times waiting for a Redis connection timed out.",
+		func(s *redis.PoolStats) float64 { return float64(s.Timeouts) })
+	counter("ethosview_redis_pool_stale_connections_total", "Stale Redis connections removed from the pool.",
+		func(s *redis.PoolStats) float64 { return float64(s.StaleConns) })
+}
+
+// readMemStats reads the runtime memory statistics
+func readMemStats() runtime.MemStats {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterPoolStats(t *testing.T) {
+	// Pool statistics are read without connecting
+	db, err := sql.Open("postgres", "postgres://127.0.0.1:1/none?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+
+	r := prometheus.NewRegistry()
+	RegisterDBStats(r, db)
+	RegisterRedisPoolStats(r, client)
+
+	assert.NoError(t, testutil.GatherAndCompare(r, strings.NewReader(`
+# HELP go_sql_max_open_connections Maximum number of open connections to the database.
+# TYPE go_sql_max_open_connections gauge
+go_sql_max_open_connections{db_name="ethosview"} 7
+# HELP ethosview_redis_pool_total_connections Redis connections in the pool.
+# TYPE ethosview_redis_pool_total_connections gauge
+ethosview_redis_pool_total_connections 0
+`), "go_sql_max_open_connections", "ethosview_redis_pool_total_connections"))
+
+	assert.Panics(t, func() { RegisterRedisPoolStats(r, client) }, "a server registers its collectors once")
+}
@@ -5,27 +5,35 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"ethosview-backend/pkg/logging"
+
 	"github.com/redis/go-redis/v9"
 )
 
 // MetricsCollector handles custom metrics collection
 type MetricsCollector struct {
-	redis *redis.Client
-	db    *sql.DB
-	mu    sync.RWMutex
-	stats map[string]interface{}
+	redis  *redis.Client
+	db     *sql.DB
+	mu     sync.RWMutex
+	stats  map[string]interface{}
+	logger *slog.Logger
 }
 
-// NewMetricsCollector creates a new metrics collector
-func NewMetricsCollector(redis *redis.Client, db *sql.DB) *MetricsCollector {
+// NewMetricsCollector creates a new metrics collector; a nil logger uses the
+// default
+func NewMetricsCollector(redis *redis.Client, db *sql.DB, logger *slog.Logger) *MetricsCollector {
 	return &MetricsCollector{
-		redis: redis,
-		db:    db,
-		stats: make(map[string]interface{}),
+		redis:  redis,
+		db:     db,
+		stats:  make(map[string]interface{}),
+		logger: logging.Or(logger).With("component", "metrics_collector"),
 	}
 }
 
@@ -36,17 +44,17 @@ func (mc *MetricsCollector) CollectMetrics() error {
 
 	// Collect database metrics
 	if err := mc.collectDatabaseMetrics(); err != nil {
-		log.Printf("Error collecting database metrics: %v", err)
+		mc.logger.Error("Error collecting database metrics", "error", err)
 	}
 
 	// Collect cache metrics
 	if err := mc.collectCacheMetrics(); err != nil {
-		log.Printf("Error collecting cache metrics: %v", err)
+		mc.logger.Error("Error collecting cache metrics", "error", err)
 	}
 
 	// Collect business metrics
 	if err := mc.collectBusinessMetrics(); err != nil {
-		log.Printf("Error collecting business metrics: %v", err)
+		mc.logger.Error("Error collecting business metrics", "error", err)
 	}
 
 	// Collect system metrics
@@ -130,15 +138,6 @@ func (mc *MetricsCollector) collectCacheMetrics() error {
 		}
 	}
 
-	// Cache key count by pattern
-	patterns := []string{"cache:companies:*", "cache:esg:*", "cache:sectors:*", "cache:analytics:*"}
-	for _, pattern := range patterns {
-		keys, err := mc.redis.Keys(ctx, pattern).Result()
-		if err == nil {
-			mc.stats[fmt.Sprintf("cache.keys.%s", pattern)] = len(keys)
-		}
-	}
-
 	return nil
 }
 
@@ -192,17 +191,14 @@ func (mc *MetricsCollector) collectBusinessMetrics() error {
 
 // collectSystemMetrics collects system-level metrics
 func (mc *MetricsCollector) collectSystemMetrics() {
-	// Timestamp
-	mc.stats["system.timestamp"] = time.Now().UTC()
-
-	// Uptime (simplified - in production you'd track this from startup)
-	mc.stats["system.uptime"] = "running"
+	memStats := readMemStats()
 
-	// Memory usage (simplified)
-	mc.stats["system.memory_usage"] = "monitored"
-
-	// CPU usage (simplified)
-	mc.stats["system.cpu_usage"] = "monitored"
+	mc.stats["system.timestamp"] = time.Now().UTC()
+	mc.stats["system.uptime_seconds"] = time.Since(startTime).Seconds()
+	mc.stats["system.memory.heap_alloc_bytes"] = memStats.HeapAlloc
+	mc.stats["system.memory.sys_bytes"] = memStats.Sys
+	mc.stats["system.goroutines"] = runtime.NumGoroutine()
+	mc.stats["system.num_cpu"] = runtime.NumCPU()
 }
 
 // storeMetrics stores collected metrics in Redis
@@ -252,11 +248,16 @@ func (mc *MetricsCollector) cleanupOldMetrics(ctx context.Context) {
 	}
 }
 
-// parseRedisInfo parses Redis info output for specific metrics
+// parseRedisInfo returns an integer field of Redis INFO output, or 0 if it is missing
 func (mc *MetricsCollector) parseRedisInfo(info, key string) interface{} {
-	// Simple parsing - in production you'd use a more robust parser
-	// This is a simplified implementation
-	return 0 // Placeholder
+	for _, line := range strings.Split(info, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), key); found {
+			if n, err := strconv.Atoi(value); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
 }
 
 // GetMetrics retrieves stored metrics
@@ -301,17 +302,20 @@ func (mc *MetricsCollector) GetMetricsHistory(limit int) ([]map[string]interface
 	return history, nil
 }
 
-// StartMetricsCollection starts periodic metrics collection
-func (mc *MetricsCollector) StartMetricsCollection(interval time.Duration) {
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+// Run collects metrics now and then every interval until ctx is cancelled
+func (mc *MetricsCollector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		// Initial collection
-		mc.CollectMetrics()
+	// Initial collection
+	mc.CollectMetrics()
 
-		for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 			mc.CollectMetrics()
 		}
-	}()
+	}
 }
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Cache request results
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+	// CacheStale is a hit on an entry past its fresh TTL, served while it is
+	// refreshed in the background
+	CacheStale = "stale"
+)
+
+// Instruments shared across packages, registered on the default Prometheus
+// registry alongside its Go runtime and process collectors
+var (
+	// HTTPRequests counts requests by method, route template and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ethosview_http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route template and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ethosview_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds by method, route template and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestsInFlight is the number of requests being served
+	HTTPRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ethosview_http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	// CacheRequests counts cache lookups by cache and result (hit, stale or miss)
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ethosview_cache_requests_total",
+		Help: "Cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	// AlertNotifications counts monitoring alert notifications by notifier
+	// and result (sent, failed or rate_limited)
+	AlertNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ethosview_alert_notifications_total",
+		Help: "Monitoring alert notifications by notifier and result.",
+	}, []string{"notifier", "result"})
+)
+
+// Handler serves the default registry and any extra registries, such as one
+// holding a server's own collectors, in the Prometheus exposition format
+func Handler(extra ...prometheus.Gatherer) http.Handler {
+	gatherers := append(prometheus.Gatherers{prometheus.DefaultGatherer}, extra...)
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// HTTPTotals are cumulative request counts and latency across all routes
+type HTTPTotals struct {
+	Requests        uint64
+	ServerErrors    uint64
+	DurationSeconds float64
+}
+
+// ReadHTTPTotals sums HTTPRequestDuration across its labels; requests with a
+// 5xx status count as server errors
+func ReadHTTPTotals() HTTPTotals {
+	ch := make(chan prometheus.Metric, 64)
+	go func() {
+		HTTPRequestDuration.Collect(ch)
+		close(ch)
+	}()
+
+	var totals HTTPTotals
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+		histogram := m.GetHistogram()
+		totals.Requests += histogram.GetSampleCount()
+		totals.DurationSeconds += histogram.GetSampleSum()
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "status" && strings.HasPrefix(label.GetValue(), "5") {
+				totals.ServerErrors += histogram.GetSampleCount()
+			}
+		}
+	}
+	return totals
+}
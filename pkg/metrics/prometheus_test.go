@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadHTTPTotals(t *testing.T) {
+	before := ReadHTTPTotals()
+	HTTPRequestDuration.WithLabelValues("GET", "/api/v1/companies", "200").Observe(0.25)
+	HTTPRequestDuration.WithLabelValues("GET", "/api/v1/companies", "503").Observe(0.5)
+	HTTPRequestDuration.WithLabelValues("POST", "/api/v1/companies", "404").Observe(0.25)
+	after := ReadHTTPTotals()
+
+	assert.Equal(t, uint64(3), after.Requests-before.Requests)
+	assert.Equal(t, uint64(1), after.ServerErrors-before.ServerErrors)
+	assert.InDelta(t, 1.0, after.DurationSeconds-before.DurationSeconds, 1e-9)
+}
@@ -38,10 +38,117 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens that were revoked by logout
+		revoked, err := jwtManager.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify token status"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("user_roles", claims.Roles)
+		c.Set("token_claims", claims)
+
+		c.Next()
+	}
+}
+
+// AuthenticateAny dispatches to API key authentication when the request carries
+// an X-API-Key header and to JWT authentication otherwise
+func AuthenticateAny(jwtAuth, apiKeyAuth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") != "" {
+			apiKeyAuth(c)
+			return
+		}
+		jwtAuth(c)
+	}
+}
+
+// RequireRole creates middleware that allows only users holding one of the roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		held, ok := userRoles(c)
+		if !ok {
+			return
+		}
+
+		if !auth.HasRole(held, roles...) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role", "required_roles": roles})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission creates middleware that allows only users whose roles grant
+// the permission. Requests authenticated with an API key additionally need the
+// permission among the key's scopes. It must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		held, ok := userRoles(c)
+		if !ok {
+			return
+		}
+
+		if !auth.HasPermission(held, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "required_permission": permission})
+			c.Abort()
+			return
+		}
+
+		if scopes, isAPIKey := c.Get("api_key_scopes"); isAPIKey && !containsString(scopes.([]string), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key scope does not allow this action", "required_permission": permission})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// RequirePermissionForWrites applies RequirePermission to every method except
+// GET, HEAD and OPTIONS, so API keys without the scope stay read-only on
+// routes any user may change
+func RequirePermissionForWrites(permission string) gin.HandlerFunc {
+	requirePermission := RequirePermission(permission)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			requirePermission(c)
+		}
+	}
+}
+
+// userRoles returns the authenticated user's roles, aborting with 401 if unauthenticated
+func userRoles(c *gin.Context) ([]string, bool) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		c.Abort()
+		return nil, false
+	}
+	return c.GetStringSlice("user_roles"), true
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethosview-backend/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewJWTManager()
+	router := gin.New()
+	writes := router.Group("", AuthMiddleware(jwtManager), RequirePermission(auth.PermWriteCompanies))
+	writes.DELETE("/companies/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		roles  []string
+		anon   bool
+		status int
+	}{
+		{"anonymous", nil, true, http.StatusUnauthorized},
+		{"viewer", []string{auth.RoleViewer}, false, http.StatusForbidden},
+		{"analyst", []string{auth.RoleViewer, auth.RoleAnalyst}, false, http.StatusForbidden},
+		{"data editor", []string{auth.RoleViewer, auth.RoleDataEditor}, false, http.StatusNoContent},
+		{"admin", []string{auth.RoleAdmin}, false, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("DELETE", "/companies/1", nil)
+			if !tt.anon {
+				token, err := jwtManager.GenerateToken(1, "user@example.com", tt.roles)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewJWTManager()
+	router := gin.New()
+	router.GET("/admin", AuthMiddleware(jwtManager), RequireRole(auth.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for roles, status := range map[string]int{
+		auth.RoleDataEditor: http.StatusForbidden,
+		auth.RoleAdmin:      http.StatusOK,
+	} {
+		token, err := jwtManager.GenerateToken(1, "user@example.com", []string{roles})
+		require.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, status, w.Code, roles)
+	}
+}
+
+func TestRequirePermissionChecksAPIKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		roles  []string
+		scopes []string
+		status int
+	}{
+		{"scoped key", []string{auth.RoleDataEditor}, []string{auth.PermWriteCompanies}, http.StatusNoContent},
+		{"read-only key", []string{auth.RoleDataEditor}, []string{}, http.StatusForbidden},
+		{"other scope", []string{auth.RoleDataEditor}, []string{auth.PermWriteESG}, http.StatusForbidden},
+		{"owner lost role", []string{auth.RoleViewer}, []string{auth.PermWriteCompanies}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyAuth := func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("user_roles", tt.roles)
+				c.Set("api_key_scopes", tt.scopes)
+				c.Next()
+			}
+			jwtAuth := func(c *gin.Context) {
+				c.AbortWithStatus(http.StatusTeapot)
+			}
+
+			router := gin.New()
+			router.DELETE("/companies/:id", AuthenticateAny(jwtAuth, apiKeyAuth), RequirePermission(auth.PermWriteCompanies), func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			req, _ := http.NewRequest("DELETE", "/companies/1", nil)
+			req.Header.Set("X-API-Key", "ev_abc_secret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestRequirePermissionForWritesKeepsScopelessKeysReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		method string
+		apiKey bool
+		scopes []string
+		status int
+	}{
+		{"scope-less key create", "POST", true, []string{}, http.StatusForbidden},
+		{"scope-less key delete", "DELETE", true, []string{}, http.StatusForbidden},
+		{"scope-less key read", "GET", true, []string{}, http.StatusOK},
+		{"scoped key create", "POST", true, []string{auth.PermWriteAccount}, http.StatusOK},
+		{"bearer token create", "POST", false, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyAuth := func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("user_roles", []string{auth.RoleViewer})
+				c.Set("api_key_scopes", tt.scopes)
+				c.Next()
+			}
+			jwtAuth := func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("user_roles", []string{auth.RoleViewer})
+				c.Next()
+			}
+
+			router := gin.New()
+			me := router.Group("/me", AuthenticateAny(jwtAuth, apiKeyAuth), RequirePermissionForWrites(auth.PermWriteAccount))
+			me.Handle(tt.method, "/webhooks", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(tt.method, "/me/webhooks", nil)
+			if tt.apiKey {
+				req.Header.Set("X-API-Key", "ev_abc_secret")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -4,68 +4,296 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"ethosview-backend/pkg/cache"
+
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
-// CacheMiddleware creates caching middleware
-func CacheMiddleware(redis *redis.Client, ttl time.Duration) gin.HandlerFunc {
+// cacheTagsKey is the context key holding the tags of the response being built
+const cacheTagsKey = "cache_tags"
+
+// CacheTags tags every response cached further down the chain, for route
+// groups whose responses all depend on the same data
+func CacheTags(tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		AddCacheTags(c, tags...)
+		c.Next()
+	}
+}
+
+// AddCacheTags records data the response depends on, such as
+// cache.CompanyTag(id), so writes to it invalidate the cached response
+func AddCacheTags(c *gin.Context, tags ...string) {
+	c.Set(cacheTagsKey, append(ResponseCacheTags(c), tags...))
+}
+
+// ResponseCacheTags returns the tags recorded for the response
+func ResponseCacheTags(c *gin.Context) []string {
+	tags, _ := c.Get(cacheTagsKey)
+	existing, _ := tags.([]string)
+	return append([]string(nil), existing...)
+}
+
+// CacheVary says which requests may share a cached response
+type CacheVary int
+
+const (
+	// VaryPublic shares responses between all clients. Responses built for an
+	// authenticated request are never stored, since they may depend on who
+	// asked.
+	VaryPublic CacheVary = iota
+	// VaryUser caches responses per authenticated user. It must run after
+	// authentication; unauthenticated requests are not cached.
+	VaryUser
+	// VaryAPIKey caches responses per API key. It must run after
+	// authentication; requests without an API key are not cached.
+	VaryAPIKey
+)
+
+// CachePolicy describes how CacheMiddleware caches a route's responses
+type CachePolicy struct {
+	// Vary says which requests share a cached response
+	Vary CacheVary
+	// Defaults are the values the handler uses for omitted query parameters,
+	// so requests that spell them out share the entry of those that don't
+	Defaults map[string]string
+}
+
+// CacheMiddleware serves GET responses from responses, caching successful
+// ones for strategy and not-found ones briefly, indexed under their cache
+// tags and keyed by route, parameters and, unless policy is public, the
+// requesting user or API key. Concurrent misses for a key run the handlers
+// once. Stale responses are served immediately while the request is re-run
+// through router in the background to refresh them. Cached responses keep
+// their status and headers, carry an ETag and Last-Modified, and answer
+// conditional requests with 304.
+func CacheMiddleware(responses *cache.AdvancedCache, strategy cache.CacheStrategy, router http.Handler, policy CachePolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip caching for non-GET requests
-		if c.Request.Method != "GET" {
+		if c.Request.Method != http.MethodGet {
 			c.Next()
 			return
 		}
 
-		// Create cache key from request
-		cacheKey := generateCacheKey(c.Request.URL.String())
-
-		// Try to get from cache
-		ctx := context.Background()
-		cachedData, err := redis.Get(ctx, cacheKey).Result()
-		if err == nil {
-			// Return cached response
-			var response map[string]interface{}
-			if err := json.Unmarshal([]byte(cachedData), &response); err == nil {
-				c.JSON(http.StatusOK, response)
-				c.Abort()
-				return
-			}
+		// A background refresh re-running the request: load without the cache
+		if result, ok := c.Request.Context().Value(refreshKey{}).(*refreshResult); ok {
+			result.value, result.tags, result.err = cacheableResponse(c, bufferResponse(c), policy.Vary)
+			return
+		}
+
+		principal, ok := cachePrincipal(c, policy.Vary)
+		if !ok {
+			c.Next()
+			return
 		}
+		cacheKey := generateCacheKey(c, policy, principal)
 
-		// Create a custom response writer to capture the response
-		responseWriter := &responseWriter{ResponseWriter: c.Writer, body: []byte{}}
-		c.Writer = responseWriter
+		// Keep a copy of the request for refreshing in the background
+		replay := c.Request.Clone(context.Background())
+		replay.Header.Del("Accept-Encoding")
 
-		// Continue with the request
-		c.Next()
+		var own *cachedResponse
+		load := func(ctx context.Context) (interface{}, []string, error) {
+			response := bufferResponse(c)
+			own = &response
+			return cacheableResponse(c, response, policy.Vary)
+		}
 
-		// Cache the response if it's successful
-		if c.Writer.Status() == http.StatusOK && len(responseWriter.body) > 0 {
-			// Set cache with TTL
-			redis.Set(ctx, cacheKey, string(responseWriter.body), ttl)
+		var response cachedResponse
+		err := responses.Fetch(c.Request.Context(), cacheKey, &response, strategy, load, refreshLoader(router, replay))
+		switch {
+		case own != nil:
+			// The handlers ran for this request; send what they wrote
+			writeResponse(c, *own, policy.Vary)
+		case (err == nil || errors.Is(err, cache.ErrNotFound)) && response.Status != 0:
+			writeResponse(c, response, policy.Vary)
+			c.Abort()
+		default:
+			// The shared load failed or its response is not cacheable
+			c.Next()
 		}
 	}
 }
 
-// responseWriter captures the response body
+// cachePrincipal returns whose cached responses the request shares under
+// vary, empty for public ones, and false if it cannot be cached
+func cachePrincipal(c *gin.Context, vary CacheVary) (string, bool) {
+	switch vary {
+	case VaryUser:
+		if id, ok := c.Get("user_id"); ok {
+			return fmt.Sprintf("user:%v", id), true
+		}
+		return "", false
+	case VaryAPIKey:
+		if id, ok := c.Get("api_key_id"); ok {
+			return fmt.Sprintf("api-key:%v", id), true
+		}
+		return "", false
+	}
+	return "", true
+}
+
+// authenticated reports whether authentication middleware identified the
+// requesting user
+func authenticated(c *gin.Context) bool {
+	_, ok := c.Get("user_id")
+	return ok
+}
+
+// cachedResponse is a response stored by CacheMiddleware. Header holds the
+// headers the handlers set.
+type cachedResponse struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified time.Time   `json:"last_modified,omitempty"`
+}
+
+// errUncacheable reports a response CacheMiddleware does not store
+var errUncacheable = errors.New("response is not cacheable")
+
+// bufferResponse runs the handlers with their response buffered instead of
+// sent, and returns it
+func bufferResponse(c *gin.Context) cachedResponse {
+	before := c.Writer.Header().Clone()
+	writer := &responseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+	c.Writer = writer
+	defer func() { c.Writer = writer.ResponseWriter }()
+	c.Next()
+
+	response := cachedResponse{
+		Status:       writer.status,
+		Header:       make(http.Header),
+		Body:         writer.body,
+		ETag:         strongETag(writer.body),
+		LastModified: ResponseLastModified(c),
+	}
+	for name, values := range writer.Header() {
+		if name != "Content-Length" && !slices.Equal(values, before[name]) {
+			response.Header[name] = slices.Clone(values)
+		}
+	}
+	return response
+}
+
+// cacheableResponse returns response and its cache tags as a loader result:
+// successful responses as the value to cache, not-found ones as a
+// cache.NotFoundError and anything else as errUncacheable. Responses marked
+// no-store, and under VaryPublic those built for an authenticated request or
+// marked private, are never cached.
+func cacheableResponse(c *gin.Context, response cachedResponse, vary CacheVary) (interface{}, []string, error) {
+	cacheControl := strings.ToLower(strings.Join(response.Header.Values("Cache-Control"), ","))
+	if strings.Contains(cacheControl, "no-store") {
+		return nil, nil, errUncacheable
+	}
+	if vary == VaryPublic && (authenticated(c) || strings.Contains(cacheControl, "private")) {
+		return nil, nil, errUncacheable
+	}
+
+	switch {
+	case response.Status == http.StatusOK && len(response.Body) > 0:
+		return response, ResponseCacheTags(c), nil
+	case response.Status == http.StatusNotFound:
+		return nil, ResponseCacheTags(c), &cache.NotFoundError{Data: response}
+	}
+	return nil, nil, errUncacheable
+}
+
+// refreshKey is the context key marking a request re-run to refresh its
+// cached response; the value receives the result
+type refreshKey struct{}
+
+// refreshResult is what CacheMiddleware loaded for a re-run request
+type refreshResult struct {
+	value interface{}
+	tags  []string
+	err   error
+}
+
+// refreshLoader returns a loader that re-runs req through router, discarding
+// the response written to the client side
+func refreshLoader(router http.Handler, req *http.Request) cache.Loader {
+	return func(ctx context.Context) (interface{}, []string, error) {
+		result := &refreshResult{err: errUncacheable}
+		router.ServeHTTP(&discardResponseWriter{header: make(http.Header)}, req.WithContext(context.WithValue(ctx, refreshKey{}, result)))
+		return result.value, result.tags, result.err
+	}
+}
+
+// discardResponseWriter accepts and discards a response
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+func (w *discardResponseWriter) Flush()                      {}
+
+// responseWriter buffers the response status and body; headers go to the
+// wrapped writer
 type responseWriter struct {
 	gin.ResponseWriter
-	body []byte
+	status  int
+	body    []byte
+	written bool
 }
 
 func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
 	w.body = append(w.body, b...)
-	return w.ResponseWriter.Write(b)
+	return len(b), nil
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
 }
 
-// generateCacheKey creates a unique cache key from URL
-func generateCacheKey(url string) string {
-	hash := md5.Sum([]byte(url))
+func (w *responseWriter) WriteHeaderNow() { w.written = true }
+func (w *responseWriter) Status() int     { return w.status }
+func (w *responseWriter) Size() int       { return len(w.body) }
+func (w *responseWriter) Written() bool   { return w.written }
+func (w *responseWriter) Flush()          {}
+
+// generateCacheKey identifies the response to a GET request: its route
+// template and path parameters, its query with policy's defaults filled in
+// and parameters sorted, and principal
+func generateCacheKey(c *gin.Context, policy CachePolicy, principal string) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	query := c.Request.URL.Query()
+	for name, value := range policy.Defaults {
+		if _, ok := query[name]; !ok {
+			query.Set(name, value)
+		}
+	}
+
+	var key strings.Builder
+	key.WriteString(route)
+	for _, param := range c.Params {
+		key.WriteString("\n" + param.Key + "=" + param.Value)
+	}
+	key.WriteString("\n?" + query.Encode())
+	key.WriteString("\n" + principal)
+
+	hash := md5.Sum([]byte(key.String()))
 	return "cache:" + hex.EncodeToString(hash[:])
 }
 
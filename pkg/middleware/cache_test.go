@@ -0,0 +1,391 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ethosview-backend/pkg/cache"
+	"ethosview-backend/pkg/cache/cachetest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCachedRouter returns a router caching responses in an in-memory Redis
+func newCachedRouter(t *testing.T) (*gin.Engine, *cache.AdvancedCache, *cachetest.Server) {
+	gin.SetMode(gin.TestMode)
+	server := cachetest.NewServer(t)
+	responses := cache.NewAdvancedCache(server.Client(t), "test", nil)
+	router := gin.New()
+	router.Use(CacheMiddleware(responses, cache.ShortTerm, router, CachePolicy{}))
+	return router, responses, server
+}
+
+func get(router http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestCacheMiddlewareInvalidatesByTag(t *testing.T) {
+	router, responses, _ := newCachedRouter(t)
+	calls := 0
+	router.GET("/companies/:id", CacheTags(cache.TagCompanies), func(c *gin.Context) {
+		calls++
+		AddCacheTags(c, cache.CompanyTag(7))
+		c.JSON(http.StatusOK, gin.H{"id": 7, "calls": calls})
+	})
+
+	get(router, "/companies/7")
+	w := get(router, "/companies/7")
+	assert.JSONEq(t, `{"id":7,"calls":1}`, w.Body.String(), "served from cache")
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	require.NoError(t, responses.InvalidateTags(context.Background(), cache.CompanyTag(7)))
+	assert.JSONEq(t, `{"id":7,"calls":2}`, get(router, "/companies/7").Body.String(), "company write invalidates")
+	assert.JSONEq(t, `{"id":7,"calls":2}`, get(router, "/companies/7").Body.String())
+
+	require.NoError(t, responses.InvalidateTags(context.Background(), cache.TagCompanies))
+	assert.JSONEq(t, `{"id":7,"calls":3}`, get(router, "/companies/7").Body.String(), "group tag invalidates")
+}
+
+func TestCacheMiddlewareSkipsResponsesInvalidatedWhileBuilding(t *testing.T) {
+	router, responses, _ := newCachedRouter(t)
+	calls := 0
+	router.GET("/esg/scores", func(c *gin.Context) {
+		calls++
+		AddCacheTags(c, cache.TagESG)
+		if calls == 1 {
+			// A write commits after this response's data was loaded
+			require.NoError(t, responses.InvalidateTags(context.Background(), cache.TagESG))
+		}
+		c.JSON(http.StatusOK, gin.H{"calls": calls})
+	})
+
+	for i := 0; i < 3; i++ {
+		get(router, "/esg/scores")
+	}
+	assert.Equal(t, 2, calls, "the stale first response is not cached, the second is")
+}
+
+func TestCacheMiddlewareCoalescesConcurrentMisses(t *testing.T) {
+	router, _, _ := newCachedRouter(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	router.GET("/analytics/summary", func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"companies": 42})
+	})
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 8)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = get(router, "/analytics/summary").Body.String()
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, body := range bodies {
+		assert.JSONEq(t, `{"companies":42}`, body)
+	}
+}
+
+func TestCacheMiddlewareRefreshesStaleResponsesInBackground(t *testing.T) {
+	router, _, server := newCachedRouter(t)
+	var calls atomic.Int32
+	router.GET("/analytics/summary", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"calls": calls.Add(1)})
+	})
+
+	get(router, "/analytics/summary")
+	server.FastForward(6 * time.Minute)
+
+	assert.JSONEq(t, `{"calls":1}`, get(router, "/analytics/summary").Body.String(), "stale response served immediately")
+	require.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, 5*time.Millisecond, "the request is re-run in the background")
+	require.Eventually(t, func() bool {
+		return get(router, "/analytics/summary").Body.String() == `{"calls":2}`
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCacheMiddlewareCachesNotFoundBriefly(t *testing.T) {
+	router, responses, server := newCachedRouter(t)
+	calls := 0
+	router.GET("/companies/:id", func(c *gin.Context) {
+		calls++
+		AddCacheTags(c, cache.TagCompanies)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+	})
+
+	for i := 0; i < 2; i++ {
+		w := get(router, "/companies/404")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"error":"Company not found"}`, w.Body.String())
+	}
+	assert.Equal(t, 1, calls)
+
+	server.FastForward(time.Minute + time.Second)
+	get(router, "/companies/404")
+	assert.Equal(t, 2, calls)
+
+	require.NoError(t, responses.InvalidateTags(context.Background(), cache.TagCompanies))
+	get(router, "/companies/404")
+	assert.Equal(t, 3, calls, "creating the company invalidates the 404")
+}
+
+func TestCacheMiddlewareDoesNotCacheErrors(t *testing.T) {
+	router, _, server := newCachedRouter(t)
+	calls := 0
+	router.GET("/companies/:id", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve company"})
+	})
+
+	for i := 0; i < 2; i++ {
+		w := get(router, "/companies/1")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+	}
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, server.Keys())
+}
+
+func conditionalGet(router http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCacheMiddlewareAnswersConditionalRequests(t *testing.T) {
+	router, _, _ := newCachedRouter(t)
+	updatedAt := time.Date(2024, 3, 1, 12, 30, 15, 500, time.UTC)
+	calls := 0
+	router.GET("/companies/:id", func(c *gin.Context) {
+		calls++
+		SetLastModified(c, updatedAt.Add(-time.Hour), updatedAt)
+		c.Header("X-Total-Count", "1")
+		c.JSON(http.StatusOK, gin.H{"id": 7})
+	})
+
+	first := get(router, "/companies/7")
+	cached := get(router, "/companies/7")
+	assert.Equal(t, 1, calls)
+	for _, w := range []*httptest.ResponseRecorder{first, cached} {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, `^"[0-9a-f]{32}"$`, w.Header().Get("ETag"))
+		assert.Equal(t, "Fri, 01 Mar 2024 12:30:15 GMT", w.Header().Get("Last-Modified"))
+		assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+		assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+		assert.Equal(t, "1", w.Header().Get("X-Total-Count"), "handler headers are cached")
+	}
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, etag, cached.Header().Get("ETag"))
+
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"matching tag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"weak matching tag", http.Header{"If-None-Match": {`"other", W/` + etag}}, http.StatusNotModified},
+		{"any tag", http.Header{"If-None-Match": {"*"}}, http.StatusNotModified},
+		{"other tag", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK},
+		{"not modified since", http.Header{"If-Modified-Since": {"Fri, 01 Mar 2024 12:30:15 GMT"}}, http.StatusNotModified},
+		{"modified since", http.Header{"If-Modified-Since": {"Fri, 01 Mar 2024 12:30:14 GMT"}}, http.StatusOK},
+		{"tag takes precedence", http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {"Fri, 01 Mar 2024 12:30:15 GMT"}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := conditionalGet(router, "/companies/7", tt.header)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			} else {
+				assert.JSONEq(t, `{"id":7}`, w.Body.String())
+			}
+		})
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestCacheMiddlewareTagsCompressedResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := cachetest.NewServer(t)
+	responses := cache.NewAdvancedCache(server.Client(t), "test", nil)
+	router := gin.New()
+	router.Use(CompressionMiddleware(), CacheMiddleware(responses, cache.ShortTerm, router, CachePolicy{}))
+	router.GET("/companies/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": 7})
+	})
+
+	identity := get(router, "/companies/7")
+	gzipped := conditionalGet(router, "/companies/7", http.Header{"Accept-Encoding": {"gzip"}})
+
+	assert.Equal(t, "gzip", gzipped.Header().Get("Content-Encoding"))
+	assert.Equal(t, []string{"Accept-Encoding"}, gzipped.Header().Values("Vary"))
+	assert.Equal(t, strings.TrimSuffix(identity.Header().Get("ETag"), `"`)+`-gzip"`, gzipped.Header().Get("ETag"))
+	reader, err := gzip.NewReader(gzipped.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":7}`, string(body))
+
+	w := conditionalGet(router, "/companies/7", http.Header{
+		"Accept-Encoding": {"gzip"},
+		"If-None-Match":   {gzipped.Header().Get("ETag")},
+	})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes(), "no gzip stream on a 304")
+
+	w = conditionalGet(router, "/companies/7", http.Header{"If-None-Match": {gzipped.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusOK, w.Code, "the compressed tag does not match the identity body")
+}
+
+// fakeAuth identifies the user named by X-User and the key named by
+// X-API-Key, leaving anonymous requests unauthenticated
+func fakeAuth(c *gin.Context) {
+	if user := c.GetHeader("X-User"); user != "" {
+		c.Set("user_id", user)
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		c.Set("api_key_id", key)
+	}
+}
+
+func getAs(router http.Handler, path, user, apiKey string) *httptest.ResponseRecorder {
+	header := http.Header{}
+	if user != "" {
+		header.Set("X-User", user)
+	}
+	if apiKey != "" {
+		header.Set("X-API-Key", apiKey)
+	}
+	return conditionalGet(router, path, header)
+}
+
+func TestCacheMiddlewareCanonicalisesKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := cachetest.NewServer(t)
+	responses := cache.NewAdvancedCache(server.Client(t), "test", nil)
+	router := gin.New()
+	calls := 0
+	paged := CachePolicy{Defaults: map[string]string{"limit": "20", "offset": "0"}}
+	router.GET("/companies", CacheMiddleware(responses, cache.ShortTerm, router, paged), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"limit": c.DefaultQuery("limit", "20"), "offset": c.DefaultQuery("offset", "0")})
+	})
+	router.GET("/companies/:id", CacheMiddleware(responses, cache.ShortTerm, router, CachePolicy{}), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+
+	for _, path := range []string{"/companies?limit=10&offset=0", "/companies?offset=0&limit=10"} {
+		assert.JSONEq(t, `{"limit":"10","offset":"0"}`, get(router, path).Body.String())
+	}
+	assert.Equal(t, 1, calls, "parameter order does not matter")
+
+	for _, path := range []string{"/companies", "/companies?limit=20", "/companies?offset=0&limit=20"} {
+		assert.JSONEq(t, `{"limit":"20","offset":"0"}`, get(router, path).Body.String())
+	}
+	assert.Equal(t, 2, calls, "omitted parameters take their defaults")
+
+	assert.JSONEq(t, `{"id":"7"}`, get(router, "/companies/7").Body.String())
+	assert.JSONEq(t, `{"id":"8"}`, get(router, "/companies/8").Body.String())
+	assert.Equal(t, 4, calls, "path parameters are part of the key")
+}
+
+func TestCacheMiddlewareCachesPerUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := cachetest.NewServer(t)
+	responses := cache.NewAdvancedCache(server.Client(t), "test", nil)
+	router := gin.New()
+	calls := 0
+	router.GET("/auth/profile", fakeAuth, CacheMiddleware(responses, cache.ShortTerm, router, CachePolicy{Vary: VaryUser}), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString("user_id")})
+	})
+
+	for i := 0; i < 2; i++ {
+		for _, user := range []string{"alice", "bob"} {
+			w := getAs(router, "/auth/profile", user, "")
+			assert.JSONEq(t, `{"user":"`+user+`"}`, w.Body.String())
+			assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+			assert.ElementsMatch(t, []string{"Accept-Encoding", "Authorization", "X-API-Key"}, w.Header().Values("Vary"))
+		}
+	}
+	assert.Equal(t, 2, calls, "each user gets their own entry")
+
+	getAs(router, "/auth/profile", "", "")
+	getAs(router, "/auth/profile", "", "")
+	assert.Equal(t, 4, calls, "unauthenticated requests are not cached")
+}
+
+func TestCacheMiddlewareCachesPerAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := cachetest.NewServer(t)
+	responses := cache.NewAdvancedCache(server.Client(t), "test", nil)
+	router := gin.New()
+	calls := 0
+	router.GET("/usage", fakeAuth, CacheMiddleware(responses, cache.ShortTerm, router, CachePolicy{Vary: VaryAPIKey}), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"key": c.GetString("api_key_id")})
+	})
+
+	for i := 0; i < 2; i++ {
+		assert.JSONEq(t, `{"key":"k1"}`, getAs(router, "/usage", "alice", "k1").Body.String())
+		assert.JSONEq(t, `{"key":"k2"}`, getAs(router, "/usage", "alice", "k2").Body.String())
+	}
+	assert.Equal(t, 2, calls, "keys of the same user do not share entries")
+
+	getAs(router, "/usage", "alice", "")
+	assert.Equal(t, 3, calls, "bearer token requests are not cached")
+}
+
+func TestCacheMiddlewareNeverSharesAuthenticatedResponses(t *testing.T) {
+	router, _, server := newCachedRouter(t)
+	calls := 0
+	router.GET("/companies/:id", fakeAuth, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"viewer": c.GetString("user_id")})
+	})
+	router.GET("/watchlists", func(c *gin.Context) {
+		calls++
+		c.Header("Cache-Control", "private")
+		c.JSON(http.StatusOK, gin.H{"watchlists": []string{}})
+	})
+
+	w := getAs(router, "/companies/7", "alice", "")
+	assert.JSONEq(t, `{"viewer":"alice"}`, w.Body.String())
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+	assert.Empty(t, server.Keys(), "a public route's response for a signed-in user is not stored")
+	assert.JSONEq(t, `{"viewer":""}`, get(router, "/companies/7").Body.String())
+	assert.Equal(t, 2, calls)
+
+	get(router, "/watchlists")
+	get(router, "/watchlists")
+	assert.Equal(t, 4, calls, "responses marked private are not stored under a public key")
+}
@@ -30,7 +30,12 @@ func CompressionMiddleware() gin.HandlerFunc {
 
 		// Create gzip writer
 		gzipWriter := gzip.NewWriter(c.Writer)
-		defer gzipWriter.Close()
+		defer func() {
+			// 304s and 204s have no body, not even an empty gzip stream
+			if status := c.Writer.Status(); status != http.StatusNotModified && status != http.StatusNoContent {
+				gzipWriter.Close()
+			}
+		}()
 
 		// Create custom response writer
 		responseWriter := &compressionWriter{
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastModifiedKey is the context key holding when the response's data last
+// changed
+const lastModifiedKey = "last_modified"
+
+// SetLastModified records when data the response is built from last changed,
+// such as the rows' updated_at; the latest time recorded becomes the
+// Last-Modified header of responses CacheMiddleware serves
+func SetLastModified(c *gin.Context, times ...time.Time) {
+	latest := ResponseLastModified(c)
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	if !latest.IsZero() {
+		c.Set(lastModifiedKey, latest)
+	}
+}
+
+// ResponseLastModified returns the latest time recorded with SetLastModified,
+// or the zero time
+func ResponseLastModified(c *gin.Context) time.Time {
+	value, _ := c.Get(lastModifiedKey)
+	latest, _ := value.(time.Time)
+	return latest
+}
+
+// strongETag returns an entity tag derived from the response body
+func strongETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// writeResponse sends a response built by CacheMiddleware's handlers, or a
+// 304 if the request's validators match it. Successful and not-found
+// responses must be revalidated before reuse, and only public ones built for
+// anonymous requests may be stored by shared caches; anything else must not
+// be stored. Handlers' own Cache-Control headers are kept.
+func writeResponse(c *gin.Context, response cachedResponse, vary CacheVary) {
+	header := c.Writer.Header()
+	for name, values := range response.Header {
+		header[name] = append([]string(nil), values...)
+	}
+
+	revalidate := "public, no-cache"
+	switch {
+	case vary == VaryUser:
+		revalidate = "private, no-cache"
+		addVary(header, "Authorization")
+		addVary(header, "X-API-Key")
+	case vary == VaryAPIKey:
+		revalidate = "private, no-cache"
+		addVary(header, "X-API-Key")
+	case authenticated(c):
+		revalidate = "private, no-cache"
+	}
+
+	switch response.Status {
+	case http.StatusOK:
+		etag := response.ETag
+		if etag == "" {
+			etag = strongETag(response.Body)
+		}
+		// A compressed body is a different representation with its own tag
+		if header.Get("Content-Encoding") == "gzip" {
+			etag = strings.TrimSuffix(etag, `"`) + `-gzip"`
+		}
+		header.Set("ETag", etag)
+		if !response.LastModified.IsZero() {
+			header.Set("Last-Modified", response.LastModified.UTC().Format(http.TimeFormat))
+		}
+		setDefaultHeader(header, "Cache-Control", revalidate)
+		addVary(header, "Accept-Encoding")
+
+		if notModified(c.Request, etag, response.LastModified) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	case http.StatusNotFound:
+		setDefaultHeader(header, "Cache-Control", revalidate)
+		addVary(header, "Accept-Encoding")
+	default:
+		setDefaultHeader(header, "Cache-Control", "no-store")
+	}
+
+	c.Status(response.Status)
+	c.Writer.Write(response.Body)
+}
+
+// notModified reports whether the request's If-None-Match, or without one its
+// If-Modified-Since, matches the response
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if values := req.Header.Values("If-None-Match"); len(values) > 0 {
+		return etagMatches(strings.Join(values, ","), etag)
+	}
+	since := req.Header.Get("If-Modified-Since")
+	if since == "" || lastModified.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(since)
+	return err == nil && !lastModified.Truncate(time.Second).After(t)
+}
+
+// etagMatches reports whether an If-None-Match list matches etag, using the
+// weak comparison conditional GETs call for
+func etagMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds value to the Vary header unless it is already listed
+func addVary(header http.Header, value string) {
+	for _, existing := range header.Values("Vary") {
+		for _, field := range strings.Split(existing, ",") {
+			if name := strings.TrimSpace(field); name == "*" || strings.EqualFold(name, value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
+
+func setDefaultHeader(header http.Header, name, value string) {
+	if header.Get(name) == "" {
+		header.Set(name, value)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"ethosview-backend/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoggingMiddleware writes one structured access log line per request and
+// gives handlers a request-scoped logger through RequestLogger. It must run
+// after RequestIDMiddleware and TracingMiddleware so every line carries the
+// request and trace IDs. Health checks and metrics scrapes are logged at
+// debug level to keep probes out of the default output.
+func LoggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		requestLogger := logger.With(
+			"request_id", GetRequestID(c),
+			"trace_id", GetTraceID(c),
+			"method", c.Request.Method,
+			"route", route,
+		)
+		c.Request = c.Request.WithContext(logging.WithContext(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if query := logging.RedactQuery(c.Request.URL.Query()); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case isProbe(route):
+			level = slog.LevelDebug
+		}
+		requestLogger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic into a 500 response and logs it with the
+// request's logger
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		RequestLogger(c).Error("Recovered from panic", "panic", recovered)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// RequestLogger returns the request-scoped logger, with the authenticated
+// user's ID when there is one
+func RequestLogger(c *gin.Context) *slog.Logger {
+	logger := logging.FromContext(c.Request.Context())
+	if userID, ok := c.Get("user_id"); ok {
+		logger = logger.With("user_id", userID)
+	}
+	return logger
+}
+
+// isProbe reports whether a route is a health check or metrics scrape
+func isProbe(route string) bool {
+	return strings.HasPrefix(route, "/health") || strings.HasPrefix(route, "/metrics") || route == "/api/v1/health"
+}
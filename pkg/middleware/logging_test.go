@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethosview-backend/pkg/logging"
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), TracingMiddleware(tracing.NewProvider(nil)), LoggingMiddleware(logger.Logger))
+	router.GET("/me/portfolios/:id", func(c *gin.Context) {
+		c.Set("user_id", 42)
+		RequestLogger(c).Info("Loading portfolio", "api_key", "evk_secret")
+		c.Status(http.StatusNotFound)
+	})
+	router.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/me/portfolios/7?token=abc&expand=holdings", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/health", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "health checks are logged at debug level")
+
+	var handlerLine, accessLine map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerLine))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &accessLine))
+
+	assert.Equal(t, "Loading portfolio", handlerLine["msg"])
+	assert.Equal(t, "req-123", handlerLine["request_id"])
+	assert.Equal(t, "/me/portfolios/:id", handlerLine["route"])
+	assert.Equal(t, float64(42), handlerLine["user_id"])
+	assert.Equal(t, logging.Redacted, handlerLine["api_key"])
+	assert.Len(t, handlerLine["trace_id"], 32)
+
+	assert.Equal(t, "request", accessLine["msg"])
+	assert.Equal(t, "WARN", accessLine["level"])
+	assert.Equal(t, "req-123", accessLine["request_id"])
+	assert.Equal(t, handlerLine["trace_id"], accessLine["trace_id"])
+	assert.Equal(t, "GET", accessLine["method"])
+	assert.Equal(t, "/me/portfolios/:id", accessLine["route"])
+	assert.Equal(t, "/me/portfolios/7", accessLine["path"])
+	assert.Equal(t, "expand=holdings&token=%5BREDACTED%5D", accessLine["query"])
+	assert.Equal(t, float64(http.StatusNotFound), accessLine["status"])
+	assert.Equal(t, float64(42), accessLine["user_id"])
+	assert.Contains(t, accessLine, "latency_ms")
+}
+
+func TestRecoveryMiddlewareLogsPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	router := gin.New()
+	router.Use(RecoveryMiddleware(), RequestIDMiddleware(), LoggingMiddleware(logger.Logger))
+	router.GET("/boom", func(c *gin.Context) {
+		panic("nil map write")
+	})
+
+	req, _ := http.NewRequest("GET", "/boom", nil)
+	req.Header.Set("X-Request-ID", "req-456")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.Split(buf.String(), "\n")[0]), &entry))
+	assert.Equal(t, "Recovered from panic", entry["msg"])
+	assert.Equal(t, "nil map write", entry["panic"])
+	assert.Equal(t, "req-456", entry["request_id"])
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"strconv"
 	"time"
 
+	"ethosview-backend/pkg/metrics"
+
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 // Metrics stores basic request metrics
@@ -26,6 +28,8 @@ func GetMetrics() *Metrics {
 func MonitoringMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
 
 		// Process request
 		c.Next()
@@ -33,6 +37,16 @@ func MonitoringMiddleware() gin.HandlerFunc {
 		// Calculate response time
 		duration := time.Since(start)
 
+		// Export per-route counts and latency, labelled by route template so
+		// path parameters do not explode the number of series
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(duration.Seconds())
+
 		// Update metrics
 		globalMetrics.TotalRequests++
 		if c.Writer.Status() < 400 {
@@ -46,16 +60,6 @@ func MonitoringMiddleware() gin.HandlerFunc {
 			totalTime := globalMetrics.AverageResponseTime * time.Duration(globalMetrics.TotalRequests-1)
 			globalMetrics.AverageResponseTime = (totalTime + duration) / time.Duration(globalMetrics.TotalRequests)
 		}
-
-		// Log request details
-		logrus.WithFields(logrus.Fields{
-			"method":     c.Request.Method,
-			"path":       c.Request.URL.Path,
-			"status":     c.Writer.Status(),
-			"duration":   duration,
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
-		}).Info("Request processed")
 	}
 }
 
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 	"time"
@@ -35,7 +34,7 @@ func (rl *RateLimiter) RateLimitMiddleware(requestsPerMinute int) gin.HandlerFun
 		key := "rate_limit:" + clientIP
 
 		// Check current count
-		ctx := context.Background()
+		ctx := c.Request.Context()
 		count, err := rl.redis.Get(ctx, key).Int()
 		if err != nil && err != redis.Nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limit check failed"})
@@ -85,7 +84,7 @@ func (rl *RateLimiter) UserRateLimitMiddleware(requestsPerMinute int) gin.Handle
 		key := "user_rate_limit:" + strconv.Itoa(userID.(int))
 
 		// Check current count
-		ctx := context.Background()
+		ctx := c.Request.Context()
 		count, err := rl.redis.Get(ctx, key).Int()
 		if err != nil && err != redis.Nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limit check failed"})
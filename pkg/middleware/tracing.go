@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader returns the request's trace ID to the caller
+const TraceIDHeader = "X-Trace-ID"
+
+// TracingMiddleware starts a server span for each request on provider,
+// continuing the caller's trace when a valid W3C traceparent header is sent.
+// It must run after RequestIDMiddleware so the span carries the request ID.
+// WebSocket upgrades are not traced, as their span would last as long as the
+// connection.
+func TracingMiddleware(provider trace.TracerProvider) gin.HandlerFunc {
+	tracer := provider.Tracer(tracing.InstrumentationName)
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		if c.IsWebsocket() {
+			c.Next()
+			return
+		}
+
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+			attribute.String("request.id", GetRequestID(c)),
+		))
+		defer span.End()
+
+		traceID := span.SpanContext().TraceID().String()
+		c.Set("trace_id", traceID)
+		c.Header(TraceIDHeader, traceID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(status)+" "+http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.SetAttributes(attribute.String("error.message", c.Errors.String()))
+		}
+	}
+}
+
+// GetTraceID retrieves the trace ID from context
+func GetTraceID(c *gin.Context) string {
+	return c.GetString("trace_id")
+}
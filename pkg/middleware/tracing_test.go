@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethosview-backend/pkg/errors"
+	"ethosview-backend/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), TracingMiddleware(provider))
+	router.GET("/companies/:id", func(c *gin.Context) {
+		_, span := tracing.StartChild(c.Request.Context(), "CompanyRepository.GetCompanyByID", trace.WithSpanKind(trace.SpanKindClient))
+		span.End()
+		errors.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve company", errors.ErrDatabaseError)
+	})
+
+	req, _ := http.NewRequest("GET", "/companies/7", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get(TraceIDHeader))
+
+	var body errors.AppError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body.TraceID)
+	assert.Equal(t, "req-123", body.RequestID)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /companies/:id", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Equal(t, "500 Internal Server Error", server.Status().Description)
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range server.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "req-123", attributes["request.id"].AsString())
+	assert.Equal(t, "/companies/:id", attributes["http.route"].AsString())
+	assert.Equal(t, int64(500), attributes["http.response.status_code"].AsInt64())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+}
+
+func TestTracingMiddlewareStartsTraceWithoutTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(TracingMiddleware(tracing.NewProvider(nil)))
+	router.GET("/health", func(c *gin.Context) {
+		assert.Equal(t, GetTraceID(c), tracing.TraceIDFromContext(c.Request.Context()))
+		c.Status(http.StatusOK)
+	})
+
+	for _, traceparent := range []string{"", "not-a-traceparent"} {
+		req, _ := http.NewRequest("GET", "/health", nil)
+		if traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Len(t, w.Header().Get(TraceIDHeader), 32)
+		assert.NotEqual(t, "00000000000000000000000000000000", w.Header().Get(TraceIDHeader))
+	}
+}
@@ -5,38 +5,71 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"ethosview-backend/pkg/logging"
+	"ethosview-backend/pkg/metrics"
+
 	"github.com/redis/go-redis/v9"
 )
 
-// AlertManager handles performance monitoring and alerting
+// AlertManager handles performance monitoring and alerting. Metrics are
+// checked against configurable rules; alerts and their transitions are kept
+// in memory for fast reads and persisted through the store.
 type AlertManager struct {
 	db          *sql.DB
 	redis       *redis.Client
+	store       Store
 	mu          sync.RWMutex
+	persistMu   sync.Mutex
 	alerts      []Alert
-	thresholds  Thresholds
-	isMonitoring bool
+	rules       []Rule
+	pending     map[string]int
+	silences    map[string]time.Time
+	listeners   []AlertListener
+	logger      *slog.Logger
+
+	// Counters from the previous check, for rates over the interval; only
+	// touched by the goroutine running checkMetrics
+	lastCheck    time.Time
+	lastHTTP     metrics.HTTPTotals
+	lastKeyspace keyspaceStats
+}
+
+// keyspaceStats are Redis's cumulative key lookup counters
+type keyspaceStats struct {
+	hits, misses float64
 }
 
-// Alert represents a system alert
+// AlertListener is notified when an alert is raised or resolved; alert.Resolved
+// tells which. Listeners run with the alert manager locked and must not block.
+type AlertListener func(alert Alert)
+
+// Alert represents a system alert; Type is the name of the rule that raised it
 type Alert struct {
-	ID          string    `json:"id"`
-	Type        AlertType `json:"type"`
-	Severity    Severity  `json:"severity"`
-	Message     string    `json:"message"`
-	Value       float64   `json:"value"`
-	Threshold   float64   `json:"threshold"`
-	Timestamp   time.Time `json:"timestamp"`
-	Resolved    bool      `json:"resolved"`
-	ResolvedAt  time.Time `json:"resolved_at,omitempty"`
+	ID             string      `json:"id"`
+	Type           AlertType   `json:"type"`
+	Severity       Severity    `json:"severity"`
+	Status         AlertStatus `json:"status"`
+	Message        string      `json:"message"`
+	Value          float64     `json:"value"`
+	Threshold      float64     `json:"threshold"`
+	Timestamp      time.Time   `json:"timestamp"`
+	AcknowledgedAt *time.Time  `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *int        `json:"acknowledged_by,omitempty"`
+	SilencedUntil  *time.Time  `json:"silenced_until,omitempty"`
+	Resolved       bool        `json:"resolved"`
+	ResolvedAt     time.Time   `json:"resolved_at,omitempty"`
+	ResolvedBy     *int        `json:"resolved_by,omitempty"`
 }
 
-// AlertType defines different types of alerts
+// AlertType defines different types of alerts; the constants name the
+// default rules
 type AlertType string
 
 const (
@@ -46,8 +79,8 @@ const (
 	MemoryUsage         AlertType = "memory_usage"
 	ErrorRate           AlertType = "error_rate"
 	RequestRate         AlertType = "request_rate"
-	DiskSpace           AlertType = "disk_space"
 	QueryPerformance    AlertType = "query_performance"
+	Goroutines          AlertType = "goroutines"
 )
 
 // Severity defines alert severity levels
@@ -59,18 +92,6 @@ const (
 	SeverityCritical Severity = "critical"
 )
 
-// Thresholds defines monitoring thresholds
-type Thresholds struct {
-	DatabaseResponseTimeMs   float64 `json:"database_response_time_ms"`
-	DatabaseMaxConnections   float64 `json:"database_max_connections"`
-	CacheMinHitRate         float64 `json:"cache_min_hit_rate"`
-	MaxMemoryUsagePercent   float64 `json:"max_memory_usage_percent"`
-	MaxErrorRatePercent     float64 `json:"max_error_rate_percent"`
-	MaxRequestsPerSecond    float64 `json:"max_requests_per_second"`
-	MaxQueryTimeMs          float64 `json:"max_query_time_ms"`
-	MinDiskSpacePercent     float64 `json:"min_disk_space_percent"`
-}
-
 // MonitoringData represents current system metrics
 type MonitoringData struct {
 	DatabaseMetrics DatabaseMetrics `json:"database"`
@@ -87,8 +108,10 @@ type DatabaseMetrics struct {
 	LocksCount        int     `json:"locks_count"`
 }
 
+// CacheMetrics describe Redis. HitRate is the percentage of key lookups that
+// hit since the previous check and is nil when there were none.
 type CacheMetrics struct {
-	HitRate         float64 `json:"hit_rate"`
+	HitRate         *float64 `json:"hit_rate,omitempty"`
 	UsedMemoryMB    float64 `json:"used_memory_mb"`
 	ConnectedClients int     `json:"connected_clients"`
 	KeysCount       int     `json:"keys_count"`
@@ -96,82 +119,79 @@ type CacheMetrics struct {
 
 type SystemMetrics struct {
 	MemoryUsagePercent float64 `json:"memory_usage_percent"`
-	CPUUsagePercent    float64 `json:"cpu_usage_percent"`
-	DiskUsagePercent   float64 `json:"disk_usage_percent"`
 	Goroutines         int     `json:"goroutines"`
 }
 
+// AppMetrics describe the HTTP requests served since the previous check. The
+// request rate is nil on the first check; the error rate and average response
+// time are nil when no requests were served.
 type AppMetrics struct {
-	RequestsPerSecond float64 `json:"requests_per_second"`
-	ErrorRatePercent  float64 `json:"error_rate_percent"`
-	AvgResponseTimeMs float64 `json:"avg_response_time_ms"`
-	ActiveUsers       int     `json:"active_users"`
+	RequestsPerSecond *float64 `json:"requests_per_second,omitempty"`
+	ErrorRatePercent  *float64 `json:"error_rate_percent,omitempty"`
+	AvgResponseTimeMs *float64 `json:"avg_response_time_ms,omitempty"`
 }
 
-// NewAlertManager creates a new alert manager
-func NewAlertManager(db *sql.DB, redis *redis.Client) *AlertManager {
+// NewAlertManager creates a new alert manager evaluating DefaultRules until
+// LoadRules or SetRules is called. A nil store keeps alerts in memory only; a
+// nil logger uses the default.
+func NewAlertManager(db *sql.DB, redis *redis.Client, store Store, logger *slog.Logger) *AlertManager {
 	return &AlertManager{
-		db:    db,
-		redis: redis,
-		alerts: make([]Alert, 0),
-		thresholds: Thresholds{
-			DatabaseResponseTimeMs:   500,   // 500ms
-			DatabaseMaxConnections:   80,    // 80 connections
-			CacheMinHitRate:         80.0,  // 80%
-			MaxMemoryUsagePercent:   85.0,  // 85%
-			MaxErrorRatePercent:     5.0,   // 5%
-			MaxRequestsPerSecond:    1000,  // 1000 req/s
-			MaxQueryTimeMs:          200,   // 200ms
-			MinDiskSpacePercent:     15.0,  // 15% free space
-		},
+		db:       db,
+		redis:    redis,
+		store:    store,
+		logger:   logging.Or(logger).With("component", "alert_manager"),
+		alerts:   make([]Alert, 0),
+		rules:    DefaultRules(),
+		pending:  make(map[string]int),
+		silences: make(map[string]time.Time),
 	}
 }
 
-// StartMonitoring starts the monitoring and alerting system
-func (am *AlertManager) StartMonitoring(interval time.Duration) {
+// OnAlert registers a listener for raised and resolved alerts
+func (am *AlertManager) OnAlert(listener AlertListener) {
 	am.mu.Lock()
-	am.isMonitoring = true
-	am.mu.Unlock()
-
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-
-		log.Println("🔍 Starting performance monitoring and alerting...")
+	defer am.mu.Unlock()
+	am.listeners = append(am.listeners, listener)
+}
 
-		for range ticker.C {
-			if !am.isMonitoring {
-				break
-			}
+// notify calls the alert listeners; the caller must hold am.mu
+func (am *AlertManager) notify(alert Alert) {
+	for _, listener := range am.listeners {
+		listener(alert)
+	}
+}
 
-			if err := am.checkMetrics(); err != nil {
-				log.Printf("Error checking metrics: %v", err)
+// Run checks metrics against the rules every interval until ctx is cancelled
+func (am *AlertManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	am.logger.Info("Starting performance monitoring and alerting", "interval", interval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			am.logger.Info("Stopped performance monitoring and alerting")
+			return
+		case <-ticker.C:
+			if err := am.checkMetrics(ctx); err != nil {
+				am.logger.Error("Error checking metrics", "error", err)
 			}
 
 			am.cleanupResolvedAlerts()
 		}
-	}()
-}
-
-// StopMonitoring stops the monitoring system
-func (am *AlertManager) StopMonitoring() {
-	am.mu.Lock()
-	am.isMonitoring = false
-	am.mu.Unlock()
+	}
 }
 
 // checkMetrics collects and analyzes metrics for alerting
-func (am *AlertManager) checkMetrics() error {
+func (am *AlertManager) checkMetrics(ctx context.Context) error {
 	data, err := am.collectMetrics()
 	if err != nil {
 		return err
 	}
 
-	// Check each metric against thresholds
-	am.checkDatabaseMetrics(data.DatabaseMetrics)
-	am.checkCacheMetrics(data.CacheMetrics)
-	am.checkSystemMetrics(data.SystemMetrics)
-	am.checkAppMetrics(data.AppMetrics)
+	// Check each metric against the rules
+	am.Evaluate(ctx, data.Values())
 
 	// Store metrics for historical analysis
 	am.storeMetrics(data)
@@ -202,8 +222,9 @@ func (am *AlertManager) collectMetrics() (*MonitoringData, error) {
 	// Collect system metrics
 	am.collectSystemMetrics(&data.SystemMetrics)
 
-	// Collect app metrics (simplified)
-	am.collectAppMetrics(&data.AppMetrics)
+	// Collect app metrics
+	am.collectAppMetrics(&data.AppMetrics, data.Timestamp)
+	am.lastCheck = data.Timestamp
 
 	return data, nil
 }
@@ -232,29 +253,23 @@ func (am *AlertManager) collectDatabaseMetrics(metrics *DatabaseMetrics) {
 
 func (am *AlertManager) collectCacheMetrics(metrics *CacheMetrics) {
 	ctx := context.Background()
-	
-	// Cache hit rate
-	info, err := am.redis.Info(ctx, "stats").Result()
-	if err == nil {
-		metrics.HitRate = am.parseCacheHitRate(info)
-	}
 
-	// Memory usage
-	memInfo, err := am.redis.Info(ctx, "memory").Result()
-	if err == nil {
-		metrics.UsedMemoryMB = am.parseMemoryUsage(memInfo)
-	}
+	if info, err := am.redis.Info(ctx, "stats", "memory", "clients").Result(); err == nil {
+		current := keyspaceStats{hits: infoValue(info, "keyspace_hits"), misses: infoValue(info, "keyspace_misses")}
+		if !am.lastCheck.IsZero() {
+			hits, misses := current.hits-am.lastKeyspace.hits, current.misses-am.lastKeyspace.misses
+			if hits >= 0 && misses >= 0 && hits+misses > 0 {
+				metrics.HitRate = floatPtr(hits / (hits + misses) * 100)
+			}
+		}
+		am.lastKeyspace = current
 
-	// Connected clients
-	clients, err := am.redis.Info(ctx, "clients").Result()
-	if err == nil {
-		metrics.ConnectedClients = am.parseConnectedClients(clients)
+		metrics.UsedMemoryMB = infoValue(info, "used_memory") / (1024 * 1024)
+		metrics.ConnectedClients = int(infoValue(info, "connected_clients"))
 	}
 
-	// Keys count
-	keys, err := am.redis.Keys(ctx, "*").Result()
-	if err == nil {
-		metrics.KeysCount = len(keys)
+	if keys, err := am.redis.DBSize(ctx).Result(); err == nil {
+		metrics.KeysCount = int(keys)
 	}
 }
 
@@ -265,122 +280,131 @@ func (am *AlertManager) collectSystemMetrics(metrics *SystemMetrics) {
 	// Memory usage (simplified calculation)
 	metrics.MemoryUsagePercent = float64(m.Alloc) / float64(m.Sys) * 100
 	metrics.Goroutines = runtime.NumGoroutine()
-
-	// CPU and disk usage would require additional libraries in production
-	metrics.CPUUsagePercent = 15.0  // Placeholder
-	metrics.DiskUsagePercent = 45.0 // Placeholder
-}
-
-func (am *AlertManager) collectAppMetrics(metrics *AppMetrics) {
-	// In production, these would be collected from actual application metrics
-	metrics.RequestsPerSecond = 150.0 // Placeholder
-	metrics.ErrorRatePercent = 1.2    // Placeholder
-	metrics.AvgResponseTimeMs = 85.0  // Placeholder
-	metrics.ActiveUsers = 45          // Placeholder
 }
 
-// Check methods for different metric types
-
-func (am *AlertManager) checkDatabaseMetrics(metrics DatabaseMetrics) {
-	// Check response time
-	if metrics.ResponseTimeMs > am.thresholds.DatabaseResponseTimeMs {
-		am.createAlert(DatabaseResponseTime, SeverityCritical,
-			fmt.Sprintf("Database response time is %0.2fms (threshold: %0.2fms)",
-				metrics.ResponseTimeMs, am.thresholds.DatabaseResponseTimeMs),
-			metrics.ResponseTimeMs, am.thresholds.DatabaseResponseTimeMs)
-	}
-
-	// Check connections
-	if float64(metrics.ActiveConnections) > am.thresholds.DatabaseMaxConnections {
-		am.createAlert(DatabaseConnections, SeverityWarning,
-			fmt.Sprintf("High database connections: %d (threshold: %0.0f)",
-				metrics.ActiveConnections, am.thresholds.DatabaseMaxConnections),
-			float64(metrics.ActiveConnections), am.thresholds.DatabaseMaxConnections)
+// collectAppMetrics derives request rates from the HTTP request histogram
+func (am *AlertManager) collectAppMetrics(app *AppMetrics, now time.Time) {
+	current := metrics.ReadHTTPTotals()
+	previous, last := am.lastHTTP, am.lastCheck
+	am.lastHTTP = current
+	if last.IsZero() || !now.After(last) {
+		return
 	}
 
-	// Check slow queries
-	if metrics.SlowQueries > 5 {
-		am.createAlert(QueryPerformance, SeverityWarning,
-			fmt.Sprintf("High number of slow queries: %d", metrics.SlowQueries),
-			float64(metrics.SlowQueries), 5.0)
+	requests := float64(current.Requests - previous.Requests)
+	app.RequestsPerSecond = floatPtr(requests / now.Sub(last).Seconds())
+	if requests > 0 {
+		app.ErrorRatePercent = floatPtr(float64(current.ServerErrors-previous.ServerErrors) / requests * 100)
+		app.AvgResponseTimeMs = floatPtr((current.DurationSeconds - previous.DurationSeconds) / requests * 1000)
 	}
 }
 
-func (am *AlertManager) checkCacheMetrics(metrics CacheMetrics) {
-	// Check hit rate
-	if metrics.HitRate < am.thresholds.CacheMinHitRate {
-		am.createAlert(CacheHitRate, SeverityWarning,
-			fmt.Sprintf("Low cache hit rate: %0.2f%% (threshold: %0.2f%%)",
-				metrics.HitRate, am.thresholds.CacheMinHitRate),
-			metrics.HitRate, am.thresholds.CacheMinHitRate)
-	}
-}
+// Evaluate checks metric values against every rule. A rule raises an alert
+// once its condition has held for its for intervals, changes the alert's
+// severity as the value moves between tiers and resolves it when no tier is
+// breached. Listeners are notified of raises, escalations and resolutions
+// unless the rule is silenced.
+func (am *AlertManager) Evaluate(ctx context.Context, values map[string]float64) {
+	am.mu.Lock()
+	now := time.Now().UTC()
+	var pending []pendingTransition
 
-func (am *AlertManager) checkSystemMetrics(metrics SystemMetrics) {
-	// Check memory usage
-	if metrics.MemoryUsagePercent > am.thresholds.MaxMemoryUsagePercent {
-		am.createAlert(MemoryUsage, SeverityCritical,
-			fmt.Sprintf("High memory usage: %0.2f%% (threshold: %0.2f%%)",
-				metrics.MemoryUsagePercent, am.thresholds.MaxMemoryUsagePercent),
-			metrics.MemoryUsagePercent, am.thresholds.MaxMemoryUsagePercent)
-	}
+	for _, rule := range am.rules {
+		value, ok := values[rule.Metric]
+		if !ok {
+			continue
+		}
+		alert := am.openAlert(rule.Name)
+		tier, breached := rule.breached(value)
+
+		if !breached {
+			delete(am.pending, rule.Name)
+			if alert != nil {
+				alert.Value = value
+				am.resolve(alert, now)
+				am.logger.Info("Alert resolved", "alert_id", alert.ID, "type", alert.Type, "value", value)
+				if !am.silenced(alert, now) {
+					am.notify(*alert)
+				}
+				pending = append(pending, am.transition(*alert, Transition{Action: ActionResolved, Note: "metric recovered"}, now))
+			}
+			continue
+		}
 
-	// Check goroutines
-	if metrics.Goroutines > 1000 {
-		am.createAlert(MemoryUsage, SeverityWarning,
-			fmt.Sprintf("High number of goroutines: %d", metrics.Goroutines),
-			float64(metrics.Goroutines), 1000.0)
-	}
-}
+		if alert != nil {
+			alert.Value = value
+			if tier.Severity == alert.Severity {
+				continue
+			}
+			escalated := severityRank[tier.Severity] > severityRank[alert.Severity]
+			note := fmt.Sprintf("%s to %s", alert.Severity, tier.Severity)
+			alert.Severity = tier.Severity
+			alert.Threshold = tier.Threshold
+			alert.Message = rule.message(value, tier)
+			am.logger.Log(ctx, alertLogLevel(tier.Severity), "Alert severity changed",
+				"alert_id", alert.ID, "type", alert.Type, "severity", tier.Severity, "value", value)
+			if escalated && !am.silenced(alert, now) {
+				am.notify(*alert)
+			}
+			pending = append(pending, am.transition(*alert, Transition{Action: ActionSeverityChanged, Note: note}, now))
+			continue
+		}
 
-func (am *AlertManager) checkAppMetrics(metrics AppMetrics) {
-	// Check error rate
-	if metrics.ErrorRatePercent > am.thresholds.MaxErrorRatePercent {
-		am.createAlert(ErrorRate, SeverityCritical,
-			fmt.Sprintf("High error rate: %0.2f%% (threshold: %0.2f%%)",
-				metrics.ErrorRatePercent, am.thresholds.MaxErrorRatePercent),
-			metrics.ErrorRatePercent, am.thresholds.MaxErrorRatePercent)
+		am.pending[rule.Name]++
+		if am.pending[rule.Name] < rule.forIntervals() {
+			continue
+		}
+		delete(am.pending, rule.Name)
+		pending = append(pending, am.raise(ctx, rule, value, tier, now))
 	}
 
-	// Check request rate
-	if metrics.RequestsPerSecond > am.thresholds.MaxRequestsPerSecond {
-		am.createAlert(RequestRate, SeverityWarning,
-			fmt.Sprintf("High request rate: %0.2f req/s (threshold: %0.2f req/s)",
-				metrics.RequestsPerSecond, am.thresholds.MaxRequestsPerSecond),
-			metrics.RequestsPerSecond, am.thresholds.MaxRequestsPerSecond)
-	}
+	am.persistMu.Lock()
+	am.mu.Unlock()
+	am.persist(ctx, pending)
+	am.persistMu.Unlock()
 }
 
-// createAlert creates a new alert if it doesn't already exist
-func (am *AlertManager) createAlert(alertType AlertType, severity Severity, message string, value, threshold float64) {
-	am.mu.Lock()
-	defer am.mu.Unlock()
-
-	// Check if similar alert already exists and is not resolved
-	for _, alert := range am.alerts {
-		if alert.Type == alertType && !alert.Resolved {
-			return // Alert already exists
+// openAlert returns the unresolved alert raised by a rule; the caller must
+// hold am.mu
+func (am *AlertManager) openAlert(rule string) *Alert {
+	for i := range am.alerts {
+		if string(am.alerts[i].Type) == rule && !am.alerts[i].Resolved {
+			return &am.alerts[i]
 		}
 	}
+	return nil
+}
 
+// raise creates an alert for a breached rule; the caller must hold am.mu
+func (am *AlertManager) raise(ctx context.Context, rule Rule, value float64, tier Tier, now time.Time) pendingTransition {
 	alert := Alert{
-		ID:        fmt.Sprintf("%s_%d", alertType, time.Now().Unix()),
-		Type:      alertType,
-		Severity:  severity,
-		Message:   message,
+		ID:        fmt.Sprintf("%s_%d", rule.Name, now.UnixNano()),
+		Type:      AlertType(rule.Name),
+		Severity:  tier.Severity,
+		Status:    StatusFiring,
+		Message:   rule.message(value, tier),
 		Value:     value,
-		Threshold: threshold,
-		Timestamp: time.Now().UTC(),
-		Resolved:  false,
+		Threshold: tier.Threshold,
+		Timestamp: now,
+	}
+	if until, ok := am.silences[rule.Name]; ok && until.After(now) {
+		alert.SilencedUntil = &until
 	}
 
 	am.alerts = append(am.alerts, alert)
 
 	// Log alert
-	log.Printf("🚨 ALERT [%s]: %s", severity, message)
+	am.logger.Log(ctx, alertLogLevel(alert.Severity), "Alert raised",
+		"alert_id", alert.ID, "type", alert.Type, "severity", alert.Severity, "message", alert.Message,
+		"value", value, "threshold", tier.Threshold, "silenced", alert.SilencedUntil != nil)
 
 	// Store alert in Redis for external consumption
 	am.storeAlert(alert)
+
+	if alert.SilencedUntil == nil {
+		am.notify(alert)
+	}
+	return am.transition(alert, Transition{Action: ActionRaised}, now)
 }
 
 // GetActiveAlerts returns all active (unresolved) alerts
@@ -408,21 +432,15 @@ func (am *AlertManager) GetAllAlerts() []Alert {
 	return alertsCopy
 }
 
-// ResolveAlert marks an alert as resolved
-func (am *AlertManager) ResolveAlert(alertID string) error {
-	am.mu.Lock()
-	defer am.mu.Unlock()
-
-	for i, alert := range am.alerts {
-		if alert.ID == alertID && !alert.Resolved {
-			am.alerts[i].Resolved = true
-			am.alerts[i].ResolvedAt = time.Now().UTC()
-			log.Printf("✅ Alert resolved: %s", alert.Message)
-			return nil
-		}
+// alertLogLevel maps an alert severity to the level it is logged at
+func alertLogLevel(severity Severity) slog.Level {
+	switch severity {
+	case SeverityCritical:
+		return slog.LevelError
+	case SeverityWarning:
+		return slog.LevelWarn
 	}
-
-	return fmt.Errorf("alert not found or already resolved")
+	return slog.LevelInfo
 }
 
 // Helper methods
@@ -478,21 +496,28 @@ func (am *AlertManager) cleanupResolvedAlerts() {
 	}
 
 	am.alerts = activeAlerts
-}
-
-// Parsing helper methods (simplified implementations)
 
-func (am *AlertManager) parseCacheHitRate(info string) float64 {
-	// Simplified implementation - in production use proper parsing
-	return 85.0 // Placeholder
+	// Forget expired silences
+	now := time.Now()
+	for rule, until := range am.silences {
+		if !until.After(now) {
+			delete(am.silences, rule)
+		}
+	}
 }
 
-func (am *AlertManager) parseMemoryUsage(info string) float64 {
-	// Simplified implementation - in production use proper parsing
-	return 125.5 // Placeholder MB
+// infoValue returns a numeric field of Redis INFO output, or 0
+func infoValue(info, field string) float64 {
+	for _, line := range strings.Split(info, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && name == field {
+			parsed, _ := strconv.ParseFloat(value, 64)
+			return parsed
+		}
+	}
+	return 0
 }
 
-func (am *AlertManager) parseConnectedClients(info string) int {
-	// Simplified implementation - in production use proper parsing
-	return 15 // Placeholder
+func floatPtr(value float64) *float64 {
+	return &value
 }
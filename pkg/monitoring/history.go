@@ -0,0 +1,254 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AlertStatus is where an alert is in its lifecycle
+type AlertStatus string
+
+const (
+	StatusFiring       AlertStatus = "firing"
+	StatusAcknowledged AlertStatus = "acknowledged"
+	StatusResolved     AlertStatus = "resolved"
+)
+
+// Actions recorded in an alert's history
+const (
+	ActionRaised          = "raised"
+	ActionSeverityChanged = "severity_changed"
+	ActionAcknowledged    = "acknowledged"
+	ActionSilenced        = "silenced"
+	ActionResolved        = "resolved"
+)
+
+// persistTimeout bounds each write to the alert store
+const persistTimeout = 5 * time.Second
+
+var (
+	// ErrAlertNotFound is returned for unknown or pruned alert IDs
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrAlertResolved is returned when changing an alert that is resolved
+	ErrAlertResolved = errors.New("alert is already resolved")
+)
+
+// Transition is one change to an alert, kept as its history
+type Transition struct {
+	ID        int         `json:"id"`
+	AlertID   string      `json:"alert_id"`
+	Action    string      `json:"action"`
+	Status    AlertStatus `json:"status"`
+	Severity  Severity    `json:"severity"`
+	Value     float64     `json:"value"`
+	UserID    *int        `json:"user_id,omitempty"`
+	Note      string      `json:"note,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Store persists alert rules, alerts and their transitions
+type Store interface {
+	// LoadRules returns the enabled rules, or none when the table is empty
+	LoadRules(ctx context.Context) ([]Rule, error)
+	// SaveTransition upserts the alert's current state and appends the transition
+	SaveTransition(ctx context.Context, alert Alert, transition Transition) error
+	// OpenAlerts returns the alerts that are not resolved
+	OpenAlerts(ctx context.Context) ([]Alert, error)
+	// Silences returns the latest unexpired silence per rule
+	Silences(ctx context.Context, now time.Time) (map[string]time.Time, error)
+}
+
+// pendingTransition is a state change waiting to be persisted
+type pendingTransition struct {
+	alert      Alert
+	transition Transition
+}
+
+// LoadRules picks the rules to evaluate: the JSON file at path when it is set,
+// otherwise the monitoring_rules table, otherwise DefaultRules. It returns
+// where the rules came from.
+func (am *AlertManager) LoadRules(ctx context.Context, path string) (string, error) {
+	rules, source := DefaultRules(), "defaults"
+	switch {
+	case path != "":
+		fileRules, err := LoadRulesFile(path)
+		if err != nil {
+			return "", err
+		}
+		rules, source = fileRules, path
+	case am.store != nil:
+		stored, err := am.store.LoadRules(ctx)
+		if err != nil {
+			return "", fmt.Errorf("load alert rules: %w", err)
+		}
+		if len(stored) > 0 {
+			rules, source = stored, "database"
+		}
+	}
+
+	if err := am.SetRules(rules); err != nil {
+		return "", err
+	}
+	return source, nil
+}
+
+// SetRules replaces the rules being evaluated. Alerts from removed rules stay
+// open until resolved by hand.
+func (am *AlertManager) SetRules(rules []Rule) error {
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.rules = append([]Rule(nil), rules...)
+	am.pending = make(map[string]int)
+	return nil
+}
+
+// Rules returns the rules being evaluated
+func (am *AlertManager) Rules() []Rule {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return append([]Rule(nil), am.rules...)
+}
+
+// RestoreAlerts reloads open alerts and active silences from the store so a
+// restart neither forgets nor re-raises them
+func (am *AlertManager) RestoreAlerts(ctx context.Context) error {
+	if am.store == nil {
+		return nil
+	}
+	open, err := am.store.OpenAlerts(ctx)
+	if err != nil {
+		return fmt.Errorf("restore alerts: %w", err)
+	}
+	silences, err := am.store.Silences(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("restore silences: %w", err)
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.alerts = append(am.alerts, open...)
+	for rule, until := range silences {
+		am.silences[rule] = until
+	}
+	return nil
+}
+
+// Acknowledge marks an alert as being handled by userID. Acknowledged alerts
+// still resolve automatically when the metric recovers.
+func (am *AlertManager) Acknowledge(ctx context.Context, alertID string, userID int) (Alert, error) {
+	return am.update(ctx, alertID, func(alert *Alert, now time.Time) Transition {
+		alert.Status = StatusAcknowledged
+		alert.AcknowledgedAt = &now
+		alert.AcknowledgedBy = &userID
+		return Transition{Action: ActionAcknowledged, UserID: &userID}
+	})
+}
+
+// Silence stops notifications for an alert, and for any new alert from the
+// same rule, for the given duration
+func (am *AlertManager) Silence(ctx context.Context, alertID string, userID int, duration time.Duration) (Alert, error) {
+	return am.update(ctx, alertID, func(alert *Alert, now time.Time) Transition {
+		until := now.Add(duration)
+		alert.SilencedUntil = &until
+		am.silences[string(alert.Type)] = until
+		return Transition{Action: ActionSilenced, UserID: &userID, Note: "silenced until " + until.Format(time.RFC3339)}
+	})
+}
+
+// ResolveAlert resolves an alert by hand. If the condition still holds, the
+// rule raises a new alert after its for intervals.
+func (am *AlertManager) ResolveAlert(ctx context.Context, alertID string, userID int) (Alert, error) {
+	return am.update(ctx, alertID, func(alert *Alert, now time.Time) Transition {
+		am.resolve(alert, now)
+		alert.ResolvedBy = &userID
+		delete(am.pending, string(alert.Type))
+		return Transition{Action: ActionResolved, UserID: &userID}
+	})
+}
+
+// update applies change to an open alert, records the transition and
+// notifies listeners when the alert was resolved
+func (am *AlertManager) update(ctx context.Context, alertID string, change func(alert *Alert, now time.Time) Transition) (Alert, error) {
+	am.mu.Lock()
+	index := -1
+	for i := range am.alerts {
+		if am.alerts[i].ID == alertID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		am.mu.Unlock()
+		return Alert{}, ErrAlertNotFound
+	}
+	alert := &am.alerts[index]
+	if alert.Resolved {
+		am.mu.Unlock()
+		return Alert{}, ErrAlertResolved
+	}
+
+	now := time.Now().UTC()
+	transition := change(alert, now)
+	am.logger.Info("Alert updated", "alert_id", alert.ID, "action", transition.Action, "user_id", transition.UserID)
+	if alert.Resolved && !am.silenced(alert, now) {
+		am.notify(*alert)
+	}
+	pending := []pendingTransition{am.transition(*alert, transition, now)}
+	updated := *alert
+
+	am.persistMu.Lock()
+	am.mu.Unlock()
+	am.persist(ctx, pending)
+	am.persistMu.Unlock()
+	return updated, nil
+}
+
+// resolve marks an alert resolved; the caller must hold am.mu
+func (am *AlertManager) resolve(alert *Alert, now time.Time) {
+	alert.Resolved = true
+	alert.ResolvedAt = now
+	alert.Status = StatusResolved
+}
+
+// silenced reports whether notifications for an alert are silenced; the
+// caller must hold am.mu
+func (am *AlertManager) silenced(alert *Alert, now time.Time) bool {
+	if alert.SilencedUntil != nil && alert.SilencedUntil.After(now) {
+		return true
+	}
+	until, ok := am.silences[string(alert.Type)]
+	return ok && until.After(now)
+}
+
+// transition fills in a transition from the alert's new state
+func (am *AlertManager) transition(alert Alert, transition Transition, now time.Time) pendingTransition {
+	transition.AlertID = alert.ID
+	transition.Status = alert.Status
+	transition.Severity = alert.Severity
+	transition.Value = alert.Value
+	transition.CreatedAt = now
+	return pendingTransition{alert: alert, transition: transition}
+}
+
+// persist writes transitions in order. Failures are logged rather than
+// returned: alerting must keep working while the database is the problem.
+// The caller must hold am.persistMu.
+func (am *AlertManager) persist(ctx context.Context, pending []pendingTransition) {
+	if am.store == nil {
+		return
+	}
+	for _, p := range pending {
+		writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+		err := am.store.SaveTransition(writeCtx, p.alert, p.transition)
+		cancel()
+		if err != nil {
+			am.logger.Error("Failed to persist alert transition", "alert_id", p.alert.ID, "action", p.transition.Action, "error", err)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"ethosview-backend/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore records persisted transitions in memory
+type memoryStore struct {
+	mu          sync.Mutex
+	rules       []Rule
+	alerts      map[string]Alert
+	transitions []Transition
+	silences    map[string]time.Time
+	err         error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{alerts: make(map[string]Alert), silences: make(map[string]time.Time)}
+}
+
+func (s *memoryStore) LoadRules(ctx context.Context) ([]Rule, error) {
+	return s.rules, s.err
+}
+
+func (s *memoryStore) SaveTransition(ctx context.Context, alert Alert, transition Transition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.alerts[alert.ID] = alert
+	s.transitions = append(s.transitions, transition)
+	return nil
+}
+
+func (s *memoryStore) OpenAlerts(ctx context.Context) ([]Alert, error) {
+	var open []Alert
+	for _, alert := range s.alerts {
+		if !alert.Resolved {
+			open = append(open, alert)
+		}
+	}
+	return open, s.err
+}
+
+func (s *memoryStore) Silences(ctx context.Context, now time.Time) (map[string]time.Time, error) {
+	return s.silences, s.err
+}
+
+func (s *memoryStore) actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := make([]string, len(s.transitions))
+	for i, t := range s.transitions {
+		actions[i] = t.Action
+	}
+	return actions
+}
+
+var slowDatabase = Rule{
+	Name:     "slow_db",
+	Metric:   "database.response_time_ms",
+	Operator: ">",
+	For:      2,
+	Tiers:    []Tier{{SeverityWarning, 250}, {SeverityCritical, 500}},
+}
+
+func newTestManager(t *testing.T, store Store) (*AlertManager, *[]Alert) {
+	am := NewAlertManager(nil, nil, store, nil)
+	require.NoError(t, am.SetRules([]Rule{slowDatabase}))
+	var notified []Alert
+	am.OnAlert(func(alert Alert) { notified = append(notified, alert) })
+	return am, &notified
+}
+
+func evaluate(am *AlertManager, value float64) {
+	am.Evaluate(context.Background(), map[string]float64{"database.response_time_ms": value})
+}
+
+func TestEvaluateLifecycle(t *testing.T) {
+	store := newMemoryStore()
+	am, notified := newTestManager(t, store)
+
+	evaluate(am, 300)
+	assert.Empty(t, am.GetActiveAlerts(), "the condition must hold for two intervals")
+
+	evaluate(am, 100)
+	evaluate(am, 300)
+	assert.Empty(t, am.GetActiveAlerts(), "a recovery resets the count")
+
+	evaluate(am, 300)
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1)
+	assert.Equal(t, AlertType("slow_db"), active[0].Type)
+	assert.Equal(t, SeverityWarning, active[0].Severity)
+	assert.Equal(t, StatusFiring, active[0].Status)
+	assert.Equal(t, 250.0, active[0].Threshold)
+
+	evaluate(am, 350)
+	evaluate(am, 800)
+	active = am.GetActiveAlerts()
+	require.Len(t, active, 1, "an open alert is updated, not duplicated")
+	assert.Equal(t, SeverityCritical, active[0].Severity)
+	assert.Equal(t, 500.0, active[0].Threshold)
+
+	evaluate(am, 400)
+	evaluate(am, 90)
+	assert.Empty(t, am.GetActiveAlerts())
+
+	all := am.GetAllAlerts()
+	require.Len(t, all, 1)
+	assert.True(t, all[0].Resolved)
+	assert.Equal(t, StatusResolved, all[0].Status)
+	assert.Nil(t, all[0].ResolvedBy, "resolved automatically")
+
+	assert.Equal(t, []string{ActionRaised, ActionSeverityChanged, ActionSeverityChanged, ActionResolved}, store.actions())
+	require.Len(t, *notified, 3, "raise, escalation and resolution notify; de-escalation does not")
+	assert.Equal(t, SeverityWarning, (*notified)[0].Severity)
+	assert.Equal(t, SeverityCritical, (*notified)[1].Severity)
+	assert.True(t, (*notified)[2].Resolved)
+	assert.Equal(t, StatusResolved, store.alerts[all[0].ID].Status)
+}
+
+func TestAcknowledgeSilenceAndResolve(t *testing.T) {
+	store := newMemoryStore()
+	am, notified := newTestManager(t, store)
+	ctx := context.Background()
+
+	evaluate(am, 300)
+	evaluate(am, 300)
+	alertID := am.GetActiveAlerts()[0].ID
+
+	alert, err := am.Acknowledge(ctx, alertID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAcknowledged, alert.Status)
+	assert.Equal(t, 7, *alert.AcknowledgedBy)
+	assert.NotNil(t, alert.AcknowledgedAt)
+
+	alert, err = am.Silence(ctx, alertID, 7, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, alert.SilencedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *alert.SilencedUntil, time.Minute)
+
+	evaluate(am, 900)
+	assert.Len(t, *notified, 1, "escalations of silenced alerts do not notify")
+
+	alert, err = am.ResolveAlert(ctx, alertID, 8)
+	require.NoError(t, err)
+	assert.True(t, alert.Resolved)
+	assert.Equal(t, 8, *alert.ResolvedBy)
+
+	_, err = am.ResolveAlert(ctx, alertID, 8)
+	assert.ErrorIs(t, err, ErrAlertResolved)
+	_, err = am.Acknowledge(ctx, "missing", 8)
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+
+	evaluate(am, 900)
+	evaluate(am, 900)
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1, "the rule raises again while the condition holds")
+	assert.NotEqual(t, alertID, active[0].ID)
+	assert.NotNil(t, active[0].SilencedUntil, "the rule's silence covers new alerts")
+	assert.Len(t, *notified, 1)
+
+	assert.Equal(t, []string{
+		ActionRaised, ActionAcknowledged, ActionSilenced, ActionSeverityChanged, ActionResolved, ActionRaised,
+	}, store.actions())
+	assert.Equal(t, 7, *store.transitions[1].UserID)
+}
+
+func TestPersistenceFailuresDoNotStopAlerting(t *testing.T) {
+	store := newMemoryStore()
+	store.err = errors.New("connection refused")
+	am, notified := newTestManager(t, store)
+
+	evaluate(am, 300)
+	evaluate(am, 300)
+	assert.Len(t, am.GetActiveAlerts(), 1)
+	assert.Len(t, *notified, 1)
+}
+
+func TestRestoreAlerts(t *testing.T) {
+	store := newMemoryStore()
+	raisedAt := time.Now().Add(-time.Hour).UTC()
+	store.alerts["slow_db_1"] = Alert{ID: "slow_db_1", Type: "slow_db", Severity: SeverityWarning, Status: StatusAcknowledged, Timestamp: raisedAt}
+	store.silences["slow_db"] = time.Now().Add(time.Hour)
+
+	am, notified := newTestManager(t, store)
+	require.NoError(t, am.RestoreAlerts(context.Background()))
+	require.Len(t, am.GetActiveAlerts(), 1)
+
+	evaluate(am, 300)
+	require.Len(t, am.GetActiveAlerts(), 1, "a restored alert is not raised again")
+	assert.Equal(t, "slow_db_1", am.GetActiveAlerts()[0].ID)
+
+	evaluate(am, 100)
+	assert.Empty(t, am.GetActiveAlerts())
+	assert.Empty(t, *notified, "the rule is still silenced")
+}
+
+func TestLoadRules(t *testing.T) {
+	store := newMemoryStore()
+	am := NewAlertManager(nil, nil, store, nil)
+
+	source, err := am.LoadRules(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "defaults", source)
+	assert.Len(t, am.Rules(), len(DefaultRules()))
+
+	store.rules = []Rule{slowDatabase}
+	source, err = am.LoadRules(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "database", source)
+	assert.Equal(t, []Rule{slowDatabase}, am.Rules())
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"name": "busy", "metric": "system.goroutines", "operator": ">", "tiers": [{"severity": "info", "threshold": 10}]}]}`), 0o600))
+	source, err = am.LoadRules(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, path, source)
+	assert.Equal(t, "busy", am.Rules()[0].Name)
+
+	store.rules = []Rule{{Name: "broken", Metric: "nope", Operator: ">", Tiers: []Tier{{SeverityInfo, 1}}}}
+	_, err = am.LoadRules(context.Background(), "")
+	assert.Error(t, err)
+	assert.Equal(t, "busy", am.Rules()[0].Name, "invalid rules leave the current ones in place")
+}
+
+func TestCollectAppMetricsMeasuresTheInterval(t *testing.T) {
+	am := NewAlertManager(nil, nil, nil, nil)
+	start := time.Now()
+
+	var first AppMetrics
+	am.collectAppMetrics(&first, start)
+	am.lastCheck = start
+	assert.Nil(t, first.RequestsPerSecond, "no rate before a previous check")
+
+	var idle AppMetrics
+	am.collectAppMetrics(&idle, start.Add(time.Minute))
+	am.lastCheck = start.Add(time.Minute)
+	require.NotNil(t, idle.RequestsPerSecond)
+	assert.Zero(t, *idle.RequestsPerSecond)
+	assert.Nil(t, idle.ErrorRatePercent, "no error rate without requests")
+
+	metrics.HTTPRequestDuration.WithLabelValues("GET", "/health", "200").Observe(0.1)
+	metrics.HTTPRequestDuration.WithLabelValues("GET", "/health", "500").Observe(0.3)
+	var busy AppMetrics
+	am.collectAppMetrics(&busy, start.Add(2*time.Minute))
+	require.NotNil(t, busy.ErrorRatePercent)
+	assert.InDelta(t, 2.0/60, *busy.RequestsPerSecond, 1e-9)
+	assert.InDelta(t, 50, *busy.ErrorRatePercent, 1e-9)
+	assert.InDelta(t, 200, *busy.AvgResponseTimeMs, 1e-6)
+}
@@ -0,0 +1,302 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultIncidentURL is the PagerDuty Events API v2 endpoint
+const DefaultIncidentURL = "https://events.pagerduty.com/v2/enqueue"
+
+// notifierUserAgent identifies outgoing notification requests
+const notifierUserAgent = "EthosView-Alerts/1.0"
+
+// NotifiersFromEnv creates a notifier for each configured destination:
+// email when SMTP_HOST and ALERT_EMAIL_TO are set, the chat webhook when
+// ALERT_WEBHOOK_URL is set and incident events when ALERT_INCIDENT_ROUTING_KEY
+// is set
+func NotifiersFromEnv() []Notifier {
+	var notifiers []Notifier
+	if config, ok := EmailConfigFromEnv(); ok {
+		notifiers = append(notifiers, NewEmailNotifier(config))
+	}
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(url))
+	}
+	if key := os.Getenv("ALERT_INCIDENT_ROUTING_KEY"); key != "" {
+		url := os.Getenv("ALERT_INCIDENT_URL")
+		if url == "" {
+			url = DefaultIncidentURL
+		}
+		notifiers = append(notifiers, NewIncidentNotifier(url, key))
+	}
+	return notifiers
+}
+
+// EmailConfig configures alert emails
+type EmailConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailConfigFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD,
+// SMTP_FROM and ALERT_EMAIL_TO (comma-separated). ok is false when SMTP_HOST
+// or ALERT_EMAIL_TO is unset.
+func EmailConfigFromEnv() (config EmailConfig, ok bool) {
+	config = EmailConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	for _, to := range strings.Split(os.Getenv("ALERT_EMAIL_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			config.To = append(config.To, to)
+		}
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.From == "" {
+		config.From = "alerts@ethosview.com"
+	}
+	return config, config.Host != "" && len(config.To) > 0
+}
+
+// EmailNotifier emails alert groups to the operators over SMTP
+type EmailNotifier struct {
+	config EmailConfig
+}
+
+// NewEmailNotifier creates an email notifier
+func NewEmailNotifier(config EmailConfig) *EmailNotifier {
+	return &EmailNotifier{config: config}
+}
+
+// Name returns the notifier name
+func (n *EmailNotifier) Name() string {
+	return NotifierEmail
+}
+
+// Notify sends one plain-text email listing the alerts
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	addr := net.JoinHostPort(n.config.Host, n.config.Port)
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.config.From, n.config.To, emailMessage(n.config, notification))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// emailMessage formats a plain-text alert email
+func emailMessage(config EmailConfig, notification Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerSafe("[EthosView] "+notification.Title()))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	for _, alert := range notification.Alerts {
+		state := strings.ToUpper(string(alert.Severity))
+		if alert.Resolved {
+			state = "RESOLVED"
+		}
+		fmt.Fprintf(&b, "[%s] %s\r\n", state, alert.Message)
+		fmt.Fprintf(&b, "Rule: %s, value: %.2f, threshold: %.2f\r\n", alert.Type, alert.Value, alert.Threshold)
+		fmt.Fprintf(&b, "Raised at: %s\r\n", alert.Timestamp.UTC().Format("2006-01-02 15:04 MST"))
+		if alert.Resolved {
+			fmt.Fprintf(&b, "Resolved at: %s\r\n", alert.ResolvedAt.UTC().Format("2006-01-02 15:04 MST"))
+		}
+		fmt.Fprintf(&b, "Alert ID: %s\r\n\r\n", alert.ID)
+	}
+	return []byte(b.String())
+}
+
+// headerSafe strips line breaks so alert text cannot inject headers
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// severityColors are the attachment colours per severity; resolved alerts
+// are green
+var severityColors = map[Severity]string{
+	SeverityCritical: "#d32f2f",
+	SeverityWarning:  "#f9a825",
+	SeverityInfo:     "#1976d2",
+}
+
+const resolvedColor = "#2e7d32"
+
+// WebhookNotifier posts alert groups as Slack-compatible JSON, which Slack,
+// Mattermost and Teams incoming webhooks accept
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a chat webhook notifier
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// Name returns the notifier name
+func (n *WebhookNotifier) Name() string {
+	return NotifierWebhook
+}
+
+// webhookMessage is the Slack incoming webhook format
+type webhookMessage struct {
+	Text        string              `json:"text"`
+	Attachments []webhookAttachment `json:"attachments"`
+}
+
+type webhookAttachment struct {
+	Color    string `json:"color"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Fallback string `json:"fallback"`
+	Ts       int64  `json:"ts"`
+}
+
+// Notify posts one message with an attachment per alert
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	message := webhookMessage{Text: notification.Title()}
+	for _, alert := range notification.Alerts {
+		color, title, at := severityColors[alert.Severity], strings.ToUpper(string(alert.Severity)), alert.Timestamp
+		if alert.Resolved {
+			color, title, at = resolvedColor, "RESOLVED", alert.ResolvedAt
+		}
+		title = fmt.Sprintf("[%s] %s", title, alert.Type)
+		message.Attachments = append(message.Attachments, webhookAttachment{
+			Color:    color,
+			Title:    title,
+			Text:     fmt.Sprintf("%s\nAlert ID: %s", alert.Message, alert.ID),
+			Fallback: title + ": " + alert.Message,
+			Ts:       at.Unix(),
+		})
+	}
+	return postJSON(ctx, n.client, n.url, message)
+}
+
+// IncidentNotifier opens and resolves incidents through a PagerDuty-style
+// Events API, one event per alert deduplicated by alert ID
+type IncidentNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// NewIncidentNotifier creates an incident notifier for an Events API v2
+// endpoint and integration routing key
+func NewIncidentNotifier(url, routingKey string) *IncidentNotifier {
+	return &IncidentNotifier{url: url, routingKey: routingKey, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// Name returns the notifier name
+func (n *IncidentNotifier) Name() string {
+	return NotifierIncident
+}
+
+// incidentEvent is an Events API v2 event
+type incidentEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     *incidentPayload `json:"payload,omitempty"`
+}
+
+type incidentPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      Severity               `json:"severity"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Component     string                 `json:"component"`
+	Class         string                 `json:"class"`
+	CustomDetails map[string]interface{} `json:"custom_details"`
+}
+
+// Notify triggers an incident for each firing alert and resolves the
+// incident of each resolved alert
+func (n *IncidentNotifier) Notify(ctx context.Context, notification Notification) error {
+	source, _ := os.Hostname()
+	if source == "" {
+		source = "ethosview-backend"
+	}
+
+	for _, alert := range notification.Alerts {
+		event := incidentEvent{RoutingKey: n.routingKey, EventAction: "trigger", DedupKey: alert.ID}
+		if alert.Resolved {
+			event.EventAction = "resolve"
+		} else {
+			event.Payload = &incidentPayload{
+				Summary:   alert.Message,
+				Source:    source,
+				Severity:  alert.Severity,
+				Timestamp: alert.Timestamp.UTC(),
+				Component: "ethosview-backend",
+				Class:     string(alert.Type),
+				CustomDetails: map[string]interface{}{
+					"value":     alert.Value,
+					"threshold": alert.Threshold,
+					"status":    alert.Status,
+				},
+			}
+		}
+		if err := postJSON(ctx, n.client, n.url, event); err != nil {
+			return fmt.Errorf("alert %s: %w", alert.ID, err)
+		}
+	}
+	return nil
+}
+
+// postJSON posts body as JSON and treats any non-2xx response as an error
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", notifierUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return nil
+}
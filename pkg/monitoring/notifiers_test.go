@@ -0,0 +1,212 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer is a minimal local SMTP server that records one message per
+// session
+type smtpServer struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	from       string
+	recipients []string
+	data       string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &smtpServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.recipients = append(s.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailNotifierSendsOverSMTP(t *testing.T) {
+	server := newSMTPServer(t)
+	host, port, err := net.SplitHostPort(server.listener.Addr().String())
+	require.NoError(t, err)
+
+	notifier := NewEmailNotifier(EmailConfig{
+		Host: host,
+		Port: port,
+		From: "alerts@ethosview.com",
+		To:   []string{"ops@example.com", "dba@example.com"},
+	})
+	alert := testAlert("a1", SeverityCritical, false)
+	alert.Message = "Database response time\r\nBcc: attacker@example.com"
+	require.NoError(t, notifier.Notify(context.Background(), newNotification([]Alert{alert}, alert.Timestamp)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "alerts@ethosview.com", server.from)
+	assert.Equal(t, []string{"ops@example.com", "dba@example.com"}, server.recipients)
+
+	headers := server.data[:strings.Index(server.data, "\r\n\r\n")]
+	assert.Contains(t, headers, "Subject: [EthosView] [CRITICAL] Database response time  Bcc: attacker@example.com")
+	assert.NotContains(t, headers, "\r\nBcc:")
+	assert.Contains(t, server.data, "Alert ID: a1")
+}
+
+func TestWebhookNotifierPostsSlackMessage(t *testing.T) {
+	var message webhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL)
+	notification := newNotification([]Alert{
+		testAlert("a1", SeverityWarning, false),
+		testAlert("a2", SeverityCritical, true),
+	}, testAlert("", SeverityInfo, false).Timestamp)
+	require.NoError(t, notifier.Notify(context.Background(), notification))
+
+	assert.Equal(t, "[WARNING] 1 alerts firing, 1 resolved", message.Text)
+	require.Len(t, message.Attachments, 2)
+	assert.Equal(t, "#f9a825", message.Attachments[0].Color)
+	assert.Equal(t, "[WARNING] slow_db", message.Attachments[0].Title)
+	assert.Equal(t, resolvedColor, message.Attachments[1].Color)
+	assert.Equal(t, "[RESOLVED] slow_db", message.Attachments[1].Title)
+}
+
+func TestWebhookNotifierReportsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL).Notify(context.Background(), newNotification([]Alert{testAlert("a1", SeverityInfo, false)}, testAlert("", SeverityInfo, false).Timestamp))
+	assert.ErrorContains(t, err, "unexpected status 403")
+}
+
+func TestIncidentNotifierTriggersAndResolves(t *testing.T) {
+	var mu sync.Mutex
+	var events []incidentEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event incidentEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewIncidentNotifier(server.URL, "routing-key")
+	notification := newNotification([]Alert{
+		testAlert("a1", SeverityCritical, false),
+		testAlert("a2", SeverityCritical, true),
+	}, testAlert("", SeverityInfo, false).Timestamp)
+	require.NoError(t, notifier.Notify(context.Background(), notification))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "routing-key", events[0].RoutingKey)
+	assert.Equal(t, "trigger", events[0].EventAction)
+	assert.Equal(t, "a1", events[0].DedupKey)
+	require.NotNil(t, events[0].Payload)
+	assert.Equal(t, SeverityCritical, events[0].Payload.Severity)
+	assert.Equal(t, "slow_db", events[0].Payload.Class)
+	assert.Equal(t, "a1 message", events[0].Payload.Summary)
+
+	assert.Equal(t, "resolve", events[1].EventAction)
+	assert.Equal(t, "a2", events[1].DedupKey)
+	assert.Nil(t, events[1].Payload)
+}
+
+func TestNotifiersFromEnv(t *testing.T) {
+	t.Setenv("SMTP_HOST", "mail.local")
+	t.Setenv("ALERT_EMAIL_TO", "")
+	t.Setenv("ALERT_WEBHOOK_URL", "https://hooks.example.com/services/T000")
+	t.Setenv("ALERT_INCIDENT_ROUTING_KEY", "")
+
+	notifiers := NotifiersFromEnv()
+	require.Len(t, notifiers, 1, "email needs recipients and incidents need a routing key")
+	assert.Equal(t, NotifierWebhook, notifiers[0].Name())
+
+	t.Setenv("ALERT_EMAIL_TO", "ops@example.com, dba@example.com")
+	t.Setenv("ALERT_INCIDENT_ROUTING_KEY", "key")
+	notifiers = NotifiersFromEnv()
+	require.Len(t, notifiers, 3)
+
+	config, ok := EmailConfigFromEnv()
+	require.True(t, ok)
+	assert.Equal(t, []string{"ops@example.com", "dba@example.com"}, config.To)
+	assert.Equal(t, DefaultIncidentURL, notifiers[2].(*IncidentNotifier).url)
+}
@@ -0,0 +1,360 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ethosview-backend/pkg/logging"
+	"ethosview-backend/pkg/metrics"
+)
+
+// Notifier names used in routes
+const (
+	NotifierEmail    = "email"
+	NotifierWebhook  = "webhook"
+	NotifierIncident = "incident"
+)
+
+const (
+	// notifyQueueSize bounds alerts waiting to be grouped
+	notifyQueueSize = 256
+	// notifyTimeout bounds each notifier call
+	notifyTimeout = 10 * time.Second
+)
+
+// Notifier delivers a group of alerts to one destination
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Notification is the group of alerts raised, escalated or resolved within
+// one grouping window that route to a notifier
+type Notification struct {
+	Alerts   []Alert   `json:"alerts"`
+	Severity Severity  `json:"severity"`
+	Firing   int       `json:"firing"`
+	Resolved int       `json:"resolved"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// newNotification groups alerts, firing then resolved and most severe first.
+// Its severity is that of the most severe firing alert, or of the most severe
+// resolved alert when none are firing.
+func newNotification(alerts []Alert, now time.Time) Notification {
+	sorted := append([]Alert(nil), alerts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Resolved != sorted[j].Resolved {
+			return !sorted[i].Resolved
+		}
+		return severityRank[sorted[i].Severity] > severityRank[sorted[j].Severity]
+	})
+
+	n := Notification{Alerts: sorted, SentAt: now}
+	for _, alert := range sorted {
+		if alert.Resolved {
+			n.Resolved++
+		} else {
+			n.Firing++
+		}
+	}
+	if len(sorted) > 0 {
+		n.Severity = sorted[0].Severity
+	}
+	return n
+}
+
+// Title summarises the notification in one line
+func (n Notification) Title() string {
+	switch {
+	case n.Firing == 1 && n.Resolved == 0:
+		return fmt.Sprintf("[%s] %s", strings.ToUpper(string(n.Alerts[0].Severity)), n.Alerts[0].Message)
+	case n.Firing == 0 && n.Resolved == 1:
+		return fmt.Sprintf("[RESOLVED] %s", n.Alerts[0].Message)
+	case n.Resolved == 0:
+		return fmt.Sprintf("[%s] %d alerts firing", strings.ToUpper(string(n.Severity)), n.Firing)
+	case n.Firing == 0:
+		return fmt.Sprintf("[RESOLVED] %d alerts resolved", n.Resolved)
+	}
+	return fmt.Sprintf("[%s] %d alerts firing, %d resolved", strings.ToUpper(string(n.Severity)), n.Firing, n.Resolved)
+}
+
+// RouterConfig configures routing, grouping and rate limiting
+type RouterConfig struct {
+	// Routes lists the notifiers each severity is sent to
+	Routes map[Severity][]string
+	// GroupWindow is how long to collect alerts before notifying; the first
+	// alert in a quiet period opens the window
+	GroupWindow time.Duration
+	// RateLimit is the most notifications each notifier sends per RatePeriod;
+	// 0 disables rate limiting
+	RateLimit  int
+	RatePeriod time.Duration
+}
+
+// DefaultRoutes page for critical alerts, email and post warnings, and only
+// post informational alerts
+func DefaultRoutes() map[Severity][]string {
+	return map[Severity][]string{
+		SeverityCritical: {NotifierEmail, NotifierWebhook, NotifierIncident},
+		SeverityWarning:  {NotifierEmail, NotifierWebhook},
+		SeverityInfo:     {NotifierWebhook},
+	}
+}
+
+// DefaultRouterConfig groups alerts for 30 seconds and sends each notifier at
+// most 20 notifications an hour over the default routes
+func DefaultRouterConfig() RouterConfig {
+	return RouterConfig{
+		Routes:      DefaultRoutes(),
+		GroupWindow: 30 * time.Second,
+		RateLimit:   20,
+		RatePeriod:  time.Hour,
+	}
+}
+
+// RouterConfigFromEnv reads ALERT_ROUTE_CRITICAL, ALERT_ROUTE_WARNING and
+// ALERT_ROUTE_INFO (comma-separated notifier names, empty for none),
+// ALERT_GROUP_WINDOW (default 30s), ALERT_NOTIFY_LIMIT (default 20; 0 for no
+// limit) and ALERT_NOTIFY_PERIOD (default 1h)
+func RouterConfigFromEnv() (RouterConfig, error) {
+	config := DefaultRouterConfig()
+
+	for severity, variable := range map[Severity]string{
+		SeverityCritical: "ALERT_ROUTE_CRITICAL",
+		SeverityWarning:  "ALERT_ROUTE_WARNING",
+		SeverityInfo:     "ALERT_ROUTE_INFO",
+	} {
+		value, set := os.LookupEnv(variable)
+		if !set {
+			continue
+		}
+		names := []string{}
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name != NotifierEmail && name != NotifierWebhook && name != NotifierIncident {
+				return config, fmt.Errorf("%s: unknown notifier %q, expected email, webhook or incident", variable, name)
+			}
+			names = append(names, name)
+		}
+		config.Routes[severity] = names
+	}
+
+	var err error
+	if value := os.Getenv("ALERT_GROUP_WINDOW"); value != "" {
+		if config.GroupWindow, err = time.ParseDuration(value); err != nil || config.GroupWindow < 0 {
+			return config, fmt.Errorf("ALERT_GROUP_WINDOW: invalid duration %q", value)
+		}
+	}
+	if value := os.Getenv("ALERT_NOTIFY_LIMIT"); value != "" {
+		if config.RateLimit, err = strconv.Atoi(value); err != nil || config.RateLimit < 0 {
+			return config, fmt.Errorf("ALERT_NOTIFY_LIMIT: invalid count %q", value)
+		}
+	}
+	if value := os.Getenv("ALERT_NOTIFY_PERIOD"); value != "" {
+		if config.RatePeriod, err = time.ParseDuration(value); err != nil || config.RatePeriod <= 0 {
+			return config, fmt.Errorf("ALERT_NOTIFY_PERIOD: invalid duration %q", value)
+		}
+	}
+	return config, nil
+}
+
+// NotificationRouter groups alert changes from an AlertManager and sends
+// each group to the notifiers routed for its severities, within each
+// notifier's rate limit
+type NotificationRouter struct {
+	config    RouterConfig
+	notifiers map[string]Notifier
+	queue     chan Alert
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	sentAt   map[string][]time.Time
+	now      func() time.Time
+	flushNow chan chan struct{}
+}
+
+// NewNotificationRouter creates a router for the given notifiers; call
+// Subscribe and Run to start notifying. A nil logger uses the default.
+func NewNotificationRouter(config RouterConfig, logger *slog.Logger, notifiers ...Notifier) *NotificationRouter {
+	byName := make(map[string]Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byName[notifier.Name()] = notifier
+	}
+	return &NotificationRouter{
+		config:    config,
+		notifiers: byName,
+		queue:     make(chan Alert, notifyQueueSize),
+		logger:    logging.Or(logger).With("component", "alert_notifier"),
+		sentAt:    make(map[string][]time.Time),
+		now:       time.Now,
+		flushNow:  make(chan chan struct{}),
+	}
+}
+
+// Subscribe queues every alert the manager raises, escalates or resolves.
+// Alerts are dropped, with a log line, if the queue is full.
+func (r *NotificationRouter) Subscribe(am *AlertManager) {
+	am.OnAlert(func(alert Alert) {
+		select {
+		case r.queue <- alert:
+		default:
+			r.logger.Warn("Alert notification queue full; dropping alert", "alert_id", alert.ID)
+		}
+	})
+}
+
+// Run groups queued alerts and sends them until ctx is cancelled, then sends
+// the open group
+func (r *NotificationRouter) Run(ctx context.Context) {
+	var group []Alert
+	var window <-chan time.Time
+
+	for {
+		select {
+		case alert := <-r.queue:
+			group = append(group, alert)
+			if window == nil {
+				window = time.After(r.config.GroupWindow)
+			}
+		case <-window:
+			r.send(ctx, group)
+			group, window = nil, nil
+		case done := <-r.flushNow:
+			r.drain(&group)
+			r.send(ctx, group)
+			group, window = nil, nil
+			close(done)
+		case <-ctx.Done():
+			r.drain(&group)
+			r.send(context.WithoutCancel(ctx), group)
+			return
+		}
+	}
+}
+
+// Flush sends the open group without waiting for its window to close
+func (r *NotificationRouter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case r.flushNow <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain moves queued alerts into the group
+func (r *NotificationRouter) drain(group *[]Alert) {
+	for {
+		select {
+		case alert := <-r.queue:
+			*group = append(*group, alert)
+		default:
+			return
+		}
+	}
+}
+
+// send routes a group to each notifier, keeping only the latest state of each
+// alert
+func (r *NotificationRouter) send(ctx context.Context, group []Alert) {
+	if len(group) == 0 {
+		return
+	}
+	latest := make(map[string]int, len(group))
+	var alerts []Alert
+	for _, alert := range group {
+		if i, ok := latest[alert.ID]; ok {
+			alerts[i] = alert
+			continue
+		}
+		latest[alert.ID] = len(alerts)
+		alerts = append(alerts, alert)
+	}
+
+	names := make([]string, 0, len(r.notifiers))
+	for name := range r.notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var routed []Alert
+		for _, alert := range alerts {
+			if r.routes(alert.Severity, name) {
+				routed = append(routed, alert)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		if !r.allow(name) {
+			metrics.AlertNotifications.WithLabelValues(name, "rate_limited").Inc()
+			r.logger.Warn("Alert notification rate limited", "notifier", name, "alerts", len(routed))
+			continue
+		}
+
+		notification := newNotification(routed, r.now().UTC())
+		notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := r.notifiers[name].Notify(notifyCtx, notification)
+		cancel()
+		if err != nil {
+			metrics.AlertNotifications.WithLabelValues(name, "failed").Inc()
+			r.logger.Error("Failed to send alert notification", "notifier", name, "alerts", len(routed), "error", err)
+			continue
+		}
+		metrics.AlertNotifications.WithLabelValues(name, "sent").Inc()
+		r.logger.Info("Sent alert notification", "notifier", name, "alerts", len(routed), "severity", notification.Severity)
+	}
+}
+
+// routes reports whether alerts of a severity go to a notifier
+func (r *NotificationRouter) routes(severity Severity, notifier string) bool {
+	for _, name := range r.config.Routes[severity] {
+		if name == notifier {
+			return true
+		}
+	}
+	return false
+}
+
+// allow records a send for notifier if it is within the rate limit
+func (r *NotificationRouter) allow(notifier string) bool {
+	if r.config.RateLimit <= 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	cutoff := now.Add(-r.config.RatePeriod)
+	recent := r.sentAt[notifier][:0]
+	for _, at := range r.sentAt[notifier] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= r.config.RateLimit {
+		r.sentAt[notifier] = recent
+		return false
+	}
+	r.sentAt[notifier] = append(recent, now)
+	return true
+}
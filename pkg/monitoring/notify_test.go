@@ -0,0 +1,173 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the notifications it is sent
+type recordingNotifier struct {
+	name string
+	err  error
+
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (n *recordingNotifier) Name() string {
+	return n.name
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return n.err
+}
+
+func (n *recordingNotifier) notifications() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Notification(nil), n.sent...)
+}
+
+func testAlert(id string, severity Severity, resolved bool) Alert {
+	alert := Alert{ID: id, Type: "slow_db", Severity: severity, Status: StatusFiring, Message: id + " message", Timestamp: time.Now()}
+	if resolved {
+		alert.Resolved, alert.Status, alert.ResolvedAt = true, StatusResolved, time.Now()
+	}
+	return alert
+}
+
+func TestNotificationRouterRoutesBySeverity(t *testing.T) {
+	email := &recordingNotifier{name: NotifierEmail}
+	webhook := &recordingNotifier{name: NotifierWebhook}
+	incident := &recordingNotifier{name: NotifierIncident}
+	router := NewNotificationRouter(RouterConfig{Routes: DefaultRoutes()}, nil, email, webhook, incident)
+
+	router.send(context.Background(), []Alert{
+		testAlert("info", SeverityInfo, false),
+		testAlert("warning", SeverityWarning, false),
+		testAlert("critical", SeverityCritical, false),
+	})
+
+	require.Len(t, incident.notifications(), 1)
+	assert.Equal(t, []string{"critical"}, alertIDs(incident.notifications()[0]))
+
+	require.Len(t, email.notifications(), 1)
+	assert.Equal(t, []string{"critical", "warning"}, alertIDs(email.notifications()[0]), "most severe first")
+	assert.Equal(t, SeverityCritical, email.notifications()[0].Severity)
+
+	require.Len(t, webhook.notifications(), 1)
+	assert.Equal(t, []string{"critical", "warning", "info"}, alertIDs(webhook.notifications()[0]))
+	assert.Equal(t, 3, webhook.notifications()[0].Firing)
+}
+
+func TestNotificationRouterKeepsLatestStatePerAlert(t *testing.T) {
+	webhook := &recordingNotifier{name: NotifierWebhook}
+	router := NewNotificationRouter(RouterConfig{Routes: DefaultRoutes()}, nil, webhook)
+
+	router.send(context.Background(), []Alert{
+		testAlert("a", SeverityWarning, false),
+		testAlert("b", SeverityWarning, false),
+		testAlert("a", SeverityCritical, false),
+		testAlert("b", SeverityWarning, true),
+	})
+
+	require.Len(t, webhook.notifications(), 1)
+	notification := webhook.notifications()[0]
+	require.Len(t, notification.Alerts, 2)
+	assert.Equal(t, SeverityCritical, notification.Alerts[0].Severity)
+	assert.True(t, notification.Alerts[1].Resolved, "resolved alerts come last")
+	assert.Equal(t, 1, notification.Firing)
+	assert.Equal(t, 1, notification.Resolved)
+	assert.Equal(t, "[CRITICAL] 1 alerts firing, 1 resolved", notification.Title())
+}
+
+func TestNotificationRouterRateLimit(t *testing.T) {
+	webhook := &recordingNotifier{name: NotifierWebhook}
+	router := NewNotificationRouter(RouterConfig{Routes: DefaultRoutes(), RateLimit: 2, RatePeriod: time.Hour}, nil, webhook)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		router.send(context.Background(), []Alert{testAlert("a", SeverityWarning, false)})
+	}
+	assert.Len(t, webhook.notifications(), 2, "the third notification in the period is dropped")
+
+	now = now.Add(61 * time.Minute)
+	router.send(context.Background(), []Alert{testAlert("a", SeverityWarning, false)})
+	assert.Len(t, webhook.notifications(), 3, "the limit resets once the period passes")
+}
+
+func TestNotificationRouterContinuesAfterFailure(t *testing.T) {
+	email := &recordingNotifier{name: NotifierEmail, err: errors.New("smtp down")}
+	webhook := &recordingNotifier{name: NotifierWebhook}
+	router := NewNotificationRouter(RouterConfig{Routes: DefaultRoutes()}, nil, email, webhook)
+
+	router.send(context.Background(), []Alert{testAlert("a", SeverityWarning, false)})
+
+	assert.Len(t, email.notifications(), 1)
+	assert.Len(t, webhook.notifications(), 1)
+}
+
+func TestNotificationRouterGroupsWithinWindow(t *testing.T) {
+	webhook := &recordingNotifier{name: NotifierWebhook}
+	router := NewNotificationRouter(RouterConfig{Routes: DefaultRoutes(), GroupWindow: time.Hour}, nil, webhook)
+	am, _ := newTestManager(t, nil)
+	router.Subscribe(am)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		router.Run(ctx)
+		close(stopped)
+	}()
+
+	evaluate(am, 300)
+	evaluate(am, 300)
+	evaluate(am, 800)
+	require.NoError(t, router.Flush(context.Background()))
+
+	require.Len(t, webhook.notifications(), 1, "raise and escalation are grouped")
+	assert.Equal(t, SeverityCritical, webhook.notifications()[0].Alerts[0].Severity)
+
+	evaluate(am, 90)
+	cancel()
+	<-stopped
+
+	require.Len(t, webhook.notifications(), 2, "the open group is sent on shutdown")
+	assert.Equal(t, 1, webhook.notifications()[1].Resolved)
+}
+
+func TestRouterConfigFromEnv(t *testing.T) {
+	t.Setenv("ALERT_ROUTE_CRITICAL", "incident, webhook")
+	t.Setenv("ALERT_ROUTE_INFO", "")
+	t.Setenv("ALERT_GROUP_WINDOW", "1m")
+
+	config, err := RouterConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{NotifierIncident, NotifierWebhook}, config.Routes[SeverityCritical])
+	assert.Equal(t, []string{NotifierEmail, NotifierWebhook}, config.Routes[SeverityWarning])
+	assert.Empty(t, config.Routes[SeverityInfo])
+	assert.Equal(t, time.Minute, config.GroupWindow)
+	assert.Equal(t, 20, config.RateLimit)
+
+	t.Setenv("ALERT_ROUTE_WARNING", "sms")
+	_, err = RouterConfigFromEnv()
+	assert.ErrorContains(t, err, `unknown notifier "sms"`)
+}
+
+func alertIDs(notification Notification) []string {
+	ids := make([]string, len(notification.Alerts))
+	for i, alert := range notification.Alerts {
+		ids[i] = alert.ID
+	}
+	return ids
+}
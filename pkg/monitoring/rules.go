@@ -0,0 +1,230 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rule raises an alert when a metric crosses one of its severity tiers for
+// For consecutive evaluations, and resolves it once the metric recovers
+type Rule struct {
+	// Name identifies the rule and becomes the type of its alerts
+	Name string `json:"name"`
+	// Metric is a MonitoringData value such as database.response_time_ms
+	Metric string `json:"metric"`
+	// Operator compares the metric with each tier's threshold: >, >=, < or <=
+	Operator string `json:"operator"`
+	// Tiers are the thresholds per severity; the most severe breached tier wins
+	Tiers []Tier `json:"tiers"`
+	// For is how many consecutive intervals the condition must hold (default 1)
+	For int `json:"for,omitempty"`
+	// Summary describes the metric in alert messages
+	Summary string `json:"summary,omitempty"`
+}
+
+// Tier is the threshold a rule alerts at for one severity
+type Tier struct {
+	Severity  Severity `json:"severity"`
+	Threshold float64  `json:"threshold"`
+}
+
+// rulesFile is the format of ALERT_RULES_FILE
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// severityRank orders severities from least to most severe
+var severityRank = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// MetricNames lists the metrics rules can reference
+func MetricNames() []string {
+	data := &MonitoringData{}
+	names := make([]string, 0)
+	for name := range data.Values() {
+		names = append(names, name)
+	}
+	for name := range data.intervalValues() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values flattens the monitoring data into the metric names rules use.
+// Interval metrics with nothing to measure are left out, so their rules
+// neither raise nor resolve alerts until they have a value again.
+func (d *MonitoringData) Values() map[string]float64 {
+	values := map[string]float64{
+		"database.response_time_ms":   d.DatabaseMetrics.ResponseTimeMs,
+		"database.active_connections": float64(d.DatabaseMetrics.ActiveConnections),
+		"database.slow_queries":       float64(d.DatabaseMetrics.SlowQueries),
+		"database.locks_count":        float64(d.DatabaseMetrics.LocksCount),
+		"cache.used_memory_mb":        d.CacheMetrics.UsedMemoryMB,
+		"cache.connected_clients":     float64(d.CacheMetrics.ConnectedClients),
+		"cache.keys_count":            float64(d.CacheMetrics.KeysCount),
+		"system.memory_usage_percent": d.SystemMetrics.MemoryUsagePercent,
+		"system.goroutines":           float64(d.SystemMetrics.Goroutines),
+	}
+	for name, value := range d.intervalValues() {
+		if value != nil {
+			values[name] = *value
+		}
+	}
+	return values
+}
+
+// intervalValues are the metrics measured since the previous check
+func (d *MonitoringData) intervalValues() map[string]*float64 {
+	return map[string]*float64{
+		"cache.hit_rate":           d.CacheMetrics.HitRate,
+		"app.requests_per_second":  d.AppMetrics.RequestsPerSecond,
+		"app.error_rate_percent":   d.AppMetrics.ErrorRatePercent,
+		"app.avg_response_time_ms": d.AppMetrics.AvgResponseTimeMs,
+	}
+}
+
+// DefaultRules are used when no rules file is configured and the
+// monitoring_rules table is empty
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: string(DatabaseResponseTime), Metric: "database.response_time_ms", Operator: ">", For: 3, Summary: "Database response time (ms)",
+			Tiers: []Tier{{SeverityWarning, 250}, {SeverityCritical, 500}}},
+		{Name: string(DatabaseConnections), Metric: "database.active_connections", Operator: ">", Summary: "Active database connections",
+			Tiers: []Tier{{SeverityWarning, 80}}},
+		{Name: string(QueryPerformance), Metric: "database.slow_queries", Operator: ">", Summary: "Slow queries",
+			Tiers: []Tier{{SeverityWarning, 5}}},
+		{Name: string(CacheHitRate), Metric: "cache.hit_rate", Operator: "<", Summary: "Cache hit rate (%)",
+			Tiers: []Tier{{SeverityWarning, 80}}},
+		{Name: string(MemoryUsage), Metric: "system.memory_usage_percent", Operator: ">", Summary: "Memory usage (%)",
+			Tiers: []Tier{{SeverityWarning, 75}, {SeverityCritical, 85}}},
+		{Name: string(Goroutines), Metric: "system.goroutines", Operator: ">", Summary: "Goroutines",
+			Tiers: []Tier{{SeverityWarning, 1000}}},
+		{Name: string(ErrorRate), Metric: "app.error_rate_percent", Operator: ">", Summary: "Error rate (%)",
+			Tiers: []Tier{{SeverityCritical, 5}}},
+		{Name: string(RequestRate), Metric: "app.requests_per_second", Operator: ">", Summary: "Request rate (req/s)",
+			Tiers: []Tier{{SeverityWarning, 1000}}},
+	}
+}
+
+// LoadRulesFile reads rules from a JSON file of the form {"rules": [...]}
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return file.Rules, nil
+}
+
+// ValidateRules checks every rule and that names are unique
+func ValidateRules(rules []Rule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("duplicate alert rule %q", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return nil
+}
+
+// Validate checks that a rule can be evaluated
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("alert rule name is required")
+	}
+	if !isMetricName(r.Metric) {
+		return fmt.Errorf("alert rule %q: unknown metric %q, expected one of %s", r.Name, r.Metric, strings.Join(MetricNames(), ", "))
+	}
+	switch r.Operator {
+	case ">", ">=", "<", "<=":
+	default:
+		return fmt.Errorf("alert rule %q: operator must be >, >=, < or <=", r.Name)
+	}
+	if len(r.Tiers) == 0 {
+		return fmt.Errorf("alert rule %q: at least one tier is required", r.Name)
+	}
+	seen := make(map[Severity]bool, len(r.Tiers))
+	for _, tier := range r.Tiers {
+		if _, ok := severityRank[tier.Severity]; !ok {
+			return fmt.Errorf("alert rule %q: unknown severity %q", r.Name, tier.Severity)
+		}
+		if seen[tier.Severity] {
+			return fmt.Errorf("alert rule %q: duplicate %s tier", r.Name, tier.Severity)
+		}
+		seen[tier.Severity] = true
+	}
+	if r.For < 0 {
+		return fmt.Errorf("alert rule %q: for must not be negative", r.Name)
+	}
+	return nil
+}
+
+// isMetricName reports whether rules can reference a metric
+func isMetricName(metric string) bool {
+	for _, name := range MetricNames() {
+		if name == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// breached returns the most severe tier the value crosses
+func (r Rule) breached(value float64) (Tier, bool) {
+	var match Tier
+	found := false
+	for _, tier := range r.Tiers {
+		if !compare(value, r.Operator, tier.Threshold) {
+			continue
+		}
+		if !found || severityRank[tier.Severity] > severityRank[match.Severity] {
+			match, found = tier, true
+		}
+	}
+	return match, found
+}
+
+// forIntervals is how many consecutive breaches raise an alert
+func (r Rule) forIntervals() int {
+	if r.For < 1 {
+		return 1
+	}
+	return r.For
+}
+
+// message describes a breach for the alert
+func (r Rule) message(value float64, tier Tier) string {
+	summary := r.Summary
+	if summary == "" {
+		summary = r.Metric
+	}
+	return fmt.Sprintf("%s is %0.2f (%s threshold: %s %0.2f)", summary, value, tier.Severity, r.Operator, tier.Threshold)
+}
+
+func compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package monitoring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRulesAreValid(t *testing.T) {
+	require.NoError(t, ValidateRules(DefaultRules()))
+}
+
+func TestRuleValidate(t *testing.T) {
+	valid := Rule{Name: "slow_db", Metric: "database.response_time_ms", Operator: ">", Tiers: []Tier{{SeverityWarning, 250}}}
+	require.NoError(t, valid.Validate())
+
+	tests := map[string]func(r *Rule){
+		"missing name":      func(r *Rule) { r.Name = " " },
+		"unknown metric":    func(r *Rule) { r.Metric = "database.latency" },
+		"unmeasured metric": func(r *Rule) { r.Metric = "system.cpu_usage_percent" },
+		"bad operator":      func(r *Rule) { r.Operator = "!=" },
+		"no tiers":          func(r *Rule) { r.Tiers = nil },
+		"unknown severity":  func(r *Rule) { r.Tiers = []Tier{{"page", 1}} },
+		"duplicate tier":    func(r *Rule) { r.Tiers = []Tier{{SeverityWarning, 1}, {SeverityWarning, 2}} },
+		"negative for":      func(r *Rule) { r.For = -1 },
+	}
+	for name, mutate := range tests {
+		rule := valid
+		mutate(&rule)
+		assert.Error(t, rule.Validate(), name)
+	}
+
+	assert.Error(t, ValidateRules([]Rule{valid, valid}), "names must be unique")
+}
+
+func TestValuesOmitUnmeasuredIntervalMetrics(t *testing.T) {
+	assert.Contains(t, MetricNames(), "app.error_rate_percent")
+	assert.Contains(t, MetricNames(), "cache.hit_rate")
+
+	data := &MonitoringData{}
+	assert.NotContains(t, data.Values(), "app.error_rate_percent")
+
+	rate := 2.5
+	data.AppMetrics.ErrorRatePercent = &rate
+	assert.Equal(t, 2.5, data.Values()["app.error_rate_percent"])
+}
+
+func TestInfoValue(t *testing.T) {
+	info := "# Stats\r\nkeyspace_hits:90\r\nkeyspace_misses:10\r\n# Memory\r\nused_memory:1048576\r\n"
+	assert.Equal(t, 90.0, infoValue(info, "keyspace_hits"))
+	assert.Equal(t, 1048576.0, infoValue(info, "used_memory"))
+	assert.Zero(t, infoValue(info, "used_memory_rss"))
+}
+
+func TestRuleBreached(t *testing.T) {
+	rule := Rule{Metric: "database.response_time_ms", Operator: ">", Tiers: []Tier{{SeverityCritical, 500}, {SeverityWarning, 250}}}
+
+	_, ok := rule.breached(250)
+	assert.False(t, ok)
+
+	tier, ok := rule.breached(300)
+	require.True(t, ok)
+	assert.Equal(t, SeverityWarning, tier.Severity)
+
+	tier, ok = rule.breached(900)
+	require.True(t, ok)
+	assert.Equal(t, SeverityCritical, tier.Severity, "the most severe breached tier wins")
+
+	low := Rule{Metric: "cache.hit_rate", Operator: "<=", Summary: "Cache hit rate (%)", Tiers: []Tier{{SeverityWarning, 80}}}
+	tier, ok = low.breached(80)
+	require.True(t, ok)
+	assert.Equal(t, "Cache hit rate (%) is 80.00 (warning threshold: <= 80.00)", low.message(80, tier))
+}
+
+func TestLoadRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [
+		{"name": "slow_db", "metric": "database.response_time_ms", "operator": ">", "for": 3,
+		 "tiers": [{"severity": "warning", "threshold": 250}, {"severity": "critical", "threshold": 500}]}
+	]}`), 0o600))
+
+	rules, err := LoadRulesFile(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, 3, rules[0].For)
+	assert.Equal(t, Tier{SeverityCritical, 500}, rules[0].Tiers[1])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [`), 0o600))
+	_, err = LoadRulesFile(path)
+	assert.Error(t, err)
+}
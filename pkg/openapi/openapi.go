@@ -0,0 +1,181 @@
+// Package openapi builds OpenAPI 3.1 documents, deriving schemas from Go
+// types through their json and binding tags.
+package openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.1.0"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	generator  *Generator
+	operations map[string]*Operation
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on a path by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one method on a path
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the request payload by media type
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response payload by media type
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a payload
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// SecurityRequirement names the schemes an operation accepts together
+type SecurityRequirement map[string][]string
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes a way to authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// New creates an empty document
+func New(info Info) *Document {
+	generator := NewGenerator()
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: generator.Schemas()},
+		generator:  generator,
+		operations: make(map[string]*Operation),
+	}
+}
+
+// Schema returns the schema of v's type, adding the structs it uses to the
+// document's components
+func (d *Document) Schema(v interface{}) *Schema {
+	return d.generator.Schema(v)
+}
+
+// AddSecurityScheme registers a security scheme operations can require
+func (d *Document) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	if d.Components.SecuritySchemes == nil {
+		d.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+	d.Components.SecuritySchemes[name] = scheme
+}
+
+// AddOperation documents method on a gin route path such as
+// /companies/:id. Path parameters the operation does not declare are added
+// as strings.
+func (d *Document) AddOperation(method, ginPath string, op *Operation) {
+	path, params := PathTemplate(ginPath)
+	for _, name := range params {
+		if !hasParameter(op.Parameters, name, "path") {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+	d.operations[strings.ToUpper(method)+" "+ginPath] = op
+}
+
+// Operation returns the operation documented for method on a gin route
+// path, or nil
+func (d *Document) Operation(method, ginPath string) *Operation {
+	return d.operations[strings.ToUpper(method)+" "+ginPath]
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// PathTemplate converts a gin route path to an OpenAPI path template,
+// returning the template and its parameter names in order
+func PathTemplate(ginPath string) (string, []string) {
+	var params []string
+	path := ginParam.ReplaceAllStringFunc(ginPath, func(match string) string {
+		params = append(params, match[1:])
+		return "{" + match[1:] + "}"
+	})
+	return path, params
+}
+
+func hasParameter(params []Parameter, name, in string) bool {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return true
+		}
+	}
+	return false
+}
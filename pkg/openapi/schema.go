@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Object describes a JSON object built ad hoc, such as a gin.H response, by
+// example values for its properties
+type Object map[string]interface{}
+
+// Generator derives schemas from Go types. Structs become components
+// referenced by name; json tags name properties and binding tags add
+// required properties and validation limits.
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewGenerator creates a generator with no components
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Schemas returns the components generated so far by name
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Schema returns the schema of v's type, or of its properties for an Object
+func (g *Generator) Schema(v interface{}) *Schema {
+	if object, ok := v.(Object); ok {
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for name, value := range object {
+			schema.Properties[name] = g.Schema(value)
+		}
+		return schema
+	}
+	if v == nil {
+		return &Schema{}
+	}
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	}
+	// Interfaces may hold anything
+	return &Schema{}
+}
+
+// ref returns a reference to the component for a struct, generating it the
+// first time. Anonymous structs are inlined.
+func (g *Generator) ref(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		// Registered before its fields so recursive types terminate
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName names a struct's component after the type, prefixed with its
+// package when another package's type already took the name
+func (g *Generator) componentName(t reflect.Type) string {
+	name := strings.NewReplacer("[", "_", "]", "", "/", "_", ".", "_").Replace(t.Name())
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	runes := []rune(pkg)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes) + name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name contribute their fields
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := g.structSchema(embedded)
+				for property, propertySchema := range inner.Properties {
+					schema.Properties[property] = propertySchema
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() || field.Type.Kind() == reflect.Func || field.Type.Kind() == reflect.Chan {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaOf(field.Type)
+		if applyBinding(property, field.Type, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// applyBinding adds the validation limits of a binding tag to schema and
+// reports whether it makes the field required. Rules after dive apply to
+// elements and are not described.
+func applyBinding(schema *Schema, t reflect.Type, binding string) bool {
+	if binding == "" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, enumValue(t, option))
+			}
+		case "min", "gte":
+			setLimit(schema, t, value, true)
+		case "max", "lte":
+			setLimit(schema, t, value, false)
+		}
+	}
+	return required
+}
+
+// setLimit sets a lower or upper bound on a string's length, an array's size
+// or a number's value
+func setLimit(schema *Schema, t reflect.Type, value string, lower bool) {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return
+		}
+		if t.Kind() == reflect.String {
+			if lower {
+				schema.MinLength = &n
+			} else {
+				schema.MaxLength = &n
+			}
+		} else if lower {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	default:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return
+		}
+		if lower {
+			schema.Minimum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	}
+}
+
+func enumValue(t reflect.Type, option string) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(option, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(option, 64); err == nil {
+			return n
+		}
+	}
+	return option
+}
+
+// nullable allows null in addition to schema
+func nullable(schema *Schema) *Schema {
+	switch typ := schema.Type.(type) {
+	case string:
+		schema.Type = []string{typ, "null"}
+		return schema
+	case nil:
+		if schema.Ref == "" {
+			// Already accepts anything
+			return schema
+		}
+	}
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type holding struct {
+	Symbol string   `json:"symbol" binding:"required"`
+	Weight *float64 `json:"weight"`
+}
+
+type timestamps struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type portfolioRequest struct {
+	timestamps
+	Name     string            `json:"name" binding:"required,max=100"`
+	Email    string            `json:"email" binding:"required,email"`
+	Basis    string            `json:"basis" binding:"omitempty,oneof=quantity weight"`
+	Days     int               `json:"days" binding:"omitempty,min=1,max=3650"`
+	Tags     []string          `json:"tags" binding:"required,min=1"`
+	Holdings []holding         `json:"holdings" binding:"dive"`
+	Parent   *portfolioRequest `json:"parent,omitempty"`
+	Extra    map[string]int    `json:"extra"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestSchemaFromBindingTags(t *testing.T) {
+	g := NewGenerator()
+	ref := g.Schema(portfolioRequest{})
+	assert.Equal(t, "#/components/schemas/portfolioRequest", ref.Ref)
+
+	schema := g.Schemas()["portfolioRequest"]
+	require.NotNil(t, schema)
+	assert.Equal(t, []string{"email", "name", "tags"}, schema.Required)
+	assert.NotContains(t, schema.Properties, "Secret")
+	assert.NotContains(t, schema.Properties, "internal")
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created_at"], "embedded fields are inlined")
+
+	assert.Equal(t, 100, *schema.Properties["name"].MaxLength)
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+	assert.Equal(t, []interface{}{"quantity", "weight"}, schema.Properties["basis"].Enum)
+	assert.Equal(t, 1.0, *schema.Properties["days"].Minimum)
+	assert.Equal(t, 3650.0, *schema.Properties["days"].Maximum)
+	assert.Equal(t, 1, *schema.Properties["tags"].MinItems)
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}}, schema.Properties["extra"])
+
+	assert.Equal(t, "#/components/schemas/holding", schema.Properties["holdings"].Items.Ref)
+	assert.Equal(t, []string{"symbol"}, g.Schemas()["holding"].Required)
+	assert.Equal(t, []string{"number", "null"}, g.Schemas()["holding"].Properties["weight"].Type)
+
+	parent := schema.Properties["parent"]
+	require.Len(t, parent.AnyOf, 2, "recursive pointers are nullable references")
+	assert.Equal(t, "#/components/schemas/portfolioRequest", parent.AnyOf[0].Ref)
+}
+
+func TestSchemaForObjects(t *testing.T) {
+	g := NewGenerator()
+	schema := g.Schema(Object{"items": []holding{}, "count": 0})
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "#/components/schemas/holding", schema.Properties["items"].Items.Ref)
+	assert.Equal(t, "integer", schema.Properties["count"].Type)
+	assert.Equal(t, &Schema{}, g.Schema(nil))
+}
+
+func TestDocumentConvertsGinPaths(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	doc.AddOperation("GET", "/companies/:id/trends/:metric", &Operation{
+		Parameters: []Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}},
+	})
+
+	op := doc.Operation("get", "/companies/:id/trends/:metric")
+	require.NotNil(t, op)
+	require.Contains(t, doc.Paths, "/companies/{id}/trends/{metric}")
+	assert.Same(t, op, doc.Paths["/companies/{id}/trends/{metric}"]["get"])
+	require.Len(t, op.Parameters, 2)
+	assert.Equal(t, "integer", op.Parameters[0].Schema.Type)
+	assert.Equal(t, Parameter{Name: "metric", In: "path", Required: true, Schema: &Schema{Type: "string"}}, op.Parameters[1])
+
+	body, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"openapi":"3.1.0"`)
+}
@@ -5,6 +5,9 @@ import (
 	"regexp"
 	"strings"
 
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/logging"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,7 +23,7 @@ func NewSecurityMiddleware() *SecurityMiddleware {
 	return &SecurityMiddleware{
 		allowedOrigins: []string{"http://localhost:3000", "https://ethosview.com"},
 		allowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		allowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
+		allowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID"},
 	}
 }
 
@@ -159,6 +162,14 @@ func (sm *SecurityMiddleware) XSSProtection() gin.HandlerFunc {
 	}
 }
 
+// CheckOrigin reports whether a WebSocket handshake may proceed. Browsers always
+// send an Origin header, so requests without one come from non-browser clients
+// and are allowed; browser origins must be in the CORS allow-list.
+func (sm *SecurityMiddleware) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || sm.isOriginAllowed(origin)
+}
+
 // isOriginAllowed checks if the origin is in the allowed list
 func (sm *SecurityMiddleware) isOriginAllowed(origin string) bool {
 	for _, allowed := range sm.allowedOrigins {
@@ -232,15 +243,13 @@ func (sm *SecurityMiddleware) containsXSS(input string) bool {
 	return false
 }
 
-// ValidateAPIKey validates API keys
-func (sm *SecurityMiddleware) ValidateAPIKey() gin.HandlerFunc {
+// ValidateAPIKey authenticates requests carrying an X-API-Key header against the
+// key store and attaches the key owner's identity to the context, so downstream
+// rate limiting, permission checks and auditing see the same user as a JWT would.
+// Keys are not accepted in the query string, where they would end up in logs.
+func (sm *SecurityMiddleware) ValidateAPIKey(store auth.APIKeyStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
-		if apiKey == "" {
-			apiKey = c.Query("api_key")
-		}
-
-		// In production, validate against a database or external service
 		if apiKey == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "API key required",
@@ -250,30 +259,64 @@ func (sm *SecurityMiddleware) ValidateAPIKey() gin.HandlerFunc {
 			return
 		}
 
-		// Basic validation (in production, use proper validation)
-		if len(apiKey) < 10 {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid API key",
-				"code":  401,
-			})
+		identity, err := store.AuthenticateAPIKey(c.Request.Context(), apiKey)
+		if err != nil {
+			switch err {
+			case auth.ErrInvalidAPIKey, auth.ErrAPIKeyExpired:
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": err.Error(),
+					"code":  401,
+				})
+			default:
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"error": "Unable to verify API key",
+					"code":  503,
+				})
+			}
 			c.Abort()
 			return
 		}
 
+		c.Set("user_id", identity.UserID)
+		c.Set("user_email", identity.Email)
+		c.Set("user_roles", identity.Roles)
+		c.Set("api_key_id", identity.KeyID)
+		c.Set("api_key_scopes", identity.Scopes)
+		c.Set("auth_method", "api_key")
+
 		c.Next()
 	}
 }
 
-// AuditLog logs security-relevant events
+// AuditLog logs suspicious requests as security events with the request's
+// logger. The event is written once the request completes, so it includes
+// the response status and the principal (user_id and api_key_id) when the
+// request was authenticated.
 func (sm *SecurityMiddleware) AuditLog() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Log suspicious activities
-		if sm.isSuspiciousRequest(c) {
-			// In production, log to a security monitoring system
-			// log.Printf("SUSPICIOUS: IP=%s, UA=%s, Method=%s, Path=%s", c.ClientIP(), c.GetHeader("User-Agent"), c.Request.Method, c.Request.URL.Path)
-		}
+		suspicious := sm.isSuspiciousRequest(c)
 
 		c.Next()
+
+		if !suspicious {
+			return
+		}
+		attrs := []interface{}{
+			"event", "suspicious_request",
+			"client_ip", c.ClientIP(),
+			"user_agent", c.GetHeader("User-Agent"),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"query", logging.RedactQuery(c.Request.URL.Query()),
+			"status", c.Writer.Status(),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+		if keyID, ok := c.Get("api_key_id"); ok {
+			attrs = append(attrs, "api_key_id", keyID)
+		}
+		logging.FromContext(c.Request.Context()).Warn("Suspicious request", attrs...)
 	}
 }
 
@@ -2,10 +2,17 @@ package security
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"ethosview-backend/pkg/auth"
+	"ethosview-backend/pkg/logging"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -280,3 +287,91 @@ func TestSecurityMiddleware_sanitizeString(t *testing.T) {
 		})
 	}
 }
+
+type stubAPIKeyStore struct {
+	identity *auth.APIKeyIdentity
+	err      error
+}
+
+func (s stubAPIKeyStore) AuthenticateAPIKey(ctx context.Context, rawKey string) (*auth.APIKeyIdentity, error) {
+	return s.identity, s.err
+}
+
+func TestSecurityMiddleware_ValidateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	identity := &auth.APIKeyIdentity{KeyID: 7, UserID: 3, Email: "owner@example.com", Roles: []string{auth.RoleAnalyst}, Scopes: []string{auth.PermRunBacktests}}
+
+	tests := []struct {
+		name   string
+		key    string
+		store  stubAPIKeyStore
+		status int
+	}{
+		{"missing key", "", stubAPIKeyStore{identity: identity}, http.StatusUnauthorized},
+		{"valid key", "ev_abc_secret", stubAPIKeyStore{identity: identity}, http.StatusOK},
+		{"invalid key", "ev_abc_wrong", stubAPIKeyStore{err: auth.ErrInvalidAPIKey}, http.StatusUnauthorized},
+		{"expired key", "ev_abc_secret", stubAPIKeyStore{err: auth.ErrAPIKeyExpired}, http.StatusUnauthorized},
+		{"store unavailable", "ev_abc_secret", stubAPIKeyStore{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", NewSecurityMiddleware().ValidateAPIKey(tt.store), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{
+					"user_id":    c.GetInt("user_id"),
+					"api_key_id": c.GetInt("api_key_id"),
+				})
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"user_id":3,"api_key_id":7}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSecurityMiddleware_AuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	sm := NewSecurityMiddleware()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.WithContext(c.Request.Context(), logger.Logger))
+		c.Next()
+	})
+	router.Use(sm.AuditLog())
+	router.GET("/test", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Set("api_key_id", 3)
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/test?sector=Energy", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, buf.String())
+
+	req, _ = http.NewRequest("GET", "/test?q=1%20UNION%20SELECT%20password&token=abc", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "Suspicious request", entry["msg"])
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, float64(7), entry["user_id"])
+	assert.Equal(t, float64(3), entry["api_key_id"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Contains(t, entry["query"], "token=%5BREDACTED%5D")
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ExporterFromEnv builds the exporter chosen by TRACING_EXPORTER:
+//
+//   - otlp: OTLP/HTTP, configured by the standard OTEL_EXPORTER_OTLP_*
+//     variables such as OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_HEADERS
+//   - stdout: JSON lines on standard output
+//   - file: JSON lines appended to TRACING_FILE (default traces.jsonl)
+//   - none: no export; trace IDs are still assigned and propagated
+//
+// When TRACING_EXPORTER is unset it is otlp if an OTLP endpoint is set, else
+// none, which returns a nil exporter.
+func ExporterFromEnv(ctx context.Context) (sdktrace.SpanExporter, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	kind := strings.ToLower(os.Getenv("TRACING_EXPORTER"))
+	if kind == "" {
+		kind = "none"
+		if endpoint != "" {
+			kind = "otlp"
+		}
+	}
+
+	switch kind {
+	case "otlp":
+		if endpoint == "" {
+			return nil, fmt.Errorf("TRACING_EXPORTER=otlp requires OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+		}
+		return otlptracehttp.New(ctx)
+	case "stdout":
+		return NewWriterExporter(os.Stdout)
+	case "file":
+		path := os.Getenv("TRACING_FILE")
+		if path == "" {
+			path = "traces.jsonl"
+		}
+		return NewFileExporter(path)
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown TRACING_EXPORTER %q", kind)
+	}
+}
+
+// NewWriterExporter creates a stdout exporter writing one JSON object per
+// span to w, for reading traces locally without a collector
+func NewWriterExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
+
+// fileExporter closes its file when the exporter shuts down
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+// NewFileExporter creates a stdout exporter appending to the file at path
+func NewFileExporter(path string) (sdktrace.SpanExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := NewWriterExporter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exporter, file: f}, nil
+}
+
+// Shutdown flushes the exporter and closes the file
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if closeErr := e.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// serviceResource names the service ethosview-backend unless
+// OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES say otherwise
+func serviceResource() *resource.Resource {
+	// A malformed OTEL_RESOURCE_ATTRIBUTES is reported as an error alongside a
+	// resource holding the other attributes, which is good enough to export with
+	res, _ := resource.New(context.Background(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(InstrumentationName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	return res
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook adds a client span for each Redis command or pipeline issued with
+// a traced context. Only the command name and first key are recorded, never
+// values.
+type RedisHook struct{}
+
+// NewRedisHook creates a Redis tracing hook; add it with client.AddHook
+func NewRedisHook() RedisHook {
+	return RedisHook{}
+}
+
+// DialHook leaves connection dials untraced
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook traces a single command
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmd)
+		}
+		ctx, span := StartChild(ctx, "redis "+strings.ToUpper(cmd.FullName()), trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, semconv.DBQueryText(redisStatement(cmd))))
+		defer span.End()
+
+		err := next(ctx, cmd)
+		if err != nil && err != redis.Nil {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook traces a pipeline or transaction as one span
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmds)
+		}
+		statements := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			statements[i] = redisStatement(cmd)
+		}
+		ctx, span := StartChild(ctx, "redis pipeline", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBQueryText(strings.Join(statements, "; ")),
+			attribute.Int("db.redis.pipeline_length", len(cmds)),
+		))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		if err != nil && err != redis.Nil {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+// redisStatement is the command name and its first key
+func redisStatement(cmd redis.Cmder) string {
+	args := cmd.Args()
+	name := strings.ToUpper(cmd.Name())
+	if len(args) < 2 {
+		return name
+	}
+	return fmt.Sprintf("%s %v", name, args[1])
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLength bounds the db.query.text attribute
+const maxStatementLength = 2000
+
+// DB wraps a *sql.DB so each query made with a traced context gets a client
+// span named after the calling function, such as
+// CompanyRepository.GetCompanyByID. Arguments are never recorded.
+type DB struct {
+	*sql.DB
+}
+
+// WrapDB wraps db for tracing
+func WrapDB(db *sql.DB) *DB {
+	return &DB{DB: db}
+}
+
+// QueryContext runs a query inside a span. The span covers the round trip
+// that returns the first rows, not the iteration over them.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	endQuery(span, err)
+	return rows, err
+}
+
+// QueryRowContext runs a single-row query inside a span
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	endQuery(span, row.Err())
+	return row
+}
+
+// ExecContext runs a statement inside a span
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	endQuery(span, err)
+	return result, err
+}
+
+// BeginTx starts a transaction whose statements are traced like the DB's
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// Tx wraps a *sql.Tx with the same tracing as DB
+type Tx struct {
+	*sql.Tx
+}
+
+// QueryContext runs a query inside a span
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	endQuery(span, err)
+	return rows, err
+}
+
+// QueryRowContext runs a single-row query inside a span
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	endQuery(span, row.Err())
+	return row
+}
+
+// ExecContext runs a statement inside a span
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	endQuery(span, err)
+	return result, err
+}
+
+// startQuery starts a child span for a statement, named after the function
+// two frames up: the repository method calling DB or Tx
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, noopSpan
+	}
+
+	statement := compactStatement(query)
+	return StartChild(ctx, callerName(3), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation(statement)),
+		semconv.DBQueryText(statement),
+	))
+}
+
+func endQuery(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		recordError(span, err)
+	}
+	span.End()
+}
+
+// recordError marks the span as failed with err as its status message
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// callerName returns the short name of the function skip frames up, turning
+// ethosview-backend/internal/models.(*CompanyRepository).GetCompanyByID into
+// CompanyRepository.GetCompanyByID
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "db.query"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "db.query"
+	}
+
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// compactStatement collapses whitespace and truncates a SQL statement
+func compactStatement(query string) string {
+	statement := strings.Join(strings.Fields(query), " ")
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength] + "..."
+	}
+	return statement
+}
+
+// operation returns the statement's leading keyword, such as SELECT
+func operation(statement string) string {
+	keyword, _, _ := strings.Cut(statement, " ")
+	return strings.ToUpper(keyword)
+}
@@ -0,0 +1,49 @@
+// Package tracing configures the OpenTelemetry SDK and traces the database
+// and Redis calls made while serving a traced request. Spans are exported
+// over OTLP/HTTP, or written locally by the stdout exporter.
+package tracing
+
+import (
+	"context"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies this service's own spans
+const InstrumentationName = "ethosview-backend"
+
+// NewProvider creates a tracer provider exporting to exporter in batches. A
+// nil exporter records and propagates trace context without exporting
+// anything. Traces continue the sampling decision of an incoming parent and
+// sample every new trace.
+func NewProvider(exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(serviceResource())}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// noopSpan is returned for operations that are not traced
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// StartChild starts a span on the provider of the span in ctx. Without a
+// local parent it returns ctx and a no-op span, so calls made outside a traced
+// request or job do not create root spans of their own.
+func StartChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.SpanContext().IsValid() || parent.SpanContext().IsRemote() {
+		return ctx, noopSpan
+	}
+	return parent.TracerProvider().Tracer(InstrumentationName).Start(ctx, name, opts...)
+}
+
+// TraceIDFromContext returns the hex trace ID of the current span, or ""
+func TraceIDFromContext(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStartChildJoinsLocalParent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, server := provider.Tracer("test").Start(context.Background(), "GET /companies/:id")
+	childCtx, child := StartChild(ctx, "CompanyRepository.GetCompanyByID", trace.WithSpanKind(trace.SpanKindClient))
+	recordError(child, errors.New("connection refused"))
+	child.End()
+	server.End()
+
+	assert.Equal(t, server.SpanContext().TraceID().String(), TraceIDFromContext(childCtx))
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection refused", spans[0].Status().Description)
+}
+
+func TestStartChildWithoutParent(t *testing.T) {
+	ctx, span := StartChild(context.Background(), "redis GET")
+	assert.False(t, span.IsRecording(), "no span without a parent")
+	span.End()
+	assert.Empty(t, TraceIDFromContext(ctx))
+
+	remote := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, Remote: true,
+	}))
+	_, span = StartChild(remote, "redis GET")
+	assert.False(t, span.IsRecording(), "a remote parent is continued by the server span, not by queries")
+}
+
+func TestWriterExporter(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "ethosview-test")
+	var buf bytes.Buffer
+	exporter, err := NewWriterExporter(&buf)
+	require.NoError(t, err)
+	provider := NewProvider(exporter)
+
+	ctx, server := provider.Tracer("test").Start(context.Background(), "GET /analytics/sectors/comparisons")
+	_, child := StartChild(ctx, "AnalyticsRepository.GetSectorComparisons")
+	child.End()
+	server.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	output := buf.String()
+	type exported struct {
+		Name        string
+		SpanContext struct{ TraceID, SpanID string }
+		Parent      struct{ SpanID string }
+	}
+	decoder := json.NewDecoder(&buf)
+	var first, second exported
+	require.NoError(t, decoder.Decode(&first))
+	require.NoError(t, decoder.Decode(&second))
+
+	assert.Equal(t, "AnalyticsRepository.GetSectorComparisons", first.Name)
+	assert.Equal(t, second.SpanContext.SpanID, first.Parent.SpanID)
+	assert.Equal(t, server.SpanContext().TraceID().String(), second.SpanContext.TraceID)
+	assert.Contains(t, output, `{"Key":"service.name","Value":{"Type":"STRING","Value":"ethosview-test"}}`)
+}
+
+func TestOTLPExporterFromEnv(t *testing.T) {
+	var request collectortrace.ExportTraceServiceRequest
+	var path, auth string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		proto.Unmarshal(data, &request)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	t.Setenv("TRACING_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer secret")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+
+	exporter, err := ExporterFromEnv(context.Background())
+	require.NoError(t, err)
+	provider := NewProvider(exporter)
+	_, span := provider.Tracer("test").Start(context.Background(), "GET /companies")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "Bearer secret", auth)
+	require.Len(t, request.ResourceSpans, 1)
+	resource := request.ResourceSpans[0]
+	serviceName := ""
+	for _, attribute := range resource.Resource.Attributes {
+		if attribute.Key == "service.name" {
+			serviceName = attribute.Value.GetStringValue()
+		}
+	}
+	assert.Equal(t, "ethosview-backend", serviceName, "the default service name")
+	require.Len(t, resource.ScopeSpans, 1)
+	require.Len(t, resource.ScopeSpans[0].Spans, 1)
+	assert.Equal(t, "GET /companies", resource.ScopeSpans[0].Spans[0].Name)
+}
+
+func TestExporterFromEnv(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TRACING_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	exporter, err := ExporterFromEnv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	_, err = ExporterFromEnv(ctx)
+	assert.Error(t, err, "otlp needs an endpoint")
+
+	t.Setenv("TRACING_EXPORTER", "stdout")
+	exporter, err = ExporterFromEnv(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, exporter)
+
+	t.Setenv("TRACING_EXPORTER", "zipkin")
+	_, err = ExporterFromEnv(ctx)
+	assert.Error(t, err)
+}
+
+func TestCallerName(t *testing.T) {
+	assert.Equal(t, "TestCallerName", callerName(1))
+	func() {
+		assert.Equal(t, "TestCallerName", callerName(1), "closures are named after their function")
+	}()
+	assert.Equal(t, "SELECT", operation(compactStatement("\n\t\tselect id\n\t\tFROM companies  WHERE id = $1")))
+	assert.Equal(t, "select id FROM companies WHERE id = $1", compactStatement("\n\t\tselect id\n\t\tFROM companies  WHERE id = $1"))
+}
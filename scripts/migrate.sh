@@ -24,6 +24,30 @@ psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PAS
 echo "Applying performance optimization migration..."
 psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/003_performance_optimization.sql
 
+echo "Applying user portfolios migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/004_user_portfolios.sql
+
+echo "Applying refresh tokens migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/005_refresh_tokens.sql
+
+echo "Applying user roles migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/006_user_roles.sql
+
+echo "Applying API keys migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/007_api_keys.sql
+
+echo "Applying webhooks migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/008_webhooks.sql
+
+echo "Applying alert rules migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/009_alert_rules.sql
+
+echo "Applying monitoring alerts migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/010_monitoring_alerts.sql
+
+echo "Applying event outbox migration..."
+psql "host=$DB_HOST port=$DB_PORT dbname=$DB_NAME user=$DB_USER password=$DB_PASSWORD" -f scripts/migrations/011_event_outbox.sql
+
 echo "Database migrations completed successfully!"
 
 # Optional: Run seed data
@@ -0,0 +1,13 @@
+-- User Roles Migration
+-- Role-based access control: viewer, analyst, data-editor, admin
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT ARRAY['viewer']::TEXT[];
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_roles_valid;
+ALTER TABLE users ADD CONSTRAINT users_roles_valid
+    CHECK (roles <@ ARRAY['viewer', 'analyst', 'data-editor', 'admin']::TEXT[]);
+
+CREATE INDEX IF NOT EXISTS idx_users_roles ON users USING gin(roles);
+
+-- Grant the first administrator to an existing account with:
+-- go run ./cmd/grant-role -email admin@example.com
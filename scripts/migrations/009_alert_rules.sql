@@ -0,0 +1,47 @@
+-- Alert Rules Migration
+-- User-defined ESG and price alert rules and the notifications they trigger
+
+-- Alert rules apply to one company or to every company on one of the user's watchlists
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('esg_below', 'esg_drop', 'price_drop')),
+    company_id INTEGER REFERENCES companies(id) ON DELETE CASCADE,
+    watchlist_id INTEGER REFERENCES watchlists(id) ON DELETE CASCADE,
+    metric VARCHAR(20) NOT NULL DEFAULT 'overall' CHECK (metric IN ('overall', 'environmental', 'social', 'governance', 'close')),
+    threshold DECIMAL(10,4) NOT NULL,
+    channels TEXT[] NOT NULL DEFAULT ARRAY['websocket'],
+    cooldown_minutes INTEGER NOT NULL DEFAULT 1440 CHECK (cooldown_minutes >= 0),
+    active BOOLEAN NOT NULL DEFAULT true,
+    last_triggered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((company_id IS NULL) <> (watchlist_id IS NULL))
+);
+
+-- Triggered notifications; dedup_key identifies the data that triggered one
+-- (an ESG score or a price date) so the same change never notifies twice
+CREATE TABLE IF NOT EXISTS alert_notifications (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    company_id INTEGER NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    dedup_key VARCHAR(100) NOT NULL,
+    message TEXT NOT NULL,
+    value DECIMAL(20,4) NOT NULL,
+    threshold DECIMAL(10,4) NOT NULL,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    triggered_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rule_id, company_id, dedup_key)
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_alert_rules_user_id ON alert_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_company_id ON alert_rules(company_id) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_alert_rules_watchlist_id ON alert_rules(watchlist_id) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_alert_notifications_rule_company ON alert_notifications(rule_id, company_id, triggered_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_notifications_user_triggered ON alert_notifications(user_id, triggered_at DESC);
+
+-- Add triggers for updated_at
+CREATE TRIGGER update_alert_rules_updated_at BEFORE UPDATE ON alert_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
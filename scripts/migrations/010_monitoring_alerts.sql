@@ -0,0 +1,58 @@
+-- Monitoring Alerts Migration
+-- Configurable system monitoring rules and the alerts they raise, with each
+-- alert's history of state transitions
+
+-- Rules override the built-in defaults when any are enabled; tiers is a JSON
+-- array such as [{"severity": "warning", "threshold": 250}, {"severity": "critical", "threshold": 500}]
+CREATE TABLE IF NOT EXISTS monitoring_rules (
+    name VARCHAR(100) PRIMARY KEY,
+    metric VARCHAR(100) NOT NULL,
+    operator VARCHAR(2) NOT NULL CHECK (operator IN ('>', '>=', '<', '<=')),
+    tiers JSONB NOT NULL,
+    for_intervals INTEGER NOT NULL DEFAULT 1 CHECK (for_intervals >= 1),
+    summary TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Alerts keep their latest state; rule is the name of the rule that raised them
+CREATE TABLE IF NOT EXISTS monitoring_alerts (
+    id VARCHAR(150) PRIMARY KEY,
+    rule VARCHAR(100) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('info', 'warning', 'critical')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('firing', 'acknowledged', 'resolved')),
+    message TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    raised_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    silenced_until TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every raise, severity change, acknowledgement, silence and resolution
+CREATE TABLE IF NOT EXISTS monitoring_alert_transitions (
+    id SERIAL PRIMARY KEY,
+    alert_id VARCHAR(150) NOT NULL REFERENCES monitoring_alerts(id) ON DELETE CASCADE,
+    action VARCHAR(30) NOT NULL CHECK (action IN ('raised', 'severity_changed', 'acknowledged', 'silenced', 'resolved')),
+    status VARCHAR(20) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_monitoring_alerts_open ON monitoring_alerts(raised_at) WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS idx_monitoring_alerts_raised_at ON monitoring_alerts(raised_at DESC);
+CREATE INDEX IF NOT EXISTS idx_monitoring_alerts_silenced ON monitoring_alerts(rule, silenced_until) WHERE silenced_until IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_monitoring_alert_transitions_alert ON monitoring_alert_transitions(alert_id, created_at);
+
+-- Add triggers for updated_at
+CREATE TRIGGER update_monitoring_rules_updated_at BEFORE UPDATE ON monitoring_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_monitoring_alerts_updated_at BEFORE UPDATE ON monitoring_alerts FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
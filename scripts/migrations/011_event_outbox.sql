@@ -0,0 +1,12 @@
+-- Event Outbox Migration
+-- Domain events recorded in the same transaction as the write they describe.
+-- The webhook dispatcher turns each into deliveries and deletes it in one
+-- statement, so an event is delivered if and only if its write committed.
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
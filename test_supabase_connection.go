@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -28,7 +29,7 @@ func main() {
 	companyRepo := models.NewCompanyRepository(db)
 
 	// Test getting companies
-	companies, err := companyRepo.ListCompanies(5, 0, "")
+	companies, err := companyRepo.ListCompanies(context.Background(), 5, 0, "")
 	if err != nil {
 		log.Printf("❌ Failed to get companies: %v", err)
 	} else {
@@ -42,7 +43,7 @@ func main() {
 	esgRepo := models.NewESGScoreRepository(db)
 
 	// Test getting ESG scores
-	esgScores, err := esgRepo.ListESGScores(5, 0, 0)
+	esgScores, err := esgRepo.ListESGScores(context.Background(), 5, 0, 0)
 	if err != nil {
 		log.Printf("❌ Failed to get ESG scores: %v", err)
 	} else {